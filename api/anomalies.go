@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/constructor"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/utils"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+)
+
+func (api *Api) Anomalies(w http.ResponseWriter, r *http.Request, u *db.User) {
+	projectId := mux.Vars(r)["project"]
+	if !api.IsAllowed(u, rbac.Actions.Project(projectId).Anomalies().View()) {
+		http.Error(w, "You are not allowed to view anomalies.", http.StatusForbidden)
+		return
+	}
+	world, project, cacheStatus, err := api.LoadWorldByRequest(r, constructor.OptionLoadAnomalyHistory)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if project == nil || world == nil {
+		utils.WriteJson(w, api.WithContext(project, cacheStatus, world, nil))
+		return
+	}
+
+	auditor.Audit(world, project, nil, project.ClickHouseConfig(api.globalClickHouse) != nil, nil)
+
+	anomalies := make([]*model.Anomaly, 0)
+	for _, app := range world.Applications {
+		anomalies = append(anomalies, app.Anomalies...)
+	}
+	sort.Slice(anomalies, func(i, j int) bool {
+		ai, aj := anomalies[i], anomalies[j]
+		if ai.Severity != aj.Severity {
+			return ai.Severity > aj.Severity
+		}
+		return ai.Score > aj.Score
+	})
+	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, anomalies))
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/coroot/coroot/collector"
 	"github.com/coroot/coroot/constructor"
 	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/exporter"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/prom"
 	"github.com/coroot/coroot/rbac"
@@ -49,7 +50,8 @@ type Api struct {
 	deploymentUuid string
 	instanceUuid   string
 
-	loadWorld LoadWorldF
+	loadWorld        LoadWorldF
+	metricsSnapshots *exporter.Snapshots
 }
 
 func NewApi(cache *cache.Cache, db *db.DB, collector *collector.Collector, pricing *pricing.Manager, roles rbac.RoleManager, licenseMgr LicenseManager,
@@ -71,6 +73,11 @@ func NewApi(cache *cache.Cache, db *db.DB, collector *collector.Collector, prici
 	}
 }
 
+// SetMetricsSnapshots lets the metrics endpoint reuse the snapshots of the worlds audited by the watchers.
+func (api *Api) SetMetricsSnapshots(snapshots *exporter.Snapshots) {
+	api.metricsSnapshots = snapshots
+}
+
 func (api *Api) User(w http.ResponseWriter, r *http.Request, u *db.User) {
 	if r.Method == http.MethodPost {
 		if u.Anonymous {
@@ -311,6 +318,9 @@ func (api *Api) Project(w http.ResponseWriter, r *http.Request, u *db.User) {
 			http.Error(w, "", http.StatusInternalServerError)
 			return
 		}
+		if api.metricsSnapshots != nil {
+			api.metricsSnapshots.Delete(db.ProjectId(projectId))
+		}
 		http.Error(w, "", http.StatusOK)
 
 	default:
@@ -667,6 +677,72 @@ func (api *Api) ApplicationCategories(w http.ResponseWriter, r *http.Request, u
 	utils.WriteJson(w, forms.ApplicationCategoryForm{Id: category.Name, ApplicationCategory: *category})
 }
 
+func (api *Api) ProjectClusters(w http.ResponseWriter, r *http.Request, u *db.User) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodPost {
+		if !api.IsAllowed(u, rbac.Actions.Project(string(projectId)).Settings().Edit()) {
+			http.Error(w, "You are not allowed to configure the project.", http.StatusForbidden)
+			return
+		}
+		if project.Settings.Readonly {
+			http.Error(w, "This project is defined through the config and cannot be modified via the UI.", http.StatusForbidden)
+			return
+		}
+		var form forms.ProjectClustersForm
+		if err = forms.ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid clusters", http.StatusBadRequest)
+			return
+		}
+		for _, id := range form.Clusters {
+			member, err := api.db.GetProject(id)
+			if err != nil {
+				if errors.Is(err, db.ErrNotFound) {
+					http.Error(w, fmt.Sprintf("Project %s not found.", id), http.StatusBadRequest)
+					return
+				}
+				klog.Errorln(err)
+				http.Error(w, "", http.StatusInternalServerError)
+				return
+			}
+			if member.Id == project.Id || member.Multicluster() {
+				http.Error(w, fmt.Sprintf("Project %s can't be a cluster of this project.", member.Name), http.StatusBadRequest)
+				return
+			}
+		}
+		project.Settings.Clusters = form.Clusters
+		if err = api.db.SaveProjectSettings(project); err != nil {
+			klog.Errorln("failed to save:", err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		return
+	}
+
+	type Cluster struct {
+		Id   db.ProjectId `json:"id"`
+		Name string       `json:"name"`
+	}
+	members, err := api.db.GetClusters(project)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	res := make([]Cluster, 0, len(members))
+	for _, member := range members {
+		res = append(res, Cluster{Id: member.Id, Name: member.Name})
+	}
+	utils.WriteJson(w, res)
+}
+
 func (api *Api) CustomApplications(w http.ResponseWriter, r *http.Request, u *db.User) {
 	vars := mux.Vars(r)
 	projectId := vars["project"]
@@ -785,6 +861,35 @@ func (api *Api) Integrations(w http.ResponseWriter, r *http.Request, u *db.User)
 	})
 }
 
+func (api *Api) WebhookTestSend(w http.ResponseWriter, r *http.Request, u *db.User) {
+	projectId := mux.Vars(r)["project"]
+	if !api.IsAllowed(u, rbac.Actions.Project(projectId).Integrations().Edit()) {
+		http.Error(w, "You are not allowed to configure integrations.", http.StatusForbidden)
+		return
+	}
+	project, err := api.db.GetProject(db.ProjectId(projectId))
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	var form forms.WebhookTestSendForm
+	if err = forms.ReadAndValidate(r, &form); err != nil {
+		klog.Warningln("bad request:", err)
+		http.Error(w, "invalid data", http.StatusBadRequest)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+	res, err := form.Send(ctx, project)
+	if err != nil {
+		klog.Warningln("failed to send test webhook:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	utils.WriteJson(w, res)
+}
+
 func (api *Api) Integration(w http.ResponseWriter, r *http.Request, u *db.User) {
 	vars := mux.Vars(r)
 	projectId := vars["project"]
@@ -822,11 +927,7 @@ func (api *Api) Integration(w http.ResponseWriter, r *http.Request, u *db.User)
 			cfg := project.ClickHouseConfig(api.globalClickHouse)
 			var ci *clickhouse.ClusterInfo
 			if cfg != nil {
-				config := clickhouse.NewClientConfig(cfg.Addr, cfg.Auth.User, cfg.Auth.Password)
-				config.Protocol = cfg.Protocol
-				config.Database = cfg.Database
-				config.TlsEnable = cfg.TlsEnable
-				config.TlsSkipVerify = cfg.TlsSkipVerify
+				config := api.clickhouseClientConfig(cfg)
 				cInfo, err := api.collector.GetClickhouseClusterInfo(project)
 				if err != nil {
 					klog.Errorln(err)
@@ -913,13 +1014,7 @@ func (api *Api) Prom(w http.ResponseWriter, r *http.Request, u *db.User) {
 		http.Error(w, "", http.StatusInternalServerError)
 		return
 	}
-	p := project.PrometheusConfig(api.globalPrometheus)
-	cfg := prom.NewClientConfig(p.Url, p.RefreshInterval)
-	cfg.BasicAuth = p.BasicAuth
-	cfg.TlsSkipVerify = p.TlsSkipVerify
-	cfg.ExtraSelector = p.ExtraSelector
-	cfg.CustomHeaders = p.CustomHeaders
-	c, err := prom.NewClient(cfg)
+	c, err := api.promClient(project)
 	if err != nil {
 		klog.Errorln(err)
 		http.Error(w, "", http.StatusInternalServerError)
@@ -928,6 +1023,19 @@ func (api *Api) Prom(w http.ResponseWriter, r *http.Request, u *db.User) {
 	c.Proxy(r, w)
 }
 
+func (api *Api) promClient(project *db.Project) (*prom.Client, error) {
+	p := project.PrometheusConfig(api.globalPrometheus)
+	if p.Url == "" {
+		return nil, fmt.Errorf("prometheus is not configured")
+	}
+	cfg := prom.NewClientConfig(p.Url, p.RefreshInterval)
+	cfg.BasicAuth = p.BasicAuth
+	cfg.TlsSkipVerify = p.TlsSkipVerify
+	cfg.ExtraSelector = p.ExtraSelector
+	cfg.CustomHeaders = p.CustomHeaders
+	return prom.NewClient(cfg)
+}
+
 func (api *Api) Application(w http.ResponseWriter, r *http.Request, u *db.User) {
 	projectId := mux.Vars(r)["project"]
 	appId, err := GetApplicationId(r)
@@ -961,6 +1069,7 @@ func (api *Api) Application(w http.ResponseWriter, r *http.Request, u *db.User)
 	auditor.Audit(world, project, app, project.ClickHouseConfig(api.globalClickHouse) != nil, nil)
 
 	if project.ClickHouseConfig(api.globalClickHouse) != nil {
+		api.addClickHouseStorageInfo(r.Context(), project, app)
 		app.AddReport(model.AuditReportProfiling, &model.Widget{Profiling: &model.Profiling{ApplicationId: app.Id}, Width: "100%"})
 		app.AddReport(model.AuditReportTracing, &model.Widget{Tracing: &model.Tracing{ApplicationId: app.Id}, Width: "100%"})
 	}
@@ -1319,15 +1428,16 @@ func (api *Api) Profiling(w http.ResponseWriter, r *http.Request, u *db.User) {
 		http.Error(w, "Application not found", http.StatusNotFound)
 		return
 	}
+	chProject := api.clusterProject(project, app.Id)
 	var ch *clickhouse.Client
-	if ch, err = api.GetClickhouseClient(project); err != nil {
+	if ch, err = api.GetClickhouseClient(chProject); err != nil {
 		klog.Warningln(err)
 		http.Error(w, "ClickHouse is not available", http.StatusInternalServerError)
 		return
 	}
 	defer ch.Close()
 	q := r.URL.Query()
-	auditor.Audit(world, project, nil, project.ClickHouseConfig(api.globalClickHouse) != nil, nil)
+	auditor.Audit(world, project, nil, chProject.ClickHouseConfig(api.globalClickHouse) != nil, nil)
 	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, views.Profiling(r.Context(), ch, app, q, world)))
 }
 
@@ -1376,14 +1486,15 @@ func (api *Api) Tracing(w http.ResponseWriter, r *http.Request, u *db.User) {
 		return
 	}
 	q := r.URL.Query()
+	chProject := api.clusterProject(project, app.Id)
 	var ch *clickhouse.Client
-	if ch, err = api.GetClickhouseClient(project); err != nil {
+	if ch, err = api.GetClickhouseClient(chProject); err != nil {
 		klog.Warningln(err)
 		http.Error(w, "ClickHouse is not available", http.StatusInternalServerError)
 		return
 	}
 	defer ch.Close()
-	auditor.Audit(world, project, nil, project.ClickHouseConfig(api.globalClickHouse) != nil, nil)
+	auditor.Audit(world, project, nil, chProject.ClickHouseConfig(api.globalClickHouse) != nil, nil)
 	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, views.Tracing(r.Context(), ch, app, q, world)))
 }
 
@@ -1431,12 +1542,13 @@ func (api *Api) Logs(w http.ResponseWriter, r *http.Request, u *db.User) {
 		http.Error(w, "Application not found", http.StatusNotFound)
 		return
 	}
-	ch, chErr := api.GetClickhouseClient(project)
+	chProject := api.clusterProject(project, app.Id)
+	ch, chErr := api.GetClickhouseClient(chProject)
 	if chErr != nil {
 		klog.Warningln(chErr)
 	}
 	defer ch.Close()
-	auditor.Audit(world, project, nil, project.ClickHouseConfig(api.globalClickHouse) != nil, nil)
+	auditor.Audit(world, project, nil, chProject.ClickHouseConfig(api.globalClickHouse) != nil, nil)
 	q := r.URL.Query()
 	res := views.Logs(r.Context(), ch, app, q, world)
 	if chErr != nil {
@@ -1555,12 +1667,20 @@ func (api *Api) Node(w http.ResponseWriter, r *http.Request, u *db.User) {
 	utils.WriteJson(w, api.WithContext(project, cacheStatus, world, auditor.AuditNode(world, node)))
 }
 
-func (api *Api) LoadWorld(ctx context.Context, project *db.Project, from, to timeseries.Time) (*model.World, *cache.Status, error) {
+func (api *Api) LoadWorld(ctx context.Context, project *db.Project, from, to timeseries.Time, options ...constructor.Option) (*model.World, *cache.Status, error) {
 	if api.loadWorld != nil {
 		w, err := api.loadWorld(ctx, project, from, to)
 		return w, &cache.Status{}, err
 	}
 
+	if snap := project.Settings.Snapshot; snap != nil {
+		from, to = snapshotTimeRange(snap, from, to)
+	}
+
+	if project.Multicluster() {
+		return api.loadMulticlusterWorld(ctx, project, from, to, options...)
+	}
+
 	cacheClient := api.cache.GetCacheClient(project.Id)
 
 	cacheStatus, err := cacheClient.GetStatus()
@@ -1587,12 +1707,97 @@ func (api *Api) LoadWorld(ctx context.Context, project *db.Project, from, to tim
 	}
 	step = increaseStepForBigDurations(from, to, step)
 
-	ctr := constructor.New(api.db, project, cacheClient, api.pricing)
+	ctr := constructor.New(api.db, project, cacheClient, api.pricing, options...)
+	ch, err := api.GetClickhouseClient(project)
+	if err != nil {
+		klog.Warningln(err)
+	} else if ch != nil {
+		defer ch.Close()
+		ctr.WithTraces(ch)
+	}
 	world, err := ctr.LoadWorld(ctx, from, to, step, nil)
 	return world, cacheStatus, err
 }
 
-func (api *Api) LoadWorldByRequest(r *http.Request) (*model.World, *db.Project, *cache.Status, error) {
+// loadMulticlusterWorld merges the worlds of the member projects. The time range is limited by the cluster
+// whose cache lags behind the most, and the status reports the worst of the cache statuses.
+func (api *Api) loadMulticlusterWorld(ctx context.Context, project *db.Project, from, to timeseries.Time, options ...constructor.Option) (*model.World, *cache.Status, error) {
+	members, err := api.db.GetClusters(project)
+	if err != nil {
+		return nil, nil, err
+	}
+	cacheStatus := &cache.Status{}
+	var clusters []constructor.Cluster
+	var cacheTo timeseries.Time
+	var step timeseries.Duration
+	for _, member := range members {
+		cacheClient := api.cache.GetCacheClient(member.Id)
+		status, err := cacheClient.GetStatus()
+		if err != nil {
+			return nil, nil, err
+		}
+		if status.Error != "" && cacheStatus.Error == "" {
+			cacheStatus.Error = fmt.Sprintf("%s: %s", member.Name, status.Error)
+		}
+		cacheStatus.LagMax = max(cacheStatus.LagMax, status.LagMax)
+		cacheStatus.LagAvg = max(cacheStatus.LagAvg, status.LagAvg)
+		memberTo, err := cacheClient.GetTo()
+		if err != nil {
+			return nil, cacheStatus, err
+		}
+		if memberTo.IsZero() || memberTo.Before(from) {
+			continue
+		}
+		memberStep, err := cacheClient.GetStep(from, to)
+		if err != nil {
+			return nil, cacheStatus, err
+		}
+		if cacheTo.IsZero() || memberTo.Before(cacheTo) {
+			cacheTo = memberTo
+		}
+		step = max(step, memberStep)
+		cl := constructor.Cluster{Project: member, Cache: cacheClient}
+		if ch, err := api.GetClickhouseClient(member); err != nil {
+			klog.Warningln(err)
+		} else if ch != nil {
+			defer ch.Close()
+			cl.Traces = ch
+		}
+		clusters = append(clusters, cl)
+	}
+	if len(clusters) == 0 {
+		return nil, cacheStatus, nil
+	}
+	if cacheTo.Before(to) {
+		to = cacheTo
+	}
+	step = increaseStepForBigDurations(from, to, step)
+
+	ctr := constructor.New(api.db, project, nil, api.pricing, options...)
+	world, err := ctr.LoadMulticlusterWorld(ctx, clusters, from, to, step, nil)
+	return world, cacheStatus, err
+}
+
+// clusterProject returns the member project the application belongs to, so that its ClickHouse can be queried.
+// For the regular projects, it returns the project itself.
+func (api *Api) clusterProject(project *db.Project, appId model.ApplicationId) *db.Project {
+	if !project.Multicluster() || appId.ClusterId == "" {
+		return project
+	}
+	members, err := api.db.GetClusters(project)
+	if err != nil {
+		klog.Errorln(err)
+		return project
+	}
+	for _, member := range members {
+		if string(member.Id) == appId.ClusterId {
+			return member
+		}
+	}
+	return project
+}
+
+func (api *Api) LoadWorldByRequest(r *http.Request, options ...constructor.Option) (*model.World, *db.Project, *cache.Status, error) {
 	projectId := db.ProjectId(mux.Vars(r)["project"])
 	project, err := api.db.GetProject(projectId)
 	if err != nil {
@@ -1604,7 +1809,7 @@ func (api *Api) LoadWorldByRequest(r *http.Request) (*model.World, *db.Project,
 	}
 
 	from, to, _ := api.getTimeContext(r)
-	world, cacheStatus, err := api.LoadWorld(r.Context(), project, from, to)
+	world, cacheStatus, err := api.LoadWorld(r.Context(), project, from, to, options...)
 	if world == nil {
 		step := increaseStepForBigDurations(from, to, 15*timeseries.Second)
 		world = model.NewWorld(from, to.Add(-step), step, step)
@@ -1667,16 +1872,11 @@ func (api *Api) GetClickhouseClient(project *db.Project) (*clickhouse.Client, er
 	if cfg == nil {
 		return nil, nil
 	}
-	config := clickhouse.NewClientConfig(cfg.Addr, cfg.Auth.User, cfg.Auth.Password)
-	config.Protocol = cfg.Protocol
-	config.Database = cfg.Database
-	config.TlsEnable = cfg.TlsEnable
-	config.TlsSkipVerify = cfg.TlsSkipVerify
 	clusterInfo, err := api.collector.GetClickhouseClusterInfo(project)
 	if err != nil {
 		return nil, err
 	}
-	return clickhouse.NewClient(config, clusterInfo)
+	return clickhouse.NewClient(api.clickhouseClientConfig(cfg), clusterInfo)
 }
 
 func GetApplicationId(r *http.Request) (model.ApplicationId, error) {
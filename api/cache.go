@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/coroot/coroot/api/forms"
+	"github.com/coroot/coroot/cache"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/utils"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+)
+
+func (api *Api) CacheBackfill(w http.ResponseWriter, r *http.Request, u *db.User) {
+	vars := mux.Vars(r)
+	projectId := db.ProjectId(vars["project"])
+	if !api.IsAllowed(u, rbac.Actions.Project(string(projectId)).Settings().Edit()) {
+		http.Error(w, "You are not allowed to manage the cache.", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		status, err := api.cache.GetCacheClient(projectId).GetStatus()
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		res := struct {
+			Jobs     []*cache.BackfillJob    `json:"jobs"`
+			Progress *cache.BackfillProgress `json:"progress"`
+		}{
+			Jobs:     api.cache.GetBackfillJobs(projectId),
+			Progress: status.Backfill,
+		}
+		utils.WriteJson(w, res)
+
+	case http.MethodPost:
+		var form forms.CacheBackfillForm
+		if err := forms.ReadAndValidate(r, &form); err != nil {
+			klog.Warningln("bad request:", err)
+			http.Error(w, "Invalid time range.", http.StatusBadRequest)
+			return
+		}
+		job, err := api.cache.Backfill(projectId, cache.BackfillRequest{
+			Queries:   form.Queries,
+			From:      form.FromTs,
+			To:        form.ToTs,
+			Recompute: form.Recompute,
+		})
+		if err != nil {
+			switch {
+			case errors.Is(err, cache.ErrUnknownQuery):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, db.ErrNotFound):
+				http.Error(w, "Project not found.", http.StatusNotFound)
+			default:
+				klog.Errorln(err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		utils.WriteJson(w, job)
+
+	case http.MethodDelete:
+		if err := api.cache.CancelBackfill(projectId, vars["job"]); err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "Job not found.", http.StatusNotFound)
+				return
+			}
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/coroot/coroot/clickhouse"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+)
+
+func (api *Api) clickhouseClientConfig(cfg *db.IntegrationClickhouse) clickhouse.ClientConfig {
+	config := clickhouse.NewClientConfig(cfg.Addr, cfg.Auth.User, cfg.Auth.Password)
+	config.Protocol = cfg.Protocol
+	config.Database = cfg.Database
+	config.TlsEnable = cfg.TlsEnable
+	config.TlsSkipVerify = cfg.TlsSkipVerify
+	return config
+}
+
+// addClickHouseStorageInfo adds the table sizes and disks of the ClickHouse cluster Coroot stores its telemetry in
+// to the ClickHouse report if the application is this cluster.
+func (api *Api) addClickHouseStorageInfo(ctx context.Context, project *db.Project, app *model.Application) {
+	var report *model.AuditReport
+	for _, r := range app.Reports {
+		if r.Name == model.AuditReportClickHouse {
+			report = r
+		}
+	}
+	if report == nil {
+		return
+	}
+	cfg := project.ClickHouseConfig(api.globalClickHouse)
+	if cfg == nil || !isClickHouseStorage(ctx, app, cfg.Addr) {
+		return
+	}
+	cInfo, err := api.collector.GetClickhouseClusterInfo(project)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	ci, err := clickhouse.GetClusterInfo(ctx, api.clickhouseClientConfig(cfg), cInfo)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+
+	tables := report.GetOrCreateTable("Telemetry table", "Size", "Compression ratio", "TTL")
+	for _, t := range ci.TableSizes {
+		size, unit := utils.FormatBytes(float32(t.BytesOnDisk))
+		tables.AddRow(
+			model.NewTableCell(t.Table),
+			model.NewTableCell(size).SetUnit(unit),
+			model.NewTableCell(fmt.Sprintf("%.1f", t.CompressionRatio)),
+			model.NewTableCell(t.TTLInfo),
+		)
+	}
+	disks := report.GetOrCreateTable("Storage disk", "Server", "Type", "Used", "Free", "Total")
+	for _, s := range ci.ServerDisks {
+		if s.Error != "" {
+			disks.AddRow(model.NewTableCell(), model.NewTableCell(s.Addr), model.NewTableCell().SetStatus(model.WARNING, s.Error))
+			continue
+		}
+		for _, d := range s.Disks {
+			free, freeUnit := utils.FormatBytes(float32(d.FreeSpace))
+			total, totalUnit := utils.FormatBytes(float32(d.TotalSpace))
+			disks.AddRow(
+				model.NewTableCell(d.Name).AddTag(d.Path),
+				model.NewTableCell(s.Addr),
+				model.NewTableCell(d.Type),
+				model.NewTableCell(fmt.Sprintf("%.0f", d.UsagePercent())).SetUnit("%"),
+				model.NewTableCell(free).SetUnit(freeUnit),
+				model.NewTableCell(total).SetUnit(totalUnit),
+			)
+		}
+	}
+}
+
+// isClickHouseStorage reports whether the address Coroot connects to ClickHouse at belongs to the application:
+// either to one of its instances or to one of its Kubernetes services.
+func isClickHouseStorage(ctx context.Context, app *model.Application, addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	ips := []string{host}
+	if net.ParseIP(host) == nil {
+		if ips, err = net.DefaultResolver.LookupHost(ctx, host); err != nil {
+			return false
+		}
+	}
+	for _, ip := range ips {
+		for _, s := range app.KubernetesServices {
+			if s.ClusterIP == ip {
+				return true
+			}
+		}
+		for _, i := range app.Instances {
+			for l := range i.TcpListens {
+				if l.IP == ip {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
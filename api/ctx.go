@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/coroot/coroot/cache"
 	"github.com/coroot/coroot/db"
@@ -19,6 +20,7 @@ type Context struct {
 	Search    Search                            `json:"search"`
 	Incidents map[model.ApplicationCategory]int `json:"incidents"`
 	Fluxcd    bool                              `json:"fluxcd"`
+	Argocd    bool                              `json:"argocd"`
 	License   *License                          `json:"license,omitempty"`
 }
 
@@ -78,6 +80,7 @@ func (api *Api) WithContext(p *db.Project, cacheStatus *cache.Status, w *model.W
 			Search:    renderSearch(w),
 			Incidents: renderIncidents(w),
 			Fluxcd:    w != nil && w.Flux != nil,
+			Argocd:    w != nil && w.ArgoCD != nil,
 		},
 		Data: data,
 	}
@@ -127,7 +130,11 @@ func renderStatus(p *db.Project, cacheStatus *cache.Status, w *model.World, glob
 		refreshInterval = cache.MinRefreshInterval
 	}
 	switch {
-	case promCfg.Url == "":
+	case p.Settings.Snapshot != nil:
+		snap := p.Settings.Snapshot
+		res.Prometheus.Status = model.INFO
+		res.Prometheus.Message = fmt.Sprintf("This is a read-only snapshot of %s taken at %s.", snap.SourceProjectName, snap.Created.ToStandard().Format(time.RFC3339))
+	case promCfg.Url == "" && !p.Multicluster():
 		res.Prometheus.Status = model.WARNING
 		res.Prometheus.Message = "Prometheus is not configured."
 		res.Prometheus.Action = "configure"
@@ -141,6 +148,10 @@ func renderStatus(p *db.Project, cacheStatus *cache.Status, w *model.World, glob
 		res.Prometheus.Status = model.WARNING
 		res.Prometheus.Message = fmt.Sprintf("The Prometheus cache lag is %s, likely due to a restart or upgrade. Synchronization is in progress.", lag)
 		res.Prometheus.Action = "wait"
+	case cacheStatus != nil && cacheStatus.Backfill != nil && cacheStatus.Backfill.ChunksTotal > 0:
+		b := cacheStatus.Backfill
+		res.Prometheus.Status = model.INFO
+		res.Prometheus.Message = fmt.Sprintf("Backfilling historical data: %d%% done.", b.ChunksDone*100/b.ChunksTotal)
 	}
 
 	if res.Prometheus.Status >= model.WARNING {
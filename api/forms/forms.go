@@ -53,6 +53,21 @@ func (f *ProjectForm) Valid() bool {
 	return true
 }
 
+type ProjectClustersForm struct {
+	Clusters []db.ProjectId `json:"clusters"`
+}
+
+func (f *ProjectClustersForm) Valid() bool {
+	seen := map[db.ProjectId]bool{}
+	for _, id := range f.Clusters {
+		if id == "" || seen[id] {
+			return false
+		}
+		seen[id] = true
+	}
+	return true
+}
+
 type ApiKeyForm struct {
 	Action string `json:"action"`
 	db.ApiKey
@@ -86,7 +101,12 @@ type CheckConfigSLOAvailabilityForm struct {
 
 func (f *CheckConfigSLOAvailabilityForm) Valid() bool {
 	for _, c := range f.Configs {
-		if c.Custom && (c.TotalRequestsQuery == "" || c.FailedRequestsQuery == "") {
+		switch {
+		case c.FromSpans():
+			if !validSLOSpans(c.Spans) {
+				return false
+			}
+		case c.Custom && (c.TotalRequestsQuery == "" || c.FailedRequestsQuery == ""):
 			return false
 		}
 	}
@@ -100,13 +120,28 @@ type CheckConfigSLOLatencyForm struct {
 
 func (f *CheckConfigSLOLatencyForm) Valid() bool {
 	for _, c := range f.Configs {
-		if c.Custom && (c.HistogramQuery == "" || c.ObjectiveBucket <= 0) {
+		switch {
+		case c.Custom && c.ObjectiveBucket <= 0:
+			return false
+		case c.FromSpans():
+			if !validSLOSpans(c.Spans) {
+				return false
+			}
+		case c.Custom && c.HistogramQuery == "":
 			return false
 		}
 	}
 	return true
 }
 
+func validSLOSpans(s *model.CheckConfigSLOSpans) bool {
+	if s.Route == "" {
+		return true
+	}
+	_, err := regexp.Compile(s.Route)
+	return err == nil
+}
+
 type ApplicationCategoryForm struct {
 	Action string                    `json:"action"`
 	Id     model.ApplicationCategory `json:"id"`
@@ -133,6 +168,15 @@ func (f *ApplicationCategoryForm) Valid() bool {
 			return false
 		}
 	}
+	if f.NotificationSettings.Incidents.Validate() != nil {
+		return false
+	}
+	if f.NotificationSettings.Deployments.Validate() != nil {
+		return false
+	}
+	if f.NotificationSettings.SLOReports.Validate() != nil {
+		return false
+	}
 	return true
 }
 
@@ -159,6 +203,18 @@ func (f *ApplicationCategoryForm) SendTestNotification(ctx context.Context, proj
 		if webhook := f.Test.Incident.Webhook; webhook != nil && integrations.Webhook != nil {
 			client = notifications.NewWebhook(integrations.Webhook)
 		}
+		if email := f.Test.Incident.Email; email != nil && integrations.Email != nil {
+			client = notifications.NewEmail(integrations.Email, email.To)
+		}
+		if mattermost := f.Test.Incident.Mattermost; mattermost != nil && integrations.Mattermost != nil {
+			client = notifications.NewMattermost(integrations.Mattermost.Url, integrations.Mattermost.Token, cmp.Or(mattermost.ChannelId, integrations.Mattermost.DefaultChannelId))
+		}
+		if googleChat := f.Test.Incident.GoogleChat; googleChat != nil && integrations.GoogleChat != nil {
+			client = notifications.NewGoogleChat(integrations.GoogleChat.WebhookUrl)
+		}
+		if discord := f.Test.Incident.Discord; discord != nil && integrations.Discord != nil {
+			client = notifications.NewDiscord(integrations.Discord.WebhookUrl)
+		}
 		if client != nil {
 			return client.SendIncident(ctx, integrations.BaseUrl, testIncidentNotification(project))
 		}
@@ -172,6 +228,18 @@ func (f *ApplicationCategoryForm) SendTestNotification(ctx context.Context, proj
 		if webhook := f.Test.Deployment.Webhook; webhook != nil && integrations.Webhook != nil {
 			client = notifications.NewWebhook(integrations.Webhook)
 		}
+		if email := f.Test.Deployment.Email; email != nil && integrations.Email != nil {
+			client = notifications.NewEmail(integrations.Email, email.To)
+		}
+		if mattermost := f.Test.Deployment.Mattermost; mattermost != nil && integrations.Mattermost != nil {
+			client = notifications.NewMattermost(integrations.Mattermost.Url, integrations.Mattermost.Token, cmp.Or(mattermost.ChannelId, integrations.Mattermost.DefaultChannelId))
+		}
+		if googleChat := f.Test.Deployment.GoogleChat; googleChat != nil && integrations.GoogleChat != nil {
+			client = notifications.NewGoogleChat(integrations.GoogleChat.WebhookUrl)
+		}
+		if discord := f.Test.Deployment.Discord; discord != nil && integrations.Discord != nil {
+			client = notifications.NewDiscord(integrations.Discord.WebhookUrl)
+		}
 		if client != nil {
 			return client.SendDeployment(ctx, project, testDeploymentNotification())
 		}
@@ -198,6 +266,23 @@ func (f *CustomApplicationForm) Valid() bool {
 	return true
 }
 
+type CacheBackfillForm struct {
+	Queries   []string `json:"queries"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Recompute bool     `json:"recompute"`
+
+	FromTs timeseries.Time
+	ToTs   timeseries.Time
+}
+
+func (f *CacheBackfillForm) Valid() bool {
+	now := timeseries.Now()
+	f.FromTs = utils.ParseTime(now, f.From, 0)
+	f.ToTs = utils.ParseTime(now, f.To, now)
+	return !f.FromTs.IsZero() && f.FromTs < f.ToTs
+}
+
 type CustomCloudPricingForm struct {
 	db.CustomCloudPricing
 }
@@ -288,6 +373,14 @@ func NewIntegrationForm(t db.IntegrationType, globalClickHouse *db.IntegrationCl
 		return &IntegrationFormOpsgenie{}
 	case db.IntegrationTypeWebhook:
 		return &IntegrationFormWebhook{}
+	case db.IntegrationTypeEmail:
+		return &IntegrationFormEmail{}
+	case db.IntegrationTypeMattermost:
+		return &IntegrationFormMattermost{}
+	case db.IntegrationTypeGoogleChat:
+		return &IntegrationFormGoogleChat{}
+	case db.IntegrationTypeDiscord:
+		return &IntegrationFormDiscord{}
 	}
 	return nil
 }
@@ -634,8 +727,14 @@ func (f *IntegrationFormWebhook) Get(project *db.Project, masked bool) {
 		return
 	}
 	f.IntegrationWebhook = *cfg
+	f.Destinations = append([]db.IntegrationWebhookDestination{}, cfg.Destinations...)
 	if masked {
 		f.Url = "<hidden>"
+		f.Secret = ""
+		for i := range f.Destinations {
+			f.Destinations[i].Url = "<hidden>"
+			f.Destinations[i].Secret = ""
+		}
 	}
 }
 
@@ -666,6 +765,214 @@ func (f *IntegrationFormWebhook) Test(ctx context.Context, project *db.Project)
 	return nil
 }
 
+type WebhookTestSendForm struct {
+	Webhook *db.IntegrationWebhook `json:"webhook"` // the saved integration is used if not specified
+	Event   string                 `json:"event"`   // incident, deployment or digest
+}
+
+type WebhookTestSendResult struct {
+	Payload    string                          `json:"payload"`
+	Deliveries []notifications.WebhookDelivery `json:"deliveries"`
+}
+
+func (f *WebhookTestSendForm) Valid() bool {
+	switch f.Event {
+	case "incident", "deployment", "digest":
+	default:
+		return false
+	}
+	if f.Webhook != nil {
+		if err := f.Webhook.Validate(); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *WebhookTestSendForm) Send(ctx context.Context, project *db.Project) (*WebhookTestSendResult, error) {
+	cfg := f.Webhook
+	if cfg == nil {
+		cfg = project.Settings.Integrations.Webhook
+	}
+	if cfg == nil {
+		return nil, fmt.Errorf("webhook integration is not configured")
+	}
+	wh := notifications.NewWebhook(cfg)
+	baseUrl := project.Settings.Integrations.BaseUrl
+	var payload []byte
+	var err error
+	switch f.Event {
+	case "incident":
+		payload, err = wh.RenderIncident(baseUrl, testIncidentNotification(project))
+	case "deployment":
+		payload, err = wh.RenderDeployment(project, testDeploymentNotification())
+	case "digest":
+		resolved := *testIncidentNotification(project)
+		resolved.IncidentKey = "789cd012"
+		resolved.Status = model.OK
+		resolved.Details = nil
+		payload, err = wh.RenderIncidentDigest(baseUrl, notifications.NewIncidentDigest([]db.IncidentNotification{*testIncidentNotification(project), resolved}))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookTestSendResult{Payload: string(payload), Deliveries: wh.Deliver(ctx, payload)}, nil
+}
+
+type IntegrationFormEmail struct {
+	db.IntegrationEmail
+}
+
+func (f *IntegrationFormEmail) Valid() bool {
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormEmail) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Email
+	if cfg == nil {
+		f.SmtpPort = 587
+		f.SmtpTls = db.SmtpTlsStartTls
+		f.Incidents = true
+		f.Deployments = true
+		return
+	}
+	f.IntegrationEmail = *cfg
+	if masked {
+		f.SmtpHost = "<hidden>"
+		if f.Auth != nil {
+			f.Auth = &utils.BasicAuth{User: "<hidden>", Password: "<hidden>"}
+		}
+	}
+}
+
+func (f *IntegrationFormEmail) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationEmail
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Email = cfg
+	return nil
+}
+
+func (f *IntegrationFormEmail) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewEmail(&f.IntegrationEmail, nil).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
+type IntegrationFormMattermost struct {
+	db.IntegrationMattermost
+}
+
+func (f *IntegrationFormMattermost) Valid() bool {
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormMattermost) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Mattermost
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		return
+	}
+	f.IntegrationMattermost = *cfg
+	if masked {
+		f.Url = "<hidden>"
+		f.Token = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormMattermost) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationMattermost
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Mattermost = cfg
+	return nil
+}
+
+func (f *IntegrationFormMattermost) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewMattermost(f.Url, f.Token, f.DefaultChannelId).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
+type IntegrationFormGoogleChat struct {
+	db.IntegrationGoogleChat
+}
+
+func (f *IntegrationFormGoogleChat) Valid() bool {
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormGoogleChat) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.GoogleChat
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		return
+	}
+	f.IntegrationGoogleChat = *cfg
+	if masked {
+		f.WebhookUrl = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormGoogleChat) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationGoogleChat
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.GoogleChat = cfg
+	return nil
+}
+
+func (f *IntegrationFormGoogleChat) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewGoogleChat(f.WebhookUrl).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
+type IntegrationFormDiscord struct {
+	db.IntegrationDiscord
+}
+
+func (f *IntegrationFormDiscord) Valid() bool {
+	if err := f.Validate(); err != nil {
+		return false
+	}
+	return true
+}
+
+func (f *IntegrationFormDiscord) Get(project *db.Project, masked bool) {
+	cfg := project.Settings.Integrations.Discord
+	if cfg == nil {
+		f.Incidents = true
+		f.Deployments = true
+		return
+	}
+	f.IntegrationDiscord = *cfg
+	if masked {
+		f.WebhookUrl = "<hidden>"
+	}
+}
+
+func (f *IntegrationFormDiscord) Update(ctx context.Context, project *db.Project, clear bool) error {
+	cfg := &f.IntegrationDiscord
+	if clear {
+		cfg = nil
+	}
+	project.Settings.Integrations.Discord = cfg
+	return nil
+}
+
+func (f *IntegrationFormDiscord) Test(ctx context.Context, project *db.Project) error {
+	return notifications.NewDiscord(f.WebhookUrl).SendIncident(ctx, project.Settings.Integrations.BaseUrl, testIncidentNotification(project))
+}
+
 func testIncidentNotification(project *db.Project) *db.IncidentNotification {
 	return &db.IncidentNotification{
 		ProjectId:     project.Id,
@@ -677,6 +984,12 @@ func testIncidentNotification(project *db.Project) *db.IncidentNotification {
 				{Name: model.AuditReportNetwork, Check: model.Checks.NetworkRTT.Title, Message: "high network latency to 2 upstream services"},
 				{Name: model.AuditReportLogs, Check: model.Checks.LogErrors.Title, Message: "1206 errors occurred"},
 			},
+			AvailabilityBurnRates: []model.BurnRate{
+				{LongWindow: timeseries.Hour, ShortWindow: 5 * timeseries.Minute, LongWindowBurnRate: 16.2, ShortWindowBurnRate: 21.7, Threshold: 14.4, Severity: model.CRITICAL},
+			},
+			AvailabilityImpact: 2.3,
+			Summary:            "Network latency between fake-app and its upstream services",
+			RootCause:          "Packet loss on the node hosting the upstream database",
 		},
 	}
 }
@@ -696,6 +1009,12 @@ func testDeploymentNotification() model.ApplicationDeploymentStatus {
 			StartedAt:     timeseries.Now().Add(-model.ApplicationDeploymentMinLifetime),
 			Details:       &model.ApplicationDeploymentDetails{ContainerImages: []string{"app:v1.8.2"}},
 			Notifications: &model.ApplicationDeploymentNotifications{},
+			MetricsSnapshot: &model.MetricsSnapshot{
+				Duration: model.ApplicationDeploymentMetricsSnapshotWindow, Requests: 360000, Errors: 46800, CPUUsage: 3240, MemoryUsage: 512 << 20,
+			},
+		},
+		Previous: &model.MetricsSnapshot{
+			Duration: model.ApplicationDeploymentMetricsSnapshotWindow, Requests: 352000, Errors: 352, CPUUsage: 2680, MemoryUsage: 540 << 20,
 		},
 	}
 }
@@ -0,0 +1,305 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/collector"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/exporter"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/prom"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	promModel "github.com/prometheus/common/model"
+	"k8s.io/klog"
+)
+
+// maxQueryRangePoints matches the limit of the Prometheus query API.
+const maxQueryRangePoints = 11000
+
+// metricsSnapshotMaxAge is how far the snapshot of the watchers may lag behind before the metrics endpoint loads the world itself.
+const metricsSnapshotMaxAge = 5 * timeseries.Minute
+
+// ApiKeyAuth authenticates requests to the project's read-only APIs with the project API keys.
+// The key is accepted in the X-API-Key header or as the basic auth password, which is what Grafana data sources support.
+func (api *Api) ApiKeyAuth(h func(http.ResponseWriter, *http.Request, *db.Project)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(collector.ApiKeyHeader)
+		if key == "" {
+			_, key, _ = r.BasicAuth()
+		}
+		// the all-zeros key is created for agents that send data without a key, it must not grant read access
+		if key == "" || strings.Trim(key, "0") == "" {
+			http.Error(w, "", http.StatusUnauthorized)
+			return
+		}
+		project, err := api.db.GetProject(db.ProjectId(mux.Vars(r)["project"]))
+		if err != nil {
+			if errors.Is(err, db.ErrNotFound) {
+				http.Error(w, "", http.StatusUnauthorized)
+				return
+			}
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		for _, k := range project.Settings.ApiKeys {
+			if subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
+				h(w, r, project)
+				return
+			}
+		}
+		http.Error(w, "", http.StatusUnauthorized)
+	}
+}
+
+// PromQueryRange implements the Prometheus /api/v1/query_range endpoint.
+// Queries are answered from the metric cache when possible and forwarded to the upstream Prometheus otherwise.
+func (api *Api) PromQueryRange(w http.ResponseWriter, r *http.Request, project *db.Project) {
+	if err := r.ParseForm(); err != nil {
+		writePromError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	query := r.Form.Get("query")
+	if query == "" {
+		writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("query is required"))
+		return
+	}
+	from, err := parsePromTime(r.Form.Get("start"))
+	if err != nil {
+		writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("invalid start: %w", err))
+		return
+	}
+	to, err := parsePromTime(r.Form.Get("end"))
+	if err != nil {
+		writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("invalid end: %w", err))
+		return
+	}
+	step, err := parsePromDuration(r.Form.Get("step"))
+	if err != nil {
+		writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("invalid step: %w", err))
+		return
+	}
+	if to.Before(from) {
+		writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("end timestamp must not be before start time"))
+		return
+	}
+	if to.Sub(from)/step > maxQueryRangePoints {
+		writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("exceeded maximum resolution of %d points per timeseries", maxQueryRangePoints))
+		return
+	}
+
+	mvs, cached, err := api.cache.GetCacheClient(project.Id).QueryRangePromQL(r.Context(), query, from, to, step)
+	if err != nil {
+		writePromError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	source := "cache"
+	if !cached {
+		source = "upstream"
+		c, err := api.promClient(project)
+		if err != nil {
+			writePromError(w, http.StatusServiceUnavailable, "unavailable", err)
+			return
+		}
+		if mvs, err = c.QueryRange(r.Context(), query, prom.FilterLabelsKeepAll, from, to, step); err != nil {
+			writePromError(w, http.StatusUnprocessableEntity, "execution", err)
+			return
+		}
+	}
+
+	type series struct {
+		Metric model.Labels `json:"metric"`
+		Values [][2]any     `json:"values"`
+	}
+	result := make([]series, 0, len(mvs))
+	for _, mv := range mvs {
+		s := series{Metric: mv.Labels}
+		if s.Metric == nil {
+			s.Metric = model.Labels{}
+		}
+		iter := mv.Values.Iter()
+		for iter.Next() {
+			t, v := iter.Value()
+			if timeseries.IsNaN(v) || t.Before(from) || t.After(to) {
+				continue
+			}
+			s.Values = append(s.Values, [2]any{int64(t), strconv.FormatFloat(float64(v), 'f', -1, 32)})
+		}
+		if len(s.Values) > 0 {
+			result = append(result, s)
+		}
+	}
+	w.Header().Set("X-Coroot-Source", source)
+	writePromData(w, map[string]any{"resultType": "matrix", "result": result})
+}
+
+// PromSeries implements the Prometheus /api/v1/series endpoint.
+func (api *Api) PromSeries(w http.ResponseWriter, r *http.Request, project *db.Project) {
+	if err := r.ParseForm(); err != nil {
+		writePromError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	selectors := r.Form["match[]"]
+	if len(selectors) == 0 {
+		writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("no match[] parameter provided"))
+		return
+	}
+	now := timeseries.Now()
+	from, to := now.Add(-timeseries.Hour), now
+	var err error
+	if v := r.Form.Get("start"); v != "" {
+		if from, err = parsePromTime(v); err != nil {
+			writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("invalid start: %w", err))
+			return
+		}
+	}
+	if v := r.Form.Get("end"); v != "" {
+		if to, err = parsePromTime(v); err != nil {
+			writePromError(w, http.StatusBadRequest, "bad_data", fmt.Errorf("invalid end: %w", err))
+			return
+		}
+	}
+
+	res, cached, err := api.cache.GetCacheClient(project.Id).SeriesPromQL(r.Context(), selectors, from, to)
+	if err != nil {
+		writePromError(w, http.StatusBadRequest, "bad_data", err)
+		return
+	}
+	source := "cache"
+	if !cached {
+		source = "upstream"
+		c, err := api.promClient(project)
+		if err != nil {
+			writePromError(w, http.StatusServiceUnavailable, "unavailable", err)
+			return
+		}
+		if res, err = c.Series(r.Context(), selectors, from, to); err != nil {
+			writePromError(w, http.StatusUnprocessableEntity, "execution", err)
+			return
+		}
+	}
+	if res == nil {
+		res = []model.Labels{}
+	}
+	w.Header().Set("X-Coroot-Source", source)
+	writePromData(w, res)
+}
+
+func writePromData(w http.ResponseWriter, data any) {
+	utils.WriteJson(w, map[string]any{"status": "success", "data": data})
+}
+
+func writePromError(w http.ResponseWriter, status int, errorType string, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "error", "errorType": errorType, "error": err.Error()})
+}
+
+// parsePromTime accepts Unix timestamps and RFC3339 strings, like the Prometheus API does.
+func parsePromTime(s string) (timeseries.Time, error) {
+	if s == "" {
+		return 0, fmt.Errorf("empty value")
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return timeseries.Time(math.Floor(f)), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, err
+	}
+	return timeseries.TimeFromStandard(t), nil
+}
+
+// parsePromDuration accepts a number of seconds or a Prometheus duration string (e.g., 1m).
+func parsePromDuration(s string) (timeseries.Duration, error) {
+	var d timeseries.Duration
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		d = timeseries.Duration(math.Round(f))
+	} else {
+		pd, err := promModel.ParseDuration(s)
+		if err != nil {
+			return 0, err
+		}
+		d = timeseries.Duration(time.Duration(pd) / time.Second)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("zero or negative step is not accepted, the step must be at least 1s")
+	}
+	return d, nil
+}
+
+// Metrics exposes the project's SLIs, check statuses, incidents, burn rates and cost estimates in the Prometheus format.
+// The snapshot of the world audited by the latest watcher iteration is reused, so a scrape doesn't load and audit the world again.
+func (api *Api) Metrics(w http.ResponseWriter, r *http.Request, project *db.Project) {
+	now := timeseries.Now()
+	from := now.Add(-model.MaxAlertRuleWindow)
+	snapshot, err := api.metricsSnapshot(r.Context(), project, now)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if snapshot == nil {
+		http.Error(w, "Metric cache is not ready yet", http.StatusServiceUnavailable)
+		return
+	}
+
+	incidents, err := api.db.GetApplicationIncidents(project.Id, from, now)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	open := map[model.ApplicationId]*model.ApplicationIncident{}
+	for appId, ii := range incidents {
+		for _, i := range ii {
+			if !i.Resolved() && (open[appId] == nil || open[appId].OpenedAt.Before(i.OpenedAt)) {
+				open[appId] = i
+			}
+		}
+	}
+
+	registry := prometheus.NewRegistry()
+	if err = registry.Register(exporter.NewCollector(project, snapshot, open)); err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: promErrorLogger{}}).ServeHTTP(w, r)
+}
+
+// metricsSnapshot returns the snapshot of the audited world covering the last hour.
+// The snapshot of the watchers is used unless it's stale, e.g., when the project is checked by another replica.
+func (api *Api) metricsSnapshot(ctx context.Context, project *db.Project, now timeseries.Time) (*exporter.Snapshot, error) {
+	if api.metricsSnapshots != nil {
+		if snapshot := api.metricsSnapshots.Get(project.Id); snapshot != nil && now.Sub(snapshot.To) <= metricsSnapshotMaxAge {
+			return snapshot, nil
+		}
+	}
+	world, _, err := api.LoadWorld(ctx, project, now.Add(-timeseries.Hour), now)
+	if err != nil || world == nil {
+		return nil, err
+	}
+	auditor.Audit(world, project, nil, project.ClickHouseConfig(api.globalClickHouse) != nil, nil)
+	return exporter.NewSnapshot(world), nil
+}
+
+type promErrorLogger struct{}
+
+func (promErrorLogger) Println(v ...any) {
+	klog.Errorln(v...)
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"sort"
 
 	"github.com/coroot/coroot/clickhouse"
 	"github.com/coroot/coroot/cloud"
@@ -54,7 +56,8 @@ func (api *Api) RCA(w http.ResponseWriter, r *http.Request, u *db.User) {
 		rca.Error = err.Error()
 		return
 	}
-	cacheClient := api.cache.GetCacheClient(project.Id)
+	dataProject := api.clusterProject(project, appId)
+	cacheClient := api.cache.GetCacheClient(dataProject.Id)
 	cacheTo, err := cacheClient.GetTo()
 	if err != nil {
 		klog.Errorln(err)
@@ -86,7 +89,7 @@ func (api *Api) RCA(w http.ResponseWriter, r *http.Request, u *db.User) {
 
 	rcaRequest := cloud.RCARequest{
 		Ctx:                         timeseries.NewContext(from, to, step),
-		ApplicationId:               appId,
+		ApplicationId:               appId.WithCluster(""),
 		ApplicationCategorySettings: project.Settings.ApplicationCategorySettings,
 		CustomApplications:          project.Settings.CustomApplications,
 		CustomCloudPricing:          project.Settings.CustomCloudPricing,
@@ -96,21 +99,7 @@ func (api *Api) RCA(w http.ResponseWriter, r *http.Request, u *db.User) {
 		rcaRequest.Ctx.From, rcaRequest.Ctx.To = api.IncidentTimeContext(projectId, incident, to)
 	}
 
-	if rcaRequest.CheckConfigs, err = api.db.GetCheckConfigs(project.Id); err != nil {
-		klog.Errorln(err)
-		rca.Status = "Failed"
-		rca.Error = err.Error()
-		return
-	}
-	if rcaRequest.ApplicationDeployments, err = api.db.GetApplicationDeployments(project.Id); err != nil {
-		klog.Errorln(err)
-		rca.Status = "Failed"
-		rca.Error = err.Error()
-		return
-	}
-
-	ctr := constructor.New(api.db, project, cacheClient, api.pricing)
-	if rcaRequest.Metrics, err = ctr.QueryCache(r.Context(), rcaRequest.Ctx.From, rcaRequest.Ctx.To, rcaRequest.Ctx.Step); err != nil {
+	if err = api.loadRCAData(r.Context(), project, appId, &rcaRequest); err != nil {
 		klog.Errorln(err)
 		rca.Status = "Failed"
 		rca.Error = err.Error()
@@ -118,7 +107,7 @@ func (api *Api) RCA(w http.ResponseWriter, r *http.Request, u *db.User) {
 	}
 
 	var ch *clickhouse.Client
-	if ch, err = api.GetClickhouseClient(project); err != nil {
+	if ch, err = api.GetClickhouseClient(dataProject); err != nil {
 		klog.Errorln(err)
 	}
 	if ch != nil {
@@ -201,27 +190,18 @@ func (api *Api) IncidentRCA(ctx context.Context, project *db.Project, world *mod
 		return
 	}
 
-	var err error
+	dataProject := api.clusterProject(project, app.Id)
 	rcaRequest := cloud.RCARequest{
 		Ctx:                         world.Ctx,
-		ApplicationId:               app.Id,
-		CheckConfigs:                world.CheckConfigs,
+		ApplicationId:               app.Id.WithCluster(""),
 		ApplicationCategorySettings: project.Settings.ApplicationCategorySettings,
 		CustomApplications:          project.Settings.CustomApplications,
 		CustomCloudPricing:          project.Settings.CustomCloudPricing,
 	}
 	rcaRequest.Ctx.From, rcaRequest.Ctx.To = api.IncidentTimeContext(project.Id, incident, world.Ctx.To)
 
-	if rcaRequest.ApplicationDeployments, err = api.db.GetApplicationDeployments(project.Id); err != nil {
-		klog.Errorln(err)
-		rca.Status = "Failed"
-		rca.Error = err.Error()
-		return
-	}
-
-	cacheClient := api.cache.GetCacheClient(project.Id)
-	ctr := constructor.New(api.db, project, cacheClient, api.pricing)
-	if rcaRequest.Metrics, err = ctr.QueryCache(ctx, rcaRequest.Ctx.From, rcaRequest.Ctx.To, rcaRequest.Ctx.Step); err != nil {
+	err := api.loadRCAData(ctx, project, app.Id, &rcaRequest)
+	if err != nil {
 		klog.Errorln(err)
 		rca.Status = "Failed"
 		rca.Error = err.Error()
@@ -229,7 +209,7 @@ func (api *Api) IncidentRCA(ctx context.Context, project *db.Project, world *mod
 	}
 
 	var ch *clickhouse.Client
-	if ch, err = api.GetClickhouseClient(project); err != nil {
+	if ch, err = api.GetClickhouseClient(dataProject); err != nil {
 		klog.Errorln(err)
 	}
 	if ch != nil {
@@ -254,6 +234,73 @@ func (api *Api) IncidentRCA(ctx context.Context, project *db.Project, world *mod
 	rca.Status = "OK"
 }
 
+// loadRCAData fills the check configs, deployments, and metrics of the request.
+// The RCA of an application of a multi-cluster project spans all the clusters: their metrics are merged,
+// so the connections to the applications of other clusters are resolved by IPs as within a single cluster.
+// The application's own cluster goes last, so its check configs and deployments take precedence.
+func (api *Api) loadRCAData(ctx context.Context, project *db.Project, appId model.ApplicationId, req *cloud.RCARequest) error {
+	projects := []*db.Project{project}
+	if project.Multicluster() {
+		members, err := api.db.GetClusters(project)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(members, func(i, j int) bool {
+			return string(members[j].Id) == appId.ClusterId
+		})
+		projects = members
+	}
+	req.CheckConfigs = model.CheckConfigs{}
+	req.ApplicationDeployments = map[model.ApplicationId][]*model.ApplicationDeployment{}
+	req.Metrics = map[string][]*model.MetricValues{}
+	mergeConfigs := func(configs model.CheckConfigs, clusterId string) {
+		for id, checks := range configs {
+			if id.ClusterId != clusterId {
+				continue
+			}
+			id = id.WithCluster("")
+			if req.CheckConfigs[id] == nil {
+				req.CheckConfigs[id] = map[model.CheckId]json.RawMessage{}
+			}
+			for checkId, cfg := range checks {
+				req.CheckConfigs[id][checkId] = cfg
+			}
+		}
+	}
+	for _, p := range projects {
+		configs, err := api.db.GetCheckConfigs(p.Id)
+		if err != nil {
+			return err
+		}
+		mergeConfigs(configs, "")
+		deployments, err := api.db.GetApplicationDeployments(p.Id)
+		if err != nil {
+			return err
+		}
+		for id, ds := range deployments {
+			req.ApplicationDeployments[id] = ds
+		}
+		ctr := constructor.New(api.db, p, api.cache.GetCacheClient(p.Id), api.pricing)
+		metrics, err := ctr.QueryCache(ctx, req.Ctx.From, req.Ctx.To, req.Ctx.Step)
+		if err != nil {
+			return err
+		}
+		for name, mvs := range metrics {
+			req.Metrics[name] = append(req.Metrics[name], mvs...)
+		}
+	}
+	if project.Multicluster() {
+		// the check configs of the multi-cluster project override the ones of the clusters
+		configs, err := api.db.GetCheckConfigs(project.Id)
+		if err != nil {
+			return err
+		}
+		mergeConfigs(configs, "")
+		mergeConfigs(configs, appId.ClusterId)
+	}
+	return nil
+}
+
 func (api *Api) IncidentTimeContext(projectId db.ProjectId, incident *model.ApplicationIncident, now timeseries.Time) (timeseries.Time, timeseries.Time) {
 	from := incident.OpenedAt.Add(-model.IncidentTimeOffset)
 	to := now
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/scenario"
+	"k8s.io/klog"
+)
+
+const maxScenarioSize = 1 << 20
+
+func (api *Api) ScenarioImport(w http.ResponseWriter, r *http.Request, u *db.User) {
+	if !api.IsAllowed(u, rbac.Actions.Project("").Settings().Edit()) {
+		http.Error(w, "You are not allowed to create projects.", http.StatusForbidden)
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxScenarioSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s, err := scenario.Parse(data)
+	if err != nil {
+		http.Error(w, "Invalid scenario: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	project, err := scenario.Import(r.Context(), api.db, api.cache, s, r.URL.Query().Get("name"))
+	if err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			http.Error(w, "This project name is already being used.", http.StatusConflict)
+			return
+		}
+		klog.Errorln("failed to import scenario:", err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	http.Error(w, string(project.Id), http.StatusOK)
+}
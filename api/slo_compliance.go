@@ -0,0 +1,97 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/notifications"
+	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+)
+
+// SLOCompliance returns the SLO compliance of the applications of the project (or of a single application)
+// over the window of complete days: as JSON by default, or as a report in Markdown or HTML (?format=markdown|html).
+func (api *Api) SLOCompliance(w http.ResponseWriter, r *http.Request, u *db.User) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	var appId model.ApplicationId
+	if mux.Vars(r)["app"] != "" {
+		var err error
+		if appId, err = GetApplicationId(r); err != nil {
+			klog.Warningln(err)
+			http.Error(w, "invalid application id", http.StatusBadRequest)
+			return
+		}
+	}
+	q := r.URL.Query()
+	window := model.SLOComplianceDefaultWindow
+	if v := q.Get("window"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 1 || timeseries.Duration(days)*timeseries.Day > model.SLOComplianceMaxWindow {
+			http.Error(w, "Invalid window: must be a number of days from 1 to 30.", http.StatusBadRequest)
+			return
+		}
+		window = timeseries.Duration(days) * timeseries.Day
+	}
+	now := timeseries.Now()
+	to := utils.ParseTime(now, q.Get("to"), now).Truncate(timeseries.Day)
+	category := model.ApplicationCategory(q.Get("category"))
+
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found.", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	items, err := api.db.GetSLOCompliance(project, appId, category, to, window)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	allowed := items[:0]
+	for _, i := range items {
+		id := i.ApplicationId
+		if api.IsAllowed(u, rbac.Actions.Project(string(projectId)).Application(project.CalcApplicationCategory(id), id.Namespace, id.Kind, id.Name).View()) {
+			allowed = append(allowed, i)
+		}
+	}
+	items = allowed
+
+	report := &notifications.SLOReport{
+		ProjectId:   project.Id,
+		ProjectName: project.Name,
+		Category:    category,
+		BaseUrl:     project.Settings.Integrations.BaseUrl,
+		From:        to.Add(-window),
+		To:          to,
+		Items:       items,
+	}
+	switch q.Get("format") {
+	case "", "json":
+		utils.WriteJson(w, report)
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(report.Markdown()))
+	case "html":
+		html, err := report.HTML()
+		if err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(html))
+	default:
+		http.Error(w, "Unsupported format: must be json, markdown, or html.", http.StatusBadRequest)
+	}
+}
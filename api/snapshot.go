@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/snapshot"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+)
+
+const maxSnapshotSize = 1 << 30
+
+func (api *Api) SnapshotExport(w http.ResponseWriter, r *http.Request, u *db.User) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	if !api.IsAllowed(u, rbac.Actions.Project(string(projectId)).Settings().Edit()) {
+		http.Error(w, "You are not allowed to export the project.", http.StatusForbidden)
+		return
+	}
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found.", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	from, to, _ := api.getTimeContext(r)
+	opts := snapshot.ExportOptions{From: from, To: to}
+	q := r.URL.Query()
+	if q.Get("logs") != "" || q.Get("traces") != "" {
+		if opts.ClickHouse, err = api.GetClickhouseClient(project); err != nil {
+			klog.Errorln(err)
+			http.Error(w, "", http.StatusInternalServerError)
+			return
+		}
+		if opts.ClickHouse != nil {
+			defer opts.ClickHouse.Close()
+		}
+	}
+	export, err := snapshot.NewExport(api.db, api.cache, project, opts)
+	if err != nil {
+		klog.Errorln("failed to export snapshot:", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.tar.gz"`, project.Name, to.ToStandard().Format("20060102-1504")))
+	if err = export.Write(r.Context(), w); err != nil {
+		klog.Errorln("failed to export snapshot:", err)
+		// the archive has been partially sent with the 200 status, so the connection is aborted to let the client know it's broken
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func (api *Api) SnapshotImport(w http.ResponseWriter, r *http.Request, u *db.User) {
+	if !api.IsAllowed(u, rbac.Actions.Project("").Settings().Edit()) {
+		http.Error(w, "You are not allowed to create projects.", http.StatusForbidden)
+		return
+	}
+	project, err := snapshot.Import(r.Context(), http.MaxBytesReader(w, r.Body, maxSnapshotSize), api.db, api.cache, r.URL.Query().Get("name"))
+	if err != nil {
+		if errors.Is(err, db.ErrConflict) {
+			http.Error(w, "This project name is already being used.", http.StatusConflict)
+			return
+		}
+		klog.Warningln("failed to import snapshot:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, string(project.Id), http.StatusOK)
+}
+
+func (api *Api) Snapshot(w http.ResponseWriter, r *http.Request, u *db.User) {
+	projectId := db.ProjectId(mux.Vars(r)["project"])
+	project, err := api.db.GetProject(projectId)
+	if err != nil {
+		if errors.Is(err, db.ErrNotFound) {
+			http.Error(w, "Project not found.", http.StatusNotFound)
+			return
+		}
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if project.Settings.Snapshot == nil {
+		http.Error(w, "The project is not a snapshot.", http.StatusNotFound)
+		return
+	}
+	excerpts, err := snapshot.GetExcerpts(r.Context(), api.cache, projectId)
+	if err != nil {
+		klog.Errorln(err)
+		http.Error(w, "", http.StatusInternalServerError)
+		return
+	}
+	if !api.IsAllowed(u, rbac.Actions.Project(string(projectId)).Logs().View()) {
+		excerpts.Logs = nil
+	}
+	if !api.IsAllowed(u, rbac.Actions.Project(string(projectId)).Traces().View()) {
+		excerpts.Traces = nil
+	}
+	res := struct {
+		*db.ProjectSnapshot
+		*snapshot.Excerpts
+	}{
+		ProjectSnapshot: project.Settings.Snapshot,
+		Excerpts:        excerpts,
+	}
+	utils.WriteJson(w, res)
+}
+
+// snapshotTimeRange moves the requested range into the snapshot's one keeping its duration,
+// so the default "last hour" view of a snapshot shows the end of the captured period.
+func snapshotTimeRange(snap *db.ProjectSnapshot, from, to timeseries.Time) (timeseries.Time, timeseries.Time) {
+	if from < snap.To && to > snap.From {
+		return from, to
+	}
+	d := to.Sub(from)
+	to = snap.To
+	from = to.Add(-d)
+	if from < snap.From {
+		from = snap.From
+	}
+	return from, to
+}
@@ -33,6 +33,7 @@ func Render(configs model.CheckConfigs) *View {
 	v.addReport(model.AuditReportSLO, cs.SLOAvailability, cs.SLOLatency)
 	v.addReport(model.AuditReportInstances, cs.InstanceAvailability, cs.InstanceRestarts)
 	v.addReport(model.AuditReportDeployments, cs.DeploymentStatus)
+	v.addReport(model.AuditReportJobs, cs.JobFailedRuns, cs.JobDurationRegression, cs.CronJobMissedSchedules)
 	v.addReport(model.AuditReportCPU, cs.CPUNode, cs.CPUContainer)
 	v.addReport(model.AuditReportMemory, cs.MemoryOOM, cs.MemoryLeakPercent)
 	v.addReport(model.AuditReportStorage, cs.StorageIOLoad, cs.StorageSpace)
@@ -41,7 +42,16 @@ func Render(configs model.CheckConfigs) *View {
 	v.addReport(model.AuditReportPostgres, cs.PostgresAvailability, cs.PostgresLatency, cs.PostgresReplicationLag, cs.PostgresConnections)
 	v.addReport(model.AuditReportRedis, cs.RedisAvailability, cs.RedisLatency)
 	v.addReport(model.AuditReportJvm, cs.JvmAvailability, cs.JvmSafepointTime)
+	v.addReport(model.AuditReportGo, cs.GoGoroutineLeak, cs.GoGcCpu, cs.GoMemoryLimit, cs.GoSchedulerLatency)
 	v.addReport(model.AuditReportMongodb, cs.MongodbAvailability, cs.MongodbReplicationLag)
+	v.addReport(model.AuditReportKafka, cs.KafkaAvailability, cs.KafkaUnderReplicated, cs.KafkaOfflinePartitions, cs.KafkaIsrShrinks, cs.KafkaConsumerLag)
+	v.addReport(model.AuditReportRabbitmq, cs.RabbitmqAvailability, cs.RabbitmqQueueGrowth, cs.RabbitmqUnackedMessages, cs.RabbitmqNoConsumers, cs.RabbitmqAlarms)
+	v.addReport(model.AuditReportNats, cs.NatsAvailability, cs.NatsSlowConsumers, cs.NatsPendingGrowth, cs.NatsAckPending, cs.NatsNoConsumers)
+	v.addReport(model.AuditReportElastic, cs.ElasticsearchClusterHealth, cs.ElasticsearchUnassigned, cs.ElasticsearchHeapUsage, cs.ElasticsearchSearchLatency, cs.ElasticsearchIndexLatency, cs.ElasticsearchRejections)
+	v.addReport(model.AuditReportCassandra, cs.CassandraAvailability, cs.CassandraReadLatency, cs.CassandraWriteLatency, cs.CassandraCompactions, cs.CassandraDroppedMessages, cs.CassandraHints)
+	v.addReport(model.AuditReportClickHouse, cs.ClickHouseAvailability, cs.ClickHouseParts, cs.ClickHouseMerges, cs.ClickHouseReplicationQueue, cs.ClickHouseReplicationDelay, cs.ClickHouseKeeperErrors, cs.ClickHouseFailedQueries, cs.ClickHouseMemoryLimit, cs.ClickHouseDiskUsage)
+	v.addReport(model.AuditReportProxy, cs.ProxyUpstreamErrors, cs.ProxyUpstreamConnectFailures, cs.ProxyCircuitBreakers)
+	v.addReport(model.AuditReportKubernetes, cs.KubernetesAPIServerLatency, cs.KubernetesAPIServerErrors, cs.KubernetesEtcdFsyncLatency, cs.KubernetesEtcdCommitLatency, cs.KubernetesEtcdLeaderChanges, cs.KubernetesSchedulerPendingPods, cs.KubernetesKubeletPLEG, cs.KubernetesCoreDNSErrors, cs.KubernetesCoreDNSLatency)
 
 	return v
 }
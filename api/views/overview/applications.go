@@ -50,6 +50,7 @@ func renderApplications(w *model.World) []*ApplicationStatus {
 		switch {
 		case app.IsK8s():
 		case app.Id.Kind == model.ApplicationKindNomadJobGroup:
+		case app.Id.Kind == model.ApplicationKindOTelService:
 		case !app.IsStandalone():
 		default:
 			continue
@@ -0,0 +1,114 @@
+package overview
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"golang.org/x/exp/maps"
+)
+
+type ArgoCDResource struct {
+	ID                model.ApplicationId   `json:"id"`
+	Type              string                `json:"type"`
+	Name              string                `json:"name"`
+	Namespace         string                `json:"namespace"`
+	Status            string                `json:"status"`
+	SyncStatus        string                `json:"sync_status,omitempty"`
+	HealthStatus      string                `json:"health_status,omitempty"`
+	Project           string                `json:"project,omitempty"`
+	Repo              string                `json:"repo,omitempty"`
+	Destination       string                `json:"destination,omitempty"`
+	Revision          string                `json:"revision,omitempty"`
+	LastSyncTime      timeseries.Time       `json:"last_sync_time,omitempty"`
+	LastSyncResult    string                `json:"last_sync_result,omitempty"`
+	OwnedApplications int                   `json:"owned_applications,omitempty"`
+	Workloads         []model.ApplicationId `json:"workloads,omitempty"`
+}
+
+func renderArgoCD(w *model.World) []*ArgoCDResource {
+	if w == nil || w.ArgoCD == nil {
+		return []*ArgoCDResource{}
+	}
+	argocd := w.ArgoCD
+	var resources []*ArgoCDResource
+
+	for id, app := range argocd.Applications {
+		workloads := maps.Keys(app.Workloads)
+		sort.Slice(workloads, func(i, j int) bool {
+			return workloads[i].String() < workloads[j].String()
+		})
+		destination := app.DestNamespace.Value()
+		if server := app.DestServer.Value(); server != "" && destination != "" {
+			destination = server + "/" + destination
+		}
+		r := &ArgoCDResource{
+			ID:           id,
+			Type:         string(id.Kind),
+			Name:         id.Name,
+			Namespace:    id.Namespace,
+			Status:       getArgoCDApplicationStatus(app),
+			SyncStatus:   app.SyncStatus.Value(),
+			HealthStatus: app.HealthStatus.Value(),
+			Project:      app.Project.Value(),
+			Repo:         app.Repo.Value(),
+			Destination:  destination,
+			Revision:     app.Revision.Value(),
+			Workloads:    workloads,
+		}
+		if s := app.LastSync(); s != nil {
+			r.LastSyncTime = s.Time
+			r.LastSyncResult = s.Phase
+		}
+		resources = append(resources, r)
+	}
+
+	for id, appSet := range argocd.ApplicationSets {
+		// the status of the ResourcesUpToDate condition
+		status := "Unknown"
+		switch strings.ToLower(appSet.Status.Value()) {
+		case "true":
+			status = "Ready"
+		case "false":
+			status = "Failed"
+		}
+		r := &ArgoCDResource{
+			ID:        id,
+			Type:      string(id.Kind),
+			Name:      id.Name,
+			Namespace: id.Namespace,
+			Status:    status,
+		}
+		if v := appSet.OwnedApplications.Last(); !timeseries.IsNaN(v) {
+			r.OwnedApplications = int(v)
+		}
+		resources = append(resources, r)
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return resources[i].ID.String() < resources[j].ID.String()
+	})
+	return resources
+}
+
+func getArgoCDApplicationStatus(app *model.ArgoCDApplication) string {
+	if s := app.LastSync(); s != nil && !s.Succeeded() {
+		return "Failed"
+	}
+	switch app.HealthStatus.Value() {
+	case "Degraded", "Missing":
+		return "Failed"
+	case "Progressing":
+		return "Progressing"
+	case "Suspended":
+		return "Suspended"
+	}
+	switch app.SyncStatus.Value() {
+	case "Synced":
+		return "Ready"
+	case "OutOfSync":
+		return "OutOfSync"
+	}
+	return "Unknown"
+}
@@ -61,6 +61,7 @@ func availabilityRisks(w *model.World) []*Risk {
 	for _, app := range w.Applications {
 		switch app.Id.Kind {
 		case model.ApplicationKindExternalService, model.ApplicationKindRds, model.ApplicationKindElasticacheCluster,
+			model.ApplicationKindCloudSQL, model.ApplicationKindMemorystore, model.ApplicationKindAzureDatabase,
 			model.ApplicationKindJob, model.ApplicationKindCronJob:
 			continue
 		}
@@ -0,0 +1,152 @@
+package auditor
+
+import (
+	"sort"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+const (
+	anomalyDetectionWindow = 15 * timeseries.Minute
+	anomalyMinPoints       = 3
+	anomalyEWMAAlpha       = 0.3
+
+	// deviations smaller than this fraction of the typical value are never considered anomalous
+	anomalyMinRelativeDeviation = 0.1
+)
+
+// anomalyMinDeviation is the absolute deviation floor for signals expressed as fractions of requests.
+var anomalyMinDeviation = map[model.AnomalySignal]float32{
+	model.AnomalySignalErrors:  0.01,
+	model.AnomalySignalLatency: 0.01,
+}
+
+// anomalyBothDirections lists signals for which a drop is as suspicious as a spike.
+var anomalyBothDirections = map[model.AnomalySignal]bool{
+	model.AnomalySignalRequests: true,
+	model.AnomalySignalTraffic:  true,
+}
+
+func (a *appAuditor) anomalies() {
+	signals := a.app.AnomalySignals()
+	if len(signals) == 0 {
+		return
+	}
+
+	report := a.addReport(model.AuditReportAnomalies)
+	check := report.CreateCheck(model.Checks.Anomalies)
+	table := report.GetOrCreateTable("Signal", "Value", "Expected", "Score", "Baseline")
+	charts := report.GetOrCreateChartGroup("Signals <selector>", nil)
+
+	names := make([]model.AnomalySignal, 0, len(signals))
+	for s := range signals {
+		names = append(names, s)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	detectFrom := a.w.Ctx.To.Add(-anomalyDetectionWindow)
+	for _, s := range names {
+		ts := signals[s]
+		kind := model.AnomalyBaselineSeasonal
+		baseline := timeseries.NewSeasonalBaseline(a.w.AnomalyHistory[a.app.Id][s]...)
+		if baseline == nil {
+			kind = model.AnomalyBaselineEWMA
+			baseline = timeseries.NewEWMABaseline(ts, anomalyEWMAAlpha, detectFrom)
+		}
+		if baseline == nil {
+			continue
+		}
+		if charts != nil {
+			charts.GetOrCreateChart(string(s)).
+				AddSeries("value", ts, "blue").
+				AddSeries("expected", baseline.Expected, "grey")
+		}
+		anomaly := detectAnomaly(s, ts, baseline, detectFrom, check.Threshold)
+		if anomaly == nil {
+			continue
+		}
+		anomaly.ApplicationId = a.app.Id
+		anomaly.Baseline = kind
+		a.app.Anomalies = append(a.app.Anomalies, anomaly)
+		check.AddItem(string(s))
+		if table != nil {
+			table.AddRow(
+				model.NewTableCell(string(s)),
+				model.NewTableCell(utils.FormatFloat(anomaly.Value)),
+				model.NewTableCell(utils.FormatFloat(anomaly.Expected)),
+				model.NewTableCell().SetStatus(anomaly.Severity, utils.FormatFloat(anomaly.Score)),
+				model.NewTableCell(string(kind)),
+			)
+		}
+	}
+}
+
+// detectAnomaly reports an anomaly if the most recent points of the series deviate from the baseline
+// by more than the threshold for at least anomalyMinPoints consecutive points.
+func detectAnomaly(s model.AnomalySignal, ts *timeseries.TimeSeries, baseline *timeseries.Baseline, detectFrom timeseries.Time, threshold float32) *model.Anomaly {
+	var values []float32
+	iter := ts.Iter()
+	for iter.Next() {
+		if _, v := iter.Value(); !timeseries.IsNaN(v) {
+			values = append(values, abs(v))
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	minDeviation := max(anomalyMinDeviation[s], timeseries.Median(values)*anomalyMinRelativeDeviation)
+	scores := baseline.Score(ts, minDeviation)
+	if scores.IsEmpty() {
+		return nil
+	}
+
+	var res *model.Anomaly
+	points := 0
+	iter = scores.IterFrom(detectFrom)
+	for iter.Next() {
+		t, z := iter.Value()
+		if timeseries.IsNaN(z) {
+			continue
+		}
+		if anomalyBothDirections[s] {
+			z = abs(z)
+		}
+		if z < threshold {
+			res, points = nil, 0
+			continue
+		}
+		if res == nil {
+			res = &model.Anomaly{Signal: s, Since: t}
+		}
+		points++
+		res.Score = max(res.Score, z)
+		res.Value = valueAt(ts, t)
+		res.Expected = baseline.ExpectedAt(t)
+	}
+	if res == nil || points < anomalyMinPoints {
+		return nil
+	}
+	res.Severity = model.WARNING
+	if res.Score >= 2*threshold {
+		res.Severity = model.CRITICAL
+	}
+	return res
+}
+
+func valueAt(ts *timeseries.TimeSeries, t timeseries.Time) float32 {
+	iter := ts.IterFrom(t)
+	if iter.Next() {
+		_, v := iter.Value()
+		return v
+	}
+	return timeseries.NaN
+}
+
+func abs(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
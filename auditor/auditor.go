@@ -63,12 +63,23 @@ func Audit(w *model.World, p *db.Project, generateDetailedReportFor *model.Appli
 		stages.stage("redis", a.redis)
 		stages.stage("mongodb", a.mongodb)
 		stages.stage("memcached", a.memcached)
+		stages.stage("kafka", a.kafka)
+		stages.stage("rabbitmq", a.rabbitmq)
+		stages.stage("nats", a.nats)
+		stages.stage("elasticsearch", a.elasticsearch)
+		stages.stage("cassandra", a.cassandra)
+		stages.stage("clickhouse", a.clickhouse)
+		stages.stage("proxy", a.proxy)
+		stages.stage("kubernetes", a.kubernetes)
 		stages.stage("jvm", a.jvm)
 		stages.stage("dotnet", a.dotnet)
 		stages.stage("python", a.python)
 		stages.stage("nodejs", a.nodejs)
+		stages.stage("golang", a.golang)
 		stages.stage("logs", a.logs)
 		stages.stage("deployments", a.deployments)
+		stages.stage("jobs", a.jobs)
+		stages.stage("anomalies", a.anomalies)
 
 		for _, r := range a.reports {
 			widgets := a.enrichWidgets(r.Widgets, app.Events)
@@ -84,7 +95,7 @@ func Audit(w *model.World, p *db.Project, generateDetailedReportFor *model.Appli
 				}
 			}
 			switch r.Name {
-			case model.AuditReportPostgres, model.AuditReportRedis, model.AuditReportInstances, model.AuditReportSLO:
+			case model.AuditReportPostgres, model.AuditReportRedis, model.AuditReportInstances, model.AuditReportSLO, model.AuditReportKubernetes, model.AuditReportJobs:
 				if app.Status < r.Status {
 					app.Status = r.Status
 				}
@@ -112,6 +123,15 @@ func (a *appAuditor) addReport(name model.AuditReportName) *model.AuditReport {
 	return r
 }
 
+func (a *appAuditor) getReport(name model.AuditReportName) *model.AuditReport {
+	for _, r := range a.reports {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
 func (a *appAuditor) delReport(name model.AuditReportName) {
 	for i, r := range a.reports {
 		if r.Name == name {
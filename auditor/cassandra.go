@@ -0,0 +1,174 @@
+package auditor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) cassandra() {
+	if !a.app.IsCassandra() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportCassandra)
+
+	availabilityCheck := report.CreateCheck(model.Checks.CassandraAvailability)
+	readLatencyCheck := report.CreateCheck(model.Checks.CassandraReadLatency)
+	writeLatencyCheck := report.CreateCheck(model.Checks.CassandraWriteLatency)
+	compactionsCheck := report.CreateCheck(model.Checks.CassandraCompactions)
+	droppedCheck := report.CreateCheck(model.Checks.CassandraDroppedMessages)
+	hintsCheck := report.CreateCheck(model.Checks.CassandraHints)
+
+	nodesTable := report.GetOrCreateTable("Node", "Status", "Pending compactions", "Dropped messages", "Hints")
+	compactionsChart := report.GetOrCreateChart("Pending compactions", nil)
+	droppedChart := report.GetOrCreateChart("Dropped messages, per second", nil)
+	hintsChart := report.GetOrCreateChart("Hints, per second", nil)
+
+	instancesByIP := map[string]*model.Instance{}
+	for _, i := range a.app.Instances {
+		for l := range i.TcpListens {
+			instancesByIP[l.IP] = i
+		}
+	}
+
+	keyspaces := map[string]*cassandraKeyspace{}
+	downEndpoints := map[string]bool{}
+	dropped := timeseries.NewAggregate(timeseries.NanSum)
+	hints := timeseries.NewAggregate(timeseries.NanSum)
+	for _, i := range a.app.Instances {
+		if i.Cassandra == nil {
+			continue
+		}
+		c := i.Cassandra
+		obsolete := i.IsObsolete()
+		if !obsolete && !c.IsUp() {
+			availabilityCheck.AddItem(i.Name)
+		}
+		if obsolete {
+			continue
+		}
+		if c.IsUp() {
+			// the peers reported down by a live node are unavailable even if they still expose their metrics
+			for endpoint, ts := range c.Endpoints {
+				if ts.Last() == 0 {
+					downEndpoints[endpoint] = true
+				}
+			}
+		}
+		for keyspace, ts := range c.ReadLatency {
+			cassandraGetOrCreateKeyspace(keyspaces, keyspace).read.Add(ts)
+		}
+		for keyspace, ts := range c.WriteLatency {
+			cassandraGetOrCreateKeyspace(keyspaces, keyspace).write.Add(ts)
+		}
+		var problems []string
+		if c.PendingCompactions.Last() > compactionsCheck.Threshold {
+			compactionsCheck.AddItem(i.Name)
+			problems = append(problems, "too many pending compactions")
+		}
+		nodeDropped := timeseries.NewAggregate(timeseries.NanSum)
+		for _, ts := range c.DroppedMessages {
+			nodeDropped.Add(ts)
+		}
+		dropped.Add(nodeDropped.Get())
+		hints.Add(c.Hints)
+
+		if compactionsChart != nil {
+			compactionsChart.AddSeries(i.Name, c.PendingCompactions)
+		}
+		if droppedChart != nil {
+			droppedChart.AddSeries(i.Name, nodeDropped.Get())
+		}
+		if hintsChart != nil {
+			hintsChart.AddSeries(i.Name, c.Hints)
+		}
+		if nodesTable != nil {
+			status := model.NewTableCell().SetStatus(model.OK, "up")
+			if !c.IsUp() {
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			} else if len(problems) > 0 {
+				status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+			}
+			nodesTable.AddRow(
+				model.NewTableCell(i.Name),
+				status,
+				model.NewTableCell(utils.FormatFloat(c.PendingCompactions.Last())),
+				model.NewTableCell(utils.FormatFloat(nodeDropped.Get().Reduce(timeseries.NanSum)*float32(a.w.Ctx.Step))),
+				model.NewTableCell(utils.FormatFloat(c.Hints.Reduce(timeseries.NanSum)*float32(a.w.Ctx.Step))),
+			)
+		}
+	}
+	for endpoint := range downEndpoints {
+		if i := instancesByIP[endpoint]; i != nil {
+			availabilityCheck.AddItem(i.Name)
+		} else {
+			availabilityCheck.AddItem(endpoint)
+		}
+	}
+	if v := dropped.Get().Reduce(timeseries.NanSum); !timeseries.IsNaN(v) {
+		droppedCheck.Inc(int64(v * float32(a.w.Ctx.Step)))
+	}
+	if v := hints.Get().Reduce(timeseries.NanSum); !timeseries.IsNaN(v) {
+		hintsCheck.Inc(int64(v * float32(a.w.Ctx.Step)))
+	}
+
+	names := make([]string, 0, len(keyspaces))
+	for name := range keyspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	keyspacesTable := report.GetOrCreateTable("Keyspace", "Status", "Read latency (p99)", "Write latency (p99)")
+	for _, name := range names {
+		ks := keyspaces[name]
+		read, write := ks.read.Get(), ks.write.Get()
+		var problems []string
+		if read.Last() > readLatencyCheck.Threshold {
+			readLatencyCheck.AddItem(name)
+			problems = append(problems, "slow reads")
+		}
+		if write.Last() > writeLatencyCheck.Threshold {
+			writeLatencyCheck.AddItem(name)
+			problems = append(problems, "slow writes")
+		}
+		report.
+			GetOrCreateChartInGroup("Latency of <selector>, seconds", name, nil).
+			AddSeries("read p99", read).
+			AddSeries("write p99", write)
+
+		if keyspacesTable == nil {
+			continue
+		}
+		status := model.NewTableCell().SetStatus(model.OK, "ok")
+		if len(problems) > 0 {
+			status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+		}
+		keyspacesTable.AddRow(
+			model.NewTableCell(name),
+			status,
+			model.NewTableCell(utils.FormatLatency(read.Last())),
+			model.NewTableCell(utils.FormatLatency(write.Last())),
+		)
+	}
+}
+
+type cassandraKeyspace struct {
+	read  *timeseries.Aggregate
+	write *timeseries.Aggregate
+}
+
+func cassandraGetOrCreateKeyspace(keyspaces map[string]*cassandraKeyspace, name string) *cassandraKeyspace {
+	ks := keyspaces[name]
+	if ks == nil {
+		// the slowest node determines the latency of the keyspace
+		ks = &cassandraKeyspace{
+			read:  timeseries.NewAggregate(timeseries.Max),
+			write: timeseries.NewAggregate(timeseries.Max),
+		}
+		keyspaces[name] = ks
+	}
+	return ks
+}
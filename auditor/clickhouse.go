@@ -0,0 +1,162 @@
+package auditor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) clickhouse() {
+	if !a.app.IsClickHouse() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportClickHouse)
+
+	availabilityCheck := report.CreateCheck(model.Checks.ClickHouseAvailability)
+	partsCheck := report.CreateCheck(model.Checks.ClickHouseParts)
+	mergesCheck := report.CreateCheck(model.Checks.ClickHouseMerges)
+	replicationQueueCheck := report.CreateCheck(model.Checks.ClickHouseReplicationQueue)
+	replicationDelayCheck := report.CreateCheck(model.Checks.ClickHouseReplicationDelay)
+	keeperErrorsCheck := report.CreateCheck(model.Checks.ClickHouseKeeperErrors)
+	failedQueriesCheck := report.CreateCheck(model.Checks.ClickHouseFailedQueries)
+	memoryLimitCheck := report.CreateCheck(model.Checks.ClickHouseMemoryLimit)
+	diskUsageCheck := report.CreateCheck(model.Checks.ClickHouseDiskUsage)
+
+	serversTable := report.GetOrCreateTable("Server", "Status", "Parts per partition", "Merge pool", "Replication queue", "Replication delay", "Readonly replicas")
+	disksTable := report.GetOrCreateTable("Disk", "Server", "Used", "Free", "Total")
+	partsChart := report.GetOrCreateChart("Max parts per partition", nil)
+	mergesChart := report.GetOrCreateChart("Merge and mutation pool usage, %", nil)
+	replicationQueueChart := report.GetOrCreateChart("Replication queue size", nil)
+	replicationDelayChart := report.GetOrCreateChart("Replication delay, seconds", nil)
+	keeperErrorsChart := report.GetOrCreateChart("ZooKeeper/Keeper session errors, per second", nil)
+	queriesChart := report.GetOrCreateChartGroup("Queries <selector>, per second", nil)
+	diskUsageChart := report.GetOrCreateChart("Disk usage, %", nil)
+
+	keeperErrors := timeseries.NewAggregate(timeseries.NanSum)
+	memoryLimitExceeded := timeseries.NewAggregate(timeseries.NanSum)
+	for _, i := range a.app.Instances {
+		ch := i.ClickHouse
+		if ch == nil {
+			continue
+		}
+		obsolete := i.IsObsolete()
+		if !obsolete && !ch.IsUp() {
+			availabilityCheck.AddItem(i.Name)
+		}
+		if obsolete {
+			continue
+		}
+		var problems []string
+		if ch.MaxPartsPerPartition.Last() > partsCheck.Threshold {
+			partsCheck.AddItem(i.Name)
+			problems = append(problems, "too many parts")
+		}
+		mergesPoolUsage := ch.MergesPoolUsage()
+		if mergesPoolUsage.Last() > mergesCheck.Threshold {
+			mergesCheck.AddItem(i.Name)
+			problems = append(problems, "merge backlog")
+		}
+		if ch.ReplicationQueueSize.Last() > replicationQueueCheck.Threshold {
+			replicationQueueCheck.AddItem(i.Name)
+			problems = append(problems, "large replication queue")
+		}
+		if ch.ReplicationDelay.Last() > replicationDelayCheck.Threshold {
+			replicationDelayCheck.AddItem(i.Name)
+			problems = append(problems, "replication delay")
+		}
+		if ch.ReadonlyReplicas.Last() > 0 {
+			problems = append(problems, "readonly replicas")
+		}
+		failedQueries := ch.FailedQueriesPercent()
+		if failedQueries.Last() > failedQueriesCheck.Threshold {
+			failedQueriesCheck.AddItem(i.Name)
+			problems = append(problems, "failing queries")
+		}
+		keeperErrors.Add(ch.KeeperErrors)
+		memoryLimitExceeded.Add(ch.MemoryLimitExceeded)
+
+		disks := make([]string, 0, len(ch.Disks))
+		for name := range ch.Disks {
+			disks = append(disks, name)
+		}
+		sort.Strings(disks)
+		for _, name := range disks {
+			d := ch.Disks[name]
+			usage := d.Usage()
+			diskName := name + "@" + i.Name
+			if usage.Last() > diskUsageCheck.Threshold {
+				diskUsageCheck.AddItem(diskName)
+			}
+			if diskUsageChart != nil {
+				diskUsageChart.AddSeries(diskName, usage)
+			}
+			if disksTable != nil {
+				usageCell := model.NewTableCell(utils.FormatFloat(usage.Last())).SetUnit("%")
+				if usage.Last() > diskUsageCheck.Threshold {
+					usageCell.SetStatus(model.WARNING, utils.FormatFloat(usage.Last())+"%")
+				}
+				disksTable.AddRow(
+					model.NewTableCell(name),
+					model.NewTableCell(i.Name),
+					usageCell,
+					bytesCell(d.Available.Last()),
+					bytesCell(d.Total.Last()),
+				)
+			}
+		}
+
+		if partsChart != nil {
+			partsChart.AddSeries(i.Name, ch.MaxPartsPerPartition)
+		}
+		if mergesChart != nil {
+			mergesChart.AddSeries(i.Name, mergesPoolUsage)
+		}
+		if replicationQueueChart != nil {
+			replicationQueueChart.AddSeries(i.Name, ch.ReplicationQueueSize)
+		}
+		if replicationDelayChart != nil {
+			replicationDelayChart.AddSeries(i.Name, ch.ReplicationDelay)
+		}
+		if keeperErrorsChart != nil {
+			keeperErrorsChart.AddSeries(i.Name, ch.KeeperErrors)
+		}
+		if queriesChart != nil {
+			queriesChart.GetOrCreateChart(i.Name).
+				AddSeries("total", ch.Queries).
+				AddSeries("failed", ch.FailedQueries, "red").
+				AddSeries("memory limit exceeded", ch.MemoryLimitExceeded, "orange")
+		}
+		if serversTable != nil {
+			status := model.NewTableCell().SetStatus(model.OK, "up")
+			switch {
+			case !ch.IsUp():
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			case len(problems) > 0:
+				status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+			}
+			delay := model.NewTableCell()
+			if v := ch.ReplicationDelay.Last(); v > 0 {
+				delay.SetValue(utils.FormatDuration(timeseries.Duration(v), 1))
+			}
+			serversTable.AddRow(
+				model.NewTableCell(i.Name),
+				status,
+				model.NewTableCell(utils.FormatFloat(ch.MaxPartsPerPartition.Last())),
+				model.NewTableCell(utils.FormatFloat(mergesPoolUsage.Last())).SetUnit("%"),
+				model.NewTableCell(utils.FormatFloat(ch.ReplicationQueueSize.Last())),
+				delay,
+				model.NewTableCell(utils.FormatFloat(ch.ReadonlyReplicas.Last())),
+			)
+		}
+	}
+	if v := keeperErrors.Get().Reduce(timeseries.NanSum); !timeseries.IsNaN(v) {
+		keeperErrorsCheck.Inc(int64(v * float32(a.w.Ctx.Step)))
+	}
+	if v := memoryLimitExceeded.Get().Reduce(timeseries.NanSum); !timeseries.IsNaN(v) {
+		memoryLimitCheck.Inc(int64(v * float32(a.w.Ctx.Step)))
+	}
+}
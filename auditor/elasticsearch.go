@@ -0,0 +1,143 @@
+package auditor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) elasticsearch() {
+	if !a.app.IsElasticsearch() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportElastic)
+
+	healthCheck := report.CreateCheck(model.Checks.ElasticsearchClusterHealth)
+	unassignedCheck := report.CreateCheck(model.Checks.ElasticsearchUnassigned)
+	heapCheck := report.CreateCheck(model.Checks.ElasticsearchHeapUsage)
+	searchLatencyCheck := report.CreateCheck(model.Checks.ElasticsearchSearchLatency)
+	indexLatencyCheck := report.CreateCheck(model.Checks.ElasticsearchIndexLatency)
+	rejectionsCheck := report.CreateCheck(model.Checks.ElasticsearchRejections)
+
+	nodesTable := report.GetOrCreateTable("Node", "Status", "Heap", "Search latency", "Indexing latency", "Rejections")
+	heapChart := report.GetOrCreateChart("JVM heap usage, %", nil)
+	searchLatencyChart := report.GetOrCreateChart("Search latency, seconds", nil)
+	indexLatencyChart := report.GetOrCreateChart("Indexing latency, seconds", nil)
+	searchesChart := report.GetOrCreateChart("Search queries, per second", nil)
+	indexedChart := report.GetOrCreateChart("Indexed documents, per second", nil)
+	rejectionsChart := report.GetOrCreateChart("Rejected tasks, per second", nil)
+
+	var clusters []*model.ElasticsearchCluster
+	seen := map[*model.ElasticsearchCluster]bool{}
+	rejections := timeseries.NewAggregate(timeseries.NanSum)
+	for _, i := range a.app.Instances {
+		if i.Elasticsearch == nil || i.IsObsolete() {
+			continue
+		}
+		es := i.Elasticsearch
+		if c := es.Cluster; c != nil && !seen[c] {
+			seen[c] = true
+			clusters = append(clusters, c)
+		}
+
+		var problems []string
+		heap := es.HeapUsage()
+		if heap.Last() > heapCheck.Threshold {
+			heapCheck.AddItem(i.Name)
+			problems = append(problems, "high heap usage")
+		}
+		searchLatency, indexLatency := es.SearchLatency(), es.IndexingLatency()
+		if searchLatency.Last() > searchLatencyCheck.Threshold {
+			searchLatencyCheck.AddItem(i.Name)
+			problems = append(problems, "slow searches")
+		}
+		if indexLatency.Last() > indexLatencyCheck.Threshold {
+			indexLatencyCheck.AddItem(i.Name)
+			problems = append(problems, "slow indexing")
+		}
+		rejected := timeseries.NewAggregate(timeseries.NanSum)
+		for _, ts := range es.RejectedTasks {
+			rejected.Add(ts)
+		}
+		nodeRejections := rejected.Get()
+		rejections.Add(nodeRejections)
+
+		if heapChart != nil {
+			heapChart.AddSeries(i.Name, heap)
+		}
+		if searchLatencyChart != nil {
+			searchLatencyChart.AddSeries(i.Name, searchLatency)
+		}
+		if indexLatencyChart != nil {
+			indexLatencyChart.AddSeries(i.Name, indexLatency)
+		}
+		if searchesChart != nil {
+			searchesChart.AddSeries(i.Name, es.Searches)
+		}
+		if indexedChart != nil {
+			indexedChart.AddSeries(i.Name, es.IndexedDocs)
+		}
+		if rejectionsChart != nil {
+			for pool, ts := range es.RejectedTasks {
+				rejectionsChart.AddSeries(i.Name+" "+pool, ts)
+			}
+		}
+		if nodesTable != nil {
+			status := model.NewTableCell().SetStatus(model.OK, "up")
+			switch {
+			case !es.IsUp():
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			case len(problems) > 0:
+				status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+			}
+			nodesTable.AddRow(
+				model.NewTableCell(i.Name).AddTag(es.Node.Value()),
+				status,
+				model.NewTableCell(utils.FormatFloat(heap.Last())).SetUnit("%"),
+				model.NewTableCell(utils.FormatLatency(searchLatency.Last())),
+				model.NewTableCell(utils.FormatLatency(indexLatency.Last())),
+				model.NewTableCell(utils.FormatFloat(nodeRejections.Reduce(timeseries.NanSum)*float32(a.w.Ctx.Step))),
+			)
+		}
+	}
+	if v := rejections.Get().Reduce(timeseries.NanSum); !timeseries.IsNaN(v) {
+		rejectionsCheck.Inc(int64(v * float32(a.w.Ctx.Step)))
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		return clusters[i].Name < clusters[j].Name
+	})
+	clustersTable := report.GetOrCreateTable("Cluster", "Status", "Nodes", "Unassigned shards", "Version")
+	for _, c := range clusters {
+		status := c.Status.Value()
+		if status != "" && status != "green" {
+			healthCheck.AddItem(c.Name)
+		}
+		if v := c.UnassignedShards.Last(); v > 0 {
+			unassignedCheck.Inc(int64(v))
+		}
+		if clustersTable == nil {
+			continue
+		}
+		statusCell := model.NewTableCell()
+		switch status {
+		case "green":
+			statusCell.SetStatus(model.OK, status)
+		case "yellow":
+			statusCell.SetStatus(model.WARNING, status)
+		case "red":
+			statusCell.SetStatus(model.CRITICAL, status)
+		}
+		clustersTable.AddRow(
+			model.NewTableCell(c.Name),
+			statusCell,
+			model.NewTableCell(utils.FormatFloat(c.Nodes.Last())),
+			model.NewTableCell(utils.FormatFloat(c.UnassignedShards.Last())),
+			model.NewTableCell(c.Version.Value()),
+		)
+	}
+}
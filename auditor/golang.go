@@ -0,0 +1,131 @@
+package auditor
+
+import (
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) golang() {
+	if !a.app.IsGo() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportGo)
+	goroutineLeakCheck := report.CreateCheck(model.Checks.GoGoroutineLeak)
+	gcCpuCheck := report.CreateCheck(model.Checks.GoGcCpu)
+	memoryLimitCheck := report.CreateCheck(model.Checks.GoMemoryLimit)
+	schedLatencyCheck := report.CreateCheck(model.Checks.GoSchedulerLatency)
+
+	table := report.GetOrCreateTable("Instance", "Status", "Goroutines", "GC CPU", "Heap", "Go version")
+	goroutinesChart := report.GetOrCreateChart("Goroutines", nil)
+	memoryChart := report.GetOrCreateChartGroup("Memory usage <selector>, bytes", nil)
+	gcCpuChart := report.GetOrCreateChart("GC CPU usage, %", nil)
+	gcPauseChart := report.GetOrCreateChart("GC stop-the-world pauses, seconds/second", nil)
+	schedLatencyChart := report.GetOrCreateChart("Scheduler latency (p99), seconds", nil)
+
+	// the runtime metrics are also shown next to the container metrics to help to tell
+	// a goroutine or heap leak from other memory consumers, and GC overhead from useful work
+	var memoryHeapChart, cpuGcChart *model.Chart
+	if r := a.getReport(model.AuditReportMemory); r != nil {
+		memoryHeapChart = r.GetOrCreateChart("Go heap size, bytes", nil)
+	}
+	if r := a.getReport(model.AuditReportCPU); r != nil {
+		cpuGcChart = r.GetOrCreateChart("Go GC CPU usage, %", nil)
+	}
+
+	for _, i := range a.app.Instances {
+		g := i.GoRuntime
+		if g == nil || i.IsObsolete() {
+			continue
+		}
+		var problems []string
+		if v := a.goroutinesGrowth(g.Goroutines); v > goroutineLeakCheck.Threshold {
+			goroutineLeakCheck.AddItem(i.Name)
+			problems = append(problems, "goroutine leak")
+		}
+		gcCpu := g.GcCpuFraction.Map(func(t timeseries.Time, v float32) float32 { return v * 100 })
+		if gcCpu.Last() > gcCpuCheck.Threshold {
+			gcCpuCheck.AddItem(i.Name)
+			problems = append(problems, "high GC overhead")
+		}
+		if g.MemoryLimitUsage().Last() > memoryLimitCheck.Threshold {
+			memoryLimitCheck.AddItem(i.Name)
+			problems = append(problems, "approaching GOMEMLIMIT")
+		}
+		if g.SchedLatency.Last() > schedLatencyCheck.Threshold {
+			schedLatencyCheck.AddItem(i.Name)
+			problems = append(problems, "high scheduler latency")
+		}
+
+		if goroutinesChart != nil {
+			goroutinesChart.AddSeries(i.Name, g.Goroutines)
+		}
+		if memoryChart != nil {
+			memoryChart.GetOrCreateChart("overview").Feature().AddSeries(i.Name, g.MemoryUsed)
+			memoryChart.GetOrCreateChart(i.Name).
+				AddSeries("total", g.MemoryUsed, "orange").
+				AddSeries("heap in use", g.HeapInuse, "blue").
+				SetThreshold("GOMEMLIMIT", g.SoftMemoryLimit())
+		}
+		if gcCpuChart != nil {
+			gcCpuChart.AddSeries(i.Name, gcCpu)
+		}
+		if gcPauseChart != nil {
+			gcPauseChart.AddSeries(i.Name, g.GcPauseTime)
+		}
+		if schedLatencyChart != nil {
+			schedLatencyChart.AddSeries(i.Name, g.SchedLatency)
+		}
+		if memoryHeapChart != nil {
+			memoryHeapChart.AddSeries(i.Name, g.HeapInuse)
+		}
+		if cpuGcChart != nil {
+			cpuGcChart.AddSeries(i.Name, gcCpu)
+		}
+
+		if table != nil {
+			status := model.NewTableCell().SetStatus(model.OK, "up")
+			switch {
+			case !g.IsUp():
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			case len(problems) > 0:
+				status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+			}
+			heap := model.NewTableCell()
+			if v := g.HeapInuse.Last(); !timeseries.IsNaN(v) {
+				value, unit := utils.FormatBytes(v)
+				heap.SetValue(value).SetUnit(unit)
+			}
+			table.AddRow(
+				model.NewTableCell(i.Name),
+				status,
+				model.NewTableCell(utils.FormatFloat(g.Goroutines.Last())),
+				model.NewTableCell(utils.FormatFloat(gcCpu.Last())).SetUnit("%"),
+				heap,
+				model.NewTableCell(g.Version.Value()),
+			)
+		}
+	}
+}
+
+// goroutinesGrowth returns the growth of the number of goroutines over the last hour in percent,
+// the same way the memory leak is calculated.
+func (a *appAuditor) goroutinesGrowth(goroutines *timeseries.TimeSeries) float32 {
+	v := goroutines.Map(timeseries.ZeroToNan)
+	if v.Reduce(timeseries.NanCount) <= float32(v.Len())*0.8 { // we require 80% of the data to be present
+		return 0
+	}
+	lr := timeseries.NewLinearRegression(v)
+	if lr == nil {
+		return 0
+	}
+	s := lr.Calc(a.w.Ctx.To.Add(-timeseries.Hour))
+	e := lr.Calc(a.w.Ctx.To)
+	if s <= 0 || e <= 0 {
+		return 0
+	}
+	return (e - s) / s * 100
+}
@@ -45,6 +45,21 @@ func (a *appAuditor) instances() {
 			default:
 				status.SetStatus(model.OK, i.Elasticache.Status.Value())
 			}
+		} else if i.CloudSQL != nil {
+			switch {
+			case timeseries.IsNaN(i.CloudSQL.LifeSpan.Last()):
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			case i.CloudSQL.State.Value() != "" && i.CloudSQL.State.Value() != "RUNNING":
+				status.SetStatus(model.WARNING, strings.ToLower(i.CloudSQL.State.Value()))
+			default:
+				status.SetStatus(model.OK, "running")
+			}
+		} else if i.Memorystore != nil || i.AzureDatabase != nil {
+			if i.Node.IsUp() {
+				status.SetStatus(model.OK, "up")
+			} else {
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			}
 		} else if i.Pod == nil {
 			if i.IsUp() {
 				status.SetStatus(model.OK, "ok")
@@ -166,7 +181,7 @@ func (a *appAuditor) instances() {
 		}
 	}
 
-	if a.app.Id.Kind == model.ApplicationKindExternalService {
+	if a.app.Id.Kind == model.ApplicationKindExternalService || a.app.Id.Kind == model.ApplicationKindKubernetesControlPlane {
 		availabilityCheck.SetStatus(model.UNKNOWN, "no data")
 		restartsCheck.SetStatus(model.UNKNOWN, "no data")
 	}
@@ -0,0 +1,147 @@
+package auditor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"golang.org/x/exp/maps"
+)
+
+// runs shorter than this are too noisy to compare their durations
+const jobDurationRegressionMinDuration = timeseries.Minute
+
+func (a *appAuditor) jobs() {
+	cj := a.app.CronJob
+	if cj == nil && len(a.app.Jobs) == 0 {
+		return
+	}
+
+	report := a.addReport(model.AuditReportJobs)
+
+	failedRunsCheck := report.CreateCheck(model.Checks.JobFailedRuns)
+	durationCheck := report.CreateCheck(model.Checks.JobDurationRegression)
+	missedSchedulesCheck := report.CreateCheck(model.Checks.CronJobMissedSchedules)
+
+	now := a.w.Ctx.To
+	runs := maps.Values(a.app.Jobs)
+	startTime := func(j *model.Job) timeseries.Time {
+		if j.StartTime.IsZero() { // the run hasn't been started yet
+			return now
+		}
+		return j.StartTime
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		ti, tj := startTime(runs[i]), startTime(runs[j])
+		if ti == tj {
+			return runs[i].Name > runs[j].Name
+		}
+		return ti.After(tj)
+	})
+
+	var (
+		last          *model.Job
+		previous      []*model.Job
+		lastSucceeded bool
+	)
+	for _, j := range runs {
+		switch j.Status() {
+		case model.JobStatusFailed:
+			if !lastSucceeded {
+				failedRunsCheck.Inc(1)
+			}
+		case model.JobStatusSucceeded:
+			lastSucceeded = true
+			if last == nil {
+				last = j
+			} else {
+				previous = append(previous, j)
+			}
+		case model.JobStatusRunning:
+			if last == nil {
+				last = j
+			}
+		}
+	}
+	if last != nil && len(previous) > 0 {
+		var total timeseries.Duration
+		for _, j := range previous {
+			total += j.Duration(now)
+		}
+		avg := float32(total) / float32(len(previous))
+		if d := last.Duration(now); d >= jobDurationRegressionMinDuration && avg > 0 {
+			durationCheck.SetValue((float32(d)/avg - 1) * 100)
+		}
+	}
+
+	if cj != nil {
+		missedSchedulesCheck.SetValue(float32(cj.MissedScheduleBy(now)))
+		report.GetOrCreateChart("Active runs", nil).Column().AddSeries("active", cj.StatusActive)
+
+		status := model.NewTableCell().SetStatus(model.OK, "ok")
+		switch {
+		case cj.Suspended:
+			status.SetStatus(model.UNKNOWN, "suspended")
+		case missedSchedulesCheck.Value() > missedSchedulesCheck.Threshold:
+			status.SetStatus(model.CRITICAL, "missed schedule")
+		}
+		ago := func(t timeseries.Time) *model.TableCell {
+			if t.IsZero() {
+				return model.NewTableCell().SetStub("never")
+			}
+			return model.NewTableCell(utils.FormatDuration(now.Sub(t), 1) + " ago")
+		}
+		next := model.NewTableCell()
+		if !cj.NextScheduleTime.IsZero() && !cj.Suspended {
+			if cj.NextScheduleTime.After(now) {
+				next.SetValue("in " + utils.FormatDuration(cj.NextScheduleTime.Sub(now), 1))
+			} else {
+				next.SetValue(utils.FormatDuration(now.Sub(cj.NextScheduleTime), 1) + " ago")
+			}
+		}
+		report.GetOrCreateTable("Schedule", "Status", "Concurrency policy", "Last scheduled", "Last successful run", "Next run").AddRow(
+			model.NewTableCell(cj.Schedule.Value()),
+			status,
+			model.NewTableCell(cj.ConcurrencyPolicy.Value()),
+			ago(cj.LastScheduleTime),
+			ago(cj.LastSuccessfulTime),
+			next,
+		)
+	}
+
+	table := report.GetOrCreateTable("Run", "Status", "Started", "Duration", "Retries")
+	if table == nil {
+		return
+	}
+	for _, j := range runs {
+		status := model.NewTableCell()
+		switch s := j.Status(); s {
+		case model.JobStatusSucceeded, model.JobStatusRunning:
+			status.SetStatus(model.OK, string(s))
+		case model.JobStatusFailed:
+			msg := string(s)
+			if reason := j.FailureReason.Value(); reason != "" {
+				msg += fmt.Sprintf(" (%s)", reason)
+			}
+			status.SetStatus(model.CRITICAL, msg)
+		default:
+			status.SetStatus(model.UNKNOWN, string(s))
+		}
+		started := model.NewTableCell()
+		if !j.StartTime.IsZero() {
+			started.SetValue(utils.FormatDuration(now.Sub(j.StartTime), 1) + " ago")
+		}
+		duration := model.NewTableCell()
+		if d := j.Duration(now); d > 0 {
+			duration.SetValue(utils.FormatDuration(d, 2))
+		}
+		retries := model.NewTableCell()
+		if r := j.Retries(); r > 0 {
+			retries.SetValue(strconv.Itoa(r))
+		}
+		table.AddRow(model.NewTableCell(j.Name), status, started, duration, retries)
+	}
+}
@@ -0,0 +1,167 @@
+package auditor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) kafka() {
+	if !a.app.IsKafka() && len(a.app.KafkaConsumerGroups) == 0 {
+		return
+	}
+
+	report := a.addReport(model.AuditReportKafka)
+
+	if !a.app.IsKafka() {
+		// the app consumes messages: its consumer groups are checked to blame it for the lag
+		a.kafkaConsumerGroups(report, a.app.KafkaConsumerGroups)
+		return
+	}
+
+	availabilityCheck := report.CreateCheck(model.Checks.KafkaAvailability)
+	underReplicatedCheck := report.CreateCheck(model.Checks.KafkaUnderReplicated)
+	offlineCheck := report.CreateCheck(model.Checks.KafkaOfflinePartitions)
+	isrShrinksCheck := report.CreateCheck(model.Checks.KafkaIsrShrinks)
+
+	table := report.GetOrCreateTable("Broker", "Status", "Id", "Controller", "Under-replicated partitions", "Offline partitions", "ISR shrinks")
+	underReplicatedChart := report.GetOrCreateChart("Under-replicated partitions", nil)
+	offlineChart := report.GetOrCreateChart("Offline partitions", nil)
+	isrShrinksChart := report.GetOrCreateChart("ISR shrinks, per second", nil)
+
+	clusters := map[*model.KafkaCluster]bool{}
+	var underReplicated, offline *timeseries.Aggregate
+	isrShrinks := timeseries.NewAggregate(timeseries.NanSum)
+	for _, i := range a.app.Instances {
+		if i.Kafka == nil {
+			continue
+		}
+		if i.Kafka.Cluster != nil {
+			clusters[i.Kafka.Cluster] = true
+		}
+		obsolete := i.IsObsolete()
+		if !obsolete && !i.Kafka.IsUp() {
+			availabilityCheck.AddItem(i.Name)
+		}
+		if obsolete {
+			continue
+		}
+		if !i.Kafka.UnderReplicatedPartitions.IsEmpty() {
+			if underReplicated == nil {
+				underReplicated = timeseries.NewAggregate(timeseries.NanSum)
+			}
+			underReplicated.Add(i.Kafka.UnderReplicatedPartitions)
+		}
+		// only the active controller reports the offline partitions, the other brokers report zero
+		if !i.Kafka.OfflinePartitions.IsEmpty() {
+			if offline == nil {
+				offline = timeseries.NewAggregate(timeseries.NanSum)
+			}
+			offline.Add(i.Kafka.OfflinePartitions)
+		}
+		isrShrinks.Add(i.Kafka.IsrShrinks)
+		if isrShrinksChart != nil {
+			isrShrinksChart.AddSeries(i.Name, i.Kafka.IsrShrinks)
+		}
+
+		if table != nil {
+			status := model.NewTableCell().SetStatus(model.OK, "up")
+			if !i.Kafka.IsUp() {
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			}
+			controller := model.NewTableCell()
+			if i.Kafka.ActiveController.Last() > 0 {
+				controller.SetIcon("mdi-crown-outline", "rgba(0,0,0,0.87)")
+			}
+			table.AddRow(
+				model.NewTableCell(i.Name),
+				status,
+				model.NewTableCell(i.Kafka.BrokerId.Value()),
+				controller,
+				model.NewTableCell(utils.FormatFloat(i.Kafka.UnderReplicatedPartitions.Last())),
+				model.NewTableCell(utils.FormatFloat(i.Kafka.OfflinePartitions.Last())),
+				model.NewTableCell(utils.FormatFloat(i.Kafka.IsrShrinks.Reduce(timeseries.NanSum)*float32(a.w.Ctx.Step))),
+			)
+		}
+	}
+
+	var groups []*model.KafkaConsumerGroup
+	for cluster := range clusters {
+		// kafka-exporter reports the partitions of the whole cluster, so its data takes precedence over JMX
+		if !cluster.UnderReplicatedPartitions.IsEmpty() {
+			underReplicated = timeseries.NewAggregate(timeseries.NanSum).Add(cluster.UnderReplicatedPartitions)
+		}
+		if !cluster.OfflinePartitions.IsEmpty() {
+			offline = timeseries.NewAggregate(timeseries.NanSum).Add(cluster.OfflinePartitions)
+		}
+		for _, g := range cluster.ConsumerGroups {
+			groups = append(groups, g)
+		}
+	}
+
+	if underReplicated != nil {
+		ts := underReplicated.Get()
+		underReplicatedCheck.Inc(int64(ts.Last()))
+		if underReplicatedChart != nil {
+			underReplicatedChart.AddSeries("under-replicated", ts)
+		}
+	}
+	if offline != nil {
+		ts := offline.Get()
+		offlineCheck.Inc(int64(ts.Last()))
+		if offlineChart != nil {
+			offlineChart.AddSeries("offline", ts)
+		}
+	}
+	if shrinks := isrShrinks.Get().Reduce(timeseries.NanSum); !timeseries.IsNaN(shrinks) {
+		isrShrinksCheck.Inc(int64(shrinks * float32(a.w.Ctx.Step)))
+	}
+
+	a.kafkaConsumerGroups(report, groups)
+}
+
+func (a *appAuditor) kafkaConsumerGroups(report *model.AuditReport, groups []*model.KafkaConsumerGroup) {
+	lagCheck := report.CreateCheck(model.Checks.KafkaConsumerLag)
+	if len(groups) == 0 {
+		return
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	table := report.GetOrCreateTable("Consumer group", "Status", "Members", "Lag", "Growing for", "Consumers")
+	lagChart := report.GetOrCreateChart("Consumer lag, messages", nil)
+	for _, g := range groups {
+		lag := g.TotalLag()
+		growing := model.KafkaLagGrowthDuration(lag)
+		status := model.NewTableCell().SetStatus(model.OK, "ok")
+		if growing > 0 && growing >= timeseries.Duration(lagCheck.Threshold) {
+			lagCheck.AddItem(g.Name)
+			status.SetStatus(model.WARNING, "falling behind")
+		}
+		if lagChart != nil {
+			lagChart.AddSeries(g.Name, lag)
+		}
+		if table == nil {
+			continue
+		}
+		growingCell := model.NewTableCell()
+		if growing > 0 {
+			growingCell.SetValue(utils.FormatDuration(growing, 1))
+		}
+		var consumers []string
+		for _, c := range g.Consumers {
+			consumers = append(consumers, c.Id.Name)
+		}
+		table.AddRow(
+			model.NewTableCell(g.Name),
+			status,
+			model.NewTableCell(utils.FormatFloat(g.Members.Last())),
+			model.NewTableCell(utils.FormatFloat(lag.Last())),
+			growingCell,
+			model.NewTableCell(strings.Join(consumers, ", ")),
+		)
+	}
+}
@@ -0,0 +1,201 @@
+package auditor
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"golang.org/x/exp/maps"
+)
+
+func (a *appAuditor) kubernetes() {
+	cp := a.w.KubernetesControlPlane
+	if a.app.Id.Kind != model.ApplicationKindKubernetesControlPlane || cp == nil {
+		return
+	}
+
+	report := a.addReport(model.AuditReportKubernetes)
+
+	apiServerLatencyCheck := report.CreateCheck(model.Checks.KubernetesAPIServerLatency)
+	apiServerErrorsCheck := report.CreateCheck(model.Checks.KubernetesAPIServerErrors)
+	etcdFsyncCheck := report.CreateCheck(model.Checks.KubernetesEtcdFsyncLatency)
+	etcdCommitCheck := report.CreateCheck(model.Checks.KubernetesEtcdCommitLatency)
+	etcdLeaderChangesCheck := report.CreateCheck(model.Checks.KubernetesEtcdLeaderChanges)
+	pendingPodsCheck := report.CreateCheck(model.Checks.KubernetesSchedulerPendingPods)
+	plegCheck := report.CreateCheck(model.Checks.KubernetesKubeletPLEG)
+	coreDNSErrorsCheck := report.CreateCheck(model.Checks.KubernetesCoreDNSErrors)
+	coreDNSLatencyCheck := report.CreateCheck(model.Checks.KubernetesCoreDNSLatency)
+
+	apiServersTable := report.GetOrCreateTable("API server", "Status", "Requests", "Errors", "Latency (p99)")
+	for _, instance := range kubernetesSortedNames(cp.APIServers) {
+		s := cp.APIServers[instance]
+		errorsPercent := s.ErrorsPercent()
+		var problems []string
+		if s.Latency.Last() > apiServerLatencyCheck.Threshold {
+			apiServerLatencyCheck.AddItem(instance)
+			problems = append(problems, "high latency")
+		}
+		if errorsPercent.Last() > apiServerErrorsCheck.Threshold {
+			apiServerErrorsCheck.AddItem(instance)
+			problems = append(problems, "5xx errors")
+		}
+		report.
+			GetOrCreateChartInGroup("API server requests <selector>, per second", instance, nil).
+			AddSeries("total", s.Requests).
+			AddSeries("5xx", s.Errors, "red")
+		report.
+			GetOrCreateChartInGroup("API server latency, seconds", "p99", nil).
+			AddSeries(instance, s.Latency)
+		if apiServersTable == nil {
+			continue
+		}
+		apiServersTable.AddRow(
+			model.NewTableCell(instance),
+			kubernetesStatus(problems),
+			model.NewTableCell(utils.FormatFloat(s.Requests.Last())).SetUnit("/s"),
+			model.NewTableCell(utils.FormatFloat(errorsPercent.Last())).SetUnit("%"),
+			model.NewTableCell(utils.FormatLatency(s.Latency.Last())),
+		)
+	}
+
+	// every member observes the same leader election, so the number of changes is the maximum across the members
+	leaderChanges := timeseries.NewAggregate(timeseries.Max)
+	etcdTable := report.GetOrCreateTable("etcd member", "Status", "WAL fsync (p99)", "Commit (p99)", "Leader changes")
+	step := float32(a.w.Ctx.Step)
+	for _, instance := range kubernetesSortedNames(cp.Etcd) {
+		e := cp.Etcd[instance]
+		leaderChanges.Add(e.LeaderChanges)
+		var problems []string
+		if e.HasLeader.Last() == 0 {
+			problems = append(problems, "no leader")
+		}
+		if e.WalFsyncLatency.Last() > etcdFsyncCheck.Threshold {
+			etcdFsyncCheck.AddItem(instance)
+			problems = append(problems, "slow WAL fsync")
+		}
+		if e.CommitLatency.Last() > etcdCommitCheck.Threshold {
+			etcdCommitCheck.AddItem(instance)
+			problems = append(problems, "slow commits")
+		}
+		report.
+			GetOrCreateChartInGroup("etcd disk latency <selector>, seconds", instance, nil).
+			AddSeries("WAL fsync p99", e.WalFsyncLatency).
+			AddSeries("commit p99", e.CommitLatency)
+		if etcdTable == nil {
+			continue
+		}
+		etcdTable.AddRow(
+			model.NewTableCell(instance),
+			kubernetesStatus(problems),
+			model.NewTableCell(utils.FormatLatency(e.WalFsyncLatency.Last())),
+			model.NewTableCell(utils.FormatLatency(e.CommitLatency.Last())),
+			model.NewTableCell(utils.FormatFloat(e.LeaderChanges.Reduce(timeseries.NanSum)*step)),
+		)
+	}
+	if changes := leaderChanges.Get(); !changes.IsEmpty() {
+		if v := changes.Reduce(timeseries.NanSum); !timeseries.IsNaN(v) {
+			etcdLeaderChangesCheck.Inc(int64(v * step))
+		}
+		report.GetOrCreateChart("etcd leader changes", nil).Column().AddSeries("changes", changes.Map(func(t timeseries.Time, v float32) float32 {
+			return v * step
+		}))
+	}
+
+	pending := map[string]*timeseries.Aggregate{}
+	for _, instance := range kubernetesSortedNames(cp.Schedulers) {
+		for queue, ts := range cp.Schedulers[instance].PendingPods {
+			// only the leader schedules pods, the other replicas report empty queues
+			if pending[queue] == nil {
+				pending[queue] = timeseries.NewAggregate(timeseries.Max)
+			}
+			pending[queue].Add(ts)
+		}
+	}
+	if len(pending) > 0 {
+		chart := report.GetOrCreateChart("Pending pods", nil).Stacked()
+		queues := maps.Keys(pending)
+		slices.Sort(queues)
+		for _, queue := range queues {
+			ts := pending[queue].Get()
+			if queue == model.SchedulerQueueUnschedulable {
+				if v := ts.Last(); !timeseries.IsNaN(v) {
+					pendingPodsCheck.SetValue(v)
+				}
+			}
+			chart.AddSeries(queue, ts)
+		}
+	}
+
+	kubeletsTable := report.GetOrCreateTable("Node", "Status", "PLEG relist (p99)")
+	for _, node := range kubernetesSortedNames(cp.Kubelets) {
+		k := cp.Kubelets[node]
+		var problems []string
+		if k.PlegRelistLatency.Last() > plegCheck.Threshold {
+			plegCheck.AddItem(node)
+			problems = append(problems, "slow PLEG relist")
+		}
+		report.
+			GetOrCreateChartInGroup("Kubelet PLEG relist duration, seconds", "p99", nil).
+			AddSeries(node, k.PlegRelistLatency)
+		if kubeletsTable == nil {
+			continue
+		}
+		nodeCell := model.NewTableCell(node)
+		if a.w.GetNode(node) != nil {
+			nodeCell.Link = model.NewRouterLink(node, "overview").SetParam("view", "nodes").SetParam("id", node)
+		}
+		kubeletsTable.AddRow(
+			nodeCell,
+			kubernetesStatus(problems),
+			model.NewTableCell(utils.FormatLatency(k.PlegRelistLatency.Last())),
+		)
+	}
+
+	coreDNSTable := report.GetOrCreateTable("CoreDNS", "Status", "Requests", "SERVFAIL", "Latency (p99)")
+	for _, instance := range kubernetesSortedNames(cp.CoreDNS) {
+		d := cp.CoreDNS[instance]
+		errorsPercent := d.ErrorsPercent()
+		var problems []string
+		if errorsPercent.Last() > coreDNSErrorsCheck.Threshold {
+			coreDNSErrorsCheck.AddItem(instance)
+			problems = append(problems, "SERVFAIL responses")
+		}
+		if d.Latency.Last() > coreDNSLatencyCheck.Threshold {
+			coreDNSLatencyCheck.AddItem(instance)
+			problems = append(problems, "high latency")
+		}
+		report.
+			GetOrCreateChartInGroup("CoreDNS requests <selector>, per second", instance, nil).
+			AddSeries("total", d.Requests).
+			AddSeries("SERVFAIL", d.ServFail, "red")
+		report.
+			GetOrCreateChartInGroup("CoreDNS latency, seconds", "p99", nil).
+			AddSeries(instance, d.Latency)
+		if coreDNSTable == nil {
+			continue
+		}
+		coreDNSTable.AddRow(
+			model.NewTableCell(instance),
+			kubernetesStatus(problems),
+			model.NewTableCell(utils.FormatFloat(d.Requests.Last())).SetUnit("/s"),
+			model.NewTableCell(utils.FormatFloat(errorsPercent.Last())).SetUnit("%"),
+			model.NewTableCell(utils.FormatLatency(d.Latency.Last())),
+		)
+	}
+}
+
+func kubernetesStatus(problems []string) *model.TableCell {
+	status := model.NewTableCell().SetStatus(model.OK, "ok")
+	if len(problems) > 0 {
+		status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+	}
+	return status
+}
+
+func kubernetesSortedNames[T any](components map[string]T) []string {
+	names := maps.Keys(components)
+	slices.Sort(names)
+	return names
+}
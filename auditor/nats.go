@@ -0,0 +1,154 @@
+package auditor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) nats() {
+	if !a.app.IsNats() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportNats)
+
+	availabilityCheck := report.CreateCheck(model.Checks.NatsAvailability)
+	slowConsumersCheck := report.CreateCheck(model.Checks.NatsSlowConsumers)
+	pendingCheck := report.CreateCheck(model.Checks.NatsPendingGrowth)
+	ackPendingCheck := report.CreateCheck(model.Checks.NatsAckPending)
+	noConsumersCheck := report.CreateCheck(model.Checks.NatsNoConsumers)
+
+	serversTable := report.GetOrCreateTable("Server", "Status", "Connections", "In", "Out", "Slow consumers")
+	inChart := report.GetOrCreateChart("Messages received, per second", nil)
+	outChart := report.GetOrCreateChart("Messages sent, per second", nil)
+	slowConsumersChart := report.GetOrCreateChart("Slow consumers, per second", nil)
+
+	streams := map[string]*timeseries.Aggregate{}
+	consumers := map[model.NatsConsumerKey]*natsConsumer{}
+	slowConsumers := timeseries.NewAggregate(timeseries.NanSum)
+	for _, i := range a.app.Instances {
+		if i.Nats == nil {
+			continue
+		}
+		obsolete := i.IsObsolete()
+		if !obsolete && !i.Nats.IsUp() {
+			availabilityCheck.AddItem(i.Name)
+		}
+		if obsolete {
+			continue
+		}
+		slowConsumers.Add(i.Nats.SlowConsumers)
+		// each replica of a stream reports its consumers, so the maximum is taken
+		for stream, count := range i.Nats.StreamConsumers {
+			s := streams[stream]
+			if s == nil {
+				s = timeseries.NewAggregate(timeseries.Max)
+				streams[stream] = s
+			}
+			s.Add(count)
+		}
+		for k, c := range i.Nats.Consumers {
+			ac := consumers[k]
+			if ac == nil {
+				ac = &natsConsumer{
+					pending:    timeseries.NewAggregate(timeseries.Max),
+					ackPending: timeseries.NewAggregate(timeseries.Max),
+				}
+				consumers[k] = ac
+			}
+			ac.pending.Add(c.Pending)
+			ac.ackPending.Add(c.AckPending)
+		}
+		if inChart != nil {
+			inChart.AddSeries(i.Name, i.Nats.InMsgs)
+		}
+		if outChart != nil {
+			outChart.AddSeries(i.Name, i.Nats.OutMsgs)
+		}
+		if slowConsumersChart != nil {
+			slowConsumersChart.AddSeries(i.Name, i.Nats.SlowConsumers)
+		}
+		if serversTable != nil {
+			status := model.NewTableCell().SetStatus(model.OK, "up")
+			if !i.Nats.IsUp() {
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			}
+			serversTable.AddRow(
+				model.NewTableCell(i.Name),
+				status,
+				model.NewTableCell(utils.FormatFloat(i.Nats.Connections.Last())),
+				model.NewTableCell(utils.FormatFloat(i.Nats.InMsgs.Last())).SetUnit("/s"),
+				model.NewTableCell(utils.FormatFloat(i.Nats.OutMsgs.Last())).SetUnit("/s"),
+				model.NewTableCell(utils.FormatFloat(i.Nats.SlowConsumers.Reduce(timeseries.NanSum)*float32(a.w.Ctx.Step))),
+			)
+		}
+	}
+	if v := slowConsumers.Get().Reduce(timeseries.NanSum); !timeseries.IsNaN(v) {
+		slowConsumersCheck.Inc(int64(v * float32(a.w.Ctx.Step)))
+	}
+
+	for stream, s := range streams {
+		count := s.Get()
+		if count.Last() == 0 && count.Reduce(timeseries.Max) > 0 {
+			noConsumersCheck.AddItem(stream)
+		}
+	}
+
+	keys := make([]model.NatsConsumerKey, 0, len(consumers))
+	for k := range consumers {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	consumersTable := report.GetOrCreateTable("JetStream consumer", "Status", "Pending", "Unacked", "Growing for")
+	for _, k := range keys {
+		c := consumers[k]
+		name := k.String()
+		pending, ackPending := c.pending.Get(), c.ackPending.Get()
+		var problems []string
+		growing := model.BacklogGrowthDuration(pending)
+		if growing > 0 && growing >= timeseries.Duration(pendingCheck.Threshold) {
+			pendingCheck.AddItem(name)
+			problems = append(problems, "falling behind")
+		}
+		if ackPending.Last() > ackPendingCheck.Threshold {
+			ackPendingCheck.AddItem(name)
+			problems = append(problems, "too many unacked")
+		}
+		report.
+			GetOrCreateChartInGroup("Pending messages <selector>", name, nil).
+			AddSeries("pending", pending).
+			AddSeries("unacked", ackPending)
+
+		if consumersTable == nil {
+			continue
+		}
+		status := model.NewTableCell().SetStatus(model.OK, "ok")
+		if len(problems) > 0 {
+			status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+		}
+		growingCell := model.NewTableCell()
+		if growing > 0 {
+			growingCell.SetValue(utils.FormatDuration(growing, 1))
+		}
+		consumersTable.AddRow(
+			model.NewTableCell(name),
+			status,
+			model.NewTableCell(utils.FormatFloat(pending.Last())),
+			model.NewTableCell(utils.FormatFloat(ackPending.Last())),
+			growingCell,
+		)
+	}
+
+	a.messagingClients(report, model.ProtocolNats)
+}
+
+type natsConsumer struct {
+	pending    *timeseries.Aggregate
+	ackPending *timeseries.Aggregate
+}
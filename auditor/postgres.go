@@ -76,6 +76,7 @@ func (a *appAuditor) postgres() {
 		primaryLsnTs := primaryLsn.Get()
 		lag := pgReplicationLag(primaryLsnTs, i.Postgres.WalReplayLsn)
 		report.GetOrCreateChart("Replication lag, bytes", nil).AddSeries(i.Name, lag)
+		report.GetOrCreateChart("Replication lag, seconds", nil).AddSeries(i.Name, i.Postgres.ReplicationLagSeconds)
 
 		if i.IsObsolete() {
 			continue
@@ -103,6 +104,9 @@ func (a *appAuditor) postgres() {
 			}
 		}
 		lagCell := checkReplicationLag(i.Name, primaryLsnTs, lag, role, replicationCheck)
+		if primaryLsnTs.IsEmpty() {
+			lagCell = checkReplicationLagSeconds(i.Name, i.Postgres.ReplicationLagSeconds, replicationCheck)
+		}
 		report.
 			GetOrCreateTable("Instance", "Role", "Status", "Queries", "Latency", "Replication lag").
 			AddRow(
@@ -159,6 +163,19 @@ func checkReplicationLag(instanceName string, primaryLsn, lag *timeseries.TimeSe
 	return res
 }
 
+func checkReplicationLagSeconds(instanceName string, lag *timeseries.TimeSeries, check *model.Check) *model.TableCell {
+	res := &model.TableCell{}
+	last := lag.Last()
+	if timeseries.IsNaN(last) {
+		return res
+	}
+	if last > check.Threshold {
+		check.AddItem(instanceName)
+	}
+	res.Value = utils.FormatDuration(timeseries.Duration(last), 1)
+	return res
+}
+
 func pgReplicationLag(primaryLsn, replayLsn *timeseries.TimeSeries) *timeseries.TimeSeries {
 	return timeseries.Aggregate2(
 		primaryLsn, replayLsn,
@@ -0,0 +1,202 @@
+package auditor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) proxy() {
+	if !a.app.IsProxy() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportProxy)
+
+	errorsCheck := report.CreateCheck(model.Checks.ProxyUpstreamErrors)
+	connectFailuresCheck := report.CreateCheck(model.Checks.ProxyUpstreamConnectFailures)
+	circuitBreakersCheck := report.CreateCheck(model.Checks.ProxyCircuitBreakers)
+
+	routes := map[string]*proxyStats{}
+	upstreams := map[string]*proxyStats{}
+	for _, i := range a.app.Instances {
+		if i.Proxy == nil || i.IsObsolete() {
+			continue
+		}
+		for name, r := range i.Proxy.Routes {
+			proxyGetOrCreateStats(routes, name).add(r)
+		}
+		for name, u := range i.Proxy.Upstreams {
+			s := proxyGetOrCreateStats(upstreams, name)
+			s.add(u.ProxyStats)
+			s.connectFailures.Add(u.ConnectFailures)
+			s.circuitBreakerTrips.Add(u.CircuitBreakerTrips)
+			if u.ServiceName != "" {
+				s.serviceName, s.serviceNamespace = u.ServiceName, u.ServiceNamespace
+			}
+		}
+	}
+
+	routesTable := report.GetOrCreateTable("Route", "Requests", "Errors", "Latency (p95)")
+	for _, name := range proxySortedNames(routes) {
+		r := routes[name].get()
+		latency := r.Latency(0.95)
+		report.
+			GetOrCreateChartInGroup("Requests to <selector>, per second", name, nil).
+			AddSeries("total", r.Requests).
+			AddSeries("5xx", r.Errors, "red")
+		report.
+			GetOrCreateChartInGroup("Latency of <selector>, seconds", name, nil).
+			AddSeries("p95", latency)
+		if routesTable == nil {
+			continue
+		}
+		routesTable.AddRow(
+			model.NewTableCell(name),
+			model.NewTableCell(utils.FormatFloat(r.Requests.Last())).SetUnit("/s"),
+			model.NewTableCell(utils.FormatFloat(r.ErrorsPercent().Last())).SetUnit("%"),
+			model.NewTableCell(utils.FormatLatency(latency.Last())),
+		)
+	}
+
+	upstreamsTable := report.GetOrCreateTable("Upstream", "Status", "Requests", "Errors", "Latency", "Connection failures", "Circuit breaker trips")
+	step := float32(a.w.Ctx.Step)
+	for _, name := range proxySortedNames(upstreams) {
+		s := upstreams[name]
+		u := s.get()
+		latency := u.Latency(0.95)
+		errorsPercent := u.ErrorsPercent()
+		connectFailures, circuitBreakerTrips := s.connectFailures.Get(), s.circuitBreakerTrips.Get()
+		connectFailuresCount := connectFailures.Reduce(timeseries.NanSum) * step
+		circuitBreakerTripsCount := circuitBreakerTrips.Reduce(timeseries.NanSum) * step
+
+		var problems []string
+		if errorsPercent.Last() > errorsCheck.Threshold {
+			errorsCheck.AddItem(name)
+			problems = append(problems, "5xx errors")
+		}
+		if connectFailuresCount > connectFailuresCheck.Threshold {
+			connectFailuresCheck.AddItem(name)
+			problems = append(problems, "connection failures")
+		}
+		if circuitBreakerTripsCount > circuitBreakersCheck.Threshold {
+			circuitBreakersCheck.AddItem(name)
+			problems = append(problems, "circuit breaker trips")
+		}
+		report.
+			GetOrCreateChartInGroup("Upstream requests <selector>, per second", name, nil).
+			AddSeries("total", u.Requests).
+			AddSeries("5xx", u.Errors, "red")
+		report.
+			GetOrCreateChartInGroup("Upstream latency <selector>, seconds", name, nil).
+			AddSeries("latency", latency)
+		report.
+			GetOrCreateChartInGroup("Upstream failures <selector>, per second", name, nil).
+			AddSeries("connection failures", connectFailures, "red").
+			AddSeries("circuit breaker trips", circuitBreakerTrips, "orange")
+
+		if upstreamsTable == nil {
+			continue
+		}
+		upstream := model.NewTableCell(name)
+		if app := a.findServiceApplication(s.serviceName, s.serviceNamespace); app != nil {
+			upstream.Link = model.NewRouterLink(app.Id.Name, "overview").
+				SetParam("view", "applications").
+				SetParam("id", app.Id)
+		}
+		status := model.NewTableCell().SetStatus(model.OK, "ok")
+		if len(problems) > 0 {
+			status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+		}
+		upstreamsTable.AddRow(
+			upstream,
+			status,
+			model.NewTableCell(utils.FormatFloat(u.Requests.Last())).SetUnit("/s"),
+			model.NewTableCell(utils.FormatFloat(errorsPercent.Last())).SetUnit("%"),
+			model.NewTableCell(utils.FormatLatency(latency.Last())),
+			model.NewTableCell(utils.FormatFloat(connectFailuresCount)),
+			model.NewTableCell(utils.FormatFloat(circuitBreakerTripsCount)),
+		)
+	}
+}
+
+// findServiceApplication returns the application behind the Kubernetes Service.
+func (a *appAuditor) findServiceApplication(name, namespace string) *model.Application {
+	if name == "" {
+		return nil
+	}
+	for _, app := range a.w.Applications {
+		for _, svc := range app.KubernetesServices {
+			if svc.Name == name && svc.Namespace == namespace {
+				return app
+			}
+		}
+	}
+	return nil
+}
+
+// proxyStats aggregates the stats of a route or an upstream across the proxy instances.
+type proxyStats struct {
+	requests   *timeseries.Aggregate
+	errors     *timeseries.Aggregate
+	histogram  map[float32]*timeseries.Aggregate
+	avgLatency *timeseries.Aggregate
+
+	connectFailures     *timeseries.Aggregate
+	circuitBreakerTrips *timeseries.Aggregate
+	serviceName         string
+	serviceNamespace    string
+}
+
+func proxyGetOrCreateStats(stats map[string]*proxyStats, name string) *proxyStats {
+	s := stats[name]
+	if s == nil {
+		s = &proxyStats{
+			requests:            timeseries.NewAggregate(timeseries.NanSum),
+			errors:              timeseries.NewAggregate(timeseries.NanSum),
+			histogram:           map[float32]*timeseries.Aggregate{},
+			avgLatency:          timeseries.NewAggregate(timeseries.Max),
+			connectFailures:     timeseries.NewAggregate(timeseries.NanSum),
+			circuitBreakerTrips: timeseries.NewAggregate(timeseries.NanSum),
+		}
+		stats[name] = s
+	}
+	return s
+}
+
+func (s *proxyStats) add(ps *model.ProxyStats) {
+	s.requests.Add(ps.Requests)
+	s.errors.Add(ps.Errors)
+	s.avgLatency.Add(ps.AvgLatency)
+	for le, ts := range ps.LatencyHistogram {
+		b := s.histogram[le]
+		if b == nil {
+			b = timeseries.NewAggregate(timeseries.NanSum)
+			s.histogram[le] = b
+		}
+		b.Add(ts)
+	}
+}
+
+func (s *proxyStats) get() *model.ProxyStats {
+	ps := model.NewProxyStats()
+	ps.Requests = s.requests.Get()
+	ps.Errors = s.errors.Get()
+	ps.AvgLatency = s.avgLatency.Get()
+	for le, b := range s.histogram {
+		ps.LatencyHistogram[le] = b.Get()
+	}
+	return ps
+}
+
+func proxySortedNames(stats map[string]*proxyStats) []string {
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,151 @@
+package auditor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+)
+
+func (a *appAuditor) rabbitmq() {
+	if !a.app.IsRabbitmq() {
+		return
+	}
+
+	report := a.addReport(model.AuditReportRabbitmq)
+
+	availabilityCheck := report.CreateCheck(model.Checks.RabbitmqAvailability)
+	alarmsCheck := report.CreateCheck(model.Checks.RabbitmqAlarms)
+	growthCheck := report.CreateCheck(model.Checks.RabbitmqQueueGrowth)
+	unackedCheck := report.CreateCheck(model.Checks.RabbitmqUnackedMessages)
+	noConsumersCheck := report.CreateCheck(model.Checks.RabbitmqNoConsumers)
+
+	nodesTable := report.GetOrCreateTable("Node", "Status", "Alarms", "Published", "Delivered", "Version")
+	publishedChart := report.GetOrCreateChart("Messages published, per second", nil)
+	deliveredChart := report.GetOrCreateChart("Messages delivered, per second", nil)
+
+	queues := map[model.RabbitmqQueueKey]*rabbitmqQueue{}
+	for _, i := range a.app.Instances {
+		if i.Rabbitmq == nil {
+			continue
+		}
+		obsolete := i.IsObsolete()
+		if !obsolete && !i.Rabbitmq.IsUp() {
+			availabilityCheck.AddItem(i.Name)
+		}
+		if obsolete {
+			continue
+		}
+		var alarms []string
+		if i.Rabbitmq.MemoryAlarm.Last() > 0 {
+			alarms = append(alarms, "memory")
+		}
+		if i.Rabbitmq.DiskAlarm.Last() > 0 {
+			alarms = append(alarms, "disk")
+		}
+		if len(alarms) > 0 {
+			alarmsCheck.AddItem(i.Name)
+		}
+		for k, q := range i.Rabbitmq.Queues {
+			aq := queues[k]
+			if aq == nil {
+				aq = &rabbitmqQueue{
+					ready:     timeseries.NewAggregate(timeseries.Max),
+					unacked:   timeseries.NewAggregate(timeseries.Max),
+					consumers: timeseries.NewAggregate(timeseries.Max),
+				}
+				queues[k] = aq
+			}
+			aq.ready.Add(q.Ready)
+			aq.unacked.Add(q.Unacked)
+			aq.consumers.Add(q.Consumers)
+		}
+		if publishedChart != nil {
+			publishedChart.AddSeries(i.Name, i.Rabbitmq.MessagesPublished)
+		}
+		if deliveredChart != nil {
+			deliveredChart.AddSeries(i.Name, i.Rabbitmq.MessagesDelivered)
+		}
+		if nodesTable != nil {
+			status := model.NewTableCell().SetStatus(model.OK, "up")
+			if !i.Rabbitmq.IsUp() {
+				status.SetStatus(model.WARNING, "down (no metrics)")
+			}
+			alarmsCell := model.NewTableCell()
+			if len(alarms) > 0 {
+				alarmsCell.SetStatus(model.WARNING, strings.Join(alarms, ", "))
+			}
+			nodesTable.AddRow(
+				model.NewTableCell(i.Name).AddTag(i.Rabbitmq.Node.Value()),
+				status,
+				alarmsCell,
+				model.NewTableCell(utils.FormatFloat(i.Rabbitmq.MessagesPublished.Last())).SetUnit("/s"),
+				model.NewTableCell(utils.FormatFloat(i.Rabbitmq.MessagesDelivered.Last())).SetUnit("/s"),
+				model.NewTableCell(i.Rabbitmq.Version.Value()),
+			)
+		}
+	}
+
+	keys := make([]model.RabbitmqQueueKey, 0, len(queues))
+	for k := range queues {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	queuesTable := report.GetOrCreateTable("Queue", "Status", "Ready", "Unacked", "Consumers", "Growing for")
+	for _, k := range keys {
+		q := queues[k]
+		name := k.String()
+		ready, unacked, consumers := q.ready.Get(), q.unacked.Get(), q.consumers.Get()
+		var problems []string
+		growing := model.BacklogGrowthDuration(ready)
+		if growing > 0 && growing >= timeseries.Duration(growthCheck.Threshold) {
+			growthCheck.AddItem(name)
+			problems = append(problems, "growing")
+		}
+		if unacked.Last() > unackedCheck.Threshold {
+			unackedCheck.AddItem(name)
+			problems = append(problems, "too many unacked")
+		}
+		if consumers.Last() == 0 && consumers.Reduce(timeseries.Max) > 0 {
+			noConsumersCheck.AddItem(name)
+			problems = append(problems, "no consumers")
+		}
+		report.
+			GetOrCreateChartInGroup("Queue depth <selector>, messages", name, nil).
+			Stacked().
+			AddSeries("ready", ready).
+			AddSeries("unacked", unacked)
+
+		if queuesTable == nil {
+			continue
+		}
+		status := model.NewTableCell().SetStatus(model.OK, "ok")
+		if len(problems) > 0 {
+			status.SetStatus(model.WARNING, strings.Join(problems, ", "))
+		}
+		growingCell := model.NewTableCell()
+		if growing > 0 {
+			growingCell.SetValue(utils.FormatDuration(growing, 1))
+		}
+		queuesTable.AddRow(
+			model.NewTableCell(name),
+			status,
+			model.NewTableCell(utils.FormatFloat(ready.Last())),
+			model.NewTableCell(utils.FormatFloat(unacked.Last())),
+			model.NewTableCell(utils.FormatFloat(consumers.Last())),
+			growingCell,
+		)
+	}
+
+	a.messagingClients(report, model.ProtocolRabbitmq)
+}
+
+type rabbitmqQueue struct {
+	ready     *timeseries.Aggregate
+	unacked   *timeseries.Aggregate
+	consumers *timeseries.Aggregate
+}
@@ -3,6 +3,7 @@ package auditor
 import (
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
 )
 
 type nodeConsumers struct {
@@ -71,3 +72,56 @@ func cpuByModeChart(ch *model.Chart, modes map[string]*timeseries.TimeSeries) {
 		ch.AddSeries(mode, v, color)
 	}
 }
+
+// messagingClients lists the applications producing and consuming messages through the message broker.
+func (a *appAuditor) messagingClients(report *model.AuditReport, protocol model.Protocol) {
+	producers, consumers := a.app.MessagingClients(protocol)
+	if len(producers) == 0 && len(consumers) == 0 {
+		return
+	}
+	table := report.GetOrCreateTable("Client", "Produced", "Consumed")
+	if table == nil {
+		return
+	}
+	type client struct {
+		app                *model.Application
+		produced, consumed *timeseries.TimeSeries
+	}
+	var clients []*client
+	byApp := map[*model.Application]*client{}
+	get := func(app *model.Application) *client {
+		c := byApp[app]
+		if c == nil {
+			c = &client{app: app}
+			byApp[app] = c
+			clients = append(clients, c)
+		}
+		return c
+	}
+	for _, p := range producers {
+		get(p.Application).produced = p.Messages
+	}
+	for _, c := range consumers {
+		get(c.Application).consumed = c.Messages
+	}
+	for _, c := range clients {
+		name := model.NewTableCell(c.app.Id.Name)
+		name.Link = model.NewRouterLink(c.app.Id.Name, "overview").
+			SetParam("view", "applications").
+			SetParam("id", c.app.Id)
+		table.AddRow(
+			name,
+			model.NewTableCell(utils.FormatFloat(c.produced.Last())).SetUnit("/s"),
+			model.NewTableCell(utils.FormatFloat(c.consumed.Last())).SetUnit("/s"),
+		)
+	}
+}
+
+func bytesCell(v float32) *model.TableCell {
+	c := model.NewTableCell()
+	if !timeseries.IsNaN(v) {
+		value, unit := utils.FormatBytes(v)
+		c.SetValue(value).SetUnit(unit)
+	}
+	return c
+}
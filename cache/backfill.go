@@ -0,0 +1,478 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/coroot/coroot/cache/chunk"
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/constructor"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/prom"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+)
+
+const backfillQueueSize = 100
+
+var ErrUnknownQuery = errors.New("unknown query")
+
+type BackfillStatus string
+
+const (
+	BackfillPending  BackfillStatus = "pending"
+	BackfillRunning  BackfillStatus = "running"
+	BackfillDone     BackfillStatus = "done"
+	BackfillFailed   BackfillStatus = "failed"
+	BackfillCanceled BackfillStatus = "canceled"
+)
+
+// BackfillRequest describes historical data to download from Prometheus.
+// Unlike the updater, which only moves forward from the last saved time, a backfill job fills any range
+// that is still within the Prometheus retention. The jobs are persisted in the cache state,
+// so the unfinished ones are restarted after a restart of Coroot.
+type BackfillRequest struct {
+	// Queries to backfill, all the queries of the project (including the recording rules) if empty.
+	Queries []string
+	From    timeseries.Time
+	To      timeseries.Time
+	// Recompute drops the existing chunks within the range first, e.g., after a query definition has changed.
+	Recompute bool
+}
+
+type BackfillJob struct {
+	Id          string          `json:"id"`
+	ProjectId   db.ProjectId    `json:"project_id"`
+	Queries     []string        `json:"queries"`
+	From        timeseries.Time `json:"from"`
+	To          timeseries.Time `json:"to"`
+	Recompute   bool            `json:"recompute"`
+	Status      BackfillStatus  `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	ChunksTotal int             `json:"chunks_total"`
+	ChunksDone  int             `json:"chunks_done"`
+	Created     timeseries.Time `json:"created"`
+
+	cancel context.CancelFunc
+}
+
+func (j *BackfillJob) active() bool {
+	return j.Status == BackfillPending || j.Status == BackfillRunning
+}
+
+type BackfillProgress struct {
+	Jobs        int
+	ChunksTotal int
+	ChunksDone  int
+}
+
+func (c *Cache) Backfill(projectId db.ProjectId, req BackfillRequest) (*BackfillJob, error) {
+	if req.To <= req.From {
+		return nil, fmt.Errorf("invalid time range")
+	}
+	project, err := c.db.GetProject(projectId)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err = c.getBackfillQueries(project, req.Queries); err != nil {
+		return nil, err
+	}
+	job := &BackfillJob{
+		Id:        utils.NanoId(8),
+		ProjectId: projectId,
+		Queries:   req.Queries,
+		From:      req.From,
+		To:        req.To,
+		Recompute: req.Recompute,
+		Status:    BackfillPending,
+		Created:   timeseries.Now(),
+		cancel:    func() {},
+	}
+	c.backfillLock.Lock()
+	defer c.backfillLock.Unlock()
+	if err = c.saveBackfillJob(job); err != nil {
+		return nil, err
+	}
+	select {
+	case c.backfillQueue <- job:
+	default:
+		if err = c.deleteBackfillJob(job.Id); err != nil {
+			klog.Errorln("failed to delete backfill job:", err)
+		}
+		return nil, fmt.Errorf("too many backfill jobs in the queue")
+	}
+	c.backfillJobs = append(c.backfillJobs, job)
+	return job.snapshot(), nil
+}
+
+// restoreBackfillJobs loads the persisted jobs and requeues the ones interrupted by a restart.
+func (c *Cache) restoreBackfillJobs() error {
+	jobs, err := c.loadBackfillJobs()
+	if err != nil {
+		return err
+	}
+	c.backfillLock.Lock()
+	defer c.backfillLock.Unlock()
+	for _, j := range jobs {
+		if j.active() {
+			j.Status = BackfillPending
+			select {
+			case c.backfillQueue <- j:
+			default:
+				j.Status = BackfillFailed
+				j.Error = "too many backfill jobs in the queue"
+				if err = c.saveBackfillJob(j); err != nil {
+					return err
+				}
+			}
+		}
+		c.backfillJobs = append(c.backfillJobs, j)
+	}
+	return nil
+}
+
+func (c *Cache) GetBackfillJobs(projectId db.ProjectId) []*BackfillJob {
+	c.backfillLock.Lock()
+	defer c.backfillLock.Unlock()
+	var res []*BackfillJob
+	for _, j := range c.backfillJobs {
+		if j.ProjectId == projectId {
+			res = append(res, j.snapshot())
+		}
+	}
+	return res
+}
+
+func (c *Cache) CancelBackfill(projectId db.ProjectId, id string) error {
+	c.backfillLock.Lock()
+	defer c.backfillLock.Unlock()
+	for _, j := range c.backfillJobs {
+		if j.ProjectId != projectId || j.Id != id {
+			continue
+		}
+		if j.active() {
+			j.Status = BackfillCanceled
+			j.cancel()
+			if err := c.saveBackfillJob(j); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return db.ErrNotFound
+}
+
+func (c *Cache) getBackfillProgress(projectId db.ProjectId) *BackfillProgress {
+	c.backfillLock.Lock()
+	defer c.backfillLock.Unlock()
+	var res *BackfillProgress
+	for _, j := range c.backfillJobs {
+		if j.ProjectId != projectId || !j.active() {
+			continue
+		}
+		if res == nil {
+			res = &BackfillProgress{}
+		}
+		res.Jobs++
+		res.ChunksTotal += j.ChunksTotal
+		res.ChunksDone += j.ChunksDone
+	}
+	return res
+}
+
+func (j *BackfillJob) snapshot() *BackfillJob {
+	res := *j
+	res.Queries = slices.Clone(j.Queries)
+	res.cancel = nil
+	return &res
+}
+
+func (c *Cache) backfiller() {
+	for job := range c.backfillQueue {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.backfillLock.Lock()
+		if job.Status != BackfillPending {
+			c.backfillLock.Unlock()
+			cancel()
+			continue
+		}
+		job.Status = BackfillRunning
+		job.cancel = cancel
+		if err := c.saveBackfillJob(job); err != nil {
+			klog.Errorln("failed to save backfill job:", err)
+		}
+		c.backfillLock.Unlock()
+
+		start := time.Now()
+		err := c.backfill(ctx, job)
+		cancel()
+
+		c.backfillLock.Lock()
+		switch {
+		case job.Status == BackfillCanceled:
+		case err != nil:
+			job.Status = BackfillFailed
+			job.Error = err.Error()
+		default:
+			job.Status = BackfillDone
+		}
+		if err := c.saveBackfillJob(job); err != nil {
+			klog.Errorln("failed to save backfill job:", err)
+		}
+		c.backfillLock.Unlock()
+		if err != nil && !errors.Is(err, context.Canceled) {
+			klog.Errorf("%s: backfill %s failed: %s", job.ProjectId, job.Id, err)
+		} else {
+			klog.Infof("%s: backfill %s %s in %s", job.ProjectId, job.Id, job.Status, time.Since(start).Truncate(time.Millisecond))
+		}
+		c.cleanupBackfillJobs()
+	}
+}
+
+// cleanupBackfillJobs keeps the history of the finished jobs short.
+func (c *Cache) cleanupBackfillJobs() {
+	c.backfillLock.Lock()
+	defer c.backfillLock.Unlock()
+	finished := 0
+	for i := len(c.backfillJobs) - 1; i >= 0; i-- {
+		if c.backfillJobs[i].active() {
+			continue
+		}
+		finished++
+		if finished > backfillQueueSize {
+			if err := c.deleteBackfillJob(c.backfillJobs[i].Id); err != nil {
+				klog.Errorln("failed to delete backfill job:", err)
+				continue
+			}
+			c.backfillJobs = slices.Delete(c.backfillJobs, i, i+1)
+		}
+	}
+}
+
+func (c *Cache) backfill(ctx context.Context, job *BackfillJob) error {
+	project, err := c.db.GetProject(job.ProjectId)
+	if err != nil {
+		return err
+	}
+	promClient, err := c.getPrometheusClient(project)
+	if err != nil {
+		return err
+	}
+	c.lock.RLock()
+	projData := c.byProject[project.Id]
+	var step timeseries.Duration
+	if projData != nil {
+		step = projData.step
+	}
+	c.lock.RUnlock()
+	if step == 0 {
+		if step, err = getScrapeInterval(promClient); err != nil {
+			return err
+		}
+	}
+
+	queries, recordingRules, err := c.getBackfillQueries(project, job.Queries)
+	if err != nil {
+		return err
+	}
+
+	now := timeseries.Now()
+	from, to := job.From, job.To
+	if retention, err := promClient.Retention(ctx); err != nil {
+		klog.Warningln("failed to get the Prometheus retention:", err)
+	} else if retention > 0 && from < now.Add(-retention) {
+		from = now.Add(-retention).Add(chunk.Size)
+	}
+	if maxTo := now.Add(-step); to > maxTo {
+		to = maxTo
+	}
+	if from >= to {
+		return fmt.Errorf("the range is beyond the Prometheus retention")
+	}
+
+	if job.Recompute {
+		hashes := map[string]bool{}
+		for _, q := range queries {
+			hashes[queryHash(q.Query)] = true
+		}
+		if recordingRules {
+			for name := range constructor.RecordingRules {
+				hashes[queryHash(name)] = true
+			}
+		}
+		to = c.invalidate(ctx, project.Id, hashes, from, to)
+	}
+
+	intervals := map[string][]interval{}
+	total := 0
+	for _, q := range queries {
+		_, jitter := QueryId(project.Id, q.Query)
+		intervals[q.Query] = backfillIntervals(from, to, step, jitter, now)
+		if !job.Recompute {
+			intervals[q.Query] = c.uncoveredIntervals(project.Id, []string{queryHash(q.Query)}, intervals[q.Query])
+		}
+		total += len(intervals[q.Query])
+	}
+	var rrIntervals []interval
+	if recordingRules {
+		rrIntervals = backfillIntervals(from, to, step, chunkJitter(project.Id, ""), now)
+		if !job.Recompute {
+			var hashes []string
+			for name := range constructor.RecordingRules {
+				hashes = append(hashes, queryHash(name))
+			}
+			rrIntervals = c.uncoveredIntervals(project.Id, hashes, rrIntervals)
+		}
+		total += len(rrIntervals)
+	}
+	c.backfillLock.Lock()
+	job.ChunksTotal = total
+	c.backfillLock.Unlock()
+
+	done := func() {
+		c.backfillLock.Lock()
+		job.ChunksDone++
+		c.backfillLock.Unlock()
+	}
+	for _, q := range queries {
+		if err = c.backfillQuery(ctx, promClient, project.Id, step, q, intervals[q.Query], done); err != nil {
+			return err
+		}
+	}
+	for _, i := range rrIntervals {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+		if err = c.writeRecordingRules(project, step, i); err != nil {
+			return err
+		}
+		done()
+	}
+	return nil
+}
+
+// getBackfillQueries resolves the requested queries, the recording rules are computed from the cached queries rather than downloaded.
+func (c *Cache) getBackfillQueries(project *db.Project, names []string) ([]constructor.Query, bool, error) {
+	queries, err := c.getQueries(project)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(names) == 0 {
+		return queries, true, nil
+	}
+	byQuery := map[string]constructor.Query{}
+	for _, q := range queries {
+		byQuery[q.Query] = q
+	}
+	var res []constructor.Query
+	recordingRules := false
+	for _, name := range names {
+		switch {
+		case constructor.RecordingRules[name] != nil:
+			recordingRules = true
+		case byQuery[name].Query != "":
+			res = append(res, byQuery[name])
+		default:
+			return nil, false, fmt.Errorf("%w: %s", ErrUnknownQuery, name)
+		}
+	}
+	return res, recordingRules, nil
+}
+
+func (c *Cache) backfillQuery(ctx context.Context, promClient *prom.Client, projectId db.ProjectId, step timeseries.Duration, q constructor.Query, intervals []interval, done func()) error {
+	hash := queryHash(q.Query)
+	pointsCount := int(chunk.Size / step)
+	for _, i := range intervals {
+		qCtx, cancel := context.WithTimeout(ctx, queryTimeout)
+		vs, err := promClient.QueryRange(qCtx, q.Query, q.Labels.Has, i.chunkTs, i.toTs, step)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to query prometheus: %w", err)
+		}
+		chunkEnd := i.chunkTs.Add(timeseries.Duration(pointsCount-1) * step)
+		if err = c.writeChunk(projectId, hash, i.chunkTs, pointsCount, step, chunkEnd == i.toTs, vs); err != nil {
+			return fmt.Errorf("failed to save chunk: %w", err)
+		}
+		done()
+	}
+	return nil
+}
+
+// invalidate deletes the chunks of the queries starting within the range.
+// The deleted chunks may end beyond the range, so it returns the end of the range to recompute.
+func (c *Cache) invalidate(ctx context.Context, projectId db.ProjectId, hashes map[string]bool, from, to timeseries.Time) timeseries.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	projData := c.byProject[projectId]
+	if projData == nil {
+		return to
+	}
+	// the chunks starting before the range are kept since they may contain data beyond the retention,
+	// the recomputed chunks take precedence over them as the newer ones
+	resTo := to
+	for hash := range hashes {
+		qData := projData.queries[hash]
+		if qData == nil {
+			continue
+		}
+		for key, ch := range qData.chunksOnDisk {
+			if ch.From < from || ch.From > to {
+				continue
+			}
+			if err := c.storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+				klog.Errorf("failed to delete chunk %s: %s", key, err)
+				continue
+			}
+			delete(qData.chunksOnDisk, key)
+			if ch.To() > resTo {
+				resTo = ch.To()
+			}
+		}
+	}
+	return resTo
+}
+
+// uncoveredIntervals drops the intervals already covered by a finalized chunk of every query,
+// including the compacted ones, so that a backfill doesn't duplicate the data.
+func (c *Cache) uncoveredIntervals(projectId db.ProjectId, hashes []string, intervals []interval) []interval {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	projData := c.byProject[projectId]
+	if projData == nil {
+		return intervals
+	}
+	covered := func(hash string, i interval) bool {
+		qData := projData.queries[hash]
+		if qData == nil {
+			return false
+		}
+		for _, ch := range qData.chunksOnDisk {
+			if !ch.Rollup && ch.Finalized && ch.From <= i.chunkTs && ch.To() >= i.toTs {
+				return true
+			}
+		}
+		return false
+	}
+	return slices.DeleteFunc(intervals, func(i interval) bool {
+		for _, hash := range hashes {
+			if !covered(hash, i) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// backfillIntervals returns the chunk intervals covering the range.
+// The last chunk is extended to its end, so it doesn't replace the chunk written by the updater with a shorter one.
+func backfillIntervals(from, to timeseries.Time, step timeseries.Duration, jitter timeseries.Duration, now timeseries.Time) []interval {
+	to = to.Add(-jitter).Truncate(chunk.Size).Add(jitter).Truncate(step).Add(chunk.Size - step)
+	if maxTo := now.Add(-step); to > maxTo {
+		to = maxTo
+	}
+	return calcIntervals(from.Add(-step), step, to.Add(step), jitter)
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfillIntervals(t *testing.T) {
+	ts := func(s string) timeseries.Time {
+		res, err := time.Parse("2006-01-02T15:04:05", s)
+		require.NoError(t, err)
+		return timeseries.Time(res.Unix())
+	}
+	calc := func(from, to, now string, jitter timeseries.Duration) string {
+		return fmt.Sprintf(`%s`, backfillIntervals(ts(from), ts(to), 30*timeseries.Second, jitter, ts(now)))
+	}
+
+	assert.Equal(t, // the chunks are extended to their boundaries
+		"[(2020-11-13T09:40:00 2020-11-13T09:49:30) (2020-11-13T09:50:00 2020-11-13T09:59:30) (2020-11-13T10:00:00 2020-11-13T10:09:30)]",
+		calc("2020-11-13T09:42:11", "2020-11-13T10:01:00", "2020-11-14T00:00:00", 0),
+	)
+	assert.Equal(t,
+		"[(2020-11-13T09:42:00 2020-11-13T09:51:30) (2020-11-13T09:52:00 2020-11-13T10:01:30)]",
+		calc("2020-11-13T09:42:11", "2020-11-13T10:01:00", "2020-11-14T00:00:00", 2*timeseries.Minute),
+	)
+	assert.Equal(t, // but not beyond the current time
+		"[(2020-11-13T09:40:00 2020-11-13T09:49:30) (2020-11-13T09:50:00 2020-11-13T09:59:30) (2020-11-13T10:00:00 2020-11-13T10:02:00)]",
+		calc("2020-11-13T09:42:11", "2020-11-13T10:01:00", "2020-11-13T10:02:31", 0),
+	)
+}
+
+func TestInvalidate(t *testing.T) {
+	projectId := db.ProjectId("p1")
+	dir := t.TempDir()
+	c := &Cache{cfg: Config{Path: dir}, storage: storage.NewFS(dir), byProject: map[db.ProjectId]*projectData{projectId: newProjectData()}}
+	step := timeseries.Minute
+	ls := model.Labels{"pod": "catalog-1"}
+	write := func(query string, from timeseries.Time, pointsCount int) {
+		mvs := []*model.MetricValues{{Labels: ls, LabelsHash: ls.Hash(), Values: timeseries.NewWithData(from, step, make([]float32, pointsCount))}}
+		require.NoError(t, c.writeChunk(projectId, queryHash(query), from, pointsCount, step, true, mvs))
+	}
+	hour := timeseries.Time(1700000000).Truncate(timeseries.Hour)
+	write("q1", hour, 60)
+	write("q1", hour.Add(timeseries.Hour), 10)
+	write("q1", hour.Add(timeseries.Hour+10*timeseries.Minute), 10)
+	write("q2", hour.Add(timeseries.Hour), 10)
+
+	to := c.invalidate(context.Background(), projectId, map[string]bool{queryHash("q1"): true}, hour.Add(30*timeseries.Minute), hour.Add(timeseries.Hour+15*timeseries.Minute))
+	assert.Equal(t, hour.Add(timeseries.Hour+19*timeseries.Minute), to)
+
+	chunks := func(query string) []timeseries.Time {
+		var res []timeseries.Time
+		for _, ch := range c.byProject[projectId].queries[queryHash(query)].chunksOnDisk {
+			res = append(res, ch.From)
+		}
+		return res
+	}
+	assert.Equal(t, []timeseries.Time{hour}, chunks("q1"), "the chunk starting before the range must be kept")
+	assert.Equal(t, []timeseries.Time{hour.Add(timeseries.Hour)}, chunks("q2"))
+
+	objects, err := c.storage.List(context.Background(), storage.Key(string(projectId), ""))
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+}
+
+func TestUncoveredIntervals(t *testing.T) {
+	projectId := db.ProjectId("p1")
+	dir := t.TempDir()
+	c := &Cache{cfg: Config{Path: dir}, storage: storage.NewFS(dir), byProject: map[db.ProjectId]*projectData{projectId: newProjectData()}}
+	step := timeseries.Minute
+	ls := model.Labels{"pod": "catalog-1"}
+	write := func(query string, from timeseries.Time, pointsCount int, finalized bool) {
+		mvs := []*model.MetricValues{{Labels: ls, LabelsHash: ls.Hash(), Values: timeseries.NewWithData(from, step, make([]float32, pointsCount))}}
+		require.NoError(t, c.writeChunk(projectId, queryHash(query), from, pointsCount, step, finalized, mvs))
+	}
+	hour := timeseries.Time(1700000000).Truncate(timeseries.Hour)
+	write("q1", hour, 60, true) // compacted
+	write("q1", hour.Add(timeseries.Hour), 10, false)
+	write("q2", hour, 10, true)
+
+	intervals := backfillIntervals(hour, hour.Add(timeseries.Hour+5*timeseries.Minute), step, 0, hour.Add(2*timeseries.Hour))
+	require.Len(t, intervals, 7)
+	assert.Equal(t, intervals[6:], c.uncoveredIntervals(projectId, []string{queryHash("q1")}, slices.Clone(intervals)),
+		"the intervals covered by the compacted chunk must be skipped, the unfinalized chunk must be refilled")
+	assert.Equal(t, intervals[1:], c.uncoveredIntervals(projectId, []string{queryHash("q1"), queryHash("q2")}, slices.Clone(intervals)))
+	assert.Equal(t, intervals, c.uncoveredIntervals(projectId, []string{queryHash("q3")}, slices.Clone(intervals)))
+}
+
+func TestBackfillJobsPersistence(t *testing.T) {
+	dir := t.TempDir()
+	state, err := db.NewSqlite(dir)
+	require.NoError(t, err)
+	require.NoError(t, state.Migrator().Migrate(&BackfillJob{}))
+	newCache := func() *Cache {
+		return &Cache{cfg: Config{Path: dir}, state: state.DB(), backfillQueue: make(chan *BackfillJob, backfillQueueSize)}
+	}
+
+	c := newCache()
+	running := &BackfillJob{Id: "j1", ProjectId: "p1", Queries: []string{"q1"}, From: 100, To: 200, Status: BackfillRunning, Created: 1}
+	done := &BackfillJob{Id: "j2", ProjectId: "p1", From: 100, To: 200, Recompute: true, Status: BackfillDone, Created: 2}
+	require.NoError(t, c.saveBackfillJob(running))
+	require.NoError(t, c.saveBackfillJob(done))
+
+	c = newCache()
+	require.NoError(t, c.restoreBackfillJobs())
+	jobs := c.GetBackfillJobs("p1")
+	require.Len(t, jobs, 2)
+	assert.Equal(t, BackfillPending, jobs[0].Status, "the interrupted job must be restarted")
+	assert.Equal(t, []string{"q1"}, jobs[0].Queries)
+	assert.Equal(t, BackfillDone, jobs[1].Status)
+	assert.True(t, jobs[1].Recompute)
+	require.Len(t, c.backfillQueue, 1)
+	assert.Equal(t, "j1", (<-c.backfillQueue).Id)
+}
@@ -1,16 +1,17 @@
 package cache
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"os"
-	"path"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/coroot/coroot/cache/chunk"
+	"github.com/coroot/coroot/cache/storage"
 	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/prom"
 	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
@@ -20,6 +21,7 @@ import (
 
 type Cache struct {
 	cfg       Config
+	storage   storage.Storage
 	byProject map[db.ProjectId]*projectData
 	lock      sync.RWMutex
 	db        *db.DB
@@ -30,6 +32,10 @@ type Cache struct {
 
 	updates chan db.ProjectId
 
+	backfillQueue chan *BackfillJob
+	backfillJobs  []*BackfillJob
+	backfillLock  sync.Mutex
+
 	pendingCompactions prometheus.Gauge
 	compactedChunks    *prometheus.CounterVec
 }
@@ -43,13 +49,18 @@ func NewCache(cfg Config, database *db.DB, globalPrometheus *db.IntegrationProme
 	if err != nil {
 		return nil, err
 	}
-	err = state.Migrator().Migrate(&PrometheusQueryState{})
+	err = state.Migrator().Migrate(&PrometheusQueryState{}, &BackfillJob{})
 	if err != nil {
 		return nil, err
 	}
 
+	if cfg.Storage == nil {
+		cfg.Storage = storage.NewFS(cfg.Path)
+	}
+
 	cache := &Cache{
 		cfg:       cfg,
+		storage:   cfg.Storage,
 		byProject: map[db.ProjectId]*projectData{},
 		db:        database,
 		state:     state.DB(),
@@ -58,6 +69,8 @@ func NewCache(cfg Config, database *db.DB, globalPrometheus *db.IntegrationProme
 
 		updates: make(chan db.ProjectId),
 
+		backfillQueue: make(chan *BackfillJob, backfillQueueSize),
+
 		pendingCompactions: prometheus.NewGauge(
 			prometheus.GaugeOpts{
 				Name: "coroot_pending_compactions",
@@ -70,7 +83,10 @@ func NewCache(cfg Config, database *db.DB, globalPrometheus *db.IntegrationProme
 			[]string{"src", "dst"},
 		),
 	}
-	if err := cache.initCacheIndexFromDir(); err != nil {
+	if err := cache.initCacheIndex(); err != nil {
+		return nil, err
+	}
+	if err := cache.restoreBackfillJobs(); err != nil {
 		return nil, err
 	}
 
@@ -80,6 +96,7 @@ func NewCache(cfg Config, database *db.DB, globalPrometheus *db.IntegrationProme
 	go cache.updater()
 	go cache.gc()
 	go cache.compaction()
+	go cache.backfiller()
 	return cache, nil
 }
 
@@ -87,54 +104,138 @@ func (c *Cache) Updates() <-chan db.ProjectId {
 	return c.updates
 }
 
-func (c *Cache) initCacheIndexFromDir() error {
+func (c *Cache) initCacheIndex() error {
 	t := time.Now()
-	files, err := os.ReadDir(c.cfg.Path)
+	byProject, err := c.loadIndex(context.Background(), "")
 	if err != nil {
 		return err
 	}
-	for _, f := range files {
-		if !f.IsDir() {
+	for projectId, projData := range byProject {
+		c.byProject[projectId] = projData
+	}
+	klog.Infof("loaded from storage in %s", time.Since(t).Truncate(time.Millisecond))
+	return nil
+}
+
+// reloadIndex replaces the index of the project with the chunks currently present in the storage
+// and advances the query states to the chunks saved by other replicas, so they are not downloaded again.
+func (c *Cache) reloadIndex(projectId db.ProjectId) error {
+	byProject, err := c.loadIndex(context.Background(), storage.Key(string(projectId), ""))
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	if projData := byProject[projectId]; projData != nil {
+		c.byProject[projectId] = projData
+	}
+	c.lock.Unlock()
+
+	states, err := c.loadStates(projectId)
+	if err != nil {
+		return err
+	}
+	for _, state := range states {
+		if t := c.lastSavedTime(projectId, state.Query); t > state.LastTs {
+			state.LastTs = t
+			if err = c.saveState(state); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Cache) loadIndex(ctx context.Context, prefix string) (map[db.ProjectId]*projectData, error) {
+	objects, err := c.storage.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	res := map[db.ProjectId]*projectData{}
+	metaFrom := map[db.ProjectId]timeseries.Time{}
+	for _, o := range objects {
+		projectId, chunkFile, ok := strings.Cut(o.Key, "/")
+		if !ok || !strings.HasSuffix(chunkFile, ".db") {
+			continue
+		}
+		parts := strings.Split(chunkFile, "-")
+		if len(parts) != 5 {
 			continue
 		}
-		projectId := f.Name()
-		projectDir := path.Join(c.cfg.Path, projectId)
-		projFiles, err := os.ReadDir(projectDir)
+		queryId := parts[1]
+		meta, err := c.readChunkMeta(ctx, o)
 		if err != nil {
-			return err
+			klog.Errorln(err)
+			continue
+		}
+		projData := res[db.ProjectId(projectId)]
+		if projData == nil {
+			projData = newProjectData()
+			res[db.ProjectId(projectId)] = projData
+		}
+		if meta.From > metaFrom[db.ProjectId(projectId)] {
+			projData.step = meta.Step
+			metaFrom[db.ProjectId(projectId)] = meta.From
+		}
+		qData, ok := projData.queries[queryId]
+		if !ok {
+			qData = newQueryData()
+			projData.queries[queryId] = qData
 		}
-		projData := newProjectData()
-		c.byProject[db.ProjectId(projectId)] = projData
+		qData.chunksOnDisk[meta.Path] = meta
+	}
+	return res, nil
+}
 
-		var metaFrom timeseries.Time
-		for _, chunkFile := range projFiles {
-			if !strings.HasSuffix(chunkFile.Name(), ".db") {
-				continue
-			}
-			parts := strings.Split(chunkFile.Name(), "-")
-			if len(parts) != 5 {
-				continue
-			}
-			queryId := parts[1]
-			meta, err := chunk.ReadMeta(path.Join(projectDir, chunkFile.Name()))
-			if err != nil {
-				klog.Errorln(err)
-				continue
-			}
-			if meta.From > metaFrom {
-				projData.step = meta.Step
-				metaFrom = meta.From
-			}
-			qData, ok := projData.queries[queryId]
-			if !ok {
-				qData = newQueryData()
-				projData.queries[queryId] = qData
-			}
-			qData.chunksOnDisk[meta.Path] = meta
+// readChunkMeta reads only the header of the chunk, so the remote chunks are not downloaded while the index is being loaded.
+func (c *Cache) readChunkMeta(ctx context.Context, o storage.Object) (*chunk.Meta, error) {
+	r, err := storage.GetRange(ctx, c.storage, o.Key, 0, chunk.HeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return chunk.ReadMetaFrom(r, o.Key, o.Modified)
+}
+
+// lastSavedTime returns the time up to which the query is saved in the storage according to the index, or zero if nothing is saved.
+// The chunks might have been written by another replica or before the local query state was lost.
+// Partial chunks are re-fetched from the beginning, so the data in them isn't counted.
+func (c *Cache) lastSavedTime(projectId db.ProjectId, query string) timeseries.Time {
+	hash, _ := QueryId(projectId, query)
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	projData := c.byProject[projectId]
+	if projData == nil || projData.queries[hash] == nil {
+		return 0
+	}
+	var res timeseries.Time
+	for _, m := range projData.queries[hash].chunksOnDisk {
+		t := m.From.Add(-m.Step)
+		if m.Finalized {
+			t = m.To()
+		}
+		if t > res {
+			res = t
 		}
 	}
-	klog.Infof("loaded from disk in %s", time.Since(t).Truncate(time.Millisecond))
-	return nil
+	return res
+}
+
+func (c *Cache) readChunk(ctx context.Context, key string, from timeseries.Time, pointsCount int, step timeseries.Duration, dest map[uint64]*model.MetricValues, fillFunc timeseries.FillFunc) error {
+	r, err := c.storage.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return chunk.ReadFrom(r, from, pointsCount, step, dest, fillFunc)
+}
+
+func (c *Cache) readRollupChunk(ctx context.Context, key string, aggregate chunk.Aggregate, from timeseries.Time, pointsCount int, step timeseries.Duration, dest map[uint64]*model.MetricValues, fillFunc timeseries.FillFunc) error {
+	r, err := c.storage.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return chunk.ReadRollup(r, aggregate, from, pointsCount, step, dest, fillFunc)
 }
 
 func (c *Cache) getPrometheusClient(p *db.Project) (*prom.Client, error) {
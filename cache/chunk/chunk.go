@@ -19,6 +19,7 @@ const (
 	V2 uint8 = 2
 	V3 uint8 = 3
 	V4 uint8 = 4
+	V5 uint8 = 5 // rollup
 
 	Size = timeseries.Minute * 10
 )
@@ -30,6 +31,7 @@ type Meta struct {
 	Step        timeseries.Duration
 	Finalized   bool
 	Created     timeseries.Time
+	Rollup      bool
 }
 
 func (m *Meta) To() timeseries.Time {
@@ -48,6 +50,9 @@ type metricMeta struct {
 
 const metricMetaSize = 16
 
+// HeaderSize is the number of bytes ReadMetaFrom needs to read.
+var HeaderSize = int64(binary.Size(header{}))
+
 type header struct {
 	Version     uint8
 	From        timeseries.Time
@@ -69,8 +74,13 @@ func ReadMeta(path string) (*Meta, error) {
 	if err != nil {
 		return nil, err
 	}
+	return ReadMetaFrom(f, path, timeseries.TimeFromStandard(stat.ModTime()))
+}
+
+// ReadMetaFrom reads the chunk header, the reader doesn't need to hold the rest of the chunk.
+func ReadMetaFrom(r io.Reader, path string, created timeseries.Time) (*Meta, error) {
 	h := header{}
-	if err = binary.Read(f, binary.LittleEndian, &h); err != nil {
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
 		return nil, err
 	}
 	return &Meta{
@@ -79,8 +89,9 @@ func ReadMeta(path string) (*Meta, error) {
 		PointsCount: h.PointsCount,
 		Step:        h.Step,
 		Finalized:   h.Finalized,
-		Created:     timeseries.TimeFromStandard(stat.ModTime()),
-	}, err
+		Created:     created,
+		Rollup:      h.Version == V5,
+	}, nil
 }
 
 func Read(path string, from timeseries.Time, pointsCount int, step timeseries.Duration, dest map[uint64]*model.MetricValues, fillFunc timeseries.FillFunc) error {
@@ -89,10 +100,13 @@ func Read(path string, from timeseries.Time, pointsCount int, step timeseries.Du
 		return err
 	}
 	defer f.Close()
+	return ReadFrom(f, from, pointsCount, step, dest, fillFunc)
+}
 
-	reader := bufio.NewReader(f)
+func ReadFrom(r io.Reader, from timeseries.Time, pointsCount int, step timeseries.Duration, dest map[uint64]*model.MetricValues, fillFunc timeseries.FillFunc) error {
+	reader := bufio.NewReader(r)
 	h := header{}
-	if err = binary.Read(reader, binary.LittleEndian, &h); err != nil {
+	if err := binary.Read(reader, binary.LittleEndian, &h); err != nil {
 		return err
 	}
 	switch h.Version {
@@ -100,6 +114,8 @@ func Read(path string, from timeseries.Time, pointsCount int, step timeseries.Du
 		return readV3(reader, &h, from, pointsCount, step, dest, fillFunc)
 	case V4:
 		return readV4(reader, &h, from, pointsCount, step, dest, fillFunc)
+	case V5:
+		return readV5(reader, &h, AggregateAvg, from, pointsCount, step, dest, fillFunc)
 	default:
 		return fmt.Errorf("unknown version: %d", h.Version)
 	}
@@ -56,3 +56,48 @@ func TestChunk(t *testing.T) {
 		res[111].Values.String(),
 	)
 }
+
+func TestRollup(t *testing.T) {
+	tmp, err := os.MkdirTemp(os.TempDir(), "")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmp)
+
+	nan := timeseries.NaN
+	data := []float32{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 100, 1, 1, 1, 1, 1, nan, nan, nan, nan, nan, nan}
+	p := path.Join(tmp, "rollup.db")
+	f, err := os.Create(p)
+	require.NoError(t, err)
+	err = WriteRollup(f, 0, 5, 180, true, []*model.MetricValues{
+		{Labels: model.Labels{"a": "bb"}, LabelsHash: 111, Values: timeseries.NewWithData(30, 30, data)},
+	})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	meta, err := ReadMeta(p)
+	require.NoError(t, err)
+	assert.True(t, meta.Rollup)
+	assert.Equal(t, timeseries.Duration(180), meta.Step)
+
+	read := func(aggregate Aggregate, fillFunc timeseries.FillFunc) string {
+		f, err := os.Open(p)
+		require.NoError(t, err)
+		defer f.Close()
+		res := map[uint64]*model.MetricValues{}
+		require.NoError(t, ReadRollup(f, aggregate, 0, 5, 180, res, fillFunc))
+		assert.Equal(t, model.Labels{"a": "bb"}, res[111].Labels)
+		return res[111].Values.String()
+	}
+	assert.Equal(t, "TimeSeries(0, 5, 180, [. 1 1 1 100])", read(AggregateMax, timeseries.FillMax))
+	assert.Equal(t, "TimeSeries(0, 5, 180, [. 1 1 1 1])", read(AggregateMin, timeseries.FillMin))
+	assert.Equal(t, "TimeSeries(0, 5, 180, [0 6 6 6 105])", read(AggregateSum, timeseries.FillSum))
+	assert.Equal(t, "TimeSeries(0, 5, 180, [0 6 6 6 6])", read(AggregateCount, timeseries.FillSum))
+	assert.Equal(t, "TimeSeries(0, 5, 180, [. 1 1 1 17.500000])", read(AggregateAvg, timeseries.FillAny))
+
+	// a wider step merges the buckets
+	f, err = os.Open(p)
+	require.NoError(t, err)
+	defer f.Close()
+	res := map[uint64]*model.MetricValues{}
+	require.NoError(t, ReadFrom(f, 0, 3, 360, res, timeseries.FillMax))
+	assert.Equal(t, "TimeSeries(0, 3, 360, [. 1 17.500000])", res[111].Values.String())
+}
@@ -52,18 +52,36 @@ func putCompressionBuffer(buf []byte) {
 }
 
 func writeBlocks(w io.Writer, from timeseries.Time, step timeseries.Duration, pointsCount int, values []*model.MetricValues) error {
-	data := getBlockBuffer()
-	defer putBlockBuffer(data)
-	compressionBuf := getCompressionBuffer()[:blockCompressBound]
-	defer putCompressionBuffer(compressionBuf)
 	floatBuf := make([]float32, pointsCount)
 	nans := make([]float32, pointsCount)
-	valueSize := pointsCount * 4
 	to := from.Add(timeseries.Duration(pointsCount-1) * step)
 
 	for i := range nans {
 		nans[i] = timeseries.NaN
 	}
+	return writeRecords(w, pointsCount*4, len(values), func(i int) []byte {
+		copy(floatBuf, nans)
+		iter := values[i].Values.Iter()
+		for iter.Next() {
+			t, v := iter.Value()
+			if t > to {
+				break
+			}
+			if t < from {
+				continue
+			}
+			floatBuf[int((t-from)/timeseries.Time(step))] = v
+		}
+		return asBytes32(floatBuf)
+	})
+}
+
+// writeRecords writes count fixed-size records into lz4-compressed blocks, a record never spans two blocks.
+func writeRecords(w io.Writer, recordSize int, count int, record func(i int) []byte) error {
+	data := getBlockBuffer()
+	defer putBlockBuffer(data)
+	compressionBuf := getCompressionBuffer()[:blockCompressBound]
+	defer putCompressionBuffer(compressionBuf)
 
 	flush := func() error {
 		if len(data) == 0 {
@@ -85,26 +103,13 @@ func writeBlocks(w io.Writer, from timeseries.Time, step timeseries.Duration, po
 		return err
 	}
 
-	for _, mv := range values {
-		if len(data)+valueSize > blockSize {
+	for i := 0; i < count; i++ {
+		if len(data)+recordSize > blockSize {
 			if err := flush(); err != nil {
 				return err
 			}
 		}
-		copy(floatBuf, nans)
-		iter := mv.Values.Iter()
-		for iter.Next() {
-			t, v := iter.Value()
-			if t > to {
-				break
-			}
-			if t < from {
-				continue
-			}
-			floatBuf[int((t-from)/timeseries.Time(step))] = v
-		}
-		data = append(data, asBytes32(floatBuf)...)
-
+		data = append(data, record(i)...)
 	}
 	return flush()
 }
@@ -142,12 +147,12 @@ func (br *blockReader) readNextBlock() error {
 	return nil
 }
 
-func newBlockReader(r io.Reader, h *header) (*blockReader, error) {
+func newBlockReader(r io.Reader, h *header, valueSize int) (*blockReader, error) {
 	br := &blockReader{
 		r:              r,
 		data:           getBlockBuffer()[:blockSize],
 		compressionBuf: getCompressionBuffer(),
-		valueSize:      int(h.PointsCount) * 4,
+		valueSize:      valueSize,
 		h:              h,
 	}
 	if err := br.readNextBlock(); err != nil {
@@ -162,14 +167,22 @@ func (br *blockReader) reclaimBuffers() {
 }
 
 func (br *blockReader) read(mv *model.MetricValues, fillFunc timeseries.FillFunc) (bool, error) {
+	record, err := br.next()
+	if err != nil {
+		return false, err
+	}
+	return fillFunc(mv.Values, br.h.From, br.h.Step, record), nil
+}
+
+func (br *blockReader) next() ([]float32, error) {
 	if br.offset+br.valueSize > blockSize {
 		if err := br.readNextBlock(); err != nil {
-			return false, err
+			return nil, err
 		}
 	}
-	changed := fillFunc(mv.Values, br.h.From, br.h.Step, asFloats32(br.data[br.offset:br.offset+br.valueSize]))
+	record := asFloats32(br.data[br.offset : br.offset+br.valueSize])
 	br.offset += br.valueSize
-	return changed, nil
+	return record, nil
 }
 
 func Write(f io.Writer, from timeseries.Time, pointsCount int, step timeseries.Duration, finalized bool, metrics []*model.MetricValues) (err error) {
@@ -466,7 +479,7 @@ func readV4(reader *bufio.Reader, h *header, from timeseries.Time, pointsCount i
 		return err
 	}
 	hashes := asUint64(hashesBuf)
-	br, err := newBlockReader(reader, h)
+	br, err := newBlockReader(reader, h, int(h.PointsCount)*4)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,190 @@
+package chunk
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+// Aggregate selects one of the values stored for every bucket of a rollup chunk.
+type Aggregate uint8
+
+const (
+	AggregateMin Aggregate = iota
+	AggregateMax
+	AggregateSum
+	AggregateCount
+	AggregateAvg
+)
+
+// the avg is not stored, it's calculated as sum/count
+const rollupAggregatesCount = 4
+
+func (a Aggregate) String() string {
+	switch a {
+	case AggregateMin:
+		return "min"
+	case AggregateMax:
+		return "max"
+	case AggregateSum:
+		return "sum"
+	case AggregateCount:
+		return "count"
+	case AggregateAvg:
+		return "avg"
+	}
+	return fmt.Sprintf("aggregate(%d)", a)
+}
+
+func (a Aggregate) index() int {
+	return int(a)
+}
+
+// WriteRollup writes a V5 chunk: for every bucket of the given step it stores the min, max, sum and count
+// of the raw points falling into the bucket.
+// It keeps short spikes visible in long-range views, where the raw points are downsampled.
+func WriteRollup(f io.Writer, from timeseries.Time, pointsCount int, step timeseries.Duration, finalized bool, metrics []*model.MetricValues) error {
+	w := bufio.NewWriter(f)
+	h := &header{
+		Version:                V5,
+		From:                   from,
+		PointsCount:            uint32(pointsCount),
+		Step:                   step,
+		Finalized:              finalized,
+		DataSizeOrMetricsCount: uint32(len(metrics)),
+	}
+	if err := binary.Write(w, binary.LittleEndian, h); err != nil {
+		return err
+	}
+	hashes := make([]uint64, 0, len(metrics))
+	for _, mv := range metrics {
+		hashes = append(hashes, mv.LabelsHash)
+	}
+	if len(hashes) > 0 {
+		if _, err := w.Write(asBytes64(hashes)); err != nil {
+			return err
+		}
+	}
+	record := make([]float32, rollupAggregatesCount*pointsCount)
+	buckets := make([][]float32, pointsCount)
+	err := writeRecords(w, len(record)*4, len(metrics), func(i int) []byte {
+		for b := range buckets {
+			buckets[b] = buckets[b][:0]
+		}
+		iter := metrics[i].Values.Iter()
+		for iter.Next() {
+			t, v := iter.Value()
+			if timeseries.IsNaN(v) {
+				continue
+			}
+			// like timeseries.FillSum, the bucket at t aggregates the points within (t-step, t]
+			b := int((t.Sub(from) + step - 1) / step)
+			if b < 0 || b >= pointsCount {
+				continue
+			}
+			buckets[b] = append(buckets[b], v)
+		}
+		for b, values := range buckets {
+			rollupBucket(values, record, b, pointsCount)
+		}
+		return asBytes32(record)
+	})
+	if err != nil {
+		return err
+	}
+	if err = writeLabelsV4(w, metrics); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func rollupBucket(values []float32, record []float32, b int, pointsCount int) {
+	set := func(a Aggregate, v float32) {
+		record[a.index()*pointsCount+b] = v
+	}
+	if len(values) == 0 {
+		for a := AggregateMin; a < AggregateAvg; a++ {
+			set(a, timeseries.NaN)
+		}
+		return
+	}
+	minV, maxV, sum := values[0], values[0], float32(0)
+	for _, v := range values {
+		minV, maxV = min(minV, v), max(maxV, v)
+		sum += v
+	}
+	set(AggregateMin, minV)
+	set(AggregateMax, maxV)
+	set(AggregateSum, sum)
+	set(AggregateCount, float32(len(values)))
+}
+
+// ReadRollup reads the given aggregate from a V5 chunk. The fill function merges the buckets into the destination series,
+// it should match the aggregate (e.g., timeseries.FillMax for AggregateMax). Since the avg of merged buckets must be
+// weighted by their point counts, the callers merging the buckets should read the sum and the count instead.
+func ReadRollup(r io.Reader, aggregate Aggregate, from timeseries.Time, pointsCount int, step timeseries.Duration, dest map[uint64]*model.MetricValues, fillFunc timeseries.FillFunc) error {
+	reader := bufio.NewReader(r)
+	h := header{}
+	if err := binary.Read(reader, binary.LittleEndian, &h); err != nil {
+		return err
+	}
+	if h.Version != V5 {
+		return fmt.Errorf("not a rollup chunk: version %d", h.Version)
+	}
+	return readV5(reader, &h, aggregate, from, pointsCount, step, dest, fillFunc)
+}
+
+func readV5(reader *bufio.Reader, h *header, aggregate Aggregate, from timeseries.Time, pointsCount int, step timeseries.Duration, dest map[uint64]*model.MetricValues, fillFunc timeseries.FillFunc) error {
+	hashesBuf := make([]byte, int(h.DataSizeOrMetricsCount)*8)
+	if _, err := io.ReadFull(reader, hashesBuf); err != nil {
+		return err
+	}
+	if len(hashesBuf) == 0 {
+		return nil
+	}
+	hashes := asUint64(hashesBuf)
+	n := int(h.PointsCount)
+	br, err := newBlockReader(reader, h, rollupAggregatesCount*n*4)
+	if err != nil {
+		return err
+	}
+	defer br.reclaimBuffers()
+	values := make([]float32, n)
+	missing := map[uint64]*model.MetricValues{}
+	for _, hash := range hashes {
+		record, err := br.next()
+		if err != nil {
+			return fmt.Errorf("failed to read data: %w", err)
+		}
+		if aggregate == AggregateAvg {
+			sum, count := record[AggregateSum.index()*n:][:n], record[AggregateCount.index()*n:][:n]
+			for i := range values {
+				values[i] = timeseries.NaN
+				if count[i] > 0 {
+					values[i] = sum[i] / count[i]
+				}
+			}
+		} else {
+			copy(values, record[aggregate.index()*n:][:n])
+		}
+		mv, exists := dest[hash]
+		if mv == nil {
+			mv = &model.MetricValues{
+				LabelsHash: hash,
+				Values:     timeseries.New(from, pointsCount, step),
+			}
+			missing[hash] = mv
+		}
+		if fillFunc(mv.Values, h.From, h.Step, values) && !exists {
+			dest[hash] = mv
+		}
+	}
+	if err = readLabelsV4(reader, hashes, missing); err != nil {
+		return fmt.Errorf("failed to read labels: %w", err)
+	}
+	return nil
+}
@@ -3,9 +3,11 @@ package cache
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 
 	"github.com/coroot/coroot/cache/chunk"
+	"github.com/coroot/coroot/constructor"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
@@ -24,6 +26,9 @@ type Client struct {
 	projectId db.ProjectId
 }
 
+// QueryRange reads the query from the cached chunks.
+// For steps coarser than the rollup resolution, the compacted ranges are read from the rollup chunks
+// using the rollup of the query (see constructor.QueryRollup), so that short spikes are not lost to downsampling.
 func (c *Client) QueryRange(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration, fillFunc timeseries.FillFunc) ([]*model.MetricValues, error) {
 	c.cache.lock.RLock()
 	defer c.cache.lock.RUnlock()
@@ -41,16 +46,40 @@ func (c *Client) QueryRange(ctx context.Context, query string, from, to timeseri
 	res := map[uint64]*model.MetricValues{}
 	resPoints := int(to.Sub(from)/step + 1)
 
-	chunks := maps.Values(qData.chunksOnDisk)
+	var chunks []*chunk.Meta
+	rollups := map[timeseries.Time]*chunk.Meta{}
+	for _, ch := range qData.chunksOnDisk {
+		if ch.From > to || ch.To() < from {
+			continue
+		}
+		if !ch.Rollup {
+			chunks = append(chunks, ch)
+			continue
+		}
+		if ch.Step > step {
+			continue
+		}
+		// the coarsest resolution that is still finer than the requested step
+		if r := rollups[ch.From]; r == nil || ch.Step > r.Step {
+			rollups[ch.From] = ch
+		}
+	}
+
+	if len(rollups) > 0 {
+		if err := c.readRollups(ctx, query, rollups, from, resPoints, step, res); err != nil {
+			return nil, err
+		}
+		chunks = slices.DeleteFunc(chunks, func(ch *chunk.Meta) bool {
+			r := rollups[ch.From]
+			return r != nil && ch.To() <= r.To()
+		})
+	}
+
 	sort.Slice(chunks, func(i, j int) bool {
 		return chunks[i].Created < chunks[j].Created
 	})
-
 	for _, ch := range chunks {
-		if ch.From > to || ch.To() < from {
-			continue
-		}
-		err := chunk.Read(ch.Path, from, resPoints, step, res, fillFunc)
+		err := c.cache.readChunk(ctx, ch.Path, from, resPoints, step, res, fillFunc)
 		if err != nil {
 			return nil, err
 		}
@@ -58,6 +87,46 @@ func (c *Client) QueryRange(ctx context.Context, query string, from, to timeseri
 	return maps.Values(res), nil
 }
 
+// readRollups reads the compacted ranges using the rollup of the query.
+// The averages are calculated from the merged sums and counts, so that the buckets are weighted by their point counts.
+func (c *Client) readRollups(ctx context.Context, query string, rollups map[timeseries.Time]*chunk.Meta, from timeseries.Time, pointsCount int, step timeseries.Duration, dest map[uint64]*model.MetricValues) error {
+	var aggregate chunk.Aggregate
+	var fillFunc timeseries.FillFunc
+	switch constructor.QueryRollup(query) {
+	case constructor.RollupMin:
+		aggregate, fillFunc = chunk.AggregateMin, timeseries.FillMin
+	case constructor.RollupSum:
+		aggregate, fillFunc = chunk.AggregateSum, timeseries.FillSum
+	case constructor.RollupAvg:
+		sums := map[uint64]*model.MetricValues{}
+		counts := map[uint64]*model.MetricValues{}
+		for _, r := range rollups {
+			if err := c.cache.readRollupChunk(ctx, r.Path, chunk.AggregateSum, from, pointsCount, step, sums, timeseries.FillSum); err != nil {
+				return err
+			}
+			if err := c.cache.readRollupChunk(ctx, r.Path, chunk.AggregateCount, from, pointsCount, step, counts, timeseries.FillSum); err != nil {
+				return err
+			}
+		}
+		for hash, sum := range sums {
+			count := counts[hash]
+			if count == nil {
+				continue
+			}
+			dest[hash] = &model.MetricValues{Labels: sum.Labels, LabelsHash: hash, Values: timeseries.Div(sum.Values, count.Values)}
+		}
+		return nil
+	default:
+		aggregate, fillFunc = chunk.AggregateMax, timeseries.FillMax
+	}
+	for _, r := range rollups {
+		if err := c.cache.readRollupChunk(ctx, r.Path, aggregate, from, pointsCount, step, dest, fillFunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Client) GetStep(from, to timeseries.Time) (timeseries.Duration, error) {
 	c.cache.lock.RLock()
 	defer c.cache.lock.RUnlock()
@@ -69,7 +138,7 @@ func (c *Client) GetStep(from, to timeseries.Time) (timeseries.Duration, error)
 	var step timeseries.Duration
 	for _, qData := range projData.queries {
 		for _, ch := range qData.chunksOnDisk {
-			if ch.From > to || ch.To() < from {
+			if ch.Rollup || ch.From > to || ch.To() < from {
 				continue
 			}
 			if ch.Step > step {
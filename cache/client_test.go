@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_QueryRangeRollup(t *testing.T) {
+	projectId := db.ProjectId("p1")
+	dir := t.TempDir()
+	c := &Cache{cfg: Config{Path: dir}, storage: storage.NewFS(dir), byProject: map[db.ProjectId]*projectData{projectId: newProjectData()}}
+
+	step := timeseries.Minute
+	from := timeseries.Time(1700000000).Truncate(timeseries.Hour)
+	pointsCount := 120
+	write := func(query string) {
+		data := make([]float32, pointsCount)
+		for i := range data {
+			data[i] = 1
+		}
+		data[37] = 100
+		ls := model.Labels{"pod": "catalog-1"}
+		metrics := []*model.MetricValues{{Labels: ls, LabelsHash: ls.Hash(), Values: timeseries.NewWithData(from, step, data)}}
+		require.NoError(t, c.writeChunk(projectId, queryHash(query), from, pointsCount, step, true, metrics))
+		require.NoError(t, c.writeRollupChunk(projectId, queryHash(query), from, 2*12+1, 5*timeseries.Minute, metrics))
+	}
+	const (
+		memory    = "container_resources_memory_rss_bytes"
+		cpu       = "rate(container_resources_cpu_usage_seconds_total[$RANGE])"
+		available = "node_resources_memory_available_bytes"
+	)
+	write(memory)
+	write(cpu)
+	write(available)
+	write("rr_application_log_messages")
+	write("rr_connection_l7_requests")
+
+	client := c.GetCacheClient(projectId)
+	ctx := context.Background()
+	to := from.Add(timeseries.Duration(pointsCount-1) * step)
+	query := func(query string, step timeseries.Duration, fillFunc timeseries.FillFunc) string {
+		mvs, err := client.QueryRange(ctx, query, from, to, step, fillFunc)
+		require.NoError(t, err)
+		require.Len(t, mvs, 1)
+		assert.Equal(t, model.Labels{"pod": "catalog-1"}, mvs[0].Labels)
+		return mvs[0].Values.String()
+	}
+
+	// the raw chunk is used when the step is finer than the rollup resolution
+	assert.Equal(t, "TimeSeries(1699999200, 120, 60, [1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 100 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1 1])",
+		query(memory, step, timeseries.FillAny))
+
+	// gauges and rates show the peak
+	assert.Equal(t, "TimeSeries(1699999200, 4, 1800, [1 1 100 1])",
+		query(memory, 30*timeseries.Minute, timeseries.FillAny))
+	assert.Equal(t, "TimeSeries(1699999200, 4, 1800, [1 1 100 1])",
+		query(cpu, 30*timeseries.Minute, timeseries.FillAny))
+	// the series where a drop is the problem show the minimum
+	assert.Equal(t, "TimeSeries(1699999200, 4, 1800, [1 1 1 1])",
+		query(available, 30*timeseries.Minute, timeseries.FillAny))
+	// counters are summed
+	assert.Equal(t, "TimeSeries(1699999200, 4, 1800, [1 30 129 30])",
+		query("rr_application_log_messages", 30*timeseries.Minute, timeseries.FillSum))
+	// throughput is averaged over the points of every bucket
+	assert.Equal(t, "TimeSeries(1699999200, 4, 1800, [1 1 4.300000 1])",
+		query("rr_connection_l7_requests", 30*timeseries.Minute, timeseries.FillAny))
+}
@@ -1,14 +1,16 @@
 package cache
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/coroot/coroot/cache/chunk"
+	"github.com/coroot/coroot/cache/storage"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
@@ -37,6 +39,9 @@ func (ct CompactionTask) String() string {
 func calcCompactionTasks(compactor Compactor, projectID db.ProjectId, queryHash string, chunks map[string]*chunk.Meta) []*CompactionTask {
 	tasks := map[timeseries.Time]*CompactionTask{}
 	for _, ch := range chunks {
+		if ch.Rollup {
+			continue
+		}
 		if timeseries.Duration(ch.PointsCount)*ch.Step != compactor.SrcChunkDuration {
 			continue
 		}
@@ -94,7 +99,7 @@ func (c *Cache) compaction() {
 		c.lock.RLock()
 
 		for projectID, projData := range c.byProject {
-			if projData == nil {
+			if projData == nil || !c.cfg.Sharding.IsOwner(projectID) {
 				continue
 			}
 			for hash, qData := range projData.queries {
@@ -116,6 +121,7 @@ func (c *Cache) compact(t CompactionTask) error {
 		return fmt.Errorf("no src chunks")
 	}
 	start := time.Now()
+	ctx := context.Background()
 	metrics := map[uint64]*model.MetricValues{}
 	sort.Slice(t.src, func(i, j int) bool {
 		return t.src[i].From < t.src[j].From
@@ -128,7 +134,7 @@ func (c *Cache) compact(t CompactionTask) error {
 	}
 	pointsCount := int(t.compactor.DstChunkDuration / step)
 	for _, i := range t.src {
-		if err := chunk.Read(i.Path, t.dstChunk, pointsCount, step, metrics, timeseries.FillAny); err != nil {
+		if err := c.readChunk(ctx, i.Path, t.dstChunk, pointsCount, step, metrics, timeseries.FillAny); err != nil {
 			return fmt.Errorf("failed to read from src chunk %s: %s", i.Path, err)
 		}
 	}
@@ -140,6 +146,16 @@ func (c *Cache) compact(t CompactionTask) error {
 	if err := c.writeChunk(t.projectID, t.queryHash, t.dstChunk, pointsCount, step, true, dst); err != nil {
 		return err
 	}
+	for _, rollupStep := range t.compactor.RollupSteps {
+		if rollupStep <= step || t.compactor.DstChunkDuration%rollupStep != 0 {
+			continue
+		}
+		// the bucket at T aggregates the points within (T-rollupStep, T], so one more bucket is needed to cover the whole chunk
+		rollupPointsCount := int(t.compactor.DstChunkDuration/rollupStep) + 1
+		if err := c.writeRollupChunk(t.projectID, t.queryHash, t.dstChunk, rollupPointsCount, rollupStep, dst); err != nil {
+			return err
+		}
+	}
 
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -152,7 +168,7 @@ func (c *Cache) compact(t CompactionTask) error {
 			klog.Errorf("query data not found: %s-%s", t.projectID, t.queryHash)
 		} else {
 			for _, src := range t.src {
-				if err := os.Remove(src.Path); err != nil {
+				if err := c.storage.Delete(ctx, src.Path); err != nil && !errors.Is(err, storage.ErrNotFound) {
 					klog.Errorf("failed to delete chunk %s: %s", src.Path, err)
 				}
 				delete(qData.chunksOnDisk, src.Path)
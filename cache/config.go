@@ -2,11 +2,16 @@ package cache
 
 import (
 	"github.com/coroot/coroot/cache/chunk"
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/sharding"
 	"github.com/coroot/coroot/timeseries"
 )
 
 type Config struct {
+	// Path is the directory for the query state database and, unless Storage is set, for the chunks.
 	Path       string
+	Storage    storage.Storage
+	Sharding   *sharding.Sharding
 	GC         *GcConfig
 	Compaction *CompactionConfig
 }
@@ -26,6 +31,9 @@ type CompactionConfig struct {
 type Compactor struct {
 	SrcChunkDuration timeseries.Duration `yaml:"src_chunk_duration_seconds"`
 	DstChunkDuration timeseries.Duration `yaml:"dst_chunk_duration_seconds"`
+	// RollupSteps are the resolutions of the rollup chunks (min, max, sum and count per bucket)
+	// written along with the compacted chunk. They are used for long-range views instead of downsampled raw points.
+	RollupSteps []timeseries.Duration `yaml:"rollup_steps_seconds"`
 }
 
 var DefaultCompactionConfig = CompactionConfig{
@@ -34,6 +42,6 @@ var DefaultCompactionConfig = CompactionConfig{
 	Compactors: []Compactor{
 		{SrcChunkDuration: chunk.Size, DstChunkDuration: timeseries.Hour},
 		{SrcChunkDuration: timeseries.Hour, DstChunkDuration: 4 * timeseries.Hour},
-		{SrcChunkDuration: 4 * timeseries.Hour, DstChunkDuration: 12 * timeseries.Hour},
+		{SrcChunkDuration: 4 * timeseries.Hour, DstChunkDuration: 12 * timeseries.Hour, RollupSteps: []timeseries.Duration{5 * timeseries.Minute, timeseries.Hour}},
 	},
 }
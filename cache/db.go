@@ -1,11 +1,12 @@
 package cache
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
-	"os"
-	"path"
 
+	"github.com/coroot/coroot/cache/storage"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/timeseries"
 	_ "github.com/mattn/go-sqlite3"
@@ -30,10 +31,27 @@ func (p *PrometheusQueryState) Migrate(m *db.Migrator) error {
 	return err
 }
 
+func (j *BackfillJob) Migrate(m *db.Migrator) error {
+	err := m.Exec(`
+	CREATE TABLE IF NOT EXISTS backfill_job (
+		id TEXT NOT NULL PRIMARY KEY,
+		project_id TEXT NOT NULL,
+		queries TEXT NOT NULL,
+		from_ts INTEGER NOT NULL,
+		to_ts INTEGER NOT NULL,
+		recompute INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		error TEXT NOT NULL,
+		created INTEGER NOT NULL
+	)`)
+	return err
+}
+
 type Status struct {
-	Error  string
-	LagMax timeseries.Duration
-	LagAvg timeseries.Duration
+	Error    string
+	LagMax   timeseries.Duration
+	LagAvg   timeseries.Duration
+	Backfill *BackfillProgress
 }
 
 func (c *Cache) saveState(state *PrometheusQueryState) error {
@@ -81,16 +99,59 @@ func (c *Cache) deleteState(state *PrometheusQueryState) error {
 func (c *Cache) deleteProject(projectId db.ProjectId) error {
 	c.stateLock.Lock()
 	defer c.stateLock.Unlock()
-	projectDir := path.Join(c.cfg.Path, string(projectId))
-	if err := os.RemoveAll(projectDir); err != nil {
+	if err := storage.DeletePrefix(context.Background(), c.storage, storage.Key(string(projectId), "")); err != nil {
 		return err
 	}
 	if _, err := c.state.Exec("DELETE FROM prometheus_query_state WHERE project_id = $1", projectId); err != nil {
 		return err
 	}
+	if _, err := c.state.Exec("DELETE FROM backfill_job WHERE project_id = $1", projectId); err != nil {
+		return err
+	}
 	return nil
 }
 
+func (c *Cache) saveBackfillJob(job *BackfillJob) error {
+	queries, err := json.Marshal(job.Queries)
+	if err != nil {
+		return err
+	}
+	c.stateLock.Lock()
+	defer c.stateLock.Unlock()
+	_, err = c.state.Exec(
+		"INSERT OR REPLACE INTO backfill_job (id, project_id, queries, from_ts, to_ts, recompute, status, error, created) values ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		job.Id, job.ProjectId, string(queries), job.From, job.To, job.Recompute, job.Status, job.Error, job.Created)
+	return err
+}
+
+func (c *Cache) loadBackfillJobs() ([]*BackfillJob, error) {
+	rows, err := c.state.Query("SELECT id, project_id, queries, from_ts, to_ts, recompute, status, error, created FROM backfill_job ORDER BY created")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []*BackfillJob
+	for rows.Next() {
+		j := &BackfillJob{cancel: func() {}}
+		var queries string
+		if err = rows.Scan(&j.Id, &j.ProjectId, &queries, &j.From, &j.To, &j.Recompute, &j.Status, &j.Error, &j.Created); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(queries), &j.Queries); err != nil {
+			return nil, err
+		}
+		res = append(res, j)
+	}
+	return res, rows.Err()
+}
+
+func (c *Cache) deleteBackfillJob(id string) error {
+	c.stateLock.Lock()
+	defer c.stateLock.Unlock()
+	_, err := c.state.Exec("DELETE FROM backfill_job WHERE id = $1", id)
+	return err
+}
+
 func (c *Cache) getMinUpdateTime(projectId db.ProjectId) (timeseries.Time, error) {
 	var min sql.NullInt64
 	err := c.state.QueryRow("SELECT min(last_ts) FROM prometheus_query_state WHERE project_id = $1", projectId).Scan(&min)
@@ -127,5 +188,6 @@ func (c *Cache) getStatus(projectId db.ProjectId) (*Status, error) {
 		s.LagMax = BackFillInterval
 		s.LagAvg = BackFillInterval
 	}
+	s.Backfill = c.getBackfillProgress(projectId)
 	return &s, nil
 }
@@ -1,9 +1,11 @@
 package cache
 
 import (
-	"os"
+	"context"
+	"errors"
 	"time"
 
+	"github.com/coroot/coroot/cache/storage"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/timeseries"
 	"k8s.io/klog"
@@ -16,12 +18,21 @@ func (c *Cache) gc() {
 	for range time.Tick(c.cfg.GC.Interval.ToStandard()) {
 		now := timeseries.Now()
 
-		if projects, err := c.db.GetProjectNames(); err != nil {
+		// snapshots are kept until the project is deleted, since their data can't be re-fetched
+		snapshots := map[db.ProjectId]bool{}
+		if projects, err := c.db.GetProjects(); err != nil {
 			klog.Errorln("failed to get projects:", err)
 		} else {
+			ids := map[db.ProjectId]bool{}
+			for _, p := range projects {
+				ids[p.Id] = true
+				if p.Settings.Snapshot != nil {
+					snapshots[p.Id] = true
+				}
+			}
 			c.lock.Lock()
 			for projectId := range c.byProject {
-				if _, ok := projects[projectId]; ok {
+				if ids[projectId] {
 					continue
 				}
 				klog.Infoln("deleting obsolete project:", projectId)
@@ -38,7 +49,7 @@ func (c *Cache) gc() {
 		toDelete := map[db.ProjectId]map[string][]string{}
 		c.lock.RLock()
 		for projectId, projData := range c.byProject {
-			if projData == nil {
+			if projData == nil || snapshots[projectId] || !c.cfg.Sharding.IsOwner(projectId) {
 				continue
 			}
 			toDeleteInProject := map[string][]string{}
@@ -65,7 +76,7 @@ func (c *Cache) gc() {
 				qData := projData.queries[hash]
 				for _, path := range chunks {
 					klog.Infoln("deleting obsolete chunk:", path)
-					if err := os.Remove(path); err != nil {
+					if err := c.storage.Delete(context.Background(), path); err != nil && !errors.Is(err, storage.ErrNotFound) {
 						klog.Errorf("failed to delete chunk %s: %s", path, err)
 					} else {
 						delete(qData.chunksOnDisk, path)
@@ -78,6 +89,12 @@ func (c *Cache) gc() {
 		}
 
 		c.lock.Unlock()
+
+		if p, ok := c.storage.(storage.Pruner); ok {
+			if err := p.Prune(context.Background()); err != nil {
+				klog.Errorln("failed to prune the local storage:", err)
+			}
+		}
 		klog.Infof("GC done in %s", time.Since(now.ToStandard()).Truncate(time.Millisecond))
 	}
 }
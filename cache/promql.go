@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/cache/chunk"
+	"github.com/coroot/coroot/constructor"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+)
+
+// cachedLabels contains the labels kept by the updater for each of the constructor queries.
+// The other labels are dropped, so selectors matching them can't be answered from the cache.
+var cachedLabels = map[string]*utils.StringSet{}
+
+func init() {
+	for _, q := range constructor.QUERIES {
+		cachedLabels[q.Query] = q.Labels
+	}
+}
+
+// QueryRangePromQL answers a PromQL query using the cached data.
+// A query can be answered if it matches a cached query exactly, or if it is a plain selector
+// of a metric cached under its name (e.g., a recording rule) — then the cached series are filtered by the selector's matchers.
+// ok is false if the query can't be answered from the cache and should be sent to the upstream Prometheus,
+// including the cases when the cached data doesn't cover the requested range.
+// The cached points are aligned to the cache step, so the query is also sent upstream
+// if the points at from+k*step can't be taken from the cache as they are:
+// the step must be a multiple of the cache step, and from must be a multiple of the step.
+func (c *Client) QueryRangePromQL(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration) ([]*model.MetricValues, bool, error) {
+	states, err := c.cache.loadStates(c.projectId)
+	if err != nil {
+		return nil, false, err
+	}
+	cacheStep, err := c.GetStep(from, to)
+	if err != nil || cacheStep <= 0 || step%cacheStep != 0 || from.Truncate(step) != from {
+		return nil, false, nil
+	}
+	if c.covers(states, query, from, to) {
+		mvs, err := c.QueryRange(ctx, query, from, to, step, timeseries.FillAny)
+		return mvs, true, err
+	}
+	expr, err := parser.ParseExpr(query)
+	if err != nil {
+		return nil, false, err
+	}
+	if cachedQuery := c.findEquivalentQuery(states, expr); cachedQuery != "" {
+		if !c.covers(states, cachedQuery, from, to) {
+			return nil, false, nil
+		}
+		mvs, err := c.QueryRange(ctx, cachedQuery, from, to, step, timeseries.FillAny)
+		return mvs, true, err
+	}
+	vs, ok := expr.(*parser.VectorSelector)
+	if !ok || !c.answerable(states, vs, from, to) {
+		return nil, false, nil
+	}
+	mvs, err := c.QueryRange(ctx, vs.Name, from, to, step, timeseries.FillAny)
+	if err != nil {
+		return nil, true, err
+	}
+	return filterByMatchers(mvs, vs.LabelMatchers), true, nil
+}
+
+// SeriesPromQL returns the label sets of the cached series matching the selectors.
+// ok is false if at least one of the selectors can't be answered from the cache.
+func (c *Client) SeriesPromQL(ctx context.Context, selectors []string, from, to timeseries.Time) ([]model.Labels, bool, error) {
+	states, err := c.cache.loadStates(c.projectId)
+	if err != nil {
+		return nil, false, err
+	}
+	var vss []*parser.VectorSelector
+	for _, s := range selectors {
+		matchers, err := parser.ParseMetricSelector(s)
+		if err != nil {
+			return nil, false, err
+		}
+		vs := &parser.VectorSelector{LabelMatchers: matchers}
+		for _, m := range matchers {
+			if m.Name == labels.MetricName && m.Type == labels.MatchEqual {
+				vs.Name = m.Value
+			}
+		}
+		if !c.answerable(states, vs, from, to) {
+			return nil, false, nil
+		}
+		vss = append(vss, vs)
+	}
+	step, err := c.GetStep(from, to)
+	if err != nil {
+		return nil, true, err
+	}
+	var res []model.Labels
+	seen := map[uint64]bool{}
+	for _, vs := range vss {
+		mvs, err := c.QueryRange(ctx, vs.Name, from, to, step, timeseries.FillAny)
+		if err != nil {
+			return nil, true, err
+		}
+		for _, mv := range filterByMatchers(mvs, vs.LabelMatchers) {
+			ls := model.Labels{labels.MetricName: vs.Name}
+			for k, v := range mv.Labels {
+				ls[k] = v
+			}
+			if h := ls.Hash(); !seen[h] {
+				seen[h] = true
+				res = append(res, ls)
+			}
+		}
+	}
+	return res, true, nil
+}
+
+// findEquivalentQuery returns the cached query that is syntactically equal to the expression, ignoring formatting.
+// $RANGE placeholders in cached queries are expanded the same way the Prometheus client does.
+func (c *Client) findEquivalentQuery(states map[string]*PrometheusQueryState, expr parser.Expr) string {
+	c.cache.lock.RLock()
+	var step timeseries.Duration
+	if projData := c.cache.byProject[c.projectId]; projData != nil {
+		step = projData.step
+	}
+	c.cache.lock.RUnlock()
+	normalized := expr.String()
+	for query := range states {
+		q := query
+		if step > 0 {
+			q = strings.ReplaceAll(q, "$RANGE", fmt.Sprintf(`%.0fs`, (step*3).ToStandard().Seconds()))
+		}
+		e, err := parser.ParseExpr(q)
+		if err != nil {
+			continue
+		}
+		if e.String() == normalized {
+			return query
+		}
+	}
+	return ""
+}
+
+// answerable reports whether the selector can be answered by filtering the series cached under the metric name.
+// The selector must not reference the labels dropped by the updater.
+func (c *Client) answerable(states map[string]*PrometheusQueryState, vs *parser.VectorSelector, from, to timeseries.Time) bool {
+	if vs.Name == "" || vs.OriginalOffset != 0 || vs.Timestamp != nil || vs.StartOrEnd != 0 {
+		return false
+	}
+	if constructor.RecordingRules[vs.Name] == nil { // recording rules exist only in the cache and keep all their labels
+		ls := cachedLabels[vs.Name]
+		if ls == nil {
+			return false
+		}
+		for _, m := range vs.LabelMatchers {
+			if m.Name != labels.MetricName && !ls.Has(m.Name) {
+				return false
+			}
+		}
+	}
+	return c.covers(states, vs.Name, from, to)
+}
+
+// covers reports whether the query has been downloaded up to the end of the range
+// and its chunks span the range without gaps.
+func (c *Client) covers(states map[string]*PrometheusQueryState, query string, from, to timeseries.Time) bool {
+	state := states[query]
+	if state == nil || state.LastTs.Before(to) {
+		return false
+	}
+	c.cache.lock.RLock()
+	projData := c.cache.byProject[c.projectId]
+	if projData == nil {
+		c.cache.lock.RUnlock()
+		return false
+	}
+	var chunks []*chunk.Meta
+	if qData := projData.queries[queryHash(query)]; qData != nil {
+		for _, ch := range qData.chunksOnDisk {
+			chunks = append(chunks, ch)
+		}
+	}
+	c.cache.lock.RUnlock()
+
+	sort.Slice(chunks, func(i, j int) bool {
+		return chunks[i].From < chunks[j].From
+	})
+	next := from
+	for _, ch := range chunks {
+		if ch.To().Before(next) {
+			continue
+		}
+		if ch.From.After(next) {
+			return false
+		}
+		next = ch.To().Add(ch.Step)
+		if next.After(to) {
+			return true
+		}
+	}
+	return false
+}
+
+func filterByMatchers(mvs []*model.MetricValues, matchers []*labels.Matcher) []*model.MetricValues {
+	res := mvs[:0]
+	for _, mv := range mvs {
+		matched := true
+		for _, m := range matchers {
+			if m.Name == labels.MetricName {
+				continue
+			}
+			if !m.Matches(mv.Labels[m.Name]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			res = append(res, mv)
+		}
+	}
+	return res
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_QueryRangePromQL(t *testing.T) {
+	projectId := db.ProjectId("p1")
+	dir := t.TempDir()
+	state, err := db.NewSqlite(dir)
+	require.NoError(t, err)
+	require.NoError(t, state.Migrator().Migrate(&PrometheusQueryState{}))
+	c := &Cache{cfg: Config{Path: dir}, storage: storage.NewFS(dir), state: state.DB(), byProject: map[db.ProjectId]*projectData{projectId: newProjectData()}}
+	cpuQuery := `sum by(pod) (rate(container_cpu_usage_seconds_total[$RANGE]))`
+	step := 15 * timeseries.Second
+	c.byProject[projectId].step = step
+	from := timeseries.Time(1700000000).Truncate(step)
+	for _, q := range []string{"rr_application_slo", cpuQuery, "kube_node_info"} {
+		require.NoError(t, c.saveState(&PrometheusQueryState{ProjectId: projectId, Query: q, LastTs: from.Add(3 * step)}))
+	}
+	series := func(ls model.Labels, v float32) *model.MetricValues {
+		return &model.MetricValues{Labels: ls, LabelsHash: ls.Hash(), Values: timeseries.NewWithData(from, step, []float32{v, v, v, v})}
+	}
+	require.NoError(t, c.writeChunk(projectId, queryHash("rr_application_slo"), from, 4, step, true, []*model.MetricValues{
+		series(model.Labels{"application": "default:Deployment:catalog"}, 1),
+		series(model.Labels{"application": "default:Deployment:orders"}, 2),
+	}))
+	require.NoError(t, c.writeChunk(projectId, queryHash(cpuQuery), from, 4, step, true, []*model.MetricValues{
+		series(model.Labels{"pod": "catalog-1"}, 3),
+	}))
+	require.NoError(t, c.writeChunk(projectId, queryHash("kube_node_info"), from, 4, step, true, []*model.MetricValues{
+		series(model.Labels{"node": "node-1"}, 1),
+	}))
+
+	client := c.GetCacheClient(projectId)
+	ctx := context.Background()
+	to := from.Add(3 * step)
+
+	mvs, ok, err := client.QueryRangePromQL(ctx, `rr_application_slo{application=~".*orders"}`, from, to, step)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.Len(t, mvs, 1)
+	assert.Equal(t, "default:Deployment:orders", mvs[0].Labels["application"])
+	assert.Equal(t, float32(2), mvs[0].Values.Last())
+
+	mvs, ok, err = client.QueryRangePromQL(ctx, `sum(rate(container_cpu_usage_seconds_total[45s])) by (pod)`, from, to, step)
+	require.NoError(t, err)
+	assert.True(t, ok, "normalized query must match the cached one")
+	require.Len(t, mvs, 1)
+
+	_, ok, err = client.QueryRangePromQL(ctx, `rate(node_cpu_seconds_total[1m])`, from, to, step)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	mvs, ok, err = client.QueryRangePromQL(ctx, `kube_node_info{node="node-1"}`, from, to, step)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.Len(t, mvs, 1)
+
+	_, ok, err = client.QueryRangePromQL(ctx, `kube_node_info{job="kube-state-metrics"}`, from, to, step)
+	require.NoError(t, err)
+	assert.False(t, ok, "the job label isn't cached")
+
+	_, ok, err = client.QueryRangePromQL(ctx, `rr_application_slo`, from, to.Add(step), step)
+	require.NoError(t, err)
+	assert.False(t, ok, "the range hasn't been downloaded yet")
+
+	_, ok, err = client.QueryRangePromQL(ctx, `rr_application_slo`, from.Add(-step), to, step)
+	require.NoError(t, err)
+	assert.False(t, ok, "the range isn't covered by the chunks")
+
+	mvs, ok, err = client.QueryRangePromQL(ctx, `kube_node_info`, from, to, step)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.Len(t, mvs, 1)
+	var ts []timeseries.Time
+	iter := mvs[0].Values.Iter()
+	for iter.Next() {
+		pt, _ := iter.Value()
+		ts = append(ts, pt)
+	}
+	assert.Equal(t, []timeseries.Time{from, from.Add(step), from.Add(2 * step), from.Add(3 * step)}, ts)
+
+	_, ok, err = client.QueryRangePromQL(ctx, `kube_node_info`, from, to, 20*timeseries.Second)
+	require.NoError(t, err)
+	assert.False(t, ok, "the step isn't a multiple of the cache step")
+
+	_, ok, err = client.QueryRangePromQL(ctx, `kube_node_info`, from.Add(5*timeseries.Second), to, step)
+	require.NoError(t, err)
+	assert.False(t, ok, "the points at start+k*step don't match the cached ones")
+
+	_, _, err = client.QueryRangePromQL(ctx, `rate(`, from, to, step)
+	assert.Error(t, err)
+
+	ls, ok, err := client.SeriesPromQL(ctx, []string{`rr_application_slo`}, from, to)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Len(t, ls, 2)
+	assert.Equal(t, "rr_application_slo", ls[0]["__name__"])
+
+	_, ok, err = client.SeriesPromQL(ctx, []string{`rr_application_slo`, `up`}, from, to)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func (c *Cache) Storage() storage.Storage {
+	return c.storage
+}
+
+// DeleteProject drops the cached data of the project, e.g., of a snapshot that failed to import.
+func (c *Cache) DeleteProject(projectId db.ProjectId) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.deleteProject(projectId); err != nil {
+		return err
+	}
+	delete(c.byProject, projectId)
+	return nil
+}
+
+// GetQueries returns the queries cached for the project.
+func (c *Client) GetQueries() ([]string, error) {
+	states, err := c.cache.loadStates(c.projectId)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(states))
+	for q := range states {
+		res = append(res, q)
+	}
+	return res, nil
+}
+
+// Import writes the series of the query as a single finalized chunk and marks the query as up to date
+// till the end of the chunk. It's used to mount snapshots, which are never updated from Prometheus.
+func (c *Cache) Import(projectId db.ProjectId, query string, from timeseries.Time, pointsCount int, step timeseries.Duration, metrics []*model.MetricValues) error {
+	if pointsCount <= 0 {
+		return fmt.Errorf("invalid points count: %d", pointsCount)
+	}
+	c.lock.Lock()
+	if projData := c.byProject[projectId]; projData == nil {
+		projData = newProjectData()
+		projData.step = step
+		c.byProject[projectId] = projData
+	}
+	c.lock.Unlock()
+	if err := c.writeChunk(projectId, queryHash(query), from, pointsCount, step, true, metrics); err != nil {
+		return err
+	}
+	// GetTo reserves one step for the points that may still be incomplete, the imported ones are complete
+	to := from.Add(timeseries.Duration(pointsCount) * step)
+	return c.saveState(&PrometheusQueryState{ProjectId: projectId, Query: query, LastTs: to})
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	projectId := db.ProjectId("snap")
+	dir := t.TempDir()
+	state, err := db.NewSqlite(dir)
+	require.NoError(t, err)
+	require.NoError(t, state.Migrator().Migrate(&PrometheusQueryState{}))
+	c := &Cache{cfg: Config{Path: dir}, storage: storage.NewFS(dir), state: state.DB(), byProject: map[db.ProjectId]*projectData{}}
+
+	step := timeseries.Minute
+	from := timeseries.Time(1700000000).Truncate(timeseries.Hour)
+	ls := model.Labels{"pod": "catalog-1"}
+	metrics := []*model.MetricValues{{Labels: ls, LabelsHash: ls.Hash(), Values: timeseries.NewWithData(from, step, []float32{1, 2, 3, 4, 5, 6})}}
+	require.NoError(t, c.Import(projectId, "container_memory_rss", from, 6, step, metrics))
+
+	client := c.GetCacheClient(projectId)
+	queries, err := client.GetQueries()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"container_memory_rss"}, queries)
+
+	to, err := client.GetTo()
+	require.NoError(t, err)
+	assert.Equal(t, from.Add(5*step), to)
+
+	s, err := client.GetStep(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, step, s)
+
+	mvs, err := client.QueryRange(context.Background(), "container_memory_rss", from, to, step, timeseries.FillAny)
+	require.NoError(t, err)
+	require.Len(t, mvs, 1)
+	assert.Equal(t, ls, mvs[0].Labels)
+	assert.Equal(t, "TimeSeries(1699999200, 6, 60, [1 2 3 4 5 6])", mvs[0].Values.String())
+
+	// the index is rebuilt from the storage after a restart
+	byProject, err := c.loadIndex(context.Background(), "")
+	require.NoError(t, err)
+	require.NotNil(t, byProject[projectId])
+	assert.Len(t, byProject[projectId].queries, 1)
+}
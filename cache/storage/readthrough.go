@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+
+	"k8s.io/klog"
+)
+
+// ReadThrough keeps a local copy of the remote objects.
+// Reads are served from the local disk, objects missing locally are downloaded on first access.
+// Writes and deletes go to both tiers, the remote storage is the source of truth.
+type ReadThrough struct {
+	local  *FS
+	remote Storage
+}
+
+func NewReadThrough(local *FS, remote Storage) *ReadThrough {
+	return &ReadThrough{local: local, remote: remote}
+}
+
+func (s *ReadThrough) List(ctx context.Context, prefix string) ([]Object, error) {
+	return s.remote.List(ctx, prefix)
+}
+
+func (s *ReadThrough) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	r, err := s.local.Get(ctx, key)
+	if err == nil {
+		return r, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		klog.Warningln("failed to read from the local storage:", err)
+	}
+	r, err = s.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.local.Put(ctx, key, data); err != nil {
+		klog.Warningln("failed to write to the local storage:", err)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// GetRange reads a part of the object without downloading it to the local storage.
+func (s *ReadThrough) GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	r, err := s.local.GetRange(ctx, key, offset, length)
+	if err == nil {
+		return r, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		klog.Warningln("failed to read from the local storage:", err)
+	}
+	return GetRange(ctx, s.remote, key, offset, length)
+}
+
+func (s *ReadThrough) Put(ctx context.Context, key string, data []byte) error {
+	if err := s.remote.Put(ctx, key, data); err != nil {
+		return err
+	}
+	if err := s.local.Put(ctx, key, data); err != nil {
+		klog.Warningln("failed to write to the local storage:", err)
+	}
+	return nil
+}
+
+func (s *ReadThrough) Delete(ctx context.Context, key string) error {
+	if err := s.local.Delete(ctx, key); err != nil && !errors.Is(err, ErrNotFound) {
+		klog.Warningln("failed to delete from the local storage:", err)
+	}
+	return s.remote.Delete(ctx, key)
+}
+
+// Prune deletes the local copies of the objects that no longer exist in the remote storage, e.g., deleted by another replica.
+// The local objects are listed first, so the objects being written concurrently are already in the remote storage.
+func (s *ReadThrough) Prune(ctx context.Context) error {
+	local, err := s.local.List(ctx, "")
+	if err != nil {
+		return err
+	}
+	remote, err := s.remote.List(ctx, "")
+	if err != nil {
+		return err
+	}
+	exists := make(map[string]bool, len(remote))
+	for _, o := range remote {
+		exists[o.Key] = true
+	}
+	for _, o := range local {
+		if exists[o.Key] {
+			continue
+		}
+		if err = s.local.Delete(ctx, o.Key); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+var ErrNotFound = errors.New("object not found")
+
+type Object struct {
+	Key      string
+	Size     int64
+	Modified timeseries.Time
+}
+
+// Storage is a flat key-value store for cache chunks.
+// Keys are slash-separated paths relative to the root of the storage, e.g. "<project_id>/<chunk>.db".
+type Storage interface {
+	List(ctx context.Context, prefix string) ([]Object, error)
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Put(ctx context.Context, key string, data []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// RangeReader is implemented by the storages that can read a part of an object without fetching the whole object.
+type RangeReader interface {
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+}
+
+// Pruner is implemented by the storages that keep local copies of the objects and need to drop the stale ones.
+type Pruner interface {
+	Prune(ctx context.Context) error
+}
+
+// GetRange reads length bytes of the object starting at offset.
+// Storages that don't implement RangeReader fetch the whole object.
+func GetRange(ctx context.Context, s Storage, key string, offset, length int64) (io.ReadCloser, error) {
+	if rr, ok := s.(RangeReader); ok {
+		return rr.GetRange(ctx, key, offset, length)
+	}
+	r, err := s.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = io.CopyN(io.Discard, r, offset); err != nil {
+		_ = r.Close()
+		return nil, err
+	}
+	return readCloser{Reader: io.LimitReader(r, length), Closer: r}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// DeletePrefix deletes all the objects whose keys start with the given prefix.
+func DeletePrefix(ctx context.Context, s Storage, prefix string) error {
+	objects, err := s.List(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, o := range objects {
+		if err = s.Delete(ctx, o.Key); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func Key(parts ...string) string {
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// s3StandIn is a minimal in-memory implementation of the S3 API: path-style PUT, GET, DELETE and ListObjectsV2.
+type s3StandIn struct {
+	t       *testing.T
+	bucket  string
+	lock    sync.Mutex
+	objects map[string][]byte
+	gets    int
+}
+
+func newS3StandIn(t *testing.T, bucket string) (*s3StandIn, *httptest.Server) {
+	s := &s3StandIn{t: t, bucket: bucket, objects: map[string][]byte{}}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key/") || !strings.Contains(auth, "/us-east-1/s3/aws4_request") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	assert.Equal(s.t, sha256Hex(body), r.Header.Get("X-Amz-Content-Sha256"))
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != s.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	switch {
+	case r.Method == http.MethodPut:
+		s.objects[key] = body
+	case r.Method == http.MethodGet && key == "":
+		prefix := r.URL.Query().Get("prefix")
+		type content struct {
+			Key          string
+			Size         int
+			LastModified time.Time
+		}
+		res := struct {
+			XMLName     xml.Name `xml:"ListBucketResult"`
+			Contents    []content
+			IsTruncated bool
+		}{}
+		for k, v := range s.objects {
+			if strings.HasPrefix(k, prefix) {
+				res.Contents = append(res.Contents, content{Key: k, Size: len(v), LastModified: time.Now().UTC()})
+			}
+		}
+		_ = xml.NewEncoder(w).Encode(res)
+	case r.Method == http.MethodGet:
+		data, ok := s.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		s.gets++
+		var from, to int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &from, &to); err == nil {
+			data = data[from:min(to+1, len(data))]
+			w.WriteHeader(http.StatusPartialContent)
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func keys(t *testing.T, s Storage, prefix string) []string {
+	objects, err := s.List(context.Background(), prefix)
+	require.NoError(t, err)
+	var res []string
+	for _, o := range objects {
+		res = append(res, o.Key)
+	}
+	sort.Strings(res)
+	return res
+}
+
+func get(t *testing.T, s Storage, key string) string {
+	r, err := s.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func getRange(t *testing.T, s Storage, key string, offset, length int64) string {
+	r, err := GetRange(context.Background(), s, key, offset, length)
+	require.NoError(t, err)
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func testStorage(t *testing.T, s Storage) {
+	ctx := context.Background()
+	require.NoError(t, s.Put(ctx, "p1/a.db", []byte("aaa")))
+	require.NoError(t, s.Put(ctx, "p1/b.db", []byte("bbb")))
+	require.NoError(t, s.Put(ctx, "p2/c.db", []byte("ccc")))
+
+	assert.Equal(t, []string{"p1/a.db", "p1/b.db", "p2/c.db"}, keys(t, s, ""))
+	assert.Equal(t, []string{"p2/c.db"}, keys(t, s, "p2/"))
+	assert.Equal(t, "bbb", get(t, s, "p1/b.db"))
+	require.NoError(t, s.Put(ctx, "p1/d.db", []byte("header+data")))
+	assert.Equal(t, "header", getRange(t, s, "p1/d.db", 0, 6))
+	assert.Equal(t, "data", getRange(t, s, "p1/d.db", 7, 4))
+
+	_, err := s.Get(ctx, "p1/missing.db")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, DeletePrefix(ctx, s, "p1/"))
+	assert.Equal(t, []string{"p2/c.db"}, keys(t, s, ""))
+}
+
+func TestFS(t *testing.T) {
+	s := NewFS(t.TempDir())
+	testStorage(t, s)
+	assert.ErrorIs(t, s.Delete(context.Background(), "p1/a.db"), ErrNotFound)
+}
+
+func TestS3(t *testing.T) {
+	standIn, srv := newS3StandIn(t, "coroot")
+	s := NewS3(S3Config{Endpoint: srv.URL, Bucket: "coroot", Prefix: "/cache/", AccessKeyId: "key", SecretAccessKey: "secret"})
+	testStorage(t, s)
+	assert.Contains(t, standIn.objects, "cache/p2/c.db")
+
+	s = NewS3(S3Config{Endpoint: srv.URL, Bucket: "coroot", AccessKeyId: "another", SecretAccessKey: "secret"})
+	_, err := s.List(context.Background(), "")
+	assert.ErrorContains(t, err, "403 Forbidden")
+}
+
+func TestReadThrough(t *testing.T) {
+	ctx := context.Background()
+	standIn, srv := newS3StandIn(t, "coroot")
+	remote := NewS3(S3Config{Endpoint: srv.URL, Bucket: "coroot", AccessKeyId: "key", SecretAccessKey: "secret"})
+	testStorage(t, NewReadThrough(NewFS(t.TempDir()), remote))
+
+	// another replica with a cold local disk
+	local := NewFS(t.TempDir())
+	s := NewReadThrough(local, remote)
+	assert.Equal(t, []string{"p2/c.db"}, keys(t, s, ""))
+	assert.Empty(t, keys(t, local, ""))
+
+	assert.Equal(t, "c", getRange(t, s, "p2/c.db", 0, 1))
+	assert.Empty(t, keys(t, local, ""), "ranged reads must not download the object")
+
+	assert.Equal(t, "ccc", get(t, s, "p2/c.db"))
+	assert.Equal(t, "ccc", get(t, s, "p2/c.db"))
+	assert.Equal(t, 2, standIn.gets)
+	assert.Equal(t, []string{"p2/c.db"}, keys(t, local, ""))
+
+	// the object is deleted by another replica
+	require.NoError(t, remote.Delete(ctx, "p2/c.db"))
+	require.NoError(t, remote.Put(ctx, "p2/e.db", []byte("eee")))
+	require.NoError(t, s.Prune(ctx))
+	assert.Empty(t, keys(t, local, ""))
+	require.NoError(t, s.Put(ctx, "p2/c.db", []byte("ccc")))
+
+	require.NoError(t, s.Prune(ctx))
+	assert.Equal(t, []string{"p2/c.db"}, keys(t, local, ""))
+
+	require.NoError(t, s.Delete(ctx, "p2/c.db"))
+	require.NoError(t, s.Delete(ctx, "p2/e.db"))
+	assert.Empty(t, keys(t, local, ""))
+	assert.Empty(t, keys(t, remote, ""))
+}
@@ -1,23 +1,21 @@
 package cache
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
-	"os"
-	"path"
-	"path/filepath"
 	"slices"
 	"sync"
 	"time"
 
 	"github.com/coroot/coroot/cache/chunk"
+	"github.com/coroot/coroot/cache/storage"
 	"github.com/coroot/coroot/constructor"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/prom"
 	"github.com/coroot/coroot/timeseries"
-	"github.com/coroot/coroot/utils"
 	"k8s.io/klog"
 )
 
@@ -38,6 +36,9 @@ func (c *Cache) updater() {
 		}
 		ids := map[db.ProjectId]bool{}
 		for _, project := range projects {
+			if !c.cfg.Sharding.IsOwner(project.Id) {
+				continue
+			}
 			promClient, _ := c.getPrometheusClient(project)
 			if promClient == nil {
 				continue
@@ -46,6 +47,12 @@ func (c *Cache) updater() {
 			_, ok := workers.Load(project.Id)
 			workers.Store(project.Id, project)
 			if !ok {
+				if c.cfg.Sharding != nil {
+					// the project might have been updated by another replica before it was assigned to this one
+					if err := c.reloadIndex(project.Id); err != nil {
+						klog.Errorln("failed to reload the cache index:", err)
+					}
+				}
 				go c.updaterWorker(workers, project.Id, promClient)
 			}
 		}
@@ -58,6 +65,26 @@ func (c *Cache) updater() {
 	}
 }
 
+// getQueries returns the Prometheus queries cached for the project, excluding the recording rules.
+func (c *Cache) getQueries(project *db.Project) ([]constructor.Query, error) {
+	checkConfigs, err := c.db.GetCheckConfigs(project.Id)
+	if err != nil {
+		return nil, err
+	}
+	queries := slices.Clone(constructor.QUERIES)
+	for appId := range checkConfigs {
+		availabilityCfg, _ := checkConfigs.GetAvailability(appId)
+		if availabilityCfg.Custom {
+			queries = append(queries, constructor.Q("", availabilityCfg.Total()), constructor.Q("", availabilityCfg.Failed()))
+		}
+		latencyCfg, _ := checkConfigs.GetLatency(appId, project.CalcApplicationCategory(appId))
+		if latencyCfg.Custom {
+			queries = append(queries, constructor.Q("", latencyCfg.Histogram(), "le"))
+		}
+	}
+	return queries, nil
+}
+
 type UpdateTask struct {
 	query constructor.Query
 	state *PrometheusQueryState
@@ -74,12 +101,6 @@ func (c *Cache) updaterWorker(projects *sync.Map, projectId db.ProjectId, promCl
 		projData = newProjectData()
 		projData.step = step
 		c.byProject[projectId] = projData
-		projectDir := path.Join(c.cfg.Path, string(projectId))
-		if err := utils.CreateDirectoryIfNotExists(projectDir); err != nil {
-			c.lock.Unlock()
-			klog.Errorln(err)
-			return
-		}
 	}
 	c.lock.Unlock()
 
@@ -97,24 +118,12 @@ func (c *Cache) updaterWorker(projects *sync.Map, projectId db.ProjectId, promCl
 			klog.Errorln("could not get query states:", err)
 			return
 		}
-		checkConfigs, err := c.db.GetCheckConfigs(projectId)
+		queries, err := c.getQueries(project)
 		if err != nil {
 			klog.Errorln("could not get check configs:", err)
 			return
 		}
 
-		queries := slices.Clone(constructor.QUERIES)
-		for appId := range checkConfigs {
-			availabilityCfg, _ := checkConfigs.GetAvailability(appId)
-			if availabilityCfg.Custom {
-				queries = append(queries, constructor.Q("", availabilityCfg.Total()), constructor.Q("", availabilityCfg.Failed()))
-			}
-			latencyCfg, _ := checkConfigs.GetLatency(appId, project.CalcApplicationCategory(appId))
-			if latencyCfg.Custom {
-				queries = append(queries, constructor.Q("", latencyCfg.Histogram(), "le"))
-			}
-		}
-
 		var recordingRules []constructor.Query
 		for q := range constructor.RecordingRules {
 			recordingRules = append(recordingRules, constructor.Q("", q))
@@ -126,7 +135,11 @@ func (c *Cache) updaterWorker(projects *sync.Map, projectId db.ProjectId, promCl
 			actualQueries[q.Query] = true
 			state := states[q.Query]
 			if state == nil {
-				state = &PrometheusQueryState{ProjectId: projectId, Query: q.Query, LastTs: now.Add(-BackFillInterval)}
+				lastTs := c.lastSavedTime(projectId, q.Query)
+				if lastTs.IsZero() {
+					lastTs = now.Add(-BackFillInterval)
+				}
+				state = &PrometheusQueryState{ProjectId: projectId, Query: q.Query, LastTs: lastTs}
 				if err := c.saveState(state); err != nil {
 					klog.Errorln("failed to create query state:", err)
 					return
@@ -240,6 +253,14 @@ func (c *Cache) download(to timeseries.Time, promClient *prom.Client, projectId
 }
 
 func (c *Cache) writeChunk(projectId db.ProjectId, queryHash string, from timeseries.Time, pointsCount int, step timeseries.Duration, finalized bool, metrics []*model.MetricValues) error {
+	return c.putChunk(projectId, queryHash, from, pointsCount, step, finalized, false, metrics)
+}
+
+func (c *Cache) writeRollupChunk(projectId db.ProjectId, queryHash string, from timeseries.Time, pointsCount int, step timeseries.Duration, metrics []*model.MetricValues) error {
+	return c.putChunk(projectId, queryHash, from, pointsCount, step, true, true, metrics)
+}
+
+func (c *Cache) putChunk(projectId db.ProjectId, queryHash string, from timeseries.Time, pointsCount int, step timeseries.Duration, finalized, rollup bool, metrics []*model.MetricValues) error {
 	if len(metrics) == 0 {
 		return nil
 	}
@@ -256,41 +277,32 @@ func (c *Cache) writeChunk(projectId db.ProjectId, queryHash string, from timese
 	}
 	c.lock.Unlock()
 
-	projectDir := path.Join(c.cfg.Path, string(projectId))
-	chunkFilePath := path.Join(projectDir, fmt.Sprintf(
+	key := storage.Key(string(projectId), fmt.Sprintf(
 		"%s-%s-%d-%d-%d.db",
 		projectId, queryHash, from, pointsCount, step))
-	dir, file := filepath.Split(chunkFilePath)
-	if dir == "" {
-		dir = "."
+	buf := &bytes.Buffer{}
+	write := chunk.Write
+	if rollup {
+		write = chunk.WriteRollup
 	}
-	f, err := os.CreateTemp(dir, file)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = f.Close()
-		_ = os.Remove(f.Name())
-	}()
-	if err = chunk.Write(f, from, pointsCount, step, finalized, metrics); err != nil {
+	if err := write(buf, from, pointsCount, step, finalized, metrics); err != nil {
 		return err
 	}
-	if err = f.Close(); err != nil {
+
+	if err := c.storage.Put(context.Background(), key, buf.Bytes()); err != nil {
 		return err
 	}
 
 	c.lock.Lock()
 	defer c.lock.Unlock()
-	if err = os.Rename(f.Name(), chunkFilePath); err != nil {
-		return err
-	}
-	qData.chunksOnDisk[chunkFilePath] = &chunk.Meta{
-		Path:        chunkFilePath,
+	qData.chunksOnDisk[key] = &chunk.Meta{
+		Path:        key,
 		From:        from,
 		PointsCount: uint32(pointsCount),
 		Step:        step,
 		Finalized:   finalized,
 		Created:     timeseries.Now(),
+		Rollup:      rollup,
 	}
 	return nil
 }
@@ -313,30 +325,16 @@ func (c *Cache) processRecordingRules(to timeseries.Time, project *db.Project, s
 	if len(intervals) == 0 {
 		return
 	}
-	cacheClient := c.GetCacheClient(project.Id)
-	pointsCount := int(chunk.Size / step)
 	for _, i := range intervals {
-		ctr := constructor.New(c.db, project, cacheClient, nil, constructor.OptionLoadInstanceToInstanceConnections, constructor.OptionDoNotLoadRawSLIs, constructor.OptionLoadContainerLogs)
-		world, err := ctr.LoadWorld(context.TODO(), i.chunkTs, i.toTs, step, nil)
-		if err != nil {
-			klog.Errorln("failed to load world:", err)
+		if err := c.writeRecordingRules(project, step, i); err != nil {
+			klog.Errorln(err)
 			return
 		}
-		chunkEnd := i.chunkTs.Add(timeseries.Duration(pointsCount-1) * step)
-		finalized := chunkEnd == i.toTs
-		for name, rule := range constructor.RecordingRules {
-			hash := queryHash(name)
-			mvs := rule(c.db, project, world)
-			err = c.writeChunk(project.Id, hash, i.chunkTs, pointsCount, step, finalized, mvs)
-			if err != nil {
-				klog.Errorln("failed to save chunk:", err)
-				return
-			}
+		for name := range constructor.RecordingRules {
 			state := states[name]
 			state.LastTs = i.toTs
 			state.LastError = ""
-			err = c.saveState(state)
-			if err != nil {
+			if err := c.saveState(state); err != nil {
 				klog.Errorln("failed to save state:", err)
 				return
 			}
@@ -344,6 +342,24 @@ func (c *Cache) processRecordingRules(to timeseries.Time, project *db.Project, s
 	}
 }
 
+func (c *Cache) writeRecordingRules(project *db.Project, step timeseries.Duration, i interval) error {
+	pointsCount := int(chunk.Size / step)
+	ctr := constructor.New(c.db, project, c.GetCacheClient(project.Id), nil, constructor.OptionLoadInstanceToInstanceConnections, constructor.OptionDoNotLoadRawSLIs, constructor.OptionLoadContainerLogs)
+	world, err := ctr.LoadWorld(context.TODO(), i.chunkTs, i.toTs, step, nil)
+	if err != nil {
+		return fmt.Errorf("failed to load world: %w", err)
+	}
+	chunkEnd := i.chunkTs.Add(timeseries.Duration(pointsCount-1) * step)
+	finalized := chunkEnd == i.toTs
+	for name, rule := range constructor.RecordingRules {
+		mvs := rule(c.db, project, world)
+		if err = c.writeChunk(project.Id, queryHash(name), i.chunkTs, pointsCount, step, finalized, mvs); err != nil {
+			return fmt.Errorf("failed to save chunk: %w", err)
+		}
+	}
+	return nil
+}
+
 type interval struct {
 	chunkTs, toTs timeseries.Time
 }
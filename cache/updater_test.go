@@ -5,8 +5,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCacheUpdater_calcIntervals(t *testing.T) {
@@ -77,3 +81,37 @@ func TestCacheUpdater_calcIntervals(t *testing.T) {
 		calc("2020-11-13T09:49:11", "2020-11-13T11:49:11"),
 	)
 }
+
+func TestCacheUpdater_lastSavedTime(t *testing.T) {
+	projectId := db.ProjectId("p1")
+	dir := t.TempDir()
+	state, err := db.NewSqlite(dir)
+	require.NoError(t, err)
+	require.NoError(t, state.Migrator().Migrate(&PrometheusQueryState{}))
+	c := &Cache{cfg: Config{Path: dir}, storage: storage.NewFS(dir), byProject: map[db.ProjectId]*projectData{projectId: newProjectData()}, state: state.DB()}
+	step := timeseries.Minute
+	ls := model.Labels{"pod": "catalog-1"}
+	write := func(query string, from timeseries.Time, finalized bool) {
+		mvs := []*model.MetricValues{{Labels: ls, LabelsHash: ls.Hash(), Values: timeseries.NewWithData(from, step, make([]float32, 10))}}
+		require.NoError(t, c.writeChunk(projectId, queryHash(query), from, 10, step, finalized, mvs))
+	}
+	hour := timeseries.Time(1700000000).Truncate(timeseries.Hour)
+	write("q1", hour, true)
+	write("q1", hour.Add(10*timeseries.Minute), true)
+	write("q2", hour, true)
+	write("q2", hour.Add(10*timeseries.Minute), false)
+
+	// the project is handed over to a replica that owned it before
+	require.NoError(t, c.saveState(&PrometheusQueryState{ProjectId: projectId, Query: "q1", LastTs: hour}))
+	require.NoError(t, c.saveState(&PrometheusQueryState{ProjectId: projectId, Query: "q2", LastTs: hour.Add(30 * timeseries.Minute)}))
+	c.byProject = map[db.ProjectId]*projectData{}
+	require.NoError(t, c.reloadIndex(projectId))
+	states, err := c.loadStates(projectId)
+	require.NoError(t, err)
+	assert.Equal(t, hour.Add(19*timeseries.Minute), states["q1"].LastTs)
+	assert.Equal(t, hour.Add(30*timeseries.Minute), states["q2"].LastTs, "states must never go back")
+
+	assert.Equal(t, hour.Add(19*timeseries.Minute), c.lastSavedTime(projectId, "q1"))
+	assert.Equal(t, hour.Add(9*timeseries.Minute), c.lastSavedTime(projectId, "q2"), "the partial chunk must be re-fetched")
+	assert.Equal(t, timeseries.Time(0), c.lastSavedTime(projectId, "q3"))
+}
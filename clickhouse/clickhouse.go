@@ -335,6 +335,13 @@ type DiskInfo struct {
 	Type       string `json:"type"`
 }
 
+func (d DiskInfo) UsagePercent() float64 {
+	if d.TotalSpace == 0 {
+		return 0
+	}
+	return float64(d.TotalSpace-d.FreeSpace) / float64(d.TotalSpace) * 100
+}
+
 type ServerDiskInfo struct {
 	Addr  string     `json:"addr"`
 	Disks []DiskInfo `json:"disks,omitempty"`
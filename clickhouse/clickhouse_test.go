@@ -75,3 +75,9 @@ func TestConvertIntervalToSeconds(t *testing.T) {
 	assert.Equal(t, uint64(0), convertIntervalToSeconds(10, "INVALID"))
 	assert.Equal(t, uint64(0), convertIntervalToSeconds(0, "DAY"))
 }
+
+func TestDiskInfoUsagePercent(t *testing.T) {
+	assert.Equal(t, float64(75), DiskInfo{FreeSpace: 25, TotalSpace: 100}.UsagePercent())
+	assert.Equal(t, float64(0), DiskInfo{FreeSpace: 100, TotalSpace: 100}.UsagePercent())
+	assert.Equal(t, float64(0), DiskInfo{}.UsagePercent())
+}
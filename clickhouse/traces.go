@@ -262,25 +262,107 @@ func (c *Client) getSpansHistogram(ctx context.Context, q SpanQuery, filters []s
 		return nil, err
 	}
 	defer rows.Close()
+	h := newSpansHistogram(from, to, step)
 	var t time.Time
 	var bucket float64
 	var total, failed uint64
-	byBucket := map[float64]*timeseries.TimeSeries{}
-	errors := map[timeseries.Time]uint64{}
 	for rows.Next() {
 		if err = rows.Scan(&t, &bucket, &total, &failed); err != nil {
 			return nil, err
 		}
-		if byBucket[bucket] == nil {
-			byBucket[bucket] = timeseries.New(from, int(to.Sub(from)/step), step)
+		h.add(t, bucket, total, failed)
+	}
+	return h.get(), nil
+}
+
+// GetServerSpansSLIs returns the latency histograms calculated from the server spans of the services within
+// the time range, optionally narrowed down to the routes matching the regular expression. As with the other
+// span histograms, the first bucket holds the rate of the failed requests, and the last one the total rate.
+func (c *Client) GetServerSpansSLIs(ctx context.Context, from, to timeseries.Time, step timeseries.Duration, services []string, route string) (map[string][]model.HistogramBucket, error) {
+	if len(services) == 0 {
+		return nil, nil
+	}
+	to = to.Add(step)
+	filters := []string{
+		"ServiceName IN (@services)",
+		"SpanKind = 'SPAN_KIND_SERVER'",
+		"Timestamp BETWEEN @from AND @to",
+	}
+	filterArgs := []any{
+		clickhouse.Named("services", services),
+		clickhouse.Named("step", int(step)),
+		clickhouse.Named("buckets", histogramBuckets[:len(histogramBuckets)-1]),
+		clickhouse.DateNamed("from", from.ToStandard(), clickhouse.NanoSeconds),
+		clickhouse.DateNamed("to", to.ToStandard(), clickhouse.NanoSeconds),
+	}
+	if route != "" {
+		filters = append(filters, "match(SpanAttributes['http.route'], @route)")
+		filterArgs = append(filterArgs, clickhouse.Named("route", route))
+	}
+
+	query := "SELECT ServiceName, toStartOfInterval(Timestamp, INTERVAL @step second), roundDown(Duration/1000000, @buckets), count(1), countIf(StatusCode = 'STATUS_CODE_ERROR')"
+	query += " FROM @@table_otel_traces@@"
+	query += " WHERE " + strings.Join(filters, " AND ")
+	query += " GROUP BY 1, 2, 3"
+
+	rows, err := c.Query(ctx, query, filterArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	byService := map[string]*spansHistogram{}
+	var service string
+	var t time.Time
+	var bucket float64
+	var total, failed uint64
+	for rows.Next() {
+		if err = rows.Scan(&service, &t, &bucket, &total, &failed); err != nil {
+			return nil, err
 		}
-		ts := timeseries.Time(t.Unix())
-		byBucket[bucket].Set(ts, float32(total)/float32(step))
-		errors[ts] += failed
+		h := byService[service]
+		if h == nil {
+			h = newSpansHistogram(from, to, step)
+			byService[service] = h
+		}
+		h.add(t, bucket, total, failed)
+	}
+	res := make(map[string][]model.HistogramBucket, len(byService))
+	for s, h := range byService {
+		res[s] = h.get()
 	}
+	return res, rows.Err()
+}
+
+type spansHistogram struct {
+	from, to timeseries.Time
+	step     timeseries.Duration
+	byBucket map[float64]*timeseries.TimeSeries
+	errors   map[timeseries.Time]uint64
+}
 
+func newSpansHistogram(from, to timeseries.Time, step timeseries.Duration) *spansHistogram {
+	return &spansHistogram{
+		from:     from,
+		to:       to,
+		step:     step,
+		byBucket: map[float64]*timeseries.TimeSeries{},
+		errors:   map[timeseries.Time]uint64{},
+	}
+}
+
+func (h *spansHistogram) add(t time.Time, bucket float64, total, failed uint64) {
+	if h.byBucket[bucket] == nil {
+		h.byBucket[bucket] = timeseries.New(h.from, int(h.to.Sub(h.from)/h.step), h.step)
+	}
+	ts := timeseries.Time(t.Unix())
+	h.byBucket[bucket].Set(ts, float32(total)/float32(h.step))
+	h.errors[ts] += failed
+}
+
+func (h *spansHistogram) get() []model.HistogramBucket {
+	from, to, step, byBucket, errors := h.from, h.to, h.step, h.byBucket, h.errors
 	if len(byBucket) == 0 {
-		return nil, nil
+		return nil
 	}
 
 	res := []model.HistogramBucket{
@@ -310,7 +392,7 @@ func (c *Client) getSpansHistogram(ctx context.Context, q SpanQuery, filters []s
 			TimeSeries: ts,
 		})
 	}
-	return res, nil
+	return res
 }
 
 func (c *Client) getSpansSummary(ctx context.Context, q SpanQuery, filters []string, filterArgs []any) (*model.TraceSpanSummary, error) {
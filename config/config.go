@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"os"
 
+	"github.com/coroot/coroot/cache/storage"
 	"github.com/coroot/coroot/cloud"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/prom"
@@ -45,6 +46,10 @@ type Config struct {
 
 	ClickHouseSpaceManager ClickHouseSpaceManager `yaml:"clickhouse_space_manager"`
 
+	Incidents Incidents `yaml:"incidents"`
+
+	Sharding Sharding `yaml:"sharding"`
+
 	CorootCloud *cloud.Settings `yaml:"corootCloud"`
 	Keep        *Keep           `yaml:"keep"`
 
@@ -84,9 +89,52 @@ type ClickHouseSpaceManager struct {
 	MinPartitions         int  `yaml:"min_partitions"`
 }
 
+// Incidents controls flapping suppression: an SLO violation must last MinDuration before an incident is opened,
+// and an incident resolved less than ReopenCooldown ago is reopened instead of creating a new one.
+type Incidents struct {
+	MinDuration    timeseries.Duration `yaml:"min_duration"`
+	ReopenCooldown timeseries.Duration `yaml:"reopen_cooldown"`
+}
+
+// Sharding distributes projects across replicas sharing the same Postgres database.
+// AdvertiseUrl is the address other replicas use to proxy API requests for the projects owned by this replica.
+type Sharding struct {
+	Enabled      bool   `yaml:"enabled"`
+	ReplicaId    string `yaml:"replica_id"`
+	AdvertiseUrl string `yaml:"advertise_url"`
+}
+
+func (s *Sharding) Validate(postgres *Postgres, cache Cache) error {
+	if !s.Enabled {
+		return nil
+	}
+	if postgres == nil {
+		return fmt.Errorf("postgres is required")
+	}
+	// a replica taking over a project must see the chunks downloaded by the previous owner
+	if cache.S3 == nil {
+		return fmt.Errorf("cache.s3 is required, the replicas must share the cache storage")
+	}
+	if s.ReplicaId == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return fmt.Errorf("replica_id is required: %w", err)
+		}
+		s.ReplicaId = hostname
+	}
+	if s.AdvertiseUrl == "" {
+		return fmt.Errorf("advertise_url is required")
+	}
+	return validateUrl(s.AdvertiseUrl)
+}
+
 type Cache struct {
 	TTL        timeseries.Duration `yaml:"ttl"`
 	GCInterval timeseries.Duration `yaml:"gc_interval"`
+
+	// S3 moves the cache chunks to an S3-compatible bucket shared by replicas.
+	// The local data directory is used as a read-through tier.
+	S3 *storage.S3Config `yaml:"s3"`
 }
 
 type Traces struct {
@@ -294,6 +342,14 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if err = cfg.Cache.S3.Validate(); err != nil {
+		return fmt.Errorf("invalid cache.s3 settings: %w", err)
+	}
+
+	if err = cfg.Sharding.Validate(cfg.Postgres, cfg.Cache); err != nil {
+		return fmt.Errorf("invalid sharding settings: %w", err)
+	}
+
 	if err = cfg.Keep.Validate(); err != nil {
 		return fmt.Errorf("invalid keep settings: %w", err)
 	}
@@ -326,6 +382,12 @@ func (cfg *Config) Validate() error {
 	if cfg.ClickHouseSpaceManager.UsageThresholdPercent < 0 || cfg.ClickHouseSpaceManager.UsageThresholdPercent > 100 {
 		return fmt.Errorf("invalid usage_threshold_percent: %d", cfg.ClickHouseSpaceManager.UsageThresholdPercent)
 	}
+	if cfg.Incidents.MinDuration < 0 {
+		return fmt.Errorf("invalid incidents.min_duration: %s", cfg.Incidents.MinDuration)
+	}
+	if cfg.Incidents.ReopenCooldown < 0 {
+		return fmt.Errorf("invalid incidents.reopen_cooldown: %s", cfg.Incidents.ReopenCooldown)
+	}
 
 	return nil
 }
@@ -30,6 +30,11 @@ var (
 	clickHouseSpaceManagerDisabled              = kingpin.Flag("disable-clickhouse-space-manager", "If enabled, Coroot will manage ClickHouse disk space by removing old partitions").Envar("CLICKHOUSE_SPACE_MANAGER_DISABLED").Bool()
 	clickHouseSpaceManagerUsageThresholdPercent = kingpin.Flag("clickhouse-space-manager-usage-threshold", "Disk usage percentage threshold for triggering partition cleanup in ClickHouse").Envar("CLICKHOUSE_SPACE_MANAGER_USAGE_THRESHOLD").Int()
 	clickHouseSpaceManagerMinPartitions         = kingpin.Flag("clickhouse-space-manager-min-partitions", "Minimum number of partitions to keep when cleaning up ClickHouse disk space").Envar("CLICKHOUSE_SPACE_MANAGER_MIN_PARTITIONS").Int()
+	incidentMinDuration                         = timeseries.DurationFlag(kingpin.Flag("incident-min-duration", "Minimum duration of an SLO violation before an incident is opened (e.g. 5m)").Envar("INCIDENT_MIN_DURATION"))
+	shardingEnabled                             = kingpin.Flag("sharding-enabled", "Distribute projects across replicas sharing the same Postgres database").Envar("SHARDING_ENABLED").Bool()
+	shardingReplicaId                           = kingpin.Flag("sharding-replica-id", "Unique id of the replica (default: hostname)").Envar("SHARDING_REPLICA_ID").String()
+	shardingAdvertiseUrl                        = kingpin.Flag("sharding-advertise-url", "URL other replicas use to reach this one, e.g. http://10.0.0.5:8080").Envar("SHARDING_ADVERTISE_URL").String()
+	incidentReopenCooldown                      = timeseries.DurationFlag(kingpin.Flag("incident-reopen-cooldown", "Period after resolution during which a recurring violation silently reopens the same incident; resolve notifications are held back for this period (e.g. 30m)").Envar("INCIDENT_REOPEN_COOLDOWN"))
 
 	globalClickhouseAddress         = kingpin.Flag("global-clickhouse-address", "").Envar("GLOBAL_CLICKHOUSE_ADDRESS").String()
 	globalClickhouseUser            = kingpin.Flag("global-clickhouse-user", "").Envar("GLOBAL_CLICKHOUSE_USER").String()
@@ -123,6 +128,21 @@ func (cfg *Config) ApplyFlags() {
 	if *clickHouseSpaceManagerMinPartitions > 0 {
 		cfg.ClickHouseSpaceManager.MinPartitions = *clickHouseSpaceManagerMinPartitions
 	}
+	if *incidentMinDuration > 0 {
+		cfg.Incidents.MinDuration = *incidentMinDuration
+	}
+	if *incidentReopenCooldown > 0 {
+		cfg.Incidents.ReopenCooldown = *incidentReopenCooldown
+	}
+	if *shardingEnabled {
+		cfg.Sharding.Enabled = true
+	}
+	if *shardingReplicaId != "" {
+		cfg.Sharding.ReplicaId = *shardingReplicaId
+	}
+	if *shardingAdvertiseUrl != "" {
+		cfg.Sharding.AdvertiseUrl = *shardingAdvertiseUrl
+	}
 
 	keep := cfg.GlobalClickhouse != nil || *globalClickhouseAddress != ""
 	if cfg.GlobalClickhouse == nil {
@@ -73,6 +73,12 @@ func (c *ApplicationCategory) Validate() error {
 	if c.Name == "" {
 		return fmt.Errorf("name is required")
 	}
+	if err := c.NotificationSettings.Incidents.Validate(); err != nil {
+		return fmt.Errorf("invalid incident notification settings: %w", err)
+	}
+	if err := c.NotificationSettings.SLOReports.Validate(); err != nil {
+		return fmt.Errorf("invalid SLO report notification settings: %w", err)
+	}
 	return nil
 }
 
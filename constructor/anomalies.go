@@ -0,0 +1,69 @@
+package constructor
+
+import (
+	"context"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+)
+
+// anomalySeasons are the offsets of the previous time windows used to learn the seasonal anomaly baselines.
+var anomalySeasons = []timeseries.Duration{
+	timeseries.Day,
+	2 * timeseries.Day,
+	3 * timeseries.Day,
+	7 * timeseries.Day,
+}
+
+// anomalySignalQueries are the queries the anomaly signals of the applications are calculated from (see model.Application.AnomalySignals):
+// the metadata needed to group the containers into applications, and the resource usage and traffic of the containers.
+// The SLIs come from the recording rules and the custom SLI queries, which are loaded anyway.
+var anomalySignalQueries = utils.NewStringSet(
+	"up", "node_agent_info", "node_info", "kube_node_info",
+	"fargate_node_machine_cpu_cores", "fargate_node_machine_memory_bytes",
+	"kube_deployment_spec_replicas", "kube_daemonset_status_desired_number_scheduled", "kube_statefulset_replicas",
+	"kube_deployment_annotations", "kube_statefulset_annotations", "kube_daemonset_annotations", "kube_cronjob_annotations", "kube_cronjob_info",
+	"kube_pod_info", "kube_pod_annotations", "kube_pod_labels",
+	"container_info", "container_cpu_usage", "container_memory_rss", "fargate_container_cpu_usage_seconds", "fargate_container_memory_rss",
+	"container_net_tcp_listen_info", "container_net_tcp_bytes_sent", "container_net_tcp_bytes_received",
+)
+
+// anomalyHistoryWindow limits the history to the end of the time window, since anomalies are detected only in the most recent points.
+const anomalyHistoryWindow = timeseries.Hour
+
+// loadAnomalyHistory loads the anomaly signals of the applications in the previous seasons.
+// Only the queries the signals depend on are loaded for the previous seasons, and the traces are not loaded at all.
+func (c *Constructor) loadAnomalyHistory(ctx context.Context, w *model.World) {
+	from := w.Ctx.From
+	if t := w.Ctx.To.Add(-anomalyHistoryWindow); t.After(from) {
+		from = t
+	}
+	options := []Option{optionLoadAnomalySignalsOnly}
+	if c.options[OptionLoadInstanceToInstanceConnections] {
+		options = append(options, OptionLoadInstanceToInstanceConnections)
+	}
+	hc := New(c.db, c.project, c.cache, c.pricing, options...)
+	for _, season := range anomalySeasons {
+		hw, err := hc.LoadWorld(ctx, from.Add(-season), w.Ctx.To.Add(-season), w.Ctx.Step, nil)
+		if err != nil {
+			klog.Warningln("failed to load the anomaly history:", err)
+			continue
+		}
+		for id, app := range hw.Applications {
+			if w.GetApplication(id) == nil {
+				continue
+			}
+			for s, ts := range app.AnomalySignals() {
+				if w.AnomalyHistory == nil {
+					w.AnomalyHistory = map[model.ApplicationId]map[model.AnomalySignal][]*timeseries.TimeSeries{}
+				}
+				if w.AnomalyHistory[id] == nil {
+					w.AnomalyHistory[id] = map[model.AnomalySignal][]*timeseries.TimeSeries{}
+				}
+				w.AnomalyHistory[id][s] = append(w.AnomalyHistory[id][s], ts.Shift(season))
+			}
+		}
+	}
+}
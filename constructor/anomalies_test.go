@@ -0,0 +1,50 @@
+package constructor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingCache struct {
+	queries []string
+	lock    sync.Mutex
+}
+
+func (c *recordingCache) QueryRange(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration, fillFunc timeseries.FillFunc) ([]*model.MetricValues, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.queries = append(c.queries, query)
+	return nil, nil
+}
+
+func (c *recordingCache) GetStep(from, to timeseries.Time) (timeseries.Duration, error) {
+	return timeseries.Minute, nil
+}
+
+func TestAnomalySignalQueries(t *testing.T) {
+	names := map[string]bool{}
+	var expected []string
+	for _, q := range QUERIES {
+		names[q.Name] = true
+		if anomalySignalQueries.Has(q.Name) && !q.InstanceToInstance {
+			expected = append(expected, q.Query)
+		}
+	}
+	for _, name := range anomalySignalQueries.Items() {
+		assert.True(t, names[name], "unknown query: %s", name)
+	}
+	expected = append(expected, qRecordingRuleApplicationL7Requests, qRecordingRuleApplicationL7Histogram)
+
+	cache := &recordingCache{}
+	c := New(nil, &db.Project{}, cache, nil, optionLoadAnomalySignalsOnly)
+	_, err := c.queryCache(context.Background(), 0, timeseries.Time(0).Add(timeseries.Hour), timeseries.Minute, timeseries.Minute, nil, nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, expected, cache.queries)
+}
@@ -0,0 +1,103 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func loadArgoCDResources(w *model.World, metrics map[string][]*model.MetricValues) {
+	argocd := model.NewArgoCD()
+
+	for _, m := range metrics["argocd_app_info"] {
+		id := model.ApplicationId{
+			Namespace: m.Labels["namespace"],
+			Kind:      model.ApplicationKindArgoCDApplication,
+			Name:      m.Labels["name"],
+		}
+		app := argocd.Applications[id]
+		if app == nil {
+			app = model.NewArgoCDApplication()
+			argocd.Applications[id] = app
+		}
+		app.Project.Update(m.Values, m.Labels["project"])
+		app.Repo.Update(m.Values, m.Labels["repo"])
+		app.DestServer.Update(m.Values, m.Labels["dest_server"])
+		app.DestNamespace.Update(m.Values, m.Labels["dest_namespace"])
+		app.SyncStatus.Update(m.Values, m.Labels["sync_status"])
+		app.HealthStatus.Update(m.Values, m.Labels["health_status"])
+		// argocd-metrics doesn't expose the revision, but it can be added to app-info (e.g., using kube-state-metrics)
+		app.UpdateRevision(m.Values, m.Labels["revision"])
+	}
+
+	for _, m := range metrics["argocd_app_syncs"] {
+		id := model.ApplicationId{
+			Namespace: m.Labels["namespace"],
+			Kind:      model.ApplicationKindArgoCDApplication,
+			Name:      m.Labels["name"],
+		}
+		app := argocd.Applications[id]
+		if app == nil {
+			continue
+		}
+		// the raw counter is used since a rate stays positive for the whole $RANGE window after a sync
+		prev := timeseries.NaN
+		iter := m.Values.Iter()
+		for iter.Next() {
+			t, v := iter.Value()
+			if timeseries.IsNaN(v) {
+				continue
+			}
+			// a decrease means the counter has been reset by a restart of the application controller
+			if !timeseries.IsNaN(prev) && (v > prev || v < prev && v > 0) {
+				app.Syncs = append(app.Syncs, &model.ArgoCDSync{Time: t, Phase: m.Labels["phase"], Revision: app.RevisionAt(t)})
+			}
+			prev = v
+		}
+	}
+
+	for _, m := range metrics["argocd_appset_info"] {
+		id := model.ApplicationId{
+			Namespace: m.Labels["namespace"],
+			Kind:      model.ApplicationKindArgoCDApplicationSet,
+			Name:      m.Labels["name"],
+		}
+		appSet := argocd.ApplicationSets[id]
+		if appSet == nil {
+			appSet = &model.ArgoCDApplicationSet{}
+			argocd.ApplicationSets[id] = appSet
+		}
+		appSet.Status.Update(m.Values, m.Labels["resource_update_status"])
+	}
+	for _, m := range metrics["argocd_appset_owned_applications"] {
+		id := model.ApplicationId{
+			Namespace: m.Labels["namespace"],
+			Kind:      model.ApplicationKindArgoCDApplicationSet,
+			Name:      m.Labels["name"],
+		}
+		if appSet := argocd.ApplicationSets[id]; appSet != nil {
+			appSet.OwnedApplications = merge(appSet.OwnedApplications, m.Values, timeseries.Any)
+		}
+	}
+
+	if len(argocd.Applications) == 0 && len(argocd.ApplicationSets) == 0 {
+		return
+	}
+
+	// Argo CD tracks the resources of an Application using the app.kubernetes.io/instance label by default
+	for id, app := range argocd.Applications {
+		app.SortSyncs()
+		destNamespace := app.DestNamespace.Value()
+		for _, a := range w.Applications {
+			for _, i := range a.Instances {
+				if i.Pod == nil || i.Pod.AppInstance != id.Name {
+					continue
+				}
+				if destNamespace != "" && a.Id.Namespace != destNamespace {
+					continue
+				}
+				app.Workloads[a.Id] = true
+			}
+		}
+	}
+	w.ArgoCD = argocd
+}
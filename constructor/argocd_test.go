@@ -0,0 +1,58 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArgoCD(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(6*timeseries.Minute), timeseries.Minute, timeseries.Minute)
+	api := w.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "api"), false)
+	api.GetOrCreateInstance("api-1", nil).Pod = &model.Pod{AppInstance: "shop"}
+	other := w.GetOrCreateApplication(model.NewApplicationId("staging", model.ApplicationKindDeployment, "api"), false)
+	other.GetOrCreateInstance("api-1", nil).Pod = &model.Pod{AppInstance: "shop"}
+
+	nan := timeseries.NaN
+	info := func(revision, syncStatus string) model.Labels {
+		return model.Labels{"name": "shop", "namespace": "argocd", "project": "default", "dest_namespace": "shop",
+			"sync_status": syncStatus, "health_status": "Healthy", "revision": revision}
+	}
+	metrics := map[string][]*model.MetricValues{
+		"argocd_app_info": {
+			{Labels: info("aaaaaaa", "Synced"), Values: values(1, 1, nan, nan, nan, nan)},
+			{Labels: info("bbbbbbb", "Synced"), Values: values(nan, nan, 1, 1, 1, 1)},
+		},
+		"argocd_app_syncs": {
+			// the counter was reset by a restart of the application controller during the scrape gap, then the app was synced again
+			{Labels: model.Labels{"name": "shop", "namespace": "argocd", "phase": "Succeeded"}, Values: values(12, 12, 13, nan, 1, 1)},
+			{Labels: model.Labels{"name": "shop", "namespace": "argocd", "phase": "Failed"}, Values: values(2, nan, 2, 2, 2, 2)},
+			{Labels: model.Labels{"name": "unknown", "namespace": "argocd", "phase": "Succeeded"}, Values: values(3, 4, 4, 4, 4, 4)},
+		},
+		"argocd_appset_info": {
+			{Labels: model.Labels{"name": "shops", "namespace": "argocd", "resource_update_status": "True"}, Values: values(1, 1, 1, 1, 1, 1)},
+		},
+	}
+	loadArgoCDResources(w, metrics)
+
+	require.NotNil(t, w.ArgoCD)
+	app := w.ArgoCD.Applications[model.ApplicationId{Namespace: "argocd", Kind: model.ApplicationKindArgoCDApplication, Name: "shop"}]
+	require.NotNil(t, app)
+	assert.Equal(t, "Synced", app.SyncStatus.Value())
+	assert.Equal(t, "bbbbbbb", app.Revision.Value())
+	require.Len(t, app.Syncs, 2)
+	assert.Equal(t, timeseries.Time(120), app.Syncs[0].Time)
+	assert.Equal(t, "bbbbbbb", app.Syncs[0].Revision)
+	assert.Equal(t, timeseries.Time(240), app.Syncs[1].Time)
+	assert.Equal(t, map[model.ApplicationId]bool{api.Id: true}, app.Workloads)
+	assert.Len(t, w.ArgoCD.ApplicationSets, 1)
+
+	calcAppEvents(w)
+	require.Len(t, api.Events, 2)
+	assert.Equal(t, model.ApplicationEventTypeArgoCDSync, api.Events[0].Type)
+	assert.Equal(t, "Argo CD sync to revision bbbbbbb", api.Events[0].Details)
+	assert.Empty(t, other.Events)
+}
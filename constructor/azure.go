@@ -0,0 +1,82 @@
+package constructor
+
+import (
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+// loadAzureDatabases builds Azure Database for PostgreSQL and MySQL servers
+// from the metrics exported by azure-metrics-exporter.
+func loadAzureDatabases(w *model.World, metrics map[string][]*model.MetricValues) {
+	instances := map[string]*model.Instance{}
+	for _, q := range QUERIES {
+		if !strings.HasPrefix(q.Name, "azure_database_") {
+			continue
+		}
+		for _, m := range metrics[q.Name] {
+			// /subscriptions/<id>/resourceGroups/<group>/providers/Microsoft.DBforPostgreSQL/flexibleServers/<name>
+			id := strings.ToLower(m.Labels["resourceID"])
+			parts := strings.Split(id, "/")
+			if len(parts) < 9 || parts[5] != "providers" {
+				continue
+			}
+			var engine string
+			switch parts[6] {
+			case "microsoft.dbforpostgresql":
+				engine = "postgres"
+			case "microsoft.dbformysql":
+				engine = "mysql"
+			default:
+				continue
+			}
+			name := parts[len(parts)-1]
+			instance := instances[id]
+			if instance == nil {
+				appId := model.NewApplicationId("", model.ApplicationKindAzureDatabase, name)
+				instance = w.GetOrCreateApplication(appId, false).GetOrCreateInstance(name, nil)
+				instance.AzureDatabase = &model.AzureDatabase{}
+				instance.Node = managedDatabaseNode(w, instance, "azure:"+name)
+				managedDatabaseVolume(instance)
+				instances[id] = instance
+			}
+			instance.AzureDatabase.Engine.Update(m.Values, engine)
+			instance.AzureDatabase.LifeSpan = merge(instance.AzureDatabase.LifeSpan, presence(m.Values), timeseries.Any)
+			instance.Node.Name.Update(m.Values, "azure:"+name)
+			instance.Node.CloudProvider.Update(m.Values, model.CloudProviderAzure)
+
+			node := instance.Node
+			volume := instance.Volumes[0]
+			pg, my := managedDatabaseEngine(instance, engine)
+			switch q.Name {
+			case "azure_database_alive":
+				if pg != nil {
+					pg.Up = merge(pg.Up, m.Values, timeseries.Any)
+				}
+			case "azure_database_cpu_usage_percent":
+				node.CpuUsagePercent = merge(node.CpuUsagePercent, m.Values, timeseries.Any)
+				// Azure Database for MySQL doesn't report the server availability
+				if my != nil {
+					my.Up = merge(my.Up, presence(m.Values), timeseries.Any)
+				}
+			case "azure_database_storage_limit_bytes":
+				volume.Device.Update(m.Values, managedDatabaseDevice)
+				volume.CapacityBytes = merge(volume.CapacityBytes, m.Values, timeseries.Any)
+			case "azure_database_storage_used_bytes":
+				volume.Device.Update(m.Values, managedDatabaseDevice)
+				volume.UsedBytes = merge(volume.UsedBytes, m.Values, timeseries.Any)
+			case "azure_database_connections":
+				if pg != nil {
+					key := model.PgConnectionKey{State: "total"}
+					pg.Connections[key] = merge(pg.Connections[key], m.Values, timeseries.Any)
+				}
+				if my != nil {
+					my.ConnectionsCurrent = merge(my.ConnectionsCurrent, m.Values, timeseries.Any)
+				}
+			case "azure_database_replication_lag_seconds":
+				managedDatabaseReplicationLag(instance, pg, my, m.Values)
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func cassandra(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Cassandra == nil {
+		instance.Cassandra = model.NewCassandra()
+	}
+	c := instance.Cassandra
+	switch queryName {
+	case "cassandra_read_latency":
+		keyspace := m.Labels["keyspace"]
+		c.ReadLatency[keyspace] = merge(c.ReadLatency[keyspace], m.Values, timeseries.Any)
+	case "cassandra_write_latency":
+		keyspace := m.Labels["keyspace"]
+		c.WriteLatency[keyspace] = merge(c.WriteLatency[keyspace], m.Values, timeseries.Any)
+	case "cassandra_pending_compactions":
+		c.PendingCompactions = merge(c.PendingCompactions, m.Values, timeseries.Any)
+		c.Up = merge(c.Up, presence(m.Values), timeseries.Any)
+	case "cassandra_dropped_messages":
+		messageType := m.Labels["message_type"]
+		c.DroppedMessages[messageType] = merge(c.DroppedMessages[messageType], m.Values, timeseries.NanSum)
+	case "cassandra_hints":
+		c.Hints = merge(c.Hints, m.Values, timeseries.NanSum)
+	case "cassandra_endpoint_active":
+		endpoint := m.Labels["endpoint"]
+		c.Endpoints[endpoint] = merge(c.Endpoints[endpoint], m.Values, timeseries.Any)
+	}
+}
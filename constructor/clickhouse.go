@@ -0,0 +1,46 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func clickhouse(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.ClickHouse == nil {
+		instance.ClickHouse = model.NewClickHouse()
+	}
+	ch := instance.ClickHouse
+	switch queryName {
+	case "clickhouse_queries_running":
+		ch.Up = merge(ch.Up, presence(m.Values), timeseries.Any)
+	case "clickhouse_max_parts_per_partition":
+		ch.MaxPartsPerPartition = merge(ch.MaxPartsPerPartition, m.Values, timeseries.Any)
+	case "clickhouse_merges_running":
+		ch.MergesRunning = merge(ch.MergesRunning, m.Values, timeseries.Any)
+	case "clickhouse_merges_pool_size":
+		ch.MergesPoolSize = merge(ch.MergesPoolSize, m.Values, timeseries.Any)
+	case "clickhouse_replication_queue_size":
+		ch.ReplicationQueueSize = merge(ch.ReplicationQueueSize, m.Values, timeseries.Any)
+	case "clickhouse_replication_delay":
+		ch.ReplicationDelay = merge(ch.ReplicationDelay, m.Values, timeseries.Any)
+	case "clickhouse_readonly_replicas":
+		ch.ReadonlyReplicas = merge(ch.ReadonlyReplicas, m.Values, timeseries.Any)
+	case "clickhouse_keeper_errors":
+		ch.KeeperErrors = merge(ch.KeeperErrors, m.Values, timeseries.Any)
+	case "clickhouse_queries":
+		ch.Queries = merge(ch.Queries, m.Values, timeseries.Any)
+	case "clickhouse_failed_queries":
+		ch.FailedQueries = merge(ch.FailedQueries, m.Values, timeseries.Any)
+	case "clickhouse_memory_limit_exceeded":
+		ch.MemoryLimitExceeded = merge(ch.MemoryLimitExceeded, m.Values, timeseries.Any)
+	case "clickhouse_disk_available":
+		d := ch.GetOrCreateDisk(m.Labels["disk"])
+		d.Available = merge(d.Available, m.Values, timeseries.Any)
+	case "clickhouse_disk_total":
+		d := ch.GetOrCreateDisk(m.Labels["disk"])
+		d.Total = merge(d.Total, m.Values, timeseries.Any)
+	}
+}
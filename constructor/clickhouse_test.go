@@ -0,0 +1,45 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickHouse(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(timeseries.Hour), timeseries.Minute, timeseries.Minute)
+	server := w.GetOrCreateApplication(model.NewApplicationId("db", model.ApplicationKindStatefulSet, "clickhouse"), false).
+		GetOrCreateInstance("clickhouse-0", nil)
+	server.TcpListens[model.Listen{IP: "10.0.0.1", Port: "9363"}] = true
+
+	ls := func(disk string) model.Labels {
+		return model.Labels{"instance": "10.0.0.1:9363", "disk": disk}
+	}
+	metrics := map[string][]*model.MetricValues{
+		"clickhouse_queries_running":  {{Labels: ls(""), Values: values(1, 0)}},
+		"clickhouse_merges_running":   {{Labels: ls(""), Values: values(4, 15)}},
+		"clickhouse_merges_pool_size": {{Labels: ls(""), Values: values(16, 16)}},
+		"clickhouse_queries":          {{Labels: ls(""), Values: values(10, 20)}},
+		"clickhouse_failed_queries":   {{Labels: ls(""), Values: values(0, 2)}},
+		"clickhouse_disk_available": {
+			{Labels: ls("default"), Values: values(50, 10)},
+			{Labels: ls("s3"), Values: values(100, 100)},
+		},
+		"clickhouse_disk_total": {
+			{Labels: ls("default"), Values: values(100, 100)},
+		},
+	}
+	enrichInstances(w, metrics, nil, nil)
+
+	ch := server.ClickHouse
+	require.NotNil(t, ch)
+	assert.True(t, ch.IsUp())
+	assert.Equal(t, float32(93.75), ch.MergesPoolUsage().Last())
+	assert.Equal(t, float32(10), ch.FailedQueriesPercent().Last())
+	require.Len(t, ch.Disks, 2)
+	assert.Equal(t, float32(90), ch.Disks["default"].Usage().Last())
+	assert.True(t, ch.Disks["s3"].Usage().IsEmpty())
+}
@@ -22,6 +22,9 @@ const (
 	OptionLoadInstanceToInstanceConnections Option = iota
 	OptionDoNotLoadRawSLIs
 	OptionLoadContainerLogs
+	OptionLoadAnomalyHistory
+
+	optionLoadAnomalySignalsOnly
 )
 
 type DB interface {
@@ -42,6 +45,7 @@ type Constructor struct {
 	cache   Cache
 	pricing *pricing.Manager
 	options map[Option]bool
+	traces  Traces
 }
 
 func New(db DB, project *db.Project, cache Cache, pricing *pricing.Manager, options ...Option) *Constructor {
@@ -98,12 +102,17 @@ func (c *Constructor) LoadWorld(ctx context.Context, from, to timeseries.Time, s
 	prof.stage("load_fqdn", func() { loadFQDNs(metrics, ip2fqdn, fqdn2ip) })
 	prof.stage("load_fargate_nodes", func() { c.loadFargateNodes(metrics, nodes) })
 	prof.stage("load_k8s_metadata", func() { loadKubernetesMetadata(w, metrics, servicesByClusterIP) })
+	prof.stage("load_k8s_jobs", func() { loadKubernetesJobs(w, metrics) })
 	prof.stage("load_flux_resources", func() { loadFluxResources(w, metrics) })
+	prof.stage("load_argocd_resources", func() { loadArgoCDResources(w, metrics) })
 	prof.stage("load_aws_status", func() { loadAWSStatus(w, metrics) })
 	prof.stage("load_rds_metadata", func() { loadRdsMetadata(w, metrics, pjs, rdsInstancesById) })
 	prof.stage("load_elasticache_metadata", func() { loadElasticacheMetadata(w, metrics, pjs, ecInstancesById) })
 	prof.stage("load_rds", func() { c.loadRds(w, metrics, pjs, rdsInstancesById) })
 	prof.stage("load_elasticache", func() { c.loadElasticache(w, metrics, pjs, ecInstancesById) })
+	prof.stage("load_cloudsql", func() { loadCloudSQL(w, metrics) })
+	prof.stage("load_memorystore", func() { loadMemorystore(w, metrics) })
+	prof.stage("load_azure_databases", func() { loadAzureDatabases(w, metrics) })
 	prof.stage("load_fargate_containers", func() { loadFargateContainers(w, metrics, pjs) })
 	prof.stage("load_containers", func() { c.loadContainers(w, metrics, pjs, nodes, containers, servicesByClusterIP, ip2fqdn) })
 	prof.stage("load_app_to_app_connections", func() { c.loadAppToAppConnections(w, metrics, fqdn2ip) })
@@ -113,16 +122,24 @@ func (c *Constructor) LoadWorld(ctx context.Context, from, to timeseries.Time, s
 	prof.stage("load_python", func() { c.loadPython(metrics, containers) })
 	prof.stage("load_nodejs", func() { c.loadNodejs(metrics, containers) })
 	prof.stage("enrich_instances", func() { enrichInstances(w, metrics, rdsInstancesById, ecInstancesById) })
+	prof.stage("load_k8s_control_plane", func() { loadKubernetesControlPlane(w, metrics) })
 	prof.stage("calc_app_categories", func() { c.calcApplicationCategories(w) })
 	prof.stage("group_custom_applications", func() { c.groupCustomApplications(w) })
 	prof.stage("join_db_cluster_components", func() { c.joinDBClusterComponents(w) })
+	prof.stage("link_kafka_consumers", func() { c.linkKafkaConsumers(w) })
 	prof.stage("load_app_settings", func() { c.loadApplicationSettings(w) })
+	var otelServices []string
+	prof.stage("load_otel_services", func() { otelServices = c.loadOTelServices(ctx, w) })
 	prof.stage("load_app_sli", func() { c.loadSLIs(w, metrics) })
+	prof.stage("load_span_sli", func() { c.loadSpanSLIs(ctx, w, otelServices) })
 	prof.stage("load_container_logs", func() { c.loadContainerLogs(metrics, containers, pjs) })
 	prof.stage("load_app_logs", func() { c.loadApplicationLogs(w, metrics) })
 	prof.stage("load_app_deployments", func() { c.loadApplicationDeployments(w) })
 	prof.stage("load_app_incidents", func() { c.loadApplicationIncidents(w) })
 	prof.stage("calc_app_events", func() { calcAppEvents(w) })
+	if c.options[OptionLoadAnomalyHistory] {
+		prof.stage("load_anomaly_history", func() { c.loadAnomalyHistory(ctx, w) })
+	}
 
 	klog.Infof("%s: got %d nodes, %d apps in %s", c.project.Id, len(w.Nodes), len(w.Applications), time.Since(start).Truncate(time.Millisecond))
 	return w, nil
@@ -172,10 +189,14 @@ func (c *Constructor) queryCache(ctx context.Context, from, to timeseries.Time,
 		}
 	}
 
+	signalsOnly := c.options[optionLoadAnomalySignalsOnly]
 	for _, q := range QUERIES {
 		if !c.options[OptionLoadInstanceToInstanceConnections] && q.InstanceToInstance {
 			continue
 		}
+		if signalsOnly && !anomalySignalQueries.Has(q.Name) {
+			continue
+		}
 		if !c.options[OptionLoadContainerLogs] && q.Name == "container_log_messages" {
 			queries[qRecordingRuleApplicationLogMessages] = cacheQuery{
 				query:     qRecordingRuleApplicationLogMessages,
@@ -188,7 +209,7 @@ func (c *Constructor) queryCache(ctx context.Context, from, to timeseries.Time,
 			continue
 		}
 		addQuery(q.Name, q.Name, q.Query, false)
-		if q.Name == "container_memory_rss" || q.Name == "fargate_container_memory_rss" {
+		if (q.Name == "container_memory_rss" || q.Name == "fargate_container_memory_rss") && !signalsOnly {
 			name := q.Name + "_for_trend"
 			queries[name] = cacheQuery{
 				query:     q.Query,
@@ -200,8 +221,10 @@ func (c *Constructor) queryCache(ctx context.Context, from, to timeseries.Time,
 		}
 	}
 	if !c.options[OptionLoadInstanceToInstanceConnections] {
-		for _, query := range qConnectionAggregations {
-			queries[query] = cacheQuery{query: query, from: from, to: to, step: step, statsName: query}
+		if !signalsOnly {
+			for _, query := range qConnectionAggregations {
+				queries[query] = cacheQuery{query: query, from: from, to: to, step: step, statsName: query}
+			}
 		}
 		addQuery(qRecordingRuleApplicationL7Requests, qRecordingRuleApplicationL7Requests, qRecordingRuleApplicationL7Requests, true)
 		addQuery(qRecordingRuleApplicationL7Histogram, qRecordingRuleApplicationL7Histogram, qRecordingRuleApplicationL7Histogram, true)
@@ -209,12 +232,12 @@ func (c *Constructor) queryCache(ctx context.Context, from, to timeseries.Time,
 	for appId := range checkConfigs {
 		qName := fmt.Sprintf("%s/%s/", qApplicationCustomSLI, appId)
 		availabilityCfg, _ := checkConfigs.GetAvailability(appId)
-		if availabilityCfg.Custom {
+		if availabilityCfg.Custom && !availabilityCfg.FromSpans() {
 			addQuery(qName+"total_requests", qApplicationCustomSLI, availabilityCfg.Total(), true)
 			addQuery(qName+"failed_requests", qApplicationCustomSLI, availabilityCfg.Failed(), true)
 		}
 		latencyCfg, _ := checkConfigs.GetLatency(appId, c.project.CalcApplicationCategory(appId))
-		if latencyCfg.Custom {
+		if latencyCfg.Custom && !latencyCfg.FromSpans() {
 			addQuery(qName+"requests_histogram", qApplicationCustomSLI, latencyCfg.Histogram(), true)
 		}
 	}
@@ -408,9 +431,29 @@ func enrichInstances(w *model.World, metrics map[string][]*model.MetricValues, r
 			case strings.HasPrefix(queryName, "mysql_"):
 				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeMysql)
 				mysql(instance, queryName, m)
+			case strings.HasPrefix(queryName, "rabbitmq_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeRabbitmq)
+				rabbitmq(instance, queryName, m)
+			case strings.HasPrefix(queryName, "nats_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeNats)
+				nats(instance, queryName, m)
+			case strings.HasPrefix(queryName, "cassandra_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeCassandra)
+				cassandra(instance, queryName, m)
+			case strings.HasPrefix(queryName, "clickhouse_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeClickHouse)
+				clickhouse(instance, queryName, m)
+			case strings.HasPrefix(queryName, "golang_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeGolang)
+				goRuntime(instance, queryName, m)
+			case strings.HasPrefix(queryName, "proxy_"):
+				instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, m.Labels, model.ApplicationTypeEnvoy, model.ApplicationTypeNginx, model.ApplicationTypeHaproxy)
+				proxy(instance, queryName, m)
 			}
 		}
 	}
+	kafka(metrics, instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById)
+	elasticsearch(metrics, instancesByPod, instancesByListenAddr)
 }
 
 type appGroup struct {
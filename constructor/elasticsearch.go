@@ -0,0 +1,120 @@
+package constructor
+
+import (
+	"net"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+type esClusterKey struct {
+	exporter, name string
+}
+
+// elasticsearch matches the metrics of elasticsearch-exporter to the Elasticsearch and OpenSearch nodes.
+// The exporter reports the metrics of all the nodes of the cluster, so the nodes are found by their addresses
+// (the "host" label) or names, rather than by the address of the exporter.
+func elasticsearch(metrics map[string][]*model.MetricValues, instancesByPod map[podId]*model.Instance, instancesByListenAddr map[string]*model.Instance) {
+	isNode := func(i *model.Instance) bool {
+		types := i.ApplicationTypes()
+		return types[model.ApplicationTypeElasticsearch] || types[model.ApplicationTypeOpensearch]
+	}
+	instancesByIP := map[string]*model.Instance{}
+	for addr, i := range instancesByListenAddr {
+		ip, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			continue
+		}
+		// an IP can be shared by several instances (e.g., pods in the host network namespace)
+		if current := instancesByIP[ip]; current == nil || !isNode(current) {
+			instancesByIP[ip] = i
+		}
+	}
+	instancesByPodName := map[string][]*model.Instance{}
+	for id, i := range instancesByPod {
+		instancesByPodName[id.name] = append(instancesByPodName[id.name], i)
+	}
+	findNode := func(ls model.Labels) *model.Instance {
+		if i := instancesByIP[ls["host"]]; i != nil {
+			return i
+		}
+		// the node names default to the pod names in Kubernetes
+		if instances := instancesByPodName[ls["name"]]; len(instances) == 1 {
+			return instances[0]
+		}
+		return nil
+	}
+
+	clusters := map[esClusterKey]*model.ElasticsearchCluster{}
+	getCluster := func(ls model.Labels) *model.ElasticsearchCluster {
+		k := esClusterKey{exporter: ls["instance"], name: ls["cluster"]}
+		c := clusters[k]
+		if c == nil {
+			c = &model.ElasticsearchCluster{Name: k.name}
+			clusters[k] = c
+		}
+		return c
+	}
+
+	for queryName := range metrics {
+		if !strings.HasPrefix(queryName, "es_node_") {
+			continue
+		}
+		for _, m := range metrics[queryName] {
+			instance := findNode(m.Labels)
+			if instance == nil {
+				continue
+			}
+			if instance.Elasticsearch == nil {
+				instance.Elasticsearch = model.NewElasticsearch()
+			}
+			es := instance.Elasticsearch
+			if es.Cluster == nil {
+				es.Cluster = getCluster(m.Labels)
+			}
+			switch queryName {
+			case "es_node_heap_used":
+				es.HeapUsed = merge(es.HeapUsed, m.Values, timeseries.Any)
+				// the node reported by the exporter is a member of the cluster
+				es.Up = merge(es.Up, presence(m.Values), timeseries.Any)
+				es.Node.Update(m.Values, m.Labels["name"])
+			case "es_node_heap_max":
+				es.HeapMax = merge(es.HeapMax, m.Values, timeseries.Any)
+			case "es_node_search_time":
+				es.SearchTime = merge(es.SearchTime, m.Values, timeseries.Any)
+			case "es_node_searches":
+				es.Searches = merge(es.Searches, m.Values, timeseries.Any)
+			case "es_node_indexing_time":
+				es.IndexingTime = merge(es.IndexingTime, m.Values, timeseries.Any)
+			case "es_node_indexed_docs":
+				es.IndexedDocs = merge(es.IndexedDocs, m.Values, timeseries.Any)
+			case "es_node_rejected_tasks":
+				pool := m.Labels["type"]
+				es.RejectedTasks[pool] = merge(es.RejectedTasks[pool], m.Values, timeseries.NanSum)
+			}
+		}
+	}
+
+	for queryName := range metrics {
+		if !strings.HasPrefix(queryName, "es_cluster_") {
+			continue
+		}
+		for _, m := range metrics[queryName] {
+			c := clusters[esClusterKey{exporter: m.Labels["instance"], name: m.Labels["cluster"]}]
+			if c == nil {
+				continue
+			}
+			switch queryName {
+			case "es_cluster_health_status":
+				c.Status.Update(m.Values, m.Labels["color"])
+			case "es_cluster_nodes":
+				c.Nodes = merge(c.Nodes, m.Values, timeseries.Any)
+			case "es_cluster_unassigned_shards":
+				c.UnassignedShards = merge(c.UnassignedShards, m.Values, timeseries.Any)
+			case "es_cluster_version":
+				c.Version.Update(m.Values, m.Labels["version"])
+			}
+		}
+	}
+}
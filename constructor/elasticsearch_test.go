@@ -0,0 +1,59 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestElasticsearch(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(timeseries.Hour), timeseries.Minute, timeseries.Minute)
+	esApp := w.GetOrCreateApplication(model.NewApplicationId("search", model.ApplicationKindStatefulSet, "es"), false)
+	node0 := esApp.GetOrCreateInstance("es-0", nil)
+	node0.TcpListens[model.Listen{IP: "10.0.0.1", Port: "9200"}] = true
+	node1 := esApp.GetOrCreateInstance("es-1", nil)
+	node1.Pod = &model.Pod{}
+
+	exporter := "10.0.1.1:9114"
+	node := func(host, name string) model.Labels {
+		return model.Labels{"instance": exporter, "cluster": "logs", "host": host, "name": name}
+	}
+	metrics := map[string][]*model.MetricValues{
+		"es_node_heap_used": {
+			{Labels: node("10.0.0.1", "es-0"), Values: values(50, 80)},
+			{Labels: node("10.0.0.2", "es-1"), Values: values(50, 50)},
+			{Labels: node("10.0.0.3", "unknown"), Values: values(50, 50)},
+		},
+		"es_node_heap_max": {
+			{Labels: node("10.0.0.1", "es-0"), Values: values(100, 100)},
+		},
+		"es_node_rejected_tasks": {
+			{Labels: model.Labels{"instance": exporter, "cluster": "logs", "host": "10.0.0.1", "name": "es-0", "type": "search"}, Values: values(0, 2)},
+		},
+		"es_cluster_health_status": {
+			{Labels: model.Labels{"instance": exporter, "cluster": "logs", "color": "yellow"}, Values: values(1, 1)},
+		},
+		"es_cluster_unassigned_shards": {
+			{Labels: model.Labels{"instance": exporter, "cluster": "logs"}, Values: values(0, 3)},
+			{Labels: model.Labels{"instance": exporter, "cluster": "other"}, Values: values(1, 1)},
+		},
+	}
+	enrichInstances(w, metrics, nil, nil)
+
+	require.NotNil(t, node0.Elasticsearch)
+	require.NotNil(t, node1.Elasticsearch)
+	assert.True(t, node0.Elasticsearch.IsUp())
+	assert.Equal(t, "es-1", node1.Elasticsearch.Node.Value())
+	assert.Equal(t, float32(80), node0.Elasticsearch.HeapUsage().Last())
+	assert.Equal(t, float32(2), node0.Elasticsearch.RejectedTasks["search"].Last())
+
+	cluster := node0.Elasticsearch.Cluster
+	require.NotNil(t, cluster)
+	assert.Same(t, cluster, node1.Elasticsearch.Cluster)
+	assert.Equal(t, "logs", cluster.Name)
+	assert.Equal(t, "yellow", cluster.Status.Value())
+	assert.Equal(t, float32(3), cluster.UnassignedShards.Last())
+}
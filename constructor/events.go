@@ -2,16 +2,19 @@ package constructor
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/coroot/coroot/model"
 	"github.com/coroot/coroot/timeseries"
 )
 
 func calcAppEvents(w *model.World) {
+	argoCDSyncs := calcArgoCDSyncEvents(w)
 	for _, app := range w.Applications {
 		var events []*model.ApplicationEvent
 		events = append(events, calcClusterSwitchovers(app)...)
 		events = append(events, calcUpDownEvents(app)...)
+		events = append(events, argoCDSyncs[app.Id]...)
 		for _, d := range app.Deployments {
 			if d.StartedAt.Before(w.Ctx.From) || d.StartedAt.After(w.Ctx.To) {
 				continue
@@ -135,3 +138,30 @@ func calcClusterSwitchovers(app *model.Application) []*model.ApplicationEvent {
 	}
 	return events
 }
+
+func calcArgoCDSyncEvents(w *model.World) map[model.ApplicationId][]*model.ApplicationEvent {
+	res := map[model.ApplicationId][]*model.ApplicationEvent{}
+	if w.ArgoCD == nil {
+		return res
+	}
+	for id, app := range w.ArgoCD.Applications {
+		for _, s := range app.Syncs {
+			details := "Argo CD sync of " + id.Name
+			if s.Revision != "" {
+				details = "Argo CD sync to revision " + s.Revision
+			}
+			if !s.Succeeded() {
+				details += " (" + strings.ToLower(s.Phase) + ")"
+			}
+			for appId := range app.Workloads {
+				res[appId] = append(res[appId], &model.ApplicationEvent{
+					Start:   s.Time,
+					End:     s.Time,
+					Type:    model.ApplicationEventTypeArgoCDSync,
+					Details: details,
+				})
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,225 @@
+package constructor
+
+import (
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+const managedDatabaseDevice = "data"
+
+// loadCloudSQL builds Cloud SQL instances from the metrics exported by stackdriver_exporter.
+// Cloud SQL doesn't expose the database engine as a label, so it's detected by the presence of engine-specific metrics.
+func loadCloudSQL(w *model.World, metrics map[string][]*model.MetricValues) {
+	instances := map[string]*model.Instance{}
+	getOrCreate := func(m *model.MetricValues) *model.Instance {
+		id := m.Labels["database_id"] // <project>:<instance>
+		_, name, ok := strings.Cut(id, ":")
+		if !ok || name == "" {
+			return nil
+		}
+		instance := instances[id]
+		if instance == nil {
+			appId := model.NewApplicationId("", model.ApplicationKindCloudSQL, name)
+			instance = w.GetOrCreateApplication(appId, false).GetOrCreateInstance(name, nil)
+			instance.CloudSQL = &model.CloudSQL{}
+			instance.Node = managedDatabaseNode(w, instance, "cloudsql:"+name)
+			managedDatabaseVolume(instance)
+			instances[id] = instance
+		}
+		instance.Node.Name.Update(m.Values, "cloudsql:"+name)
+		instance.Node.CloudProvider.Update(m.Values, model.CloudProviderGCP)
+		instance.Node.Region.Update(m.Values, m.Labels["region"])
+		return instance
+	}
+
+	for _, m := range metrics["gcp_cloudsql_postgres_connections"] {
+		if instance := getOrCreate(m); instance != nil {
+			instance.CloudSQL.Engine.Update(m.Values, "postgres")
+		}
+	}
+	for _, m := range metrics["gcp_cloudsql_mysql_queries"] {
+		if instance := getOrCreate(m); instance != nil {
+			instance.CloudSQL.Engine.Update(m.Values, "mysql")
+		}
+	}
+
+	for _, q := range QUERIES {
+		if !strings.HasPrefix(q.Name, "gcp_cloudsql_") {
+			continue
+		}
+		for _, m := range metrics[q.Name] {
+			instance := getOrCreate(m)
+			if instance == nil {
+				continue
+			}
+			node := instance.Node
+			volume := instance.Volumes[0]
+			pg, my := managedDatabaseEngine(instance, instance.CloudSQL.Engine.Value())
+			switch q.Name {
+			case "gcp_cloudsql_up":
+				instance.CloudSQL.LifeSpan = merge(instance.CloudSQL.LifeSpan, presence(m.Values), timeseries.Any)
+				if pg != nil {
+					pg.Up = merge(pg.Up, m.Values, timeseries.Any)
+				}
+				if my != nil {
+					my.Up = merge(my.Up, m.Values, timeseries.Any)
+				}
+			case "gcp_cloudsql_state":
+				instance.CloudSQL.State.Update(m.Values, m.Labels["state"])
+			case "gcp_cloudsql_cpu_cores":
+				node.CpuCapacity = merge(node.CpuCapacity, m.Values, timeseries.Any)
+			case "gcp_cloudsql_cpu_usage_percent":
+				node.CpuUsagePercent = merge(node.CpuUsagePercent, m.Values, timeseries.Any)
+			case "gcp_cloudsql_memory_total_bytes":
+				node.MemoryTotalBytes = merge(node.MemoryTotalBytes, m.Values, timeseries.Any)
+			case "gcp_cloudsql_memory_usage_bytes": // the queries are processed in order, so the total is already loaded
+				node.MemoryFreeBytes = timeseries.Sub(node.MemoryTotalBytes, m.Values)
+				node.MemoryAvailableBytes = node.MemoryFreeBytes
+			case "gcp_cloudsql_disk_total_bytes":
+				volume.Device.Update(m.Values, managedDatabaseDevice)
+				volume.CapacityBytes = merge(volume.CapacityBytes, m.Values, timeseries.Any)
+			case "gcp_cloudsql_disk_used_bytes":
+				volume.Device.Update(m.Values, managedDatabaseDevice)
+				volume.UsedBytes = merge(volume.UsedBytes, m.Values, timeseries.Any)
+			case "gcp_cloudsql_replication_lag_seconds":
+				managedDatabaseReplicationLag(instance, pg, my, m.Values)
+			case "gcp_cloudsql_postgres_connections":
+				if pg != nil {
+					key := model.PgConnectionKey{State: "total"}
+					pg.Connections[key] = merge(pg.Connections[key], m.Values, timeseries.Any)
+				}
+			case "gcp_cloudsql_mysql_connections":
+				if my != nil {
+					my.ConnectionsCurrent = merge(my.ConnectionsCurrent, m.Values, timeseries.Any)
+				}
+			case "gcp_cloudsql_mysql_max_connections":
+				if my != nil {
+					my.ConnectionsMax = merge(my.ConnectionsMax, m.Values, timeseries.Any)
+				}
+			case "gcp_cloudsql_mysql_queries":
+				if my != nil {
+					my.Queries = merge(my.Queries, m.Values, timeseries.Any)
+				}
+			}
+		}
+	}
+}
+
+// loadMemorystore builds Memorystore for Redis instances from the metrics exported by stackdriver_exporter.
+// Each node of a Standard Tier instance becomes a separate application instance.
+func loadMemorystore(w *model.World, metrics map[string][]*model.MetricValues) {
+	instances := map[string]*model.Instance{}
+	for _, q := range QUERIES {
+		if !strings.HasPrefix(q.Name, "gcp_memorystore_") {
+			continue
+		}
+		for _, m := range metrics[q.Name] {
+			id := m.Labels["instance_id"] // projects/<project>/locations/<region>/instances/<name>
+			parts := strings.Split(id, "/")
+			if len(parts) != 6 {
+				continue
+			}
+			name := parts[5]
+			instanceName := name
+			if nodeId := m.Labels["node_id"]; nodeId != "" {
+				instanceName = name + "-" + nodeId
+			}
+			instance := instances[id+"/"+instanceName]
+			if instance == nil {
+				appId := model.NewApplicationId("", model.ApplicationKindMemorystore, name)
+				instance = w.GetOrCreateApplication(appId, false).GetOrCreateInstance(instanceName, nil)
+				instance.Memorystore = &model.Memorystore{}
+				instance.Redis = model.NewRedis(false)
+				instance.Node = managedDatabaseNode(w, instance, "memorystore:"+instanceName)
+				instances[id+"/"+instanceName] = instance
+			}
+			instance.Node.Name.Update(m.Values, "memorystore:"+instanceName)
+			instance.Node.CloudProvider.Update(m.Values, model.CloudProviderGCP)
+			instance.Node.Region.Update(m.Values, m.Labels["region"])
+			node := instance.Node
+			redis := instance.Redis
+			switch q.Name {
+			case "gcp_memorystore_uptime":
+				instance.Memorystore.LifeSpan = merge(instance.Memorystore.LifeSpan, presence(m.Values), timeseries.Any)
+				redis.Up = merge(redis.Up, presence(m.Values), timeseries.Any)
+			case "gcp_memorystore_role":
+				// 1 - primary, 0 - replica
+				primary := m.Values.Map(func(t timeseries.Time, v float32) float32 {
+					if v == 1 {
+						return 1
+					}
+					return timeseries.NaN
+				})
+				replica := m.Values.Map(func(t timeseries.Time, v float32) float32 {
+					if v == 0 {
+						return 1
+					}
+					return timeseries.NaN
+				})
+				instance.UpdateClusterRole("primary", primary)
+				instance.UpdateClusterRole("replica", replica)
+				redis.Role.Update(primary, "primary")
+				redis.Role.Update(replica, "replica")
+			case "gcp_memorystore_memory_max_bytes":
+				node.MemoryTotalBytes = merge(node.MemoryTotalBytes, m.Values, timeseries.Any)
+			case "gcp_memorystore_memory_usage_bytes":
+				node.MemoryFreeBytes = timeseries.Sub(node.MemoryTotalBytes, m.Values)
+				node.MemoryAvailableBytes = node.MemoryFreeBytes
+			case "gcp_memorystore_calls":
+				cmd := m.Labels["cmd"]
+				redis.Calls[cmd] = merge(redis.Calls[cmd], m.Values, timeseries.Any)
+			case "gcp_memorystore_calls_time":
+				cmd := m.Labels["cmd"]
+				redis.CallsTime[cmd] = merge(redis.CallsTime[cmd], m.Values, timeseries.Any)
+			}
+		}
+	}
+}
+
+// managedDatabaseNode creates a pseudo-node for an instance of a managed database service.
+func managedDatabaseNode(w *model.World, instance *model.Instance, name string) *model.Node {
+	node := model.NewNode(model.NewNodeId(name, name))
+	node.Instances = append(node.Instances, instance)
+	w.Nodes = append(w.Nodes, node)
+	return node
+}
+
+// managedDatabaseVolume adds the only volume of a managed database instance.
+// The services report just the disk usage, so the volume is backed by an empty disk.
+func managedDatabaseVolume(instance *model.Instance) *model.Volume {
+	volume := &model.Volume{MountPoint: "/data"}
+	instance.Volumes = append(instance.Volumes, volume)
+	instance.Node.Disks[managedDatabaseDevice] = &model.DiskStats{}
+	return volume
+}
+
+// managedDatabaseEngine returns the Postgres or Mysql instrumentation of the instance depending on the engine.
+func managedDatabaseEngine(instance *model.Instance, engine string) (*model.Postgres, *model.Mysql) {
+	switch engine {
+	case "postgres":
+		if instance.Postgres == nil {
+			instance.Postgres = model.NewPostgres(false)
+		}
+		return instance.Postgres, nil
+	case "mysql":
+		if instance.Mysql == nil {
+			instance.Mysql = model.NewMysql(false)
+		}
+		return nil, instance.Mysql
+	}
+	return nil, nil
+}
+
+// managedDatabaseReplicationLag sets the replication lag of the instance.
+// Only read replicas report the lag, so the instance is considered a replica while it is reported.
+func managedDatabaseReplicationLag(instance *model.Instance, pg *model.Postgres, my *model.Mysql, lag *timeseries.TimeSeries) {
+	instance.UpdateClusterRole("replica", presence(lag))
+	if pg != nil {
+		pg.ReplicationLagSeconds = merge(pg.ReplicationLagSeconds, lag, timeseries.Any)
+	}
+	if my != nil {
+		my.ReplicationLagSeconds = merge(my.ReplicationLagSeconds, lag, timeseries.Any)
+	}
+}
@@ -0,0 +1,50 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func goRuntime(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil || !isGoService(instance) {
+		return
+	}
+	if instance.GoRuntime == nil {
+		instance.GoRuntime = &model.GoRuntime{}
+	}
+	g := instance.GoRuntime
+	switch queryName {
+	case "golang_info":
+		g.Version.Update(m.Values, m.Labels["version"])
+	case "golang_goroutines":
+		g.Goroutines = merge(g.Goroutines, m.Values, timeseries.Any)
+		g.Up = merge(g.Up, presence(m.Values), timeseries.Any)
+	case "golang_gc_pause_time":
+		g.GcPauseTime = merge(g.GcPauseTime, m.Values, timeseries.Any)
+	case "golang_gc_cpu_fraction":
+		g.GcCpuFraction = merge(g.GcCpuFraction, m.Values, timeseries.Any)
+	case "golang_heap_inuse":
+		g.HeapInuse = merge(g.HeapInuse, m.Values, timeseries.Any)
+	case "golang_memory_used":
+		g.MemoryUsed = merge(g.MemoryUsed, m.Values, timeseries.Any)
+	case "golang_memory_limit":
+		g.MemoryLimit = merge(g.MemoryLimit, m.Values, timeseries.Any)
+	case "golang_sched_latency":
+		g.SchedLatency = merge(g.SchedLatency, m.Values, timeseries.Any)
+	}
+}
+
+// isGoService reports whether the instance is a Go service rather than a database or a message broker
+// whose pod runs a Go exporter as a sidecar (the exporter exposes the go_* metrics of its own runtime).
+func isGoService(instance *model.Instance) bool {
+	types := instance.ApplicationTypes()
+	if !types[model.ApplicationTypeGolang] {
+		return false
+	}
+	for t := range types {
+		if t.IsDatabase() || t.IsQueue() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoRuntime(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(timeseries.Hour), timeseries.Minute, timeseries.Minute)
+	api := w.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "api"), false).
+		GetOrCreateInstance("api-1", nil)
+	api.GetOrCreateContainer("", "api").ApplicationTypes[model.ApplicationTypeGolang] = true
+	api.TcpListens[model.Listen{IP: "10.0.0.1", Port: "8080"}] = true
+
+	// postgres_exporter is written in Go and runs as a sidecar
+	pg := w.GetOrCreateApplication(model.NewApplicationId("db", model.ApplicationKindStatefulSet, "pg"), false).
+		GetOrCreateInstance("pg-0", nil)
+	pg.GetOrCreateContainer("", "postgres").ApplicationTypes[model.ApplicationTypePostgres] = true
+	pg.GetOrCreateContainer("", "exporter").ApplicationTypes[model.ApplicationTypeGolang] = true
+	pg.TcpListens[model.Listen{IP: "10.0.0.2", Port: "9187"}] = true
+
+	metrics := map[string][]*model.MetricValues{
+		"golang_goroutines": {
+			{Labels: model.Labels{"instance": "10.0.0.1:8080"}, Values: values(100, 120)},
+			{Labels: model.Labels{"instance": "10.0.0.2:9187"}, Values: values(10, 10)},
+		},
+		"golang_heap_inuse": {
+			{Labels: model.Labels{"instance": "10.0.0.1:8080"}, Values: values(50, 95)},
+		},
+		"golang_memory_used": {
+			{Labels: model.Labels{"instance": "10.0.0.1:8080"}, Values: values(80, 96)},
+		},
+		// GOMEMLIMIT is set after a restart, math.MaxInt64 means no limit
+		"golang_memory_limit": {
+			{Labels: model.Labels{"instance": "10.0.0.1:8080"}, Values: values(9223372036854775807, 100)},
+		},
+	}
+	enrichInstances(w, metrics, nil, nil)
+
+	assert.Nil(t, pg.GoRuntime)
+	require.NotNil(t, api.GoRuntime)
+	assert.True(t, api.GoRuntime.IsUp())
+	assert.Equal(t, float32(120), api.GoRuntime.Goroutines.Last())
+	assert.Equal(t, float32(1), api.GoRuntime.SoftMemoryLimit().Reduce(timeseries.NanCount))
+	assert.Equal(t, float32(95), api.GoRuntime.HeapInuse.Last())
+	assert.Equal(t, float32(96), api.GoRuntime.MemoryLimitUsage().Last())
+}
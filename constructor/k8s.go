@@ -220,6 +220,9 @@ func podLabels(metrics []*model.MetricValues, pods map[string]*model.Instance) {
 		if instance == nil {
 			continue
 		}
+		if v := m.Labels["label_app_kubernetes_io_instance"]; v != "" {
+			instance.Pod.AppInstance = v
+		}
 		cluster, role := "", ""
 		switch {
 		case m.Labels["label_postgres_operator_crunchydata_com_cluster"] != "":
@@ -0,0 +1,112 @@
+package constructor
+
+import (
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+// loadKubernetesJobs tracks the runs of Jobs and the schedules of CronJobs.
+// The runs of a CronJob are attached to the CronJob application.
+// Standalone Jobs are only attached to the applications created from their pods.
+func loadKubernetesJobs(w *model.World, metrics map[string][]*model.MetricValues) {
+	cronJobs := map[model.ApplicationId]*model.CronJob{}
+	getOrCreateCronJob := func(m *model.MetricValues) *model.CronJob {
+		appId := model.NewApplicationId(m.Labels["namespace"], model.ApplicationKindCronJob, m.Labels["cronjob"])
+		cj := cronJobs[appId]
+		if cj == nil {
+			app := w.GetOrCreateApplication(appId, false)
+			cj = &model.CronJob{}
+			app.CronJob = cj
+			cronJobs[appId] = cj
+		}
+		return cj
+	}
+
+	jobs := map[string]*model.Job{}
+	getJob := func(m *model.MetricValues) *model.Job {
+		ns, name := m.Labels["namespace"], m.Labels["job_name"]
+		key := ns + "/" + name
+		if j, ok := jobs[key]; ok {
+			return j
+		}
+		app := w.GetApplication(model.NewApplicationId(ns, model.ApplicationKindJob, name))
+		if app == nil {
+			jobs[key] = nil
+			return nil
+		}
+		j := &model.Job{Name: name}
+		app.Jobs[name] = j
+		jobs[key] = j
+		return j
+	}
+
+	for _, q := range QUERIES {
+		switch {
+		case strings.HasPrefix(q.Name, "kube_cronjob_"):
+			if q.Name == "kube_cronjob_annotations" {
+				continue
+			}
+			for _, m := range metrics[q.Name] {
+				cj := getOrCreateCronJob(m)
+				switch q.Name {
+				case "kube_cronjob_info":
+					cj.Schedule.Update(m.Values, m.Labels["schedule"])
+					cj.ConcurrencyPolicy.Update(m.Values, m.Labels["concurrency_policy"])
+				case "kube_cronjob_spec_suspend":
+					cj.Suspended = m.Values.Last() == 1
+				case "kube_cronjob_status_active":
+					cj.StatusActive = merge(cj.StatusActive, m.Values, timeseries.Any)
+				case "kube_cronjob_status_last_schedule_time":
+					cj.LastScheduleTime = timestampFromAge(m.Values)
+				case "kube_cronjob_status_last_successful_time":
+					cj.LastSuccessfulTime = timestampFromAge(m.Values)
+				case "kube_cronjob_next_schedule_time":
+					cj.NextScheduleTime = timestampFromAge(m.Values)
+				}
+			}
+		case strings.HasPrefix(q.Name, "kube_job_"):
+			for _, m := range metrics[q.Name] {
+				j := getJob(m)
+				if j == nil {
+					continue
+				}
+				switch q.Name {
+				case "kube_job_status_start_time":
+					j.StartTime = timestampFromAge(m.Values)
+				case "kube_job_status_completion_time":
+					j.CompletionTime = timestampFromAge(m.Values)
+				case "kube_job_status_active":
+					j.Active = merge(j.Active, m.Values, timeseries.Any)
+				case "kube_job_status_failed":
+					// once the run has failed for a known reason, the metric is reported per reason with the value of 0 or 1
+					if reason := m.Labels["reason"]; reason != "" {
+						j.FailureReason.Update(m.Values.Map(func(t timeseries.Time, v float32) float32 {
+							if v > 0 {
+								return v
+							}
+							return timeseries.NaN
+						}), reason)
+					} else {
+						j.Failed = merge(j.Failed, m.Values, timeseries.Any)
+					}
+				case "kube_job_complete":
+					j.Completed = merge(j.Completed, m.Values, timeseries.Any)
+				case "kube_job_failed":
+					j.Stopped = merge(j.Stopped, m.Values, timeseries.Any)
+				}
+			}
+		}
+	}
+}
+
+// timestampFromAge converts the last value of a `timestamp(m) - m` query to the timestamp reported by the metric.
+// Unix timestamps can't be represented precisely as float32, so the queries return the age instead.
+func timestampFromAge(ts *timeseries.TimeSeries) timeseries.Time {
+	t, age := ts.LastNotNull()
+	if timeseries.IsNaN(age) {
+		return 0
+	}
+	return t.Add(-timeseries.Duration(age))
+}
@@ -0,0 +1,90 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesJobs(t *testing.T) {
+	from := timeseries.Time(1700000000)
+	now := from.Add(2 * timeseries.Minute)
+	w := model.NewWorld(from, now.Add(timeseries.Minute), timeseries.Minute, timeseries.Minute)
+	values := func(vs ...float32) *timeseries.TimeSeries {
+		return timeseries.NewWithData(from, timeseries.Minute, vs)
+	}
+	// the queries return the age of the timestamps
+	age := func(ago timeseries.Duration) *timeseries.TimeSeries {
+		a := float32(ago)
+		return values(a-120, a-60, a)
+	}
+	cronJob := model.Labels{"namespace": "default", "cronjob": "backup"}
+	run := func(name string) model.Labels {
+		return model.Labels{"namespace": "default", "job_name": name}
+	}
+	w.GetOrCreateApplication(model.NewApplicationId("default", model.ApplicationKindJob, "migrate"), false)
+
+	metrics := map[string][]*model.MetricValues{
+		"kube_cronjob_info":                        {{Labels: model.Labels{"namespace": "default", "cronjob": "backup", "schedule": "0 3 * * *", "concurrency_policy": "Forbid"}, Values: values(1, 1, 1)}},
+		"kube_cronjob_spec_suspend":                {{Labels: cronJob, Values: values(0, 0, 0)}},
+		"kube_cronjob_status_active":               {{Labels: cronJob, Values: values(1, 1, 0)}},
+		"kube_cronjob_status_last_schedule_time":   {{Labels: cronJob, Values: age(20 * timeseries.Minute)}},
+		"kube_cronjob_status_last_successful_time": {{Labels: cronJob, Values: age(24 * timeseries.Hour)}},
+		"kube_cronjob_next_schedule_time":          {{Labels: cronJob, Values: age(10 * timeseries.Minute)}},
+		"kube_job_status_start_time": {
+			{Labels: run("backup-28100000"), Values: age(20 * timeseries.Minute)},
+			{Labels: run("backup-28098560"), Values: age(24*timeseries.Hour + 10*timeseries.Minute)},
+			{Labels: run("migrate"), Values: age(5 * timeseries.Minute)},
+			{Labels: run("unknown"), Values: age(5 * timeseries.Minute)},
+		},
+		"kube_job_status_completion_time": {
+			{Labels: run("backup-28098560"), Values: age(24 * timeseries.Hour)},
+		},
+		"kube_job_status_active": {
+			{Labels: run("backup-28100000"), Values: values(1, 1, 0)},
+			{Labels: run("migrate"), Values: values(1, 1, 1)},
+		},
+		"kube_job_status_failed": {
+			{Labels: run("backup-28100000"), Values: values(1, 2, timeseries.NaN)},
+			{Labels: model.Labels{"namespace": "default", "job_name": "backup-28100000", "reason": "BackoffLimitExceeded"}, Values: values(timeseries.NaN, timeseries.NaN, 1)},
+			{Labels: model.Labels{"namespace": "default", "job_name": "backup-28100000", "reason": "DeadlineExceeded"}, Values: values(timeseries.NaN, timeseries.NaN, 0)},
+		},
+		"kube_job_complete": {{Labels: run("backup-28098560"), Values: values(1, 1, 1)}},
+		"kube_job_failed":   {{Labels: run("backup-28100000"), Values: values(timeseries.NaN, timeseries.NaN, 1)}},
+	}
+	loadKubernetesJobs(w, metrics)
+
+	backup := w.GetApplication(model.NewApplicationId("default", model.ApplicationKindCronJob, "backup"))
+	require.NotNil(t, backup)
+	cj := backup.CronJob
+	require.NotNil(t, cj)
+	assert.Equal(t, "0 3 * * *", cj.Schedule.Value())
+	assert.Equal(t, "Forbid", cj.ConcurrencyPolicy.Value())
+	assert.False(t, cj.Suspended)
+	assert.Equal(t, now.Add(-20*timeseries.Minute), cj.LastScheduleTime)
+	assert.Equal(t, now.Add(-24*timeseries.Hour), cj.LastSuccessfulTime)
+	assert.Equal(t, 10*timeseries.Minute, cj.MissedScheduleBy(now))
+
+	require.Len(t, backup.Jobs, 2)
+	failed := backup.Jobs["backup-28100000"]
+	assert.Equal(t, model.JobStatusFailed, failed.Status())
+	assert.Equal(t, "BackoffLimitExceeded", failed.FailureReason.Value())
+	assert.Equal(t, 2, failed.Retries())
+	assert.Equal(t, timeseries.Duration(0), failed.Duration(now))
+
+	succeeded := backup.Jobs["backup-28098560"]
+	assert.Equal(t, model.JobStatusSucceeded, succeeded.Status())
+	assert.Equal(t, 10*timeseries.Minute, succeeded.Duration(now))
+	assert.Equal(t, 0, succeeded.Retries())
+
+	migrate := w.GetApplication(model.NewApplicationId("default", model.ApplicationKindJob, "migrate"))
+	require.Len(t, migrate.Jobs, 1)
+	assert.Equal(t, model.JobStatusRunning, migrate.Jobs["migrate"].Status())
+	assert.Equal(t, 5*timeseries.Minute, migrate.Jobs["migrate"].Duration(now))
+	assert.Nil(t, migrate.CronJob)
+
+	assert.Nil(t, w.GetApplication(model.NewApplicationId("default", model.ApplicationKindJob, "unknown")))
+}
@@ -0,0 +1,155 @@
+package constructor
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+// kafka matches the metrics of kafka-exporter and the JMX exporter to the Kafka brokers.
+// kafka-exporter reports the state of the whole cluster, so its metrics are attributed to all the brokers
+// listed in its kafka_broker_info metric.
+func kafka(metrics map[string][]*model.MetricValues, instancesByPod map[podId]*model.Instance, instancesByListenAddr map[string]*model.Instance, rdsInstancesById, ecInstanceById map[string]*model.Instance) {
+	findBroker := func(ls model.Labels) *model.Instance {
+		instance := findInstance(instancesByPod, instancesByListenAddr, rdsInstancesById, ecInstanceById, ls, model.ApplicationTypeKafka)
+		if instance != nil {
+			return instance
+		}
+		// brokers usually advertise their DNS names, such as kafka-0.kafka-headless.ns.svc.cluster.local:9092
+		host, _, err := net.SplitHostPort(ls["address"])
+		if err != nil || net.ParseIP(host) != nil {
+			return nil
+		}
+		parts := strings.Split(host, ".")
+		ns := guessNamespace(ls)
+		if len(parts) > 2 {
+			ns = parts[2]
+		}
+		return instancesByPod[podId{name: parts[0], ns: ns}]
+	}
+
+	clusters := map[string]*model.KafkaCluster{} // by exporter
+	for _, m := range metrics["kafka_broker_info"] {
+		instance := findBroker(m.Labels)
+		if instance == nil {
+			continue
+		}
+		if instance.Kafka == nil {
+			instance.Kafka = model.NewKafka()
+		}
+		instance.Kafka.Up = merge(instance.Kafka.Up, m.Values, timeseries.Any)
+		instance.Kafka.BrokerId.Update(m.Values, m.Labels["id"])
+		exporter := m.Labels["instance"]
+		cluster := clusters[exporter]
+		if cluster == nil {
+			cluster = model.NewKafkaCluster()
+			clusters[exporter] = cluster
+		}
+		if instance.Kafka.Cluster == nil {
+			instance.Kafka.Cluster = cluster
+		}
+	}
+
+	for queryName := range metrics {
+		if !strings.HasPrefix(queryName, "kafka_") || queryName == "kafka_broker_info" {
+			continue
+		}
+		for _, m := range metrics[queryName] {
+			if strings.HasPrefix(queryName, "kafka_server_") {
+				kafkaBroker(findBroker(m.Labels), queryName, m)
+				continue
+			}
+			cluster := clusters[m.Labels["instance"]]
+			if cluster == nil {
+				continue
+			}
+			switch queryName {
+			case "kafka_brokers":
+				cluster.Brokers = merge(cluster.Brokers, m.Values, timeseries.Any)
+			case "kafka_topic_partition_under_replicated":
+				cluster.UnderReplicatedPartitions = merge(cluster.UnderReplicatedPartitions, m.Values, timeseries.Any)
+			case "kafka_topic_partition_offline":
+				cluster.OfflinePartitions = merge(cluster.OfflinePartitions, m.Values, timeseries.Any)
+			case "kafka_consumergroup_lag":
+				g := cluster.GetOrCreateConsumerGroup(m.Labels["consumergroup"])
+				topic := m.Labels["topic"]
+				g.Lag[topic] = merge(g.Lag[topic], m.Values, timeseries.Any)
+			case "kafka_consumergroup_members":
+				g := cluster.GetOrCreateConsumerGroup(m.Labels["consumergroup"])
+				g.Members = merge(g.Members, m.Values, timeseries.Any)
+			}
+		}
+	}
+}
+
+func kafkaBroker(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Kafka == nil {
+		instance.Kafka = model.NewKafka()
+	}
+	switch queryName {
+	case "kafka_server_under_replicated_partitions":
+		instance.Kafka.UnderReplicatedPartitions = merge(instance.Kafka.UnderReplicatedPartitions, m.Values, timeseries.Any)
+	case "kafka_server_offline_partitions":
+		instance.Kafka.OfflinePartitions = merge(instance.Kafka.OfflinePartitions, m.Values, timeseries.Any)
+	case "kafka_server_isr_shrinks":
+		instance.Kafka.IsrShrinks = merge(instance.Kafka.IsrShrinks, m.Values, timeseries.Any)
+	case "kafka_server_active_controller":
+		instance.Kafka.ActiveController = merge(instance.Kafka.ActiveController, m.Values, timeseries.Any)
+		instance.Kafka.Up = merge(instance.Kafka.Up, presence(m.Values), timeseries.Any)
+	}
+}
+
+// linkKafkaConsumers links the consumer groups to the applications consuming messages from the Kafka clusters.
+// A consumer group usually takes after the name of the consuming service, so the group is linked to the clients
+// of the cluster whose names are contained in the group name (the longest match wins).
+func (c *Constructor) linkKafkaConsumers(w *model.World) {
+	linked := map[*model.KafkaCluster]bool{}
+	for _, app := range w.Applications {
+		for _, i := range app.Instances {
+			if i.Kafka == nil || i.Kafka.Cluster == nil || linked[i.Kafka.Cluster] {
+				continue
+			}
+			linked[i.Kafka.Cluster] = true
+			for _, g := range i.Kafka.Cluster.ConsumerGroups {
+				g.Consumers = kafkaConsumers(w, app, g.Name)
+				for _, consumer := range g.Consumers {
+					consumer.KafkaConsumerGroups = append(consumer.KafkaConsumerGroups, g)
+				}
+			}
+		}
+	}
+}
+
+func kafkaConsumers(w *model.World, kafkaApp *model.Application, group string) []*model.Application {
+	group = strings.ToLower(group)
+	var res []*model.Application
+	longest := 0
+	for _, d := range kafkaApp.Downstreams {
+		name := strings.ToLower(d.Application.Id.Name)
+		if name == "" || len(name) < longest || !strings.Contains(group, name) {
+			continue
+		}
+		if len(name) > longest {
+			longest = len(name)
+			res = res[:0]
+		}
+		res = append(res, d.Application)
+	}
+	if len(res) == 0 {
+		for _, app := range w.Applications {
+			if app != kafkaApp && strings.ToLower(app.Id.Name) == group {
+				res = append(res, app)
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Id.String() < res[j].Id.String()
+	})
+	return res
+}
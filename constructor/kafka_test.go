@@ -0,0 +1,70 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafka(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(timeseries.Hour), timeseries.Minute, timeseries.Minute)
+	kafkaApp := w.GetOrCreateApplication(model.NewApplicationId("mq", model.ApplicationKindStatefulSet, "kafka"), false)
+	broker0 := kafkaApp.GetOrCreateInstance("kafka-0", nil)
+	broker0.TcpListens[model.Listen{IP: "10.0.0.1", Port: "9092"}] = true
+	broker1 := kafkaApp.GetOrCreateInstance("kafka-1", nil)
+	broker1.Pod = &model.Pod{}
+	broker1.TcpListens[model.Listen{IP: "10.0.0.2", Port: "9092"}] = true
+
+	connect := func(name string) *model.Application {
+		app := w.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, name), false)
+		conn := &model.AppToAppConnection{Application: app, RemoteApplication: kafkaApp}
+		app.Upstreams[kafkaApp.Id] = conn
+		kafkaApp.Downstreams[app.Id] = conn
+		return app
+	}
+	orders := connect("orders")
+	connect("orders-api")
+	connect("billing")
+
+	exporter := "10.0.1.1:9308"
+	metrics := map[string][]*model.MetricValues{
+		"kafka_broker_info": {
+			{Labels: model.Labels{"instance": exporter, "address": "10.0.0.1:9092", "id": "0"}, Values: values(1, 1)},
+			{Labels: model.Labels{"instance": exporter, "address": "kafka-1.kafka-headless.mq.svc.cluster.local:9092", "id": "1"}, Values: values(1, 1)},
+		},
+		"kafka_topic_partition_under_replicated": {
+			{Labels: model.Labels{"instance": exporter}, Values: values(0, 2)},
+		},
+		"kafka_consumergroup_lag": {
+			{Labels: model.Labels{"instance": exporter, "consumergroup": "orders-consumer", "topic": "orders"}, Values: values(10, 100)},
+			{Labels: model.Labels{"instance": "10.0.1.2:9308", "consumergroup": "unknown", "topic": "orders"}, Values: values(1, 1)},
+		},
+		"kafka_server_active_controller": {
+			{Labels: model.Labels{"instance": "10.0.0.2:9092"}, Values: values(1, 1)},
+		},
+	}
+	enrichInstances(w, metrics, nil, nil)
+
+	require.NotNil(t, broker0.Kafka)
+	require.NotNil(t, broker1.Kafka)
+	assert.Equal(t, "0", broker0.Kafka.BrokerId.Value())
+	assert.Equal(t, "1", broker1.Kafka.BrokerId.Value())
+	assert.Equal(t, float32(1), broker1.Kafka.ActiveController.Last())
+	assert.True(t, broker1.Kafka.IsUp())
+
+	cluster := broker0.Kafka.Cluster
+	require.NotNil(t, cluster)
+	assert.Same(t, cluster, broker1.Kafka.Cluster)
+	assert.Equal(t, float32(2), cluster.UnderReplicatedPartitions.Last())
+	require.Len(t, cluster.ConsumerGroups, 1)
+
+	New(nil, nil, nil, nil).linkKafkaConsumers(w)
+	g := cluster.ConsumerGroups["orders-consumer"]
+	require.NotNil(t, g)
+	assert.Equal(t, []*model.Application{orders}, g.Consumers)
+	assert.Equal(t, []*model.KafkaConsumerGroup{g}, orders.KafkaConsumerGroups)
+	assert.Equal(t, float32(100), g.TotalLag().Last())
+}
@@ -0,0 +1,124 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func loadKubernetesControlPlane(w *model.World, metrics map[string][]*model.MetricValues) {
+	cp := model.NewKubernetesControlPlane()
+
+	for _, m := range metrics["control_plane_apiserver_requests"] {
+		s := cp.GetOrCreateAPIServer(m.Labels["instance"])
+		s.Requests = merge(s.Requests, m.Values, timeseries.NanSum)
+	}
+	for _, m := range metrics["control_plane_apiserver_errors"] {
+		s := cp.GetOrCreateAPIServer(m.Labels["instance"])
+		s.Errors = merge(s.Errors, m.Values, timeseries.NanSum)
+	}
+	for _, m := range metrics["control_plane_apiserver_latency"] {
+		s := cp.GetOrCreateAPIServer(m.Labels["instance"])
+		s.Latency = merge(s.Latency, m.Values, timeseries.Max)
+	}
+
+	for _, m := range metrics["control_plane_etcd_has_leader"] {
+		e := cp.GetOrCreateEtcd(m.Labels["instance"])
+		e.HasLeader = merge(e.HasLeader, m.Values, timeseries.Any)
+	}
+	for _, m := range metrics["control_plane_etcd_leader_changes"] {
+		e := cp.GetOrCreateEtcd(m.Labels["instance"])
+		e.LeaderChanges = merge(e.LeaderChanges, m.Values, timeseries.NanSum)
+	}
+	for _, m := range metrics["control_plane_etcd_wal_fsync_latency"] {
+		e := cp.GetOrCreateEtcd(m.Labels["instance"])
+		e.WalFsyncLatency = merge(e.WalFsyncLatency, m.Values, timeseries.Max)
+	}
+	for _, m := range metrics["control_plane_etcd_commit_latency"] {
+		e := cp.GetOrCreateEtcd(m.Labels["instance"])
+		e.CommitLatency = merge(e.CommitLatency, m.Values, timeseries.Max)
+	}
+
+	for _, m := range metrics["control_plane_scheduler_pending_pods"] {
+		s := cp.GetOrCreateScheduler(m.Labels["instance"])
+		queue := m.Labels["queue"]
+		s.PendingPods[queue] = merge(s.PendingPods[queue], m.Values, timeseries.NanSum)
+	}
+
+	for _, m := range metrics["control_plane_kubelet_pleg_relist_latency"] {
+		// kubelets are usually scraped through the API server proxy, so the node label is more meaningful than the instance
+		node := m.Labels["node"]
+		if node == "" {
+			node = m.Labels["instance"]
+		}
+		k := cp.GetOrCreateKubelet(node)
+		k.PlegRelistLatency = merge(k.PlegRelistLatency, m.Values, timeseries.Max)
+	}
+
+	for _, m := range metrics["control_plane_coredns_requests"] {
+		d := cp.GetOrCreateCoreDNS(m.Labels["instance"])
+		d.Requests = merge(d.Requests, m.Values, timeseries.NanSum)
+	}
+	for _, m := range metrics["control_plane_coredns_servfail"] {
+		d := cp.GetOrCreateCoreDNS(m.Labels["instance"])
+		d.ServFail = merge(d.ServFail, m.Values, timeseries.NanSum)
+	}
+	for _, m := range metrics["control_plane_coredns_latency"] {
+		d := cp.GetOrCreateCoreDNS(m.Labels["instance"])
+		d.Latency = merge(d.Latency, m.Values, timeseries.Max)
+	}
+
+	if cp.IsEmpty() {
+		return
+	}
+	w.KubernetesControlPlane = cp
+	// the pseudo-application has no instances; its audit report is built from w.KubernetesControlPlane
+	k8s := w.GetOrCreateApplication(model.KubernetesControlPlaneApplicationId, false)
+	linkKubernetesControlPlane(w, cp, k8s)
+}
+
+// linkKubernetesControlPlane connects the applications depending on the cluster components to the pseudo-application,
+// so that the RCA can get to it from an affected application: the clients of the API server, the clients of CoreDNS
+// and the applications running on the nodes where the kubelet is slow to relist pods.
+func linkKubernetesControlPlane(w *model.World, cp *model.KubernetesControlPlane, k8s *model.Application) {
+	plegThreshold := w.CheckConfigs.GetSimple(model.Checks.KubernetesKubeletPLEG.Id, k8s.Id).Threshold
+	slowKubelets := map[string]bool{}
+	for node, k := range cp.Kubelets {
+		if k.PlegRelistLatency.Last() > plegThreshold {
+			slowKubelets[node] = true
+		}
+	}
+
+	for _, app := range w.Applications {
+		if app == k8s || app.Upstreams[k8s.Id] != nil {
+			continue
+		}
+		dependsOn := len(cp.CoreDNS) > 0 && len(app.DNSRequests) > 0
+		for _, i := range app.Instances {
+			if dependsOn {
+				break
+			}
+			if i.Node != nil && slowKubelets[i.Node.GetName()] {
+				dependsOn = true
+				break
+			}
+			for _, u := range i.Upstreams {
+				// the API server is exposed as the `kubernetes` service in the default namespace
+				if u.Service != nil && u.Service.Name == "kube-apiserver" && u.Service.Namespace == "default" {
+					dependsOn = true
+					break
+				}
+			}
+		}
+		if !dependsOn {
+			continue
+		}
+		conn := &model.AppToAppConnection{
+			Application:       app,
+			RemoteApplication: k8s,
+			RequestsCount:     map[model.Protocol]map[string]*timeseries.TimeSeries{},
+			RequestsLatency:   map[model.Protocol]*timeseries.TimeSeries{},
+		}
+		app.Upstreams[k8s.Id] = conn
+		k8s.Downstreams[app.Id] = conn
+	}
+}
@@ -0,0 +1,82 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesControlPlane(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(3*timeseries.Minute), timeseries.Minute, timeseries.Minute)
+	loadKubernetesControlPlane(w, map[string][]*model.MetricValues{})
+	assert.Nil(t, w.KubernetesControlPlane)
+	assert.Nil(t, w.GetApplication(model.KubernetesControlPlaneApplicationId))
+
+	controller := w.GetOrCreateApplication(model.NewApplicationId("ops", model.ApplicationKindDeployment, "controller"), false)
+	controller.GetOrCreateInstance("controller-1", nil).Upstreams[model.ConnectionKey{Destination: "10.96.0.1:443"}] = &model.Connection{
+		Service: &model.Service{Name: "kube-apiserver", Namespace: "default"},
+	}
+	dnsClient := w.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "api"), false)
+	dnsClient.DNSRequests[model.DNSRequest{Type: "TypeA", Domain: "db.shop.svc.cluster.local"}] = map[string]*timeseries.TimeSeries{"ok": values(10, 10, 10)}
+	node1 := model.NewNode(model.NewNodeId("node-1", ""))
+	node1.Name.Update(values(1, 1, 1), "node-1")
+	onSlowNode := w.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "worker"), false)
+	onSlowNode.GetOrCreateInstance("worker-1", node1)
+	node2 := model.NewNode(model.NewNodeId("node-2", ""))
+	node2.Name.Update(values(1, 1, 1), "node-2")
+	other := w.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "batch"), false)
+	other.GetOrCreateInstance("batch-1", node2)
+
+	metrics := map[string][]*model.MetricValues{
+		"control_plane_apiserver_requests": {
+			{Labels: model.Labels{"instance": "10.0.0.1:6443"}, Values: values(100, 100, 100)},
+		},
+		"control_plane_apiserver_errors": {
+			{Labels: model.Labels{"instance": "10.0.0.1:6443"}, Values: values(0, 1, 5)},
+		},
+		"control_plane_etcd_has_leader": {
+			{Labels: model.Labels{"instance": "10.0.0.1:2379"}, Values: values(1, 1, 1)},
+		},
+		"control_plane_scheduler_pending_pods": {
+			{Labels: model.Labels{"instance": "10.0.0.1:10259", "queue": "active"}, Values: values(0, 0, 0)},
+			{Labels: model.Labels{"instance": "10.0.0.1:10259", "queue": "unschedulable"}, Values: values(0, 2, 3)},
+		},
+		"control_plane_kubelet_pleg_relist_latency": {
+			{Labels: model.Labels{"instance": "10.0.0.2:10250", "node": "node-1"}, Values: values(0.01, 0.02, 2)},
+			{Labels: model.Labels{"instance": "10.0.0.4:10250", "node": "node-2"}, Values: values(0.01, 0.02, 0.01)},
+			{Labels: model.Labels{"instance": "10.0.0.3:10250"}, Values: values(0.01, 0.02, 0.01)},
+		},
+		"control_plane_coredns_requests": {
+			{Labels: model.Labels{"instance": "10.1.0.5:9153"}, Values: values(10, 10, 10)},
+		},
+		"control_plane_coredns_servfail": {
+			{Labels: model.Labels{"instance": "10.1.0.5:9153"}, Values: values(0, 0, 1)},
+		},
+	}
+	loadKubernetesControlPlane(w, metrics)
+
+	cp := w.KubernetesControlPlane
+	require.NotNil(t, cp)
+	app := w.GetApplication(model.KubernetesControlPlaneApplicationId)
+	require.NotNil(t, app)
+	assert.Empty(t, app.Instances)
+	assert.Equal(t, model.ApplicationTypeKubernetes, app.ApplicationType())
+
+	require.Contains(t, cp.APIServers, "10.0.0.1:6443")
+	assert.Equal(t, float32(5), cp.APIServers["10.0.0.1:6443"].ErrorsPercent().Last())
+	assert.Contains(t, cp.Etcd, "10.0.0.1:2379")
+	assert.Equal(t, float32(3), cp.Schedulers["10.0.0.1:10259"].PendingPods[model.SchedulerQueueUnschedulable].Last())
+	assert.Contains(t, cp.Kubelets, "node-1")
+	assert.Contains(t, cp.Kubelets, "10.0.0.3:10250")
+	assert.Equal(t, float32(10), cp.CoreDNS["10.1.0.5:9153"].ErrorsPercent().Last())
+
+	for _, a := range []*model.Application{controller, dnsClient, onSlowNode} {
+		assert.Same(t, app, a.Upstreams[app.Id].RemoteApplication, a.Id.String())
+		assert.Contains(t, app.Downstreams, a.Id)
+	}
+	assert.NotContains(t, other.Upstreams, app.Id)
+	assert.Len(t, app.Downstreams, 3)
+}
@@ -0,0 +1,105 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManagedDatabases(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(3*timeseries.Minute), timeseries.Minute, timeseries.Minute)
+	pg := model.Labels{"database_id": "shop:orders-db", "region": "europe-west1"}
+	replica := model.Labels{"database_id": "shop:orders-db-replica", "region": "europe-west1"}
+	my := model.Labels{"database_id": "shop:users-db", "region": "europe-west1"}
+	metrics := map[string][]*model.MetricValues{
+		"gcp_cloudsql_up":                      {{Labels: pg, Values: values(1, 1, 1)}, {Labels: replica, Values: values(1, 1, 1)}, {Labels: my, Values: values(1, 1, 0)}},
+		"gcp_cloudsql_state":                   {{Labels: model.Labels{"database_id": "shop:orders-db", "state": "RUNNING"}, Values: values(1, 1, 1)}},
+		"gcp_cloudsql_memory_total_bytes":      {{Labels: pg, Values: values(1000, 1000, 1000)}},
+		"gcp_cloudsql_memory_usage_bytes":      {{Labels: pg, Values: values(100, 200, 300)}},
+		"gcp_cloudsql_disk_total_bytes":        {{Labels: pg, Values: values(100, 100, 100)}},
+		"gcp_cloudsql_disk_used_bytes":         {{Labels: pg, Values: values(10, 20, 30)}},
+		"gcp_cloudsql_postgres_connections":    {{Labels: pg, Values: values(5, 5, 5)}, {Labels: replica, Values: values(2, 2, 2)}},
+		"gcp_cloudsql_replication_lag_seconds": {{Labels: replica, Values: values(1, 2, 40)}},
+		"gcp_cloudsql_mysql_queries":           {{Labels: my, Values: values(10, 10, 10)}},
+		"gcp_cloudsql_mysql_connections":       {{Labels: my, Values: values(50, 60, 70)}},
+
+		"gcp_memorystore_uptime": {
+			{Labels: model.Labels{"instance_id": "projects/shop/locations/europe-west1/instances/cache", "node_id": "node-0"}, Values: values(1, 1, 1)},
+			{Labels: model.Labels{"instance_id": "projects/shop/locations/europe-west1/instances/cache", "node_id": "node-1"}, Values: values(1, 1, 1)},
+		},
+		"gcp_memorystore_role": {
+			{Labels: model.Labels{"instance_id": "projects/shop/locations/europe-west1/instances/cache", "node_id": "node-0"}, Values: values(1, 1, 1)},
+			{Labels: model.Labels{"instance_id": "projects/shop/locations/europe-west1/instances/cache", "node_id": "node-1"}, Values: values(0, 0, 0)},
+		},
+
+		"azure_database_alive": {
+			{Labels: model.Labels{"resourceID": "/subscriptions/1/resourceGroups/shop/providers/Microsoft.DBforPostgreSQL/flexibleServers/billing"}, Values: values(1, 1, 1)},
+		},
+		"azure_database_cpu_usage_percent": {
+			{Labels: model.Labels{"resourceID": "/subscriptions/1/resourcegroups/shop/providers/microsoft.dbformysql/flexibleservers/catalog"}, Values: values(10, 20, 30)},
+			{Labels: model.Labels{"resourceID": "/subscriptions/1/resourcegroups/shop/providers/microsoft.storage/storageaccounts/files"}, Values: values(10, 20, 30)},
+		},
+		"azure_database_connections": {
+			{Labels: model.Labels{"resourceID": "/subscriptions/1/resourceGroups/shop/providers/Microsoft.DBforPostgreSQL/flexibleServers/billing"}, Values: values(3, 3, 3)},
+		},
+	}
+	loadCloudSQL(w, metrics)
+	loadMemorystore(w, metrics)
+	loadAzureDatabases(w, metrics)
+
+	orders := w.GetApplication(model.NewApplicationId("", model.ApplicationKindCloudSQL, "orders-db"))
+	require.NotNil(t, orders)
+	require.Len(t, orders.Instances, 1)
+	i := orders.Instances[0]
+	assert.Equal(t, model.ApplicationTypePostgres, orders.ApplicationType())
+	assert.Equal(t, "postgres (Cloud SQL)", orders.Labels()["db"])
+	assert.Equal(t, "RUNNING", i.CloudSQL.State.Value())
+	require.NotNil(t, i.Postgres)
+	assert.True(t, i.Postgres.IsUp())
+	assert.Equal(t, float32(5), i.Postgres.Connections[model.PgConnectionKey{State: "total"}].Last())
+	assert.Equal(t, float32(700), i.Node.MemoryAvailableBytes.Last())
+	assert.Equal(t, model.CloudProviderGCP, i.Node.CloudProvider.Value())
+	require.Len(t, i.Volumes, 1)
+	assert.Equal(t, float32(30), i.Volumes[0].UsedBytes.Last())
+	assert.Equal(t, model.ClusterRoleNone, i.ClusterRoleLast())
+
+	r := w.GetApplication(model.NewApplicationId("", model.ApplicationKindCloudSQL, "orders-db-replica")).Instances[0]
+	assert.Equal(t, model.ClusterRoleReplica, r.ClusterRoleLast())
+	assert.Equal(t, float32(40), r.Postgres.ReplicationLagSeconds.Last())
+
+	users := w.GetApplication(model.NewApplicationId("", model.ApplicationKindCloudSQL, "users-db"))
+	require.NotNil(t, users)
+	assert.Equal(t, model.ApplicationTypeMysql, users.ApplicationType())
+	require.NotNil(t, users.Instances[0].Mysql)
+	assert.False(t, users.Instances[0].Mysql.IsUp())
+	assert.Equal(t, float32(70), users.Instances[0].Mysql.ConnectionsCurrent.Last())
+
+	cache := w.GetApplication(model.NewApplicationId("", model.ApplicationKindMemorystore, "cache"))
+	require.NotNil(t, cache)
+	require.Len(t, cache.Instances, 2)
+	assert.Equal(t, model.ApplicationTypeRedis, cache.ApplicationType())
+	for _, i := range cache.Instances {
+		require.NotNil(t, i.Redis)
+		assert.True(t, i.Redis.IsUp())
+		assert.True(t, i.Node.IsUp())
+	}
+	assert.Equal(t, "primary", cache.Instances[0].Redis.Role.Value())
+	assert.Equal(t, "replica", cache.Instances[1].Redis.Role.Value())
+
+	billing := w.GetApplication(model.NewApplicationId("", model.ApplicationKindAzureDatabase, "billing"))
+	require.NotNil(t, billing)
+	assert.Equal(t, "postgres (Azure)", billing.Labels()["db"])
+	assert.True(t, billing.Instances[0].Postgres.IsUp())
+	assert.Equal(t, float32(3), billing.Instances[0].Postgres.Connections[model.PgConnectionKey{State: "total"}].Last())
+
+	catalog := w.GetApplication(model.NewApplicationId("", model.ApplicationKindAzureDatabase, "catalog"))
+	require.NotNil(t, catalog)
+	assert.Equal(t, model.ApplicationTypeMysql, catalog.ApplicationType())
+	assert.True(t, catalog.Instances[0].Mysql.IsUp())
+	assert.Equal(t, model.CloudProviderAzure, catalog.Instances[0].Node.CloudProvider.Value())
+
+	assert.Nil(t, w.GetApplication(model.NewApplicationId("", model.ApplicationKindAzureDatabase, "files")))
+}
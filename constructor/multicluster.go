@@ -0,0 +1,280 @@
+package constructor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+)
+
+// Cluster is a member of a multi-cluster project: a regular project with its own Prometheus and ClickHouse.
+type Cluster struct {
+	Project *db.Project
+	Cache   Cache
+	// Traces is optional, it enables the span SLIs and the OpenTelemetry services of the cluster.
+	Traces Traces
+}
+
+func (c Cluster) Id() string {
+	return string(c.Project.Id)
+}
+
+func (c Cluster) Name() string {
+	return c.Project.Name
+}
+
+// LoadMulticlusterWorld loads the worlds of the clusters and merges them into a single world of the multi-cluster project.
+// The applications are qualified with the cluster ids, and the connections to external services that turn out to be
+// applications of other clusters are redirected to these applications. The settings, deployments, incidents, and check
+// configs of the multi-cluster project take precedence over the ones of the clusters.
+func (c *Constructor) LoadMulticlusterWorld(ctx context.Context, clusters []Cluster, from, to timeseries.Time, step timeseries.Duration, prof *Profile) (*model.World, error) {
+	start := time.Now()
+	if prof == nil {
+		prof = &Profile{}
+	}
+	var options []Option
+	for o := range c.options {
+		options = append(options, o)
+	}
+
+	w := model.NewWorld(from, to, step, step)
+	var err error
+	prof.stage("get_check_configs", func() {
+		w.CheckConfigs, err = c.db.GetCheckConfigs(c.project.Id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cl := range clusters {
+		ctr := New(c.db, cl.Project, cl.Cache, c.pricing, options...)
+		if cl.Traces != nil {
+			ctr.WithTraces(cl.Traces)
+		}
+		cw, err := ctr.LoadWorld(ctx, from, to, step, nil)
+		if err != nil {
+			return nil, err
+		}
+		prof.stage("merge_"+cl.Name(), func() { mergeWorld(w, cw, cl) })
+	}
+
+	prof.stage("resolve_cross_cluster_connections", func() { resolveCrossClusterConnections(w) })
+	prof.stage("calc_app_categories", func() { c.calcApplicationCategories(w) })
+	prof.stage("load_app_settings", func() { c.loadApplicationSettings(w) })
+	prof.stage("load_app_deployments", func() { c.loadApplicationDeployments(w) })
+	prof.stage("load_app_incidents", func() { c.loadApplicationIncidents(w) })
+	prof.stage("calc_app_events", func() { calcAppEvents(w) })
+
+	klog.Infof("%s: got %d nodes, %d apps from %d clusters in %s", c.project.Id, len(w.Nodes), len(w.Applications), len(clusters), time.Since(start).Truncate(time.Millisecond))
+	return w, nil
+}
+
+func mergeWorld(dst, src *model.World, cluster Cluster) {
+	if src.Ctx.RawStep > dst.Ctx.RawStep {
+		dst.Ctx.RawStep = src.Ctx.RawStep
+	}
+	dst.Nodes = append(dst.Nodes, src.Nodes...)
+	// the flux resources reference the applications by their ids within a cluster, so they are not merged
+
+	dst.IntegrationStatus.NodeAgent.Installed = dst.IntegrationStatus.NodeAgent.Installed || src.IntegrationStatus.NodeAgent.Installed
+	dst.IntegrationStatus.KubeStateMetrics.Required = dst.IntegrationStatus.KubeStateMetrics.Required || src.IntegrationStatus.KubeStateMetrics.Required
+	dst.IntegrationStatus.KubeStateMetrics.Installed = dst.IntegrationStatus.KubeStateMetrics.Installed || src.IntegrationStatus.KubeStateMetrics.Installed
+	for k, v := range src.AWS.DiscoveryErrors {
+		dst.AWS.DiscoveryErrors[k] = dst.AWS.DiscoveryErrors[k] || v
+	}
+
+	for id, history := range src.AnomalyHistory {
+		if dst.AnomalyHistory == nil {
+			dst.AnomalyHistory = map[model.ApplicationId]map[model.AnomalySignal][]*timeseries.TimeSeries{}
+		}
+		dst.AnomalyHistory[id.WithCluster(cluster.Id())] = history
+	}
+
+	for id, checks := range src.CheckConfigs {
+		if id.IsZero() {
+			continue
+		}
+		if id = id.WithCluster(cluster.Id()); dst.CheckConfigs[id] == nil {
+			dst.CheckConfigs[id] = checks
+		}
+	}
+
+	services := map[*model.Service]bool{}
+	for _, app := range src.Applications {
+		app.Id = app.Id.WithCluster(cluster.Id())
+		app.ClusterName = cluster.Name()
+		// the deployments and incidents are tracked by the multi-cluster project
+		app.Deployments = nil
+		app.Incidents = nil
+		dst.Applications[app.Id] = app
+		for _, s := range app.KubernetesServices {
+			services[s] = true
+		}
+	}
+	for _, app := range src.Applications {
+		app.Upstreams = qualifyConnections(app.Upstreams, cluster.Id())
+		app.Downstreams = qualifyConnections(app.Downstreams, cluster.Id())
+	}
+	for s := range services {
+		apps := map[model.ApplicationId]*model.Application{}
+		for _, app := range s.DestinationApps {
+			apps[app.Id] = app
+		}
+		s.DestinationApps = apps
+	}
+}
+
+func qualifyConnections(connections map[model.ApplicationId]*model.AppToAppConnection, cluster string) map[model.ApplicationId]*model.AppToAppConnection {
+	res := make(map[model.ApplicationId]*model.AppToAppConnection, len(connections))
+	for id, conn := range connections {
+		res[id.WithCluster(cluster)] = conn
+	}
+	return res
+}
+
+type clusterService struct {
+	name, ns string
+}
+
+// resolveCrossClusterConnections redirects the connections to the external services that are actually
+// the applications of other clusters. An external service is resolved by its FQDN if it follows the Kubernetes
+// service naming (<service>.<namespace>.svc.<domain>) or by its IPs matched to the pods and load balancers.
+func resolveCrossClusterConnections(w *model.World) {
+	services := map[clusterService][]*model.Application{}
+	appsByIP := map[string]*model.Application{}
+	ambiguousIPs := utils.NewStringSet()
+	addIP := func(ip string, app *model.Application) {
+		if ip == "" || ambiguousIPs.Has(ip) {
+			return
+		}
+		if a := appsByIP[ip]; a != nil && a != app {
+			delete(appsByIP, ip)
+			ambiguousIPs.Add(ip)
+			return
+		}
+		appsByIP[ip] = app
+	}
+	for _, app := range w.Applications {
+		if app.Id.Kind == model.ApplicationKindExternalService {
+			continue
+		}
+		for _, s := range app.KubernetesServices {
+			if s.GetDestinationApplication() != app {
+				continue
+			}
+			sid := clusterService{name: s.Name, ns: s.Namespace}
+			services[sid] = append(services[sid], app)
+			for _, ip := range s.LoadBalancerIPs.Items() {
+				addIP(ip, app)
+			}
+		}
+		for _, i := range app.Instances {
+			for l := range i.TcpListens {
+				if ip := net.ParseIP(l.IP); ip != nil && !ip.IsLoopback() && !ip.IsUnspecified() {
+					addIP(l.IP, app)
+				}
+			}
+		}
+	}
+
+	for id, app := range w.Applications {
+		if id.Kind != model.ApplicationKindExternalService {
+			continue
+		}
+		target := resolveByFQDN(app, services)
+		if target == nil {
+			target = resolveByIPs(app, appsByIP)
+		}
+		if target == nil || target.Id.ClusterId == id.ClusterId {
+			continue
+		}
+		redirectConnections(app, target)
+		delete(w.Applications, id)
+	}
+}
+
+func resolveByFQDN(app *model.Application, services map[clusterService][]*model.Application) *model.Application {
+	host, _, err := net.SplitHostPort(app.Id.Name)
+	if err != nil {
+		return nil
+	}
+	parts := strings.Split(host, ".")
+	if len(parts) < 3 || parts[2] != "svc" {
+		return nil
+	}
+	var candidates []*model.Application
+	for _, a := range services[clusterService{name: parts[0], ns: parts[1]}] {
+		if a.Id.ClusterId != app.Id.ClusterId {
+			candidates = append(candidates, a)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	// e.g., catalog.shop.svc.cluster-b.local
+	var res *model.Application
+	for _, a := range candidates {
+		for _, p := range parts[3:] {
+			if p == a.ClusterName {
+				if res != nil && res != a {
+					return nil
+				}
+				res = a
+			}
+		}
+	}
+	return res
+}
+
+func resolveByIPs(app *model.Application, appsByIP map[string]*model.Application) *model.Application {
+	var res *model.Application
+	for _, i := range app.Instances {
+		for l := range i.TcpListens {
+			a := appsByIP[l.IP]
+			if a == nil || (res != nil && res != a) {
+				return nil
+			}
+			res = a
+		}
+	}
+	return res
+}
+
+func redirectConnections(from, to *model.Application) {
+	for id, conn := range from.Downstreams {
+		src := conn.Application
+		delete(src.Upstreams, from.Id)
+		existing := src.Upstreams[to.Id]
+		if existing == nil {
+			conn.RemoteApplication = to
+			src.Upstreams[to.Id] = conn
+			to.Downstreams[id] = conn
+			continue
+		}
+		existing.Rtt = merge(existing.Rtt, conn.Rtt, timeseries.Any)
+		existing.ConnectionTime = merge(existing.ConnectionTime, conn.ConnectionTime, timeseries.Any)
+		existing.SuccessfulConnections = merge(existing.SuccessfulConnections, conn.SuccessfulConnections, timeseries.NanSum)
+		existing.Active = merge(existing.Active, conn.Active, timeseries.NanSum)
+		existing.FailedConnections = merge(existing.FailedConnections, conn.FailedConnections, timeseries.NanSum)
+		existing.BytesSent = merge(existing.BytesSent, conn.BytesSent, timeseries.NanSum)
+		existing.BytesReceived = merge(existing.BytesReceived, conn.BytesReceived, timeseries.NanSum)
+		existing.Retransmissions = merge(existing.Retransmissions, conn.Retransmissions, timeseries.NanSum)
+		for proto, latency := range conn.RequestsLatency {
+			existing.RequestsLatency[proto] = merge(existing.RequestsLatency[proto], latency, timeseries.Any)
+		}
+		for proto, byStatus := range conn.RequestsCount {
+			if existing.RequestsCount[proto] == nil {
+				existing.RequestsCount[proto] = map[string]*timeseries.TimeSeries{}
+			}
+			for status, count := range byStatus {
+				existing.RequestsCount[proto][status] = merge(existing.RequestsCount[proto][status], count, timeseries.NanSum)
+			}
+		}
+	}
+}
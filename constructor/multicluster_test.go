@@ -0,0 +1,74 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeWorlds(t *testing.T) {
+	newWorld := func() *model.World {
+		w := model.NewWorld(0, timeseries.Time(0).Add(timeseries.Hour), timeseries.Minute, timeseries.Minute)
+		w.CheckConfigs = model.CheckConfigs{}
+		return w
+	}
+	connect := func(app, dest *model.Application) {
+		conn := &model.AppToAppConnection{
+			Application:           app,
+			RemoteApplication:     dest,
+			SuccessfulConnections: timeseries.NewWithData(0, timeseries.Minute, []float32{1, 1}),
+			RequestsCount:         map[model.Protocol]map[string]*timeseries.TimeSeries{},
+			RequestsLatency:       map[model.Protocol]*timeseries.TimeSeries{},
+		}
+		app.Upstreams[dest.Id] = conn
+		dest.Downstreams[app.Id] = conn
+	}
+	service := func(app *model.Application, lbIPs ...string) {
+		s := &model.Service{
+			Name:            app.Id.Name,
+			Namespace:       app.Id.Namespace,
+			EndpointIPs:     utils.NewStringSet(),
+			LoadBalancerIPs: utils.NewStringSet(lbIPs...),
+			DestinationApps: map[model.ApplicationId]*model.Application{app.Id: app},
+		}
+		app.KubernetesServices = append(app.KubernetesServices, s)
+	}
+	external := func(w *model.World, name string, ips ...string) *model.Application {
+		app := w.GetOrCreateApplication(model.NewApplicationId("external", model.ApplicationKindExternalService, name), false)
+		for _, ip := range ips {
+			app.GetOrCreateInstance(ip, nil).TcpListens[model.Listen{IP: ip, Port: "80"}] = true
+		}
+		return app
+	}
+
+	eu := newWorld()
+	frontend := eu.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "frontend"), false)
+	connect(frontend, external(eu, "catalog.shop.svc.us.local:80"))
+	connect(frontend, external(eu, "cart.example.com:80", "10.1.0.5"))
+	connect(frontend, external(eu, "api.stripe.com:443", "1.2.3.4"))
+
+	us := newWorld()
+	catalog := us.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "catalog"), false)
+	service(catalog)
+	cart := us.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "cart"), false)
+	service(cart, "10.1.0.5")
+
+	w := newWorld()
+	mergeWorld(w, eu, Cluster{Project: &db.Project{Id: "c1", Name: "eu"}})
+	mergeWorld(w, us, Cluster{Project: &db.Project{Id: "c2", Name: "us"}})
+	resolveCrossClusterConnections(w)
+
+	assert.Equal(t, "c1@shop:Deployment:frontend", frontend.Id.String())
+	assert.Equal(t, "eu", frontend.Labels()["cluster"])
+	assert.Len(t, w.Applications, 4)
+	assert.Same(t, catalog, frontend.Upstreams[catalog.Id].RemoteApplication)
+	assert.Same(t, frontend, catalog.Downstreams[frontend.Id].Application)
+	assert.Same(t, cart, frontend.Upstreams[cart.Id].RemoteApplication)
+	assert.NotNil(t, w.GetApplication(model.ApplicationId{ClusterId: "c1", Namespace: "external", Kind: model.ApplicationKindExternalService, Name: "api.stripe.com:443"}))
+	assert.Same(t, catalog, catalog.KubernetesServices[0].GetDestinationApplication())
+	assert.NotNil(t, catalog.KubernetesServices[0].DestinationApps[catalog.Id])
+}
@@ -0,0 +1,36 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func nats(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Nats == nil {
+		instance.Nats = model.NewNats()
+	}
+	n := instance.Nats
+	switch queryName {
+	case "nats_connections":
+		n.Connections = merge(n.Connections, m.Values, timeseries.Any)
+		n.Up = merge(n.Up, presence(m.Values), timeseries.Any)
+	case "nats_slow_consumers":
+		n.SlowConsumers = merge(n.SlowConsumers, m.Values, timeseries.Any)
+	case "nats_in_msgs":
+		n.InMsgs = merge(n.InMsgs, m.Values, timeseries.Any)
+	case "nats_out_msgs":
+		n.OutMsgs = merge(n.OutMsgs, m.Values, timeseries.Any)
+	case "nats_stream_consumers":
+		stream := m.Labels["stream_name"]
+		n.StreamConsumers[stream] = merge(n.StreamConsumers[stream], m.Values, timeseries.Any)
+	case "nats_consumer_pending":
+		c := n.GetOrCreateConsumer(m.Labels["stream_name"], m.Labels["consumer_name"])
+		c.Pending = merge(c.Pending, m.Values, timeseries.Any)
+	case "nats_consumer_ack_pending":
+		c := n.GetOrCreateConsumer(m.Labels["stream_name"], m.Labels["consumer_name"])
+		c.AckPending = merge(c.AckPending, m.Values, timeseries.Any)
+	}
+}
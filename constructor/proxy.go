@@ -0,0 +1,130 @@
+package constructor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+func proxy(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Proxy == nil {
+		instance.Proxy = model.NewProxy()
+	}
+	p := instance.Proxy
+	switch queryName {
+	case "proxy_envoy_downstream_requests":
+		r := p.GetOrCreateRoute(m.Labels["envoy_http_conn_manager_prefix"])
+		proxyRequests(r, m, m.Labels["envoy_response_code_class"] == "5")
+	case "proxy_envoy_downstream_latency":
+		// Envoy measures the request time in milliseconds
+		proxyLatencyBucket(p.GetOrCreateRoute(m.Labels["envoy_http_conn_manager_prefix"]), m, 1000)
+	case "proxy_envoy_upstream_requests":
+		u := envoyUpstream(p, m.Labels["envoy_cluster_name"])
+		proxyRequests(u.ProxyStats, m, m.Labels["envoy_response_code_class"] == "5")
+	case "proxy_envoy_upstream_latency":
+		proxyLatencyBucket(envoyUpstream(p, m.Labels["envoy_cluster_name"]).ProxyStats, m, 1000)
+	case "proxy_envoy_upstream_connect_failures":
+		u := envoyUpstream(p, m.Labels["envoy_cluster_name"])
+		u.ConnectFailures = merge(u.ConnectFailures, m.Values, timeseries.NanSum)
+	case "proxy_envoy_upstream_overflows":
+		u := envoyUpstream(p, m.Labels["envoy_cluster_name"])
+		u.CircuitBreakerTrips = merge(u.CircuitBreakerTrips, m.Values, timeseries.NanSum)
+
+	case "proxy_nginx_requests", "proxy_nginx_latency":
+		route := m.Labels["host"]
+		if route == "" || route == "_" {
+			route = m.Labels["ingress"]
+		}
+		r := p.GetOrCreateRoute(route)
+		u := nginxUpstream(p, m.Labels)
+		if queryName == "proxy_nginx_latency" {
+			proxyLatencyBucket(r, m, 1)
+			proxyLatencyBucket(u.ProxyStats, m, 1)
+			return
+		}
+		failed := strings.HasPrefix(m.Labels["status"], "5")
+		proxyRequests(r, m, failed)
+		proxyRequests(u.ProxyStats, m, failed)
+
+	case "proxy_haproxy_frontend_requests":
+		r := p.GetOrCreateRoute(m.Labels["proxy"])
+		r.Requests = merge(r.Requests, m.Values, timeseries.NanSum)
+	case "proxy_haproxy_frontend_errors":
+		r := p.GetOrCreateRoute(m.Labels["proxy"])
+		r.Errors = merge(r.Errors, m.Values, timeseries.NanSum)
+	case "proxy_haproxy_backend_responses":
+		u := p.GetOrCreateUpstream(m.Labels["proxy"])
+		proxyRequests(u.ProxyStats, m, m.Labels["code"] == "5xx")
+	case "proxy_haproxy_backend_connection_errors":
+		u := p.GetOrCreateUpstream(m.Labels["proxy"])
+		u.ConnectFailures = merge(u.ConnectFailures, m.Values, timeseries.NanSum)
+	case "proxy_haproxy_backend_latency":
+		u := p.GetOrCreateUpstream(m.Labels["proxy"])
+		u.AvgLatency = merge(u.AvgLatency, m.Values, timeseries.Any)
+	}
+}
+
+func proxyRequests(s *model.ProxyStats, m *model.MetricValues, failed bool) {
+	s.Requests = merge(s.Requests, m.Values, timeseries.NanSum)
+	if failed {
+		s.Errors = merge(s.Errors, m.Values, timeseries.NanSum)
+	} else {
+		// a route without 5xx responses has zero errors rather than no data
+		zero := m.Values.Map(func(t timeseries.Time, v float32) float32 {
+			if timeseries.IsNaN(v) {
+				return v
+			}
+			return 0
+		})
+		s.Errors = merge(s.Errors, zero, timeseries.NanSum)
+	}
+}
+
+func proxyLatencyBucket(s *model.ProxyStats, m *model.MetricValues, divider float64) {
+	le, err := strconv.ParseFloat(m.Labels["le"], 32)
+	if err != nil {
+		klog.Warningln(err)
+		return
+	}
+	b := float32(le / divider)
+	s.LatencyHistogram[b] = merge(s.LatencyHistogram[b], m.Values, timeseries.NanSum)
+}
+
+// envoyUpstream returns the upstream for the Envoy cluster.
+// The clusters created by Istio are named as "outbound|<port>|<subset>|<service>.<namespace>.svc.<cluster domain>".
+func envoyUpstream(p *model.Proxy, cluster string) *model.ProxyUpstream {
+	u := p.GetOrCreateUpstream(cluster)
+	if u.ServiceName != "" {
+		return u
+	}
+	parts := strings.Split(cluster, "|")
+	if len(parts) != 4 || parts[0] != "outbound" {
+		return u
+	}
+	host := strings.Split(parts[3], ".")
+	if len(host) > 2 && host[2] == "svc" {
+		u.ServiceName, u.ServiceNamespace = host[0], host[1]
+	}
+	return u
+}
+
+func nginxUpstream(p *model.Proxy, ls model.Labels) *model.ProxyUpstream {
+	// the label is renamed by Prometheus if it conflicts with the namespace of the target
+	ns := ls["exported_namespace"]
+	if ns == "" {
+		ns = ls["namespace"]
+	}
+	name := ls["service"]
+	if ns != "" {
+		name = ns + "/" + name
+	}
+	u := p.GetOrCreateUpstream(name)
+	u.ServiceName, u.ServiceNamespace = ls["service"], ns
+	return u
+}
@@ -0,0 +1,71 @@
+package constructor
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(timeseries.Hour), timeseries.Minute, timeseries.Minute)
+	envoy := w.GetOrCreateApplication(model.NewApplicationId("gw", model.ApplicationKindDeployment, "envoy"), false).
+		GetOrCreateInstance("envoy-1", nil)
+	envoy.TcpListens[model.Listen{IP: "10.0.0.1", Port: "9901"}] = true
+	ingress := w.GetOrCreateApplication(model.NewApplicationId("ingress-nginx", model.ApplicationKindDeployment, "ingress-nginx-controller"), false).
+		GetOrCreateInstance("ingress-nginx-controller-1", nil)
+	ingress.TcpListens[model.Listen{IP: "10.0.0.2", Port: "10254"}] = true
+
+	envoyLabels := func(ls model.Labels) model.Labels {
+		ls["instance"] = "10.0.0.1:9901"
+		return ls
+	}
+	nginxLabels := func(ls model.Labels) model.Labels {
+		ls["instance"] = "10.0.0.2:10254"
+		ls["host"] = "shop.example.com"
+		ls["service"] = "frontend"
+		ls["exported_namespace"] = "shop"
+		return ls
+	}
+	cluster := "outbound|8080||orders.shop.svc.cluster.local"
+	metrics := map[string][]*model.MetricValues{
+		"proxy_envoy_upstream_requests": {
+			{Labels: envoyLabels(model.Labels{"envoy_cluster_name": cluster, "envoy_response_code_class": "2"}), Values: values(90, 90)},
+			{Labels: envoyLabels(model.Labels{"envoy_cluster_name": cluster, "envoy_response_code_class": "5"}), Values: values(10, 30)},
+		},
+		"proxy_envoy_upstream_latency": {
+			{Labels: envoyLabels(model.Labels{"envoy_cluster_name": cluster, "le": "100"}), Values: values(100, 60)},
+			{Labels: envoyLabels(model.Labels{"envoy_cluster_name": cluster, "le": "+Inf"}), Values: values(100, 120)},
+		},
+		"proxy_envoy_upstream_overflows": {
+			{Labels: envoyLabels(model.Labels{"envoy_cluster_name": cluster}), Values: values(0, 1)},
+		},
+		"proxy_nginx_requests": {
+			{Labels: nginxLabels(model.Labels{"status": "200"}), Values: values(5, 5)},
+			{Labels: nginxLabels(model.Labels{"status": "404"}), Values: values(1, 1)},
+		},
+	}
+	enrichInstances(w, metrics, nil, nil)
+
+	require.NotNil(t, envoy.Proxy)
+	u := envoy.Proxy.Upstreams[cluster]
+	require.NotNil(t, u)
+	assert.Equal(t, "orders", u.ServiceName)
+	assert.Equal(t, "shop", u.ServiceNamespace)
+	assert.Equal(t, float32(25), u.ErrorsPercent().Last())
+	assert.Equal(t, float32(0.1), u.Latency(0.5).Last())
+	assert.Equal(t, float32(0.1), u.Latency(0.95).Last())
+	assert.Equal(t, float32(1), u.CircuitBreakerTrips.Last())
+
+	require.NotNil(t, ingress.Proxy)
+	r := ingress.Proxy.Routes["shop.example.com"]
+	require.NotNil(t, r)
+	assert.Equal(t, float32(6), r.Requests.Last())
+	assert.Equal(t, float32(0), r.ErrorsPercent().Last())
+	fe := ingress.Proxy.Upstreams["shop/frontend"]
+	require.NotNil(t, fe)
+	assert.Equal(t, "frontend", fe.ServiceName)
+	assert.Equal(t, float32(6), fe.Requests.Last())
+}
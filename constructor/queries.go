@@ -58,10 +58,26 @@ var (
 	possibleDBInstanceLabels = []string{"address", "instance", "rds_instance_id", "ec_instance_id"}
 )
 
+// Rollup defines how the points of a query are aggregated when a coarse step is read from the compacted chunks.
+type Rollup uint8
+
+const (
+	// RollupMax keeps the peak of every bucket. It's the default for gauges and rates,
+	// so that short spikes (e.g., CPU usage or throttling) are not averaged away.
+	RollupMax Rollup = iota
+	// RollupAvg is used for the throughput series combined into ratios or sums (requests, histograms).
+	RollupAvg
+	// RollupMin is used for the series where a drop is the problem (e.g., available memory or disk space).
+	RollupMin
+	// RollupSum is used for the series counting events per step.
+	RollupSum
+)
+
 type Query struct {
 	Name   string
 	Query  string
 	Labels *utils.StringSet
+	Rollup Rollup
 
 	InstanceToInstance bool
 }
@@ -69,12 +85,22 @@ type Query struct {
 func Q(name, query string, labels ...string) Query {
 	ls := utils.NewStringSet(model.LabelMachineId, model.LabelSystemUuid, model.LabelContainerId, model.LabelDestination, model.LabelDestinationIP, model.LabelActualDestination)
 	ls.Add(labels...)
-	return Query{Name: name, Query: query, Labels: ls}
+	q := Query{Name: name, Query: query, Labels: ls}
+	if ls.Has("le") {
+		q.Rollup = RollupAvg
+	}
+	return q
 }
 
 func qItoI(name, query string, labels ...string) Query {
 	q := Q(name, query, append(labels, "app_id")...)
 	q.InstanceToInstance = true
+	q.Rollup = RollupAvg
+	return q
+}
+
+func (q Query) withRollup(r Rollup) Query {
+	q.Rollup = r
 	return q
 }
 
@@ -86,6 +112,18 @@ func qRDS(name, query string, labels ...string) Query {
 	return Q(name, query, slices.Concat([]string{"rds_instance_id"}, labels)...)
 }
 
+func qCloudSQL(name, query string, labels ...string) Query {
+	return Q(name, query, slices.Concat([]string{"database_id", "region"}, labels)...)
+}
+
+func qMemorystore(name, query string, labels ...string) Query {
+	return Q(name, query, slices.Concat([]string{"instance_id", "node_id", "region"}, labels)...)
+}
+
+func qAzureDatabase(name, query string, labels ...string) Query {
+	return Q(name, query, slices.Concat([]string{"resourceID"}, labels)...)
+}
+
 func qDB(name, query string, labels ...string) Query {
 	return Q(name, query, slices.Concat(possibleDBInstanceLabels, possibleNamespaceLabels, possiblePodLabels, labels)...)
 }
@@ -130,8 +168,8 @@ var QUERIES = []Query{
 	Q("node_cpu_usage_percent", `sum(rate(node_resources_cpu_usage_seconds_total{mode!="idle"}[$RANGE])) without(mode) /sum(rate(node_resources_cpu_usage_seconds_total[$RANGE])) without(mode)*100`),
 	Q("node_cpu_usage_by_mode", `rate(node_resources_cpu_usage_seconds_total{mode!="idle"}[$RANGE]) / ignoring(mode) group_left sum(rate(node_resources_cpu_usage_seconds_total[$RANGE])) without(mode)*100`, "mode"),
 	Q("node_memory_total_bytes", `node_resources_memory_total_bytes`),
-	Q("node_memory_available_bytes", `node_resources_memory_available_bytes`),
-	Q("node_memory_free_bytes", `node_resources_memory_free_bytes`),
+	Q("node_memory_available_bytes", `node_resources_memory_available_bytes`).withRollup(RollupMin),
+	Q("node_memory_free_bytes", `node_resources_memory_free_bytes`).withRollup(RollupMin),
 	Q("node_memory_cached_bytes", `node_resources_memory_cached_bytes`),
 	Q("node_disk_read_time", `rate(node_resources_disk_read_time_seconds_total[$RANGE])`, "device"),
 	Q("node_disk_write_time", `rate(node_resources_disk_write_time_seconds_total[$RANGE])`, "device"),
@@ -179,6 +217,18 @@ var QUERIES = []Query{
 	Q("kube_statefulset_annotations", `kube_statefulset_annotations`, append(applicationAnnotations, "namespace", "statefulset")...),
 	Q("kube_daemonset_annotations", `kube_daemonset_annotations`, append(applicationAnnotations, "namespace", "daemonset")...),
 	Q("kube_cronjob_annotations", `kube_cronjob_annotations`, append(applicationAnnotations, "namespace", "cronjob")...),
+	Q("kube_cronjob_info", `kube_cronjob_info`, "namespace", "cronjob", "schedule", "concurrency_policy"),
+	Q("kube_cronjob_spec_suspend", `kube_cronjob_spec_suspend`, "namespace", "cronjob"),
+	Q("kube_cronjob_status_active", `kube_cronjob_status_active`, "namespace", "cronjob"),
+	Q("kube_cronjob_status_last_schedule_time", `timestamp(kube_cronjob_status_last_schedule_time) - kube_cronjob_status_last_schedule_time`, "namespace", "cronjob"),
+	Q("kube_cronjob_status_last_successful_time", `timestamp(kube_cronjob_status_last_successful_time) - kube_cronjob_status_last_successful_time`, "namespace", "cronjob"),
+	Q("kube_cronjob_next_schedule_time", `timestamp(kube_cronjob_next_schedule_time) - kube_cronjob_next_schedule_time`, "namespace", "cronjob"),
+	Q("kube_job_status_start_time", `timestamp(kube_job_status_start_time) - kube_job_status_start_time`, "namespace", "job_name"),
+	Q("kube_job_status_completion_time", `timestamp(kube_job_status_completion_time) - kube_job_status_completion_time`, "namespace", "job_name"),
+	Q("kube_job_status_active", `kube_job_status_active`, "namespace", "job_name"),
+	Q("kube_job_status_failed", `kube_job_status_failed`, "namespace", "job_name", "reason"),
+	Q("kube_job_complete", `kube_job_complete{condition="true"}`, "namespace", "job_name"),
+	Q("kube_job_failed", `kube_job_failed{condition="true"}`, "namespace", "job_name"),
 
 	qPod("kube_pod_info", `kube_pod_info`, "namespace", "pod", "created_by_name", "created_by_kind", "node", "pod_ip", "host_ip"),
 	qPod("kube_pod_annotations", hasNotEmptyLabel("kube_pod_annotations", applicationAnnotations), applicationAnnotations...),
@@ -286,7 +336,7 @@ var QUERIES = []Query{
 	qRDS("aws_rds_cpu_usage_percent", `aws_rds_cpu_usage_percent`, "mode"),
 	qRDS("aws_rds_memory_total_bytes", `aws_rds_memory_total_bytes`),
 	qRDS("aws_rds_memory_cached_bytes", `aws_rds_memory_cached_bytes`),
-	qRDS("aws_rds_memory_free_bytes", `aws_rds_memory_free_bytes`),
+	qRDS("aws_rds_memory_free_bytes", `aws_rds_memory_free_bytes`).withRollup(RollupMin),
 	qRDS("aws_rds_storage_provisioned_iops", `aws_rds_storage_provisioned_iops`),
 	qRDS("aws_rds_allocated_storage_gibibytes", `aws_rds_allocated_storage_gibibytes`),
 	qRDS("aws_rds_fs_total_bytes", `aws_rds_fs_total_bytes{mount_point="/rdsdbdata"}`),
@@ -301,6 +351,34 @@ var QUERIES = []Query{
 	Q("aws_elasticache_info", `aws_elasticache_info`, "ec_instance_id", "cluster_id", "ipv4", "port", "engine", "engine_version", "instance_type", "region", "availability_zone"),
 	Q("aws_elasticache_status", `aws_elasticache_status`, "ec_instance_id", "status"),
 
+	qCloudSQL("gcp_cloudsql_up", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_up)`),
+	qCloudSQL("gcp_cloudsql_state", `max by(database_id, region, state) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_instance_state) > 0`, "state"),
+	qCloudSQL("gcp_cloudsql_cpu_cores", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_cpu_reserved_cores)`),
+	qCloudSQL("gcp_cloudsql_cpu_usage_percent", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_cpu_utilization) * 100`),
+	qCloudSQL("gcp_cloudsql_memory_total_bytes", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_memory_quota)`),
+	qCloudSQL("gcp_cloudsql_memory_usage_bytes", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_memory_usage)`),
+	qCloudSQL("gcp_cloudsql_disk_total_bytes", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_disk_quota)`),
+	qCloudSQL("gcp_cloudsql_disk_used_bytes", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_disk_bytes_used)`),
+	qCloudSQL("gcp_cloudsql_replication_lag_seconds", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_replication_replica_lag)`),
+	qCloudSQL("gcp_cloudsql_postgres_connections", `sum by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_postgresql_num_backends)`),
+	qCloudSQL("gcp_cloudsql_mysql_connections", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_network_connections)`),
+	qCloudSQL("gcp_cloudsql_mysql_max_connections", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_mysql_max_connections)`),
+	qCloudSQL("gcp_cloudsql_mysql_queries", `max by(database_id, region) (stackdriver_cloudsql_database_cloudsql_googleapis_com_database_mysql_queries) / 60`),
+
+	qMemorystore("gcp_memorystore_uptime", `max by(instance_id, node_id, region) (stackdriver_redis_instance_redis_googleapis_com_server_uptime)`),
+	qMemorystore("gcp_memorystore_role", `max by(instance_id, node_id, region) (stackdriver_redis_instance_redis_googleapis_com_replication_role)`),
+	qMemorystore("gcp_memorystore_memory_max_bytes", `max by(instance_id, node_id, region) (stackdriver_redis_instance_redis_googleapis_com_stats_memory_maxmemory)`),
+	qMemorystore("gcp_memorystore_memory_usage_bytes", `max by(instance_id, node_id, region) (stackdriver_redis_instance_redis_googleapis_com_stats_memory_usage)`),
+	qMemorystore("gcp_memorystore_calls", `sum by(instance_id, node_id, region, cmd) (stackdriver_redis_instance_redis_googleapis_com_commands_calls) / 60`, "cmd"),
+	qMemorystore("gcp_memorystore_calls_time", `sum by(instance_id, node_id, region, cmd) (stackdriver_redis_instance_redis_googleapis_com_commands_total_time) / 1e6`, "cmd"),
+
+	qAzureDatabase("azure_database_alive", `max by(resourceID) (azurerm_resource_metric{resourceID=~"(?i).*/providers/microsoft.dbfor(postgresql|mysql)/.*", metric="is_db_alive"})`),
+	qAzureDatabase("azure_database_cpu_usage_percent", `max by(resourceID) (azurerm_resource_metric{resourceID=~"(?i).*/providers/microsoft.dbfor(postgresql|mysql)/.*", metric="cpu_percent"})`),
+	qAzureDatabase("azure_database_storage_limit_bytes", `max by(resourceID) (azurerm_resource_metric{resourceID=~"(?i).*/providers/microsoft.dbfor(postgresql|mysql)/.*", metric="storage_limit"})`),
+	qAzureDatabase("azure_database_storage_used_bytes", `max by(resourceID) (azurerm_resource_metric{resourceID=~"(?i).*/providers/microsoft.dbfor(postgresql|mysql)/.*", metric="storage_used"})`),
+	qAzureDatabase("azure_database_connections", `max by(resourceID) (azurerm_resource_metric{resourceID=~"(?i).*/providers/microsoft.dbfor(postgresql|mysql)/.*", metric="active_connections"})`),
+	qAzureDatabase("azure_database_replication_lag_seconds", `max by(resourceID) (azurerm_resource_metric{resourceID=~"(?i).*/providers/microsoft.dbfor(postgresql|mysql)/.*", metric="physical_replication_delay_in_seconds"} or azurerm_resource_metric{resourceID=~"(?i).*/providers/microsoft.dbfor(postgresql|mysql)/.*", metric="replication_lag"})`),
+
 	qDB("pg_up", `pg_up`),
 	qDB("pg_scrape_error", `pg_scrape_error`, "error", "warning"),
 	qDB("pg_info", `pg_info`, "server_version"),
@@ -353,6 +431,54 @@ var QUERIES = []Query{
 	qDB("memcached_items_evicted_total", `rate(memcached_items_evicted_total[$RANGE])`),
 	qDB("memcached_commands_total", `rate(memcached_commands_total[$RANGE])`, "command", "status"),
 
+	qDB("kafka_brokers", `kafka_brokers`),
+	qDB("kafka_broker_info", `kafka_broker_info`, "id"),
+	qDB("kafka_topic_partition_under_replicated", `sum by(instance) (kafka_topic_partition_under_replicated_partition)`),
+	qDB("kafka_topic_partition_offline", `count by(instance) (kafka_topic_partition_leader < 0)`),
+	qDB("kafka_consumergroup_lag", `sum by(instance, consumergroup, topic) (kafka_consumergroup_lag)`, "consumergroup", "topic"),
+	qDB("kafka_consumergroup_members", `kafka_consumergroup_members`, "consumergroup"),
+	qDB("kafka_server_under_replicated_partitions", `kafka_server_replicamanager_underreplicatedpartitions`),
+	qDB("kafka_server_offline_partitions", `kafka_controller_kafkacontroller_offlinepartitionscount`),
+	qDB("kafka_server_isr_shrinks", `rate(kafka_server_replicamanager_isrshrinks_total[$RANGE])`),
+	qDB("kafka_server_active_controller", `kafka_controller_kafkacontroller_activecontrollercount`),
+
+	qDB("rabbitmq_identity_info", `rabbitmq_identity_info`, "rabbitmq_node"),
+	qDB("rabbitmq_build_info", `rabbitmq_build_info`, "rabbitmq_version"),
+	qDB("rabbitmq_alarms_memory_used_watermark", `rabbitmq_alarms_memory_used_watermark`),
+	qDB("rabbitmq_alarms_free_disk_space_watermark", `rabbitmq_alarms_free_disk_space_watermark`),
+	qDB("rabbitmq_messages_published", `sum without(protocol) (rate(rabbitmq_global_messages_received_total[$RANGE]))`),
+	qDB("rabbitmq_messages_delivered", `sum without(protocol) (rate(rabbitmq_global_messages_delivered_total[$RANGE]))`),
+	qDB("rabbitmq_queue_messages_ready", `rabbitmq_queue_messages_ready`, "vhost", "queue"),
+	qDB("rabbitmq_queue_messages_unacked", `rabbitmq_queue_messages_unacked`, "vhost", "queue"),
+	qDB("rabbitmq_queue_consumers", `rabbitmq_queue_consumers`, "vhost", "queue"),
+
+	qDB("nats_connections", `gnatsd_varz_connections`),
+	qDB("nats_slow_consumers", `rate(gnatsd_varz_slow_consumers[$RANGE])`),
+	qDB("nats_in_msgs", `rate(gnatsd_varz_in_msgs[$RANGE])`),
+	qDB("nats_out_msgs", `rate(gnatsd_varz_out_msgs[$RANGE])`),
+	qDB("nats_stream_consumers", `max without(is_stream_leader, is_stream_follower) (jetstream_stream_consumer_count)`, "stream_name"),
+	qDB("nats_consumer_pending", `max without(is_consumer_leader, is_consumer_follower) (jetstream_consumer_num_pending)`, "stream_name", "consumer_name"),
+	qDB("nats_consumer_ack_pending", `max without(is_consumer_leader, is_consumer_follower) (jetstream_consumer_num_ack_pending)`, "stream_name", "consumer_name"),
+
+	qDB("es_cluster_health_status", `elasticsearch_cluster_health_status == 1`, "cluster", "color"),
+	qDB("es_cluster_nodes", `elasticsearch_cluster_health_number_of_nodes`, "cluster"),
+	qDB("es_cluster_unassigned_shards", `elasticsearch_cluster_health_unassigned_shards`, "cluster"),
+	qDB("es_cluster_version", `elasticsearch_clusterinfo_version_info`, "cluster", "version"),
+	qDB("es_node_heap_used", `elasticsearch_jvm_memory_used_bytes{area="heap"}`, "cluster", "name", "host"),
+	qDB("es_node_heap_max", `elasticsearch_jvm_memory_max_bytes{area="heap"}`, "cluster", "name", "host"),
+	qDB("es_node_search_time", `rate(elasticsearch_indices_search_query_time_seconds[$RANGE])`, "cluster", "name", "host"),
+	qDB("es_node_searches", `rate(elasticsearch_indices_search_query_total[$RANGE])`, "cluster", "name", "host"),
+	qDB("es_node_indexing_time", `rate(elasticsearch_indices_indexing_index_time_seconds_total[$RANGE])`, "cluster", "name", "host"),
+	qDB("es_node_indexed_docs", `rate(elasticsearch_indices_indexing_index_total[$RANGE])`, "cluster", "name", "host"),
+	qDB("es_node_rejected_tasks", `rate(elasticsearch_thread_pool_rejected_count[$RANGE])`, "cluster", "name", "host", "type"),
+
+	qDB("cassandra_read_latency", `cassandra_keyspace_read_latency_seconds{quantile="0.99"}`, "keyspace"),
+	qDB("cassandra_write_latency", `cassandra_keyspace_write_latency_seconds{quantile="0.99"}`, "keyspace"),
+	qDB("cassandra_pending_compactions", `cassandra_compaction_pending_tasks`),
+	qDB("cassandra_dropped_messages", `rate(cassandra_dropped_messages_total[$RANGE])`, "message_type"),
+	qDB("cassandra_hints", `rate(cassandra_storage_hints_total[$RANGE])`),
+	qDB("cassandra_endpoint_active", `cassandra_endpoint_active`, "endpoint"),
+
 	qJVM("container_jvm_info", `container_jvm_info`, "java_version"),
 	qJVM("container_jvm_heap_size_bytes", `container_jvm_heap_size_bytes`),
 	qJVM("container_jvm_heap_used_bytes", `container_jvm_heap_used_bytes`),
@@ -371,6 +497,56 @@ var QUERIES = []Query{
 	qDotNet("container_dotnet_thread_pool_queue_length", `container_dotnet_thread_pool_queue_length`),
 	qDotNet("container_dotnet_thread_pool_size", `container_dotnet_thread_pool_size`),
 
+	qDB("clickhouse_queries_running", `ClickHouseMetrics_Query`),
+	qDB("clickhouse_max_parts_per_partition", `ClickHouseAsyncMetrics_MaxPartCountForPartition`),
+	qDB("clickhouse_merges_running", `ClickHouseMetrics_BackgroundMergesAndMutationsPoolTask`),
+	qDB("clickhouse_merges_pool_size", `ClickHouseMetrics_BackgroundMergesAndMutationsPoolSize`),
+	qDB("clickhouse_replication_queue_size", `ClickHouseAsyncMetrics_ReplicasMaxQueueSize`),
+	qDB("clickhouse_replication_delay", `ClickHouseAsyncMetrics_ReplicasMaxAbsoluteDelay`),
+	qDB("clickhouse_readonly_replicas", `ClickHouseMetrics_ReadonlyReplica`),
+	qDB("clickhouse_keeper_errors", `rate(ClickHouseProfileEvents_ZooKeeperHardwareExceptions[$RANGE])`),
+	qDB("clickhouse_queries", `rate(ClickHouseProfileEvents_Query[$RANGE])`),
+	qDB("clickhouse_failed_queries", `rate(ClickHouseProfileEvents_FailedQuery[$RANGE])`),
+	qDB("clickhouse_memory_limit_exceeded", `rate(ClickHouseProfileEvents_QueryMemoryLimitExceeded[$RANGE])`),
+	qDB("clickhouse_disk_available", `label_replace({__name__=~"ClickHouseAsyncMetrics_DiskAvailable_.+"}, "disk", "$1", "__name__", "ClickHouseAsyncMetrics_DiskAvailable_(.+)")`, "disk").withRollup(RollupMin),
+	qDB("clickhouse_disk_total", `label_replace({__name__=~"ClickHouseAsyncMetrics_DiskTotal_.+"}, "disk", "$1", "__name__", "ClickHouseAsyncMetrics_DiskTotal_(.+)")`, "disk"),
+
+	qDB("golang_info", `go_info`, "version"),
+	qDB("golang_goroutines", `go_goroutines`),
+	qDB("golang_gc_pause_time", `rate(go_gc_duration_seconds_sum[$RANGE])`),
+	qDB("golang_gc_cpu_fraction", `rate(go_cpu_classes_gc_total_cpu_seconds_total[$RANGE]) / rate(go_cpu_classes_total_cpu_seconds_total[$RANGE]) or go_memstats_gc_cpu_fraction`),
+	qDB("golang_heap_inuse", `go_memstats_heap_inuse_bytes`),
+	qDB("golang_memory_used", `go_memstats_sys_bytes - go_memstats_heap_released_bytes`),
+	qDB("golang_memory_limit", `go_gc_gomemlimit_bytes`),
+	qDB("golang_sched_latency", `histogram_quantile(0.99, rate(go_sched_latencies_seconds_bucket[$RANGE]))`),
+
+	qDB("proxy_envoy_downstream_requests", `rate(envoy_http_downstream_rq_xx{envoy_http_conn_manager_prefix!="admin"}[$RANGE])`, "envoy_http_conn_manager_prefix", "envoy_response_code_class"),
+	qDB("proxy_envoy_downstream_latency", `rate(envoy_http_downstream_rq_time_bucket{envoy_http_conn_manager_prefix!="admin"}[$RANGE])`, "envoy_http_conn_manager_prefix", "le"),
+	qDB("proxy_envoy_upstream_requests", `rate(envoy_cluster_upstream_rq_xx[$RANGE])`, "envoy_cluster_name", "envoy_response_code_class"),
+	qDB("proxy_envoy_upstream_latency", `rate(envoy_cluster_upstream_rq_time_bucket[$RANGE])`, "envoy_cluster_name", "le"),
+	qDB("proxy_envoy_upstream_connect_failures", `rate(envoy_cluster_upstream_cx_connect_fail[$RANGE])`, "envoy_cluster_name"),
+	qDB("proxy_envoy_upstream_overflows", `rate(envoy_cluster_upstream_rq_pending_overflow[$RANGE]) + rate(envoy_cluster_upstream_cx_overflow[$RANGE])`, "envoy_cluster_name"),
+	qDB("proxy_nginx_requests", `sum without(path, method, canary, controller_class, controller_namespace, controller_pod) (rate(nginx_ingress_controller_requests[$RANGE]))`, "ingress", "host", "service", "exported_namespace", "status"),
+	qDB("proxy_nginx_latency", `sum without(path, method, canary, controller_class, controller_namespace, controller_pod, status) (rate(nginx_ingress_controller_request_duration_seconds_bucket[$RANGE]))`, "ingress", "host", "service", "exported_namespace", "le"),
+	qDB("proxy_haproxy_frontend_requests", `rate(haproxy_frontend_http_requests_total[$RANGE])`, "proxy"),
+	qDB("proxy_haproxy_frontend_errors", `rate(haproxy_frontend_http_responses_total{code="5xx"}[$RANGE])`, "proxy"),
+	qDB("proxy_haproxy_backend_responses", `rate(haproxy_backend_http_responses_total[$RANGE])`, "proxy", "code"),
+	qDB("proxy_haproxy_backend_connection_errors", `rate(haproxy_backend_connection_errors_total[$RANGE])`, "proxy"),
+	qDB("proxy_haproxy_backend_latency", `haproxy_backend_response_time_average_seconds`, "proxy"),
+
+	Q("control_plane_apiserver_requests", `sum by(instance) (rate(apiserver_request_total[$RANGE]))`, "instance"),
+	Q("control_plane_apiserver_errors", `sum by(instance) (rate(apiserver_request_total{code=~"5.."}[$RANGE]))`, "instance"),
+	Q("control_plane_apiserver_latency", `histogram_quantile(0.99, sum by(instance, le) (rate(apiserver_request_duration_seconds_bucket{verb!~"WATCH|CONNECT"}[$RANGE])))`, "instance"),
+	Q("control_plane_etcd_has_leader", `etcd_server_has_leader`, "instance"),
+	Q("control_plane_etcd_leader_changes", `rate(etcd_server_leader_changes_seen_total[$RANGE])`, "instance"),
+	Q("control_plane_etcd_wal_fsync_latency", `histogram_quantile(0.99, sum by(instance, le) (rate(etcd_disk_wal_fsync_duration_seconds_bucket[$RANGE])))`, "instance"),
+	Q("control_plane_etcd_commit_latency", `histogram_quantile(0.99, sum by(instance, le) (rate(etcd_disk_backend_commit_duration_seconds_bucket[$RANGE])))`, "instance"),
+	Q("control_plane_scheduler_pending_pods", `scheduler_pending_pods`, "instance", "queue"),
+	Q("control_plane_kubelet_pleg_relist_latency", `histogram_quantile(0.99, sum by(instance, node, le) (rate(kubelet_pleg_relist_duration_seconds_bucket[$RANGE])))`, "instance", "node"),
+	Q("control_plane_coredns_requests", `sum by(instance) (rate(coredns_dns_requests_total[$RANGE]))`, "instance"),
+	Q("control_plane_coredns_servfail", `sum by(instance) (rate(coredns_dns_responses_total{rcode="SERVFAIL"}[$RANGE]))`, "instance"),
+	Q("control_plane_coredns_latency", `histogram_quantile(0.99, sum by(instance, le) (rate(coredns_dns_request_duration_seconds_bucket[$RANGE])))`, "instance"),
+
 	Q("container_python_thread_lock_wait_time_seconds", `rate(container_python_thread_lock_wait_time_seconds[$RANGE])`),
 	Q("container_nodejs_event_loop_blocked_time_seconds", `rate(container_nodejs_event_loop_blocked_time_seconds_total[$RANGE])`),
 
@@ -392,6 +568,28 @@ var QUERIES = []Query{
 	qPod("fluxcd_resourceset_status", `fluxcd_resourceset_status`, "name", "namespace", "type", "reason"),
 	qPod("fluxcd_resourceset_dependency_info", `fluxcd_resourceset_dependency_info`, "name", "namespace", "depends_on_name", "depends_on_namespace", "depends_on_kind"),
 	qPod("fluxcd_resourceset_inventory_entry_info", `fluxcd_resourceset_inventory_entry_info`, "name", "namespace", "entry_id"),
+
+	Q("argocd_app_info", `argocd_app_info`, "name", "namespace", "project", "repo", "dest_server", "dest_namespace", "sync_status", "health_status", "revision"),
+	Q("argocd_app_syncs", `sum by(name, namespace, phase) (argocd_app_sync_total)`, "name", "namespace", "phase"),
+	Q("argocd_appset_info", `argocd_appset_info`, "name", "namespace", "resource_update_status"),
+	Q("argocd_appset_owned_applications", `argocd_appset_owned_applications`, "name", "namespace"),
+}
+
+var queryRollups = func() map[string]Rollup {
+	res := map[string]Rollup{qRecordingRuleApplicationLogMessages: RollupSum}
+	for _, q := range QUERIES {
+		res[q.Query] = q.Rollup
+	}
+	return res
+}()
+
+// QueryRollup returns the rollup of the query.
+// The recording rules and custom SLI queries produce throughput series, so they are averaged by default.
+func QueryRollup(query string) Rollup {
+	if r, ok := queryRollups[query]; ok {
+		return r
+	}
+	return RollupAvg
 }
 
 var RecordingRules = map[string]func(db *db.DB, p *db.Project, w *model.World) []*model.MetricValues{
@@ -0,0 +1,40 @@
+package constructor
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+func rabbitmq(instance *model.Instance, queryName string, m *model.MetricValues) {
+	if instance == nil {
+		return
+	}
+	if instance.Rabbitmq == nil {
+		instance.Rabbitmq = model.NewRabbitmq()
+	}
+	r := instance.Rabbitmq
+	switch queryName {
+	case "rabbitmq_identity_info":
+		r.Up = merge(r.Up, m.Values, timeseries.Any)
+		r.Node.Update(m.Values, m.Labels["rabbitmq_node"])
+	case "rabbitmq_build_info":
+		r.Version.Update(m.Values, m.Labels["rabbitmq_version"])
+	case "rabbitmq_alarms_memory_used_watermark":
+		r.MemoryAlarm = merge(r.MemoryAlarm, m.Values, timeseries.Any)
+	case "rabbitmq_alarms_free_disk_space_watermark":
+		r.DiskAlarm = merge(r.DiskAlarm, m.Values, timeseries.Any)
+	case "rabbitmq_messages_published":
+		r.MessagesPublished = merge(r.MessagesPublished, m.Values, timeseries.NanSum)
+	case "rabbitmq_messages_delivered":
+		r.MessagesDelivered = merge(r.MessagesDelivered, m.Values, timeseries.NanSum)
+	case "rabbitmq_queue_messages_ready":
+		q := r.GetOrCreateQueue(m.Labels["vhost"], m.Labels["queue"])
+		q.Ready = merge(q.Ready, m.Values, timeseries.Any)
+	case "rabbitmq_queue_messages_unacked":
+		q := r.GetOrCreateQueue(m.Labels["vhost"], m.Labels["queue"])
+		q.Unacked = merge(q.Unacked, m.Values, timeseries.Any)
+	case "rabbitmq_queue_consumers":
+		q := r.GetOrCreateQueue(m.Labels["vhost"], m.Labels["queue"])
+		q.Consumers = merge(q.Consumers, m.Values, timeseries.Any)
+	}
+}
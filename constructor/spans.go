@@ -0,0 +1,278 @@
+package constructor
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// Traces provides the data of the OpenTelemetry services stored in ClickHouse.
+type Traces interface {
+	GetServicesFromTraces(ctx context.Context, from timeseries.Time) ([]string, error)
+	GetServerSpansSLIs(ctx context.Context, from, to timeseries.Time, step timeseries.Duration, services []string, route string) (map[string][]model.HistogramBucket, error)
+}
+
+// WithTraces enables the SLIs calculated from the server spans: for the services instrumented only with
+// OpenTelemetry SDKs, and for the applications whose SLO configs refer to the spans.
+func (c *Constructor) WithTraces(traces Traces) *Constructor {
+	c.traces = traces
+	return c
+}
+
+// loadOTelServices adds the services known only from their spans to the world and returns all the OpenTelemetry services.
+func (c *Constructor) loadOTelServices(ctx context.Context, w *model.World) []string {
+	if c.traces == nil {
+		return nil
+	}
+	all, err := c.traces.GetServicesFromTraces(ctx, w.Ctx.From)
+	if err != nil {
+		klog.Warningln("failed to get services from traces:", err)
+		return nil
+	}
+	var services []string
+	for _, s := range all {
+		if !strings.HasPrefix(s, "/") { // the spans produced by the eBPF tracer
+			services = append(services, s)
+		}
+	}
+	for _, s := range model.UnclaimedServices(services, w) {
+		app := w.GetOrCreateApplication(model.NewApplicationId("", model.ApplicationKindOTelService, s), false)
+		app.Category = c.project.CalcApplicationCategory(app.Id)
+	}
+	return services
+}
+
+type spanSLI struct {
+	service, route string
+}
+
+type spanSLIs struct {
+	availability *spanSLI
+	latency      *spanSLI
+}
+
+func (c *Constructor) loadSpanSLIs(ctx context.Context, w *model.World, services []string) {
+	if c.traces == nil {
+		return
+	}
+	byApp := map[*model.Application]spanSLIs{}
+	queries := map[string]map[string]bool{} // route -> services
+	addQuery := func(sli *spanSLI) {
+		if queries[sli.route] == nil {
+			queries[sli.route] = map[string]bool{}
+		}
+		queries[sli.route][sli.service] = true
+	}
+	for _, app := range w.Applications {
+		availabilityCfg, _ := w.CheckConfigs.GetAvailability(app.Id)
+		latencyCfg, _ := w.CheckConfigs.GetLatency(app.Id, app.Category)
+		var slis spanSLIs
+		switch {
+		case availabilityCfg.FromSpans():
+			slis.availability = spanSLIFromConfig(availabilityCfg.Spans, w, app, services)
+		case app.Id.Kind == model.ApplicationKindOTelService && !availabilityCfg.Custom:
+			slis.availability = &spanSLI{service: app.Id.Name}
+		}
+		switch {
+		case latencyCfg.FromSpans():
+			slis.latency = spanSLIFromConfig(latencyCfg.Spans, w, app, services)
+		case app.Id.Kind == model.ApplicationKindOTelService && !latencyCfg.Custom:
+			slis.latency = &spanSLI{service: app.Id.Name}
+		}
+		if slis.availability != nil {
+			addQuery(slis.availability)
+		}
+		if slis.latency != nil {
+			addQuery(slis.latency)
+		}
+		if slis.availability != nil || slis.latency != nil {
+			byApp[app] = slis
+		}
+	}
+	if len(queries) == 0 {
+		return
+	}
+
+	from, to, step := w.Ctx.From.Truncate(w.Ctx.Step), w.Ctx.To.Truncate(w.Ctx.Step), w.Ctx.Step
+	rawFrom := w.Ctx.From
+	if t := w.Ctx.To.Add(-model.MaxAlertRuleWindow); t.Before(rawFrom) {
+		rawFrom = t
+	}
+	rawFrom, rawTo, rawStep := rawFrom.Truncate(w.Ctx.RawStep), w.Ctx.To.Truncate(w.Ctx.RawStep), w.Ctx.RawStep
+	loadRaw := !c.options[OptionDoNotLoadRawSLIs]
+
+	histograms := map[spanSLI][]model.HistogramBucket{}
+	rawHistograms := map[spanSLI][]model.HistogramBucket{}
+	for route, ss := range queries {
+		var services []string
+		for s := range ss {
+			services = append(services, s)
+		}
+		res, err := c.traces.GetServerSpansSLIs(ctx, from, to, step, services, route)
+		if err != nil {
+			klog.Warningln("failed to get SLIs from spans:", err)
+			return
+		}
+		for s, h := range res {
+			histograms[spanSLI{service: s, route: route}] = h
+		}
+		if !loadRaw {
+			continue
+		}
+		if res, err = rawSpanSLIs.get(ctx, c.traces, c.project.Id, rawFrom, rawTo, rawStep, services, route); err != nil {
+			klog.Warningln("failed to get SLIs from spans:", err)
+			return
+		}
+		for s, h := range res {
+			rawHistograms[spanSLI{service: s, route: route}] = h
+		}
+	}
+
+	for app, slis := range byApp {
+		if sli := slis.availability; sli != nil {
+			cfg, _ := w.CheckConfigs.GetAvailability(app.Id)
+			app.AvailabilitySLIs = nil
+			if h := histograms[*sli]; len(h) > 0 {
+				s := &model.AvailabilitySLI{
+					Config:         cfg,
+					TotalRequests:  h[len(h)-1].TimeSeries,
+					FailedRequests: h[0].TimeSeries,
+				}
+				if raw := rawHistograms[*sli]; len(raw) > 0 {
+					s.TotalRequestsRaw = raw[len(raw)-1].TimeSeries
+					s.FailedRequestsRaw = raw[0].TimeSeries
+				}
+				app.AvailabilitySLIs = append(app.AvailabilitySLIs, s)
+			}
+		}
+		if sli := slis.latency; sli != nil {
+			cfg, _ := w.CheckConfigs.GetLatency(app.Id, app.Category)
+			app.LatencySLIs = nil
+			if h := histograms[*sli]; len(h) > 0 {
+				s := &model.LatencySLI{Config: cfg, Histogram: h[1:]}
+				if raw := rawHistograms[*sli]; len(raw) > 0 {
+					s.HistogramRaw = raw[1:]
+				}
+				app.LatencySLIs = append(app.LatencySLIs, s)
+			}
+		}
+	}
+}
+
+func spanSLIFromConfig(cfg *model.CheckConfigSLOSpans, w *model.World, app *model.Application, services []string) *spanSLI {
+	service := cfg.ServiceName
+	if service == "" {
+		if app.Settings != nil && app.Settings.Tracing != nil {
+			service = app.Settings.Tracing.Service
+		} else {
+			service = model.GuessService(services, w, app)
+		}
+	}
+	if service == "" {
+		return nil
+	}
+	return &spanSLI{service: service, route: cfg.Route}
+}
+
+const (
+	// the recent spans may still be arriving, so the tail of the cached SLIs is re-read on every load
+	rawSpanSLIsRefreshWindow = 5 * timeseries.Minute
+	rawSpanSLIsTTL           = 10 * time.Minute
+)
+
+// rawSpanSLIs caches the raw-step SLIs calculated from the spans. The watchers reload the world every minute,
+// and the alerting window (model.MaxAlertRuleWindow) would otherwise be scanned in ClickHouse on every load.
+var rawSpanSLIs = &spanSLIsCache{entries: map[spanSLIsKey]*spanSLIsEntry{}}
+
+type spanSLIsKey struct {
+	projectId db.ProjectId
+	services  string
+	route     string
+	step      timeseries.Duration
+}
+
+type spanSLIsEntry struct {
+	from, to timeseries.Time
+	data     map[string][]model.HistogramBucket
+	used     time.Time
+}
+
+type spanSLIsCache struct {
+	entries map[spanSLIsKey]*spanSLIsEntry
+	lock    sync.Mutex
+}
+
+// get reads only the spans that are not cached yet if the cached range overlaps the requested one.
+func (c *spanSLIsCache) get(ctx context.Context, traces Traces, projectId db.ProjectId, from, to timeseries.Time, step timeseries.Duration, services []string, route string) (map[string][]model.HistogramBucket, error) {
+	sort.Strings(services)
+	key := spanSLIsKey{projectId: projectId, services: strings.Join(services, "\n"), route: route, step: step}
+	c.lock.Lock()
+	e := c.entries[key]
+	c.lock.Unlock()
+	if e != nil && (from < e.from || from > e.to || to < e.to) {
+		e = nil
+	}
+	queryFrom := from
+	if e != nil {
+		queryFrom = max(from, e.to.Add(-rawSpanSLIsRefreshWindow).Truncate(step))
+	}
+	res, err := traces.GetServerSpansSLIs(ctx, queryFrom, to, step, services, route)
+	if err != nil {
+		return nil, err
+	}
+	if e != nil {
+		res = mergeSpanSLIs(e.data, res, from, queryFrom, to, step)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	if cur := c.entries[key]; cur == nil || cur.to <= to {
+		c.entries[key] = &spanSLIsEntry{from: from, to: to, data: res, used: now}
+	}
+	for k, e := range c.entries {
+		if now.Sub(e.used) > rawSpanSLIsTTL {
+			delete(c.entries, k)
+		}
+	}
+	return res, nil
+}
+
+// mergeSpanSLIs takes the points before the split from the cached histograms and the rest from the fresh ones.
+func mergeSpanSLIs(cached, fresh map[string][]model.HistogramBucket, from, split, to timeseries.Time, step timeseries.Duration) map[string][]model.HistogramBucket {
+	pointsCount := int(to.Sub(from)/step) + 1
+	res := make(map[string][]model.HistogramBucket, len(fresh))
+	merge := func(service string, buckets []model.HistogramBucket, keep func(t timeseries.Time) bool) {
+		if res[service] == nil {
+			res[service] = make([]model.HistogramBucket, len(buckets))
+			for i, b := range buckets {
+				res[service][i] = model.HistogramBucket{Le: b.Le, TimeSeries: timeseries.New(from, pointsCount, step)}
+			}
+		}
+		if len(res[service]) != len(buckets) {
+			return
+		}
+		for i, b := range buckets {
+			iter := b.TimeSeries.Iter()
+			for iter.Next() {
+				if t, v := iter.Value(); keep(t) {
+					res[service][i].TimeSeries.Set(t, v)
+				}
+			}
+		}
+	}
+	for service, buckets := range cached {
+		merge(service, buckets, func(t timeseries.Time) bool { return t < split })
+	}
+	for service, buckets := range fresh {
+		merge(service, buckets, func(t timeseries.Time) bool { return t >= split })
+	}
+	return res
+}
@@ -0,0 +1,114 @@
+package constructor
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTraces struct {
+	services []string
+	queries  map[string][]string // route -> services
+}
+
+func (t *fakeTraces) GetServicesFromTraces(ctx context.Context, from timeseries.Time) ([]string, error) {
+	return t.services, nil
+}
+
+func (t *fakeTraces) GetServerSpansSLIs(ctx context.Context, from, to timeseries.Time, step timeseries.Duration, services []string, route string) (map[string][]model.HistogramBucket, error) {
+	t.queries[route] = append(t.queries[route], services...)
+	res := map[string][]model.HistogramBucket{}
+	for _, s := range services {
+		res[s] = []model.HistogramBucket{
+			{TimeSeries: timeseries.NewWithData(from, step, []float32{1})},
+			{Le: 0.5, TimeSeries: timeseries.NewWithData(from, step, []float32{8})},
+			{Le: float32(math.Inf(1)), TimeSeries: timeseries.NewWithData(from, step, []float32{10})},
+		}
+	}
+	return res, nil
+}
+
+func TestSpanSLIs(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(timeseries.Hour), timeseries.Minute, timeseries.Minute)
+	catalog := w.GetOrCreateApplication(model.NewApplicationId("shop", model.ApplicationKindDeployment, "catalog"), false)
+	cfg, err := json.Marshal([]model.CheckConfigSLOAvailability{{Custom: true, Spans: &model.CheckConfigSLOSpans{Route: "^/api/"}, ObjectivePercentage: 99}})
+	require.NoError(t, err)
+	w.CheckConfigs = model.CheckConfigs{catalog.Id: {model.Checks.SLOAvailability.Id: cfg}}
+
+	traces := &fakeTraces{services: []string{"catalog", "billing", "/k8s/shop/catalog"}, queries: map[string][]string{}}
+	c := New(nil, &db.Project{}, nil, nil, OptionDoNotLoadRawSLIs).WithTraces(traces)
+	services := c.loadOTelServices(context.Background(), w)
+	assert.Equal(t, []string{"catalog", "billing"}, services)
+
+	billing := w.GetApplication(model.NewApplicationId("", model.ApplicationKindOTelService, "billing"))
+	require.NotNil(t, billing)
+	assert.Len(t, w.Applications, 2)
+
+	c.loadSpanSLIs(context.Background(), w, services)
+	assert.Equal(t, map[string][]string{"": {"billing"}, "^/api/": {"catalog"}}, traces.queries)
+
+	require.Len(t, billing.AvailabilitySLIs, 1)
+	assert.Equal(t, float32(10), billing.AvailabilitySLIs[0].TotalRequests.Last())
+	assert.Equal(t, float32(1), billing.AvailabilitySLIs[0].FailedRequests.Last())
+	require.Len(t, billing.LatencySLIs, 1)
+	total, fast := billing.LatencySLIs[0].GetTotalAndFast(false)
+	assert.Equal(t, float32(10), total.Last())
+	assert.Equal(t, float32(8), fast.Last())
+
+	require.Len(t, catalog.AvailabilitySLIs, 1)
+	assert.Equal(t, float32(99), catalog.AvailabilitySLIs[0].Config.ObjectivePercentage)
+	assert.Len(t, catalog.LatencySLIs, 0) // the latency SLI isn't configured to use the spans
+}
+
+type rangeTraces struct {
+	fakeTraces
+	ranges [][2]timeseries.Time
+}
+
+func (t *rangeTraces) GetServerSpansSLIs(ctx context.Context, from, to timeseries.Time, step timeseries.Duration, services []string, route string) (map[string][]model.HistogramBucket, error) {
+	t.ranges = append(t.ranges, [2]timeseries.Time{from, to})
+	res := map[string][]model.HistogramBucket{}
+	for _, s := range services {
+		ts := timeseries.New(from, int(to.Sub(from)/step)+1, step)
+		ts.MapInPlace(func(t timeseries.Time, v float32) float32 { return float32(t.Sub(0) / step) })
+		res[s] = []model.HistogramBucket{{TimeSeries: ts}, {Le: float32(math.Inf(1)), TimeSeries: ts}}
+	}
+	return res, nil
+}
+
+func TestRawSpanSLIsCache(t *testing.T) {
+	c := &spanSLIsCache{entries: map[spanSLIsKey]*spanSLIsEntry{}}
+	traces := &rangeTraces{}
+	step := timeseries.Minute
+	get := func(from, to timeseries.Time) *timeseries.TimeSeries {
+		res, err := c.get(context.Background(), traces, "p1", from, to, step, []string{"catalog"}, "")
+		require.NoError(t, err)
+		return res["catalog"][1].TimeSeries
+	}
+	hour := timeseries.Time(0).Add(timeseries.Hour)
+
+	ts := get(0, hour)
+	assert.Equal(t, [][2]timeseries.Time{{0, hour}}, traces.ranges)
+	assert.Equal(t, float32(60), ts.Last())
+
+	// only the new spans and the refresh window are read
+	ts = get(timeseries.Time(0).Add(2*step), hour.Add(2*step))
+	assert.Equal(t, [2]timeseries.Time{hour.Add(-5 * step), hour.Add(2 * step)}, traces.ranges[1])
+	assert.Equal(t, 61, ts.Len())
+	iter := ts.Iter()
+	for iter.Next() {
+		tt, v := iter.Value()
+		assert.Equal(t, float32(tt.Sub(0)/step), v)
+	}
+
+	// a range outside the cached one is read entirely
+	get(0, hour)
+	assert.Equal(t, [2]timeseries.Time{0, hour}, traces.ranges[2])
+}
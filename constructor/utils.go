@@ -11,3 +11,14 @@ func merge(dest, ts *timeseries.TimeSeries, f timeseries.F) *timeseries.TimeSeri
 	}
 	return timeseries.NewAggregate(f).Add(dest, ts).Get()
 }
+
+// presence converts a series into the one equal to 1 at the points where the source series has values.
+// It's used to consider an instance up while it exposes the metrics of an integration.
+func presence(ts *timeseries.TimeSeries) *timeseries.TimeSeries {
+	return ts.Map(func(t timeseries.Time, v float32) float32 {
+		if timeseries.IsNaN(v) {
+			return v
+		}
+		return 1
+	})
+}
@@ -0,0 +1,8 @@
+package constructor
+
+import "github.com/coroot/coroot/timeseries"
+
+// values returns a series of per-minute points starting at 0, which matches the worlds built in the tests.
+func values(vs ...float32) *timeseries.TimeSeries {
+	return timeseries.NewWithData(0, timeseries.Minute, vs)
+}
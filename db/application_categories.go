@@ -2,10 +2,13 @@ package db
 
 import (
 	"fmt"
+	"net/mail"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
 	"golang.org/x/exp/maps"
 )
@@ -28,6 +31,7 @@ type ApplicationCategorySettings struct {
 type ApplicationCategoryNotificationSettings struct {
 	Incidents   ApplicationCategoryIncidentNotificationSettings   `json:"incidents,omitempty" yaml:"incidents,omitempty"`
 	Deployments ApplicationCategoryDeploymentNotificationSettings `json:"deployments,omitempty" yaml:"deployments,omitempty"`
+	SLOReports  ApplicationCategorySLOReportNotificationSettings  `json:"slo_reports,omitempty" yaml:"sloReports,omitempty"`
 }
 
 type ApplicationCategoryIncidentNotificationSettings struct {
@@ -40,12 +44,89 @@ type ApplicationCategoryDeploymentNotificationSettings struct {
 	ApplicationCategoryNotificationDestinations `yaml:",inline"`
 }
 
+type SLOReportPeriod string
+
+const (
+	SLOReportPeriodWeekly  SLOReportPeriod = "weekly"  // sent on Mondays, covers the last 28 days
+	SLOReportPeriodMonthly SLOReportPeriod = "monthly" // sent on the first day of a month, covers the last 30 days
+)
+
+// ApplicationCategorySLOReportNotificationSettings configures the periodic SLO compliance reports
+// sent to the owners of the applications of the category.
+type ApplicationCategorySLOReportNotificationSettings struct {
+	Enabled                                     bool            `json:"enabled" yaml:"enabled"`
+	Period                                      SLOReportPeriod `json:"period,omitempty" yaml:"period,omitempty"`
+	ApplicationCategoryNotificationDestinations `yaml:",inline"`
+}
+
+func (s ApplicationCategorySLOReportNotificationSettings) Validate() error {
+	switch s.Period {
+	case "", SLOReportPeriodWeekly, SLOReportPeriodMonthly:
+	default:
+		return fmt.Errorf("invalid SLO report period: %s", s.Period)
+	}
+	return s.ApplicationCategoryNotificationDestinations.Validate()
+}
+
+// Window returns the time range the report covers.
+func (p SLOReportPeriod) Window() timeseries.Duration {
+	if p == SLOReportPeriodMonthly {
+		return model.SLOComplianceMaxWindow
+	}
+	return model.SLOComplianceDefaultWindow
+}
+
+// Start returns the beginning of the reporting period the given time belongs to (UTC).
+func (p SLOReportPeriod) Start(t timeseries.Time) timeseries.Time {
+	tt := t.ToStandard().UTC()
+	if p == SLOReportPeriodMonthly {
+		return timeseries.Time(time.Date(tt.Year(), tt.Month(), 1, 0, 0, 0, 0, time.UTC).Unix())
+	}
+	day := tt.Truncate(24 * time.Hour)
+	shift := (int(day.Weekday()) + 6) % 7 // days since Monday
+	return timeseries.Time(day.AddDate(0, 0, -shift).Unix())
+}
+
 type ApplicationCategoryNotificationDestinations struct {
 	Slack     *ApplicationCategoryNotificationSettingsSlack     `json:"slack,omitempty" yaml:"slack,omitempty"`
 	Teams     *ApplicationCategoryNotificationSettingsTeams     `json:"teams,omitempty" yaml:"teams,omitempty"`
 	Pagerduty *ApplicationCategoryNotificationSettingsPagerduty `json:"pagerduty,omitempty" yaml:"pagerduty,omitempty"`
 	Opsgenie  *ApplicationCategoryNotificationSettingsOpsgenie  `json:"opsgenie,omitempty" yaml:"opsgenie,omitempty"`
 	Webhook   *ApplicationCategoryNotificationSettingsWebhook   `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+
+	Email      *ApplicationCategoryNotificationSettingsEmail      `json:"email,omitempty" yaml:"email,omitempty"`
+	Mattermost *ApplicationCategoryNotificationSettingsMattermost `json:"mattermost,omitempty" yaml:"mattermost,omitempty"`
+	GoogleChat *ApplicationCategoryNotificationSettingsGoogleChat `json:"googlechat,omitempty" yaml:"googleChat,omitempty"`
+	Discord    *ApplicationCategoryNotificationSettingsDiscord    `json:"discord,omitempty" yaml:"discord,omitempty"`
+}
+
+// MaxNotificationDigestInterval limits how long incident notifications can be held back to be sent as a digest.
+const MaxNotificationDigestInterval = 24 * timeseries.Hour
+
+func (s ApplicationCategoryNotificationDestinations) Validate() error {
+	var intervals []timeseries.Duration
+	if s.Slack != nil {
+		intervals = append(intervals, s.Slack.DigestInterval)
+	}
+	if s.Teams != nil {
+		intervals = append(intervals, s.Teams.DigestInterval)
+	}
+	if s.Webhook != nil {
+		intervals = append(intervals, s.Webhook.DigestInterval)
+	}
+	for _, i := range intervals {
+		if i < 0 || i > MaxNotificationDigestInterval {
+			return fmt.Errorf("invalid digest interval: %s (max %s)", i, MaxNotificationDigestInterval)
+		}
+	}
+	if s.Email != nil {
+		for _, to := range s.Email.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("invalid email recipient %s: %w", to, err)
+			}
+		}
+	}
+	return nil
 }
 
 func (s ApplicationCategoryNotificationDestinations) hasEnabled() bool {
@@ -53,16 +134,22 @@ func (s ApplicationCategoryNotificationDestinations) hasEnabled() bool {
 		(s.Teams != nil && s.Teams.Enabled) ||
 		(s.Pagerduty != nil && s.Pagerduty.Enabled) ||
 		(s.Opsgenie != nil && s.Opsgenie.Enabled) ||
-		(s.Webhook != nil && s.Webhook.Enabled)
+		(s.Webhook != nil && s.Webhook.Enabled) ||
+		(s.Email != nil && s.Email.Enabled) ||
+		(s.Mattermost != nil && s.Mattermost.Enabled) ||
+		(s.GoogleChat != nil && s.GoogleChat.Enabled) ||
+		(s.Discord != nil && s.Discord.Enabled)
 }
 
 type ApplicationCategoryNotificationSettingsSlack struct {
-	Enabled bool   `json:"enabled" yaml:"enabled"`
-	Channel string `json:"channel" yaml:"channel"`
+	Enabled        bool                `json:"enabled" yaml:"enabled"`
+	Channel        string              `json:"channel" yaml:"channel"`
+	DigestInterval timeseries.Duration `json:"digest_interval,omitempty" yaml:"digestInterval,omitempty"`
 }
 
 type ApplicationCategoryNotificationSettingsTeams struct {
-	Enabled bool `json:"enabled" yaml:"enabled"`
+	Enabled        bool                `json:"enabled" yaml:"enabled"`
+	DigestInterval timeseries.Duration `json:"digest_interval,omitempty" yaml:"digestInterval,omitempty"`
 }
 
 type ApplicationCategoryNotificationSettingsPagerduty struct {
@@ -74,6 +161,25 @@ type ApplicationCategoryNotificationSettingsOpsgenie struct {
 }
 
 type ApplicationCategoryNotificationSettingsWebhook struct {
+	Enabled        bool                `json:"enabled" yaml:"enabled"`
+	DigestInterval timeseries.Duration `json:"digest_interval,omitempty" yaml:"digestInterval,omitempty"`
+}
+
+type ApplicationCategoryNotificationSettingsEmail struct {
+	Enabled bool     `json:"enabled" yaml:"enabled"`
+	To      []string `json:"to,omitempty" yaml:"to,omitempty"`
+}
+
+type ApplicationCategoryNotificationSettingsMattermost struct {
+	Enabled   bool   `json:"enabled" yaml:"enabled"`
+	ChannelId string `json:"channel_id" yaml:"channelId"`
+}
+
+type ApplicationCategoryNotificationSettingsGoogleChat struct {
+	Enabled bool `json:"enabled" yaml:"enabled"`
+}
+
+type ApplicationCategoryNotificationSettingsDiscord struct {
 	Enabled bool `json:"enabled" yaml:"enabled"`
 }
 
@@ -212,6 +318,110 @@ func (p *Project) GetApplicationCategories() map[model.ApplicationCategory]*Appl
 				category.NotificationSettings.Deployments.Webhook = nil
 			}
 		}
+		{
+			integrationEmail := p.Settings.Integrations.Email
+			if integrationEmail != nil {
+				if integrationEmail.Incidents {
+					if category.NotificationSettings.Incidents.Email == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.Email = &ApplicationCategoryNotificationSettingsEmail{Enabled: true}
+					}
+					if len(category.NotificationSettings.Incidents.Email.To) == 0 {
+						category.NotificationSettings.Incidents.Email.To = integrationEmail.DefaultTo
+					}
+				}
+				if integrationEmail.Deployments {
+					if category.NotificationSettings.Deployments.Email == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.Email = &ApplicationCategoryNotificationSettingsEmail{Enabled: notifyOfDeployments}
+					}
+					if len(category.NotificationSettings.Deployments.Email.To) == 0 {
+						category.NotificationSettings.Deployments.Email.To = integrationEmail.DefaultTo
+					}
+				}
+			}
+			if integrationEmail == nil || !integrationEmail.Incidents {
+				category.NotificationSettings.Incidents.Email = nil
+			}
+			if integrationEmail == nil || !integrationEmail.Deployments {
+				category.NotificationSettings.Deployments.Email = nil
+			}
+		}
+		{
+			integrationMattermost := p.Settings.Integrations.Mattermost
+			if integrationMattermost != nil {
+				if integrationMattermost.Incidents {
+					if category.NotificationSettings.Incidents.Mattermost == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{Enabled: true}
+					}
+					if category.NotificationSettings.Incidents.Mattermost.ChannelId == "" {
+						category.NotificationSettings.Incidents.Mattermost.ChannelId = integrationMattermost.DefaultChannelId
+					}
+				}
+				if integrationMattermost.Deployments {
+					if category.NotificationSettings.Deployments.Mattermost == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{Enabled: notifyOfDeployments}
+					}
+					if category.NotificationSettings.Deployments.Mattermost.ChannelId == "" {
+						category.NotificationSettings.Deployments.Mattermost.ChannelId = integrationMattermost.DefaultChannelId
+					}
+				}
+			}
+			if integrationMattermost == nil || !integrationMattermost.Incidents {
+				category.NotificationSettings.Incidents.Mattermost = nil
+			}
+			if integrationMattermost == nil || !integrationMattermost.Deployments {
+				category.NotificationSettings.Deployments.Mattermost = nil
+			}
+		}
+		{
+			integrationGoogleChat := p.Settings.Integrations.GoogleChat
+			if integrationGoogleChat != nil {
+				if integrationGoogleChat.Incidents {
+					if category.NotificationSettings.Incidents.GoogleChat == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{Enabled: true}
+					}
+				}
+				if integrationGoogleChat.Deployments {
+					if category.NotificationSettings.Deployments.GoogleChat == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{Enabled: notifyOfDeployments}
+					}
+				}
+			}
+			if integrationGoogleChat == nil || !integrationGoogleChat.Incidents {
+				category.NotificationSettings.Incidents.GoogleChat = nil
+			}
+			if integrationGoogleChat == nil || !integrationGoogleChat.Deployments {
+				category.NotificationSettings.Deployments.GoogleChat = nil
+			}
+		}
+		{
+			integrationDiscord := p.Settings.Integrations.Discord
+			if integrationDiscord != nil {
+				if integrationDiscord.Incidents {
+					if category.NotificationSettings.Incidents.Discord == nil {
+						category.NotificationSettings.Incidents.Enabled = true
+						category.NotificationSettings.Incidents.Discord = &ApplicationCategoryNotificationSettingsDiscord{Enabled: true}
+					}
+				}
+				if integrationDiscord.Deployments {
+					if category.NotificationSettings.Deployments.Discord == nil {
+						category.NotificationSettings.Deployments.Enabled = notifyOfDeployments
+						category.NotificationSettings.Deployments.Discord = &ApplicationCategoryNotificationSettingsDiscord{Enabled: notifyOfDeployments}
+					}
+				}
+			}
+			if integrationDiscord == nil || !integrationDiscord.Incidents {
+				category.NotificationSettings.Incidents.Discord = nil
+			}
+			if integrationDiscord == nil || !integrationDiscord.Deployments {
+				category.NotificationSettings.Deployments.Discord = nil
+			}
+		}
 		{
 			integrationPagerduty := p.Settings.Integrations.Pagerduty
 			if integrationPagerduty != nil {
@@ -247,6 +457,9 @@ func (p *Project) GetApplicationCategories() map[model.ApplicationCategory]*Appl
 		if !category.NotificationSettings.Deployments.hasEnabled() {
 			category.NotificationSettings.Deployments.Enabled = false
 		}
+		if !category.NotificationSettings.SLOReports.hasEnabled() {
+			category.NotificationSettings.SLOReports.Enabled = false
+		}
 	}
 
 	return res
@@ -278,6 +491,38 @@ func (p *Project) NewApplicationCategory() *ApplicationCategory {
 			category.NotificationSettings.Deployments.Webhook = &ApplicationCategoryNotificationSettingsWebhook{}
 		}
 	}
+	if email := p.Settings.Integrations.Email; email != nil {
+		if email.Incidents {
+			category.NotificationSettings.Incidents.Email = &ApplicationCategoryNotificationSettingsEmail{To: email.DefaultTo}
+		}
+		if email.Deployments {
+			category.NotificationSettings.Deployments.Email = &ApplicationCategoryNotificationSettingsEmail{To: email.DefaultTo}
+		}
+	}
+	if mattermost := p.Settings.Integrations.Mattermost; mattermost != nil {
+		if mattermost.Incidents {
+			category.NotificationSettings.Incidents.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{ChannelId: mattermost.DefaultChannelId}
+		}
+		if mattermost.Deployments {
+			category.NotificationSettings.Deployments.Mattermost = &ApplicationCategoryNotificationSettingsMattermost{ChannelId: mattermost.DefaultChannelId}
+		}
+	}
+	if googleChat := p.Settings.Integrations.GoogleChat; googleChat != nil {
+		if googleChat.Incidents {
+			category.NotificationSettings.Incidents.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{}
+		}
+		if googleChat.Deployments {
+			category.NotificationSettings.Deployments.GoogleChat = &ApplicationCategoryNotificationSettingsGoogleChat{}
+		}
+	}
+	if discord := p.Settings.Integrations.Discord; discord != nil {
+		if discord.Incidents {
+			category.NotificationSettings.Incidents.Discord = &ApplicationCategoryNotificationSettingsDiscord{}
+		}
+		if discord.Deployments {
+			category.NotificationSettings.Deployments.Discord = &ApplicationCategoryNotificationSettingsDiscord{}
+		}
+	}
 	if pagerduty := p.Settings.Integrations.Pagerduty; pagerduty != nil {
 		if pagerduty.Incidents {
 			category.NotificationSettings.Incidents.Pagerduty = &ApplicationCategoryNotificationSettingsPagerduty{}
@@ -332,6 +577,16 @@ func (db *DB) SaveApplicationCategory(project *Project, name model.ApplicationCa
 			slack.Channel = ""
 		}
 	}
+	for _, email := range []*ApplicationCategoryNotificationSettingsEmail{categorySettings.NotificationSettings.Incidents.Email, categorySettings.NotificationSettings.Deployments.Email} {
+		if e := project.Settings.Integrations.Email; email != nil && e != nil && slices.Equal(email.To, e.DefaultTo) {
+			email.To = nil
+		}
+	}
+	for _, mattermost := range []*ApplicationCategoryNotificationSettingsMattermost{categorySettings.NotificationSettings.Incidents.Mattermost, categorySettings.NotificationSettings.Deployments.Mattermost} {
+		if m := project.Settings.Integrations.Mattermost; mattermost != nil && m != nil && mattermost.ChannelId == m.DefaultChannelId {
+			mattermost.ChannelId = ""
+		}
+	}
 
 	return db.SaveProjectSettings(project)
 }
@@ -85,11 +85,14 @@ func (db *DB) Migrate(extraTables ...Table) error {
 		&CheckConfigs{},
 		&Incident{},
 		&IncidentNotification{},
+		&IncidentViolation{},
 		&ApplicationDeployment{},
 		&ApplicationSettings{},
+		&SLOComplianceDay{},
 		&Dashboards{},
 		&Setting{},
 		&User{},
+		&Replica{},
 	}
 	return db.Migrator().Migrate(append(defaultTables, extraTables...)...)
 }
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/coroot/coroot/model"
@@ -40,6 +41,21 @@ func (i *Incident) Migrate(m *Migrator) error {
 	return nil
 }
 
+// IncidentViolation is the time an application started violating its SLOs.
+// It is persisted so that the minimum incident duration survives restarts and the handover of projects between replicas.
+type IncidentViolation struct{}
+
+func (v *IncidentViolation) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS incident_violation (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		application_id TEXT NOT NULL,
+		since INT NOT NULL,
+		PRIMARY KEY (project_id, application_id)
+	);
+`)
+}
+
 type IncidentNotification struct {
 	ProjectId     ProjectId
 	ApplicationId model.ApplicationId
@@ -69,21 +85,84 @@ func (n *IncidentNotification) Migrate(m *Migrator) error {
 }
 
 type IncidentNotificationDestination struct {
-	IntegrationType IntegrationType
-	SlackChannel    string
+	IntegrationType   IntegrationType
+	SlackChannel      string
+	MattermostChannel string
+	EmailTo           EmailRecipients
+	DigestInterval    timeseries.Duration
+}
+
+// EmailRecipients is a list of email addresses encoded as a JSON array. It keeps the destination comparable
+// and stores the addresses with display names containing commas or colons as is.
+type EmailRecipients string
+
+func NewEmailRecipients(addresses []string) EmailRecipients {
+	if len(addresses) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(addresses)
+	return EmailRecipients(data)
+}
+
+func (r EmailRecipients) List() []string {
+	if r == "" {
+		return nil
+	}
+	var res []string
+	if err := json.Unmarshal([]byte(r), &res); err != nil {
+		klog.Warningln("invalid email recipients:", err)
+	}
+	return res
+}
+
+func (d IncidentNotificationDestination) Digest() bool {
+	return d.DigestInterval > 0
 }
 
 func (d IncidentNotificationDestination) Value() (driver.Value, error) {
+	var v string
 	switch d.IntegrationType {
 	case IntegrationTypeSlack:
-		return fmt.Sprintf("%s:%s", d.IntegrationType, d.SlackChannel), nil
+		v = fmt.Sprintf("%s:%s", d.IntegrationType, d.SlackChannel)
+	case IntegrationTypeMattermost:
+		v = fmt.Sprintf("%s:%s", d.IntegrationType, d.MattermostChannel)
+	case IntegrationTypeEmail:
+		v = fmt.Sprintf("%s:%s", d.IntegrationType, d.EmailTo)
+	default:
+		v = fmt.Sprintf("%s", d.IntegrationType)
+	}
+	if d.Digest() {
+		v += fmt.Sprintf("#digest=%d", d.DigestInterval)
 	}
-	return fmt.Sprintf("%s", d.IntegrationType), nil
+	return v, nil
 }
 
 func (d *IncidentNotificationDestination) Scan(src any) error {
 	*d = IncidentNotificationDestination{}
-	parts := strings.Split(src.(string), ":")
+	v := src.(string)
+	if to, ok := strings.CutPrefix(v, string(IntegrationTypeEmail)+":"); ok {
+		// no recipients are stored if the default ones are used
+		if to != "" && !strings.HasPrefix(to, "#") {
+			// the recipients may contain any characters, so the JSON array is read up to its end
+			dec := json.NewDecoder(strings.NewReader(to))
+			var addresses []string
+			if err := dec.Decode(&addresses); err != nil {
+				return fmt.Errorf("invalid email recipients: %w", err)
+			}
+			d.EmailTo = NewEmailRecipients(addresses)
+			to = to[dec.InputOffset():]
+		}
+		v = string(IntegrationTypeEmail) + to
+	}
+	v, digest, _ := strings.Cut(v, "#digest=")
+	if digest != "" {
+		interval, err := strconv.ParseInt(digest, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid digest interval: %s", digest)
+		}
+		d.DigestInterval = timeseries.Duration(interval)
+	}
+	parts := strings.Split(v, ":")
 	if len(parts) == 0 {
 		return nil
 	}
@@ -92,6 +171,8 @@ func (d *IncidentNotificationDestination) Scan(src any) error {
 		switch d.IntegrationType {
 		case IntegrationTypeSlack:
 			d.SlackChannel = parts[1]
+		case IntegrationTypeMattermost:
+			d.MattermostChannel = parts[1]
 		}
 	}
 	return nil
@@ -99,6 +180,13 @@ func (d *IncidentNotificationDestination) Scan(src any) error {
 
 type IncidentNotificationDetails struct {
 	Reports []IncidentNotificationDetailsReport `json:"reports"`
+
+	AvailabilityBurnRates []model.BurnRate `json:"availability_burn_rates,omitempty"`
+	LatencyBurnRates      []model.BurnRate `json:"latency_burn_rates,omitempty"`
+	AvailabilityImpact    float32          `json:"availability_impact,omitempty"`
+	LatencyImpact         float32          `json:"latency_impact,omitempty"`
+	Summary               string           `json:"summary,omitempty"`
+	RootCause             string           `json:"root_cause,omitempty"`
 }
 
 type IncidentNotificationDetailsReport struct {
@@ -225,6 +313,29 @@ func (db *DB) GetLastOpenIncident(projectId ProjectId, appId model.ApplicationId
 	}
 }
 
+func (db *DB) GetLastResolvedIncident(projectId ProjectId, appId model.ApplicationId) (*model.ApplicationIncident, error) {
+	last := model.ApplicationIncident{
+		ApplicationId: appId,
+	}
+	var dd sql.NullString
+	err := db.db.QueryRow(
+		"SELECT key, opened_at, resolved_at, severity, details FROM incident WHERE project_id = $1 AND application_id = $2 AND resolved_at > 0 ORDER BY resolved_at DESC LIMIT 1",
+		projectId, appId.String()).Scan(&last.Key, &last.OpenedAt, &last.ResolvedAt, &last.Severity, &dd)
+	switch err {
+	case nil:
+		if dd.String != "" {
+			if err = json.Unmarshal([]byte(dd.String), &last.Details); err != nil {
+				return nil, err
+			}
+		}
+		return &last, nil
+	case sql.ErrNoRows:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
 func (db *DB) CreateIncident(projectId ProjectId, appId model.ApplicationId, i *model.ApplicationIncident) error {
 	appIdStr := appId.String()
 
@@ -260,6 +371,57 @@ func (db *DB) ResolveIncident(projectId ProjectId, appId model.ApplicationId, in
 	return err
 }
 
+func (db *DB) ReopenIncident(projectId ProjectId, appId model.ApplicationId, incident *model.ApplicationIncident) error {
+	d, _ := json.Marshal(incident.Details)
+	_, err := db.db.Exec(
+		"UPDATE incident SET resolved_at = 0, severity = $1, details = $2 WHERE project_id = $3 AND application_id = $4 AND key = $5",
+		incident.Severity, string(d), projectId, appId.String(), incident.Key)
+	return err
+}
+
+func (db *DB) GetIncidentViolations(projectId ProjectId) (map[model.ApplicationId]timeseries.Time, error) {
+	rows, err := db.db.Query("SELECT application_id, since FROM incident_violation WHERE project_id = $1", projectId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := map[model.ApplicationId]timeseries.Time{}
+	for rows.Next() {
+		var appId string
+		var since timeseries.Time
+		if err = rows.Scan(&appId, &since); err != nil {
+			return nil, err
+		}
+		id, err := model.NewApplicationIdFromString(appId)
+		if err != nil {
+			klog.Warningln(err)
+			continue
+		}
+		res[id] = since
+	}
+	return res, rows.Err()
+}
+
+// SaveIncidentViolations replaces the violations of the project, so the applications that are no longer violating are removed.
+func (db *DB) SaveIncidentViolations(projectId ProjectId, violations map[model.ApplicationId]timeseries.Time) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	if _, err = tx.Exec("DELETE FROM incident_violation WHERE project_id = $1", projectId); err != nil {
+		return err
+	}
+	for appId, since := range violations {
+		if _, err = tx.Exec("INSERT INTO incident_violation (project_id, application_id, since) VALUES ($1, $2, $3)", projectId, appId.String(), since); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
 func (db *DB) PutIncidentNotification(n IncidentNotification) {
 	details, err := marshal(n.Details)
 	if err != nil {
@@ -283,6 +445,19 @@ func (db *DB) UpdateIncidentNotification(n IncidentNotification) error {
 	return err
 }
 
+// DeleteNotSentIncidentNotifications deletes the pending notifications of the incident having the given status
+// and returns the number of the deleted ones.
+func (db *DB) DeleteNotSentIncidentNotifications(projectId ProjectId, appId model.ApplicationId, incidentKey string, status model.Status) (int64, error) {
+	res, err := db.db.Exec(
+		"DELETE FROM incident_notification WHERE project_id = $1 AND application_id = $2 AND incident_key = $3 AND status = $4 AND sent_at = 0",
+		projectId, appId, incidentKey, status,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func (db *DB) GetNotSentIncidentNotifications(from timeseries.Time) ([]IncidentNotification, error) {
 	rows, err := db.db.Query(`
 		SELECT project_id, application_id, incident_key, status, destination, timestamp, external_key, details 
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"net/mail"
 	"net/url"
 	"strings"
 
@@ -20,6 +21,10 @@ const (
 	IntegrationTypeTeams      IntegrationType = "teams"
 	IntegrationTypeOpsgenie   IntegrationType = "opsgenie"
 	IntegrationTypeWebhook    IntegrationType = "webhook"
+	IntegrationTypeEmail      IntegrationType = "email"
+	IntegrationTypeMattermost IntegrationType = "mattermost"
+	IntegrationTypeGoogleChat IntegrationType = "googlechat"
+	IntegrationTypeDiscord    IntegrationType = "discord"
 )
 
 type Integrations struct {
@@ -39,6 +44,11 @@ type NotificationIntegrations struct {
 	Pagerduty *IntegrationPagerduty `json:"pagerduty,omitempty" yaml:"pagerduty,omitempty"`
 	Opsgenie  *IntegrationOpsgenie  `json:"opsgenie,omitempty" yaml:"opsgenie,omitempty"`
 	Webhook   *IntegrationWebhook   `json:"webhook,omitempty" yaml:"webhook,omitempty"`
+
+	Email      *IntegrationEmail      `json:"email,omitempty" yaml:"email,omitempty"`
+	Mattermost *IntegrationMattermost `json:"mattermost,omitempty" yaml:"mattermost,omitempty"`
+	GoogleChat *IntegrationGoogleChat `json:"googlechat,omitempty" yaml:"googleChat,omitempty"`
+	Discord    *IntegrationDiscord    `json:"discord,omitempty" yaml:"discord,omitempty"`
 }
 
 func (i *NotificationIntegrations) Validate() error {
@@ -75,6 +85,26 @@ func (i *NotificationIntegrations) Validate() error {
 			return fmt.Errorf("invalid webhook configuration: %w", err)
 		}
 	}
+	if i.Email != nil {
+		if err := i.Email.Validate(); err != nil {
+			return fmt.Errorf("invalid email configuration: %w", err)
+		}
+	}
+	if i.Mattermost != nil {
+		if err := i.Mattermost.Validate(); err != nil {
+			return fmt.Errorf("invalid mattermost configuration: %w", err)
+		}
+	}
+	if i.GoogleChat != nil {
+		if err := i.GoogleChat.Validate(); err != nil {
+			return fmt.Errorf("invalid google chat configuration: %w", err)
+		}
+	}
+	if i.Discord != nil {
+		if err := i.Discord.Validate(); err != nil {
+			return fmt.Errorf("invalid discord configuration: %w", err)
+		}
+	}
 
 	return nil
 
@@ -133,6 +163,43 @@ func (integrations Integrations) GetInfo() []IntegrationInfo {
 		i.Configured = true
 		i.Incidents = cfg.Incidents
 		i.Deployments = cfg.Deployments
+		if n := len(cfg.AllDestinations()); n > 1 {
+			i.Details = fmt.Sprintf("destinations: %d", n)
+		}
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeEmail, Title: "Email"}
+	if cfg := integrations.Email; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Details = fmt.Sprintf("default recipients: %s", strings.Join(cfg.DefaultTo, ", "))
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeMattermost, Title: "Mattermost"}
+	if cfg := integrations.Mattermost; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+		i.Details = fmt.Sprintf("default channel: %s", cfg.DefaultChannelId)
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeGoogleChat, Title: "Google Chat"}
+	if cfg := integrations.GoogleChat; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
+	}
+	res = append(res, i)
+
+	i = IntegrationInfo{Type: IntegrationTypeDiscord, Title: "Discord"}
+	if cfg := integrations.Discord; cfg != nil {
+		i.Configured = true
+		i.Incidents = cfg.Incidents
+		i.Deployments = cfg.Deployments
 	}
 	res = append(res, i)
 
@@ -217,20 +284,48 @@ func (i *IntegrationOpsgenie) Validate() error {
 	return nil
 }
 
+type IntegrationWebhookDestination struct {
+	Url           string           `json:"url" yaml:"url"`
+	TlsSkipVerify bool             `json:"tls_skip_verify" yaml:"tlsSkipVerify"`
+	BasicAuth     *utils.BasicAuth `json:"basic_auth" yaml:"basicAuth"`
+	CustomHeaders []utils.Header   `json:"custom_headers" yaml:"customHeaders"`
+	Secret        string           `json:"secret" yaml:"secret"` // HMAC-SHA256 signing key, payloads are not signed if empty
+}
+
+func (d *IntegrationWebhookDestination) Validate() error {
+	if d.Url == "" {
+		return fmt.Errorf("url is required")
+	}
+	if _, err := url.Parse(d.Url); err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	for _, h := range d.CustomHeaders {
+		if h.Key == "" {
+			return fmt.Errorf("custom header name is required")
+		}
+	}
+	return nil
+}
+
 type IntegrationWebhook struct {
-	Url                string           `json:"url" yaml:"url"`
-	TlsSkipVerify      bool             `json:"tls_skip_verify" yaml:"tlsSkipVerify"`
-	BasicAuth          *utils.BasicAuth `json:"basic_auth" yaml:"basicAuth"`
-	CustomHeaders      []utils.Header   `json:"custom_headers" yaml:"customHeaders"`
-	Incidents          bool             `json:"incidents" yaml:"incidents"`
-	Deployments        bool             `json:"deployments" yaml:"deployments"`
-	IncidentTemplate   string           `json:"incident_template" yaml:"incidentTemplate"`
-	DeploymentTemplate string           `json:"deployment_template" yaml:"deploymentTemplate"`
+	IntegrationWebhookDestination `yaml:",inline"`
+	Destinations                  []IntegrationWebhookDestination `json:"destinations" yaml:"destinations"` // additional endpoints receiving the same payloads
+
+	Incidents          bool   `json:"incidents" yaml:"incidents"`
+	Deployments        bool   `json:"deployments" yaml:"deployments"`
+	IncidentTemplate   string `json:"incident_template" yaml:"incidentTemplate"`
+	DeploymentTemplate string `json:"deployment_template" yaml:"deploymentTemplate"`
+	DigestTemplate     string `json:"digest_template" yaml:"digestTemplate"`
 }
 
 func (i *IntegrationWebhook) Validate() error {
-	if i.Url == "" {
-		return fmt.Errorf("url is required")
+	if err := i.IntegrationWebhookDestination.Validate(); err != nil {
+		return err
+	}
+	for _, d := range i.Destinations {
+		if err := d.Validate(); err != nil {
+			return fmt.Errorf("destination %s: %w", d.Url, err)
+		}
 	}
 	if i.Incidents && i.IncidentTemplate == "" {
 		return fmt.Errorf("incident template is required")
@@ -241,6 +336,113 @@ func (i *IntegrationWebhook) Validate() error {
 	return nil
 }
 
+// AllDestinations returns the primary destination followed by the additional ones, skipping those without a URL.
+func (i *IntegrationWebhook) AllDestinations() []IntegrationWebhookDestination {
+	var res []IntegrationWebhookDestination
+	for _, d := range append([]IntegrationWebhookDestination{i.IntegrationWebhookDestination}, i.Destinations...) {
+		if d.Url != "" {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+type IntegrationEmail struct {
+	SmtpHost    string           `json:"smtp_host" yaml:"smtpHost"`
+	SmtpPort    int              `json:"smtp_port" yaml:"smtpPort"`
+	SmtpTls     string           `json:"smtp_tls" yaml:"smtpTls"` // none, starttls or tls
+	Auth        *utils.BasicAuth `json:"auth" yaml:"auth"`
+	From        string           `json:"from" yaml:"from"`
+	DefaultTo   []string         `json:"default_to" yaml:"defaultTo"`
+	Incidents   bool             `json:"incidents" yaml:"incidents"`
+	Deployments bool             `json:"deployments" yaml:"deployments"`
+}
+
+const (
+	SmtpTlsNone     = "none"
+	SmtpTlsStartTls = "starttls"
+	SmtpTlsTls      = "tls"
+)
+
+func (i *IntegrationEmail) Validate() error {
+	if i.SmtpHost == "" {
+		return fmt.Errorf("smtp host is required")
+	}
+	if i.SmtpPort <= 0 || i.SmtpPort > 65535 {
+		return fmt.Errorf("invalid smtp port: %d", i.SmtpPort)
+	}
+	switch i.SmtpTls {
+	case "":
+		i.SmtpTls = SmtpTlsNone
+	case SmtpTlsNone, SmtpTlsStartTls, SmtpTlsTls:
+	default:
+		return fmt.Errorf("invalid smtp tls mode: %s", i.SmtpTls)
+	}
+	if _, err := mail.ParseAddress(i.From); err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	if len(i.DefaultTo) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+	for _, to := range i.DefaultTo {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid recipient %s: %w", to, err)
+		}
+	}
+	return nil
+}
+
+type IntegrationMattermost struct {
+	Url              string `json:"url" yaml:"url"`
+	Token            string `json:"token" yaml:"token"`
+	DefaultChannelId string `json:"default_channel_id" yaml:"defaultChannelId"`
+	Incidents        bool   `json:"incidents" yaml:"incidents"`
+	Deployments      bool   `json:"deployments" yaml:"deployments"`
+}
+
+func (i *IntegrationMattermost) Validate() error {
+	if i.Url == "" {
+		return fmt.Errorf("url is required")
+	}
+	if _, err := url.Parse(i.Url); err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	i.Url = strings.TrimRight(i.Url, "/")
+	if i.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+	if i.DefaultChannelId == "" {
+		return fmt.Errorf("default channel id is required")
+	}
+	return nil
+}
+
+type IntegrationGoogleChat struct {
+	WebhookUrl  string `json:"webhook_url" yaml:"webhookURL"`
+	Incidents   bool   `json:"incidents" yaml:"incidents"`
+	Deployments bool   `json:"deployments" yaml:"deployments"`
+}
+
+func (i *IntegrationGoogleChat) Validate() error {
+	if i.WebhookUrl == "" {
+		return fmt.Errorf("webhook url is required")
+	}
+	return nil
+}
+
+type IntegrationDiscord struct {
+	WebhookUrl  string `json:"webhook_url" yaml:"webhookURL"`
+	Incidents   bool   `json:"incidents" yaml:"incidents"`
+	Deployments bool   `json:"deployments" yaml:"deployments"`
+}
+
+func (i *IntegrationDiscord) Validate() error {
+	if i.WebhookUrl == "" {
+		return fmt.Errorf("webhook url is required")
+	}
+	return nil
+}
+
 type IntegrationAWS struct {
 	Region          string `json:"region"`
 	AccessKeyID     string `json:"access_key_id"`
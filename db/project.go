@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
 	"github.com/coroot/coroot/utils"
 )
 
@@ -34,6 +35,17 @@ type ProjectSettings struct {
 	CustomApplications          map[string]model.CustomApplication                         `json:"custom_applications"`
 	ApiKeys                     []ApiKey                                                   `json:"api_keys"`
 	CustomCloudPricing          *CustomCloudPricing                                        `json:"custom_cloud_pricing"`
+	Snapshot                    *ProjectSnapshot                                           `json:"snapshot,omitempty"`
+	Clusters                    []ProjectId                                                `json:"clusters,omitempty"`
+}
+
+// ProjectSnapshot marks a read-only project mounted from an exported bundle rather than fed by Prometheus.
+type ProjectSnapshot struct {
+	SourceProjectId   ProjectId       `json:"source_project_id"`
+	SourceProjectName string          `json:"source_project_name"`
+	From              timeseries.Time `json:"from"`
+	To                timeseries.Time `json:"to"`
+	Created           timeseries.Time `json:"created"`
 }
 
 type ApiKey struct {
@@ -96,7 +108,16 @@ func (p *Project) GetCustomApplicationName(instance string) string {
 	return ""
 }
 
+// Multicluster reports whether the project aggregates other projects (clusters) instead of having its own data sources.
+// The names of the member projects are used as the cluster names.
+func (p *Project) Multicluster() bool {
+	return len(p.Settings.Clusters) > 0
+}
+
 func (p *Project) PrometheusConfig(globalPrometheus *IntegrationPrometheus) *IntegrationPrometheus {
+	if p.Settings.Snapshot != nil || p.Multicluster() {
+		return &IntegrationPrometheus{}
+	}
 	if globalPrometheus != nil {
 		gp := *globalPrometheus
 		gp.ExtraSelector = fmt.Sprintf(`{coroot_project_id="%s"}`, p.Id)
@@ -107,6 +128,9 @@ func (p *Project) PrometheusConfig(globalPrometheus *IntegrationPrometheus) *Int
 }
 
 func (p *Project) ClickHouseConfig(globalClickHouse *IntegrationClickhouse) *IntegrationClickhouse {
+	if p.Settings.Snapshot != nil || p.Multicluster() {
+		return nil
+	}
 	if globalClickHouse != nil {
 		gc := *globalClickHouse
 		gc.Database = "coroot_" + string(p.Id)
@@ -200,6 +224,43 @@ func (db *DB) GetProject(id ProjectId) (*Project, error) {
 	return &p, nil
 }
 
+// GetClusters returns the member projects of the multi-cluster project. The members that have been deleted are skipped.
+func (db *DB) GetClusters(p *Project) ([]*Project, error) {
+	var res []*Project
+	for _, id := range p.Settings.Clusters {
+		member, err := db.GetProject(id)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if member.Multicluster() {
+			continue
+		}
+		res = append(res, member)
+	}
+	return res, nil
+}
+
+// GetMulticlusterProjects returns the ids of the multi-cluster projects the project is a member of.
+func (db *DB) GetMulticlusterProjects(member ProjectId) ([]ProjectId, error) {
+	projects, err := db.GetProjects()
+	if err != nil {
+		return nil, err
+	}
+	var res []ProjectId
+	for _, p := range projects {
+		for _, id := range p.Settings.Clusters {
+			if id == member {
+				res = append(res, p.Id)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
 func (db *DB) SaveProject(p *Project) error {
 	if p.Prometheus.RefreshInterval == 0 {
 		p.Prometheus.RefreshInterval = DefaultRefreshInterval
@@ -239,12 +300,18 @@ func (db *DB) DeleteProject(id ProjectId) error {
 	if _, err = tx.Exec("DELETE FROM incident WHERE project_id = $1", id); err != nil {
 		return err
 	}
+	if _, err = tx.Exec("DELETE FROM incident_violation WHERE project_id = $1", id); err != nil {
+		return err
+	}
 	if _, err = tx.Exec("DELETE FROM application_deployment WHERE project_id = $1", id); err != nil {
 		return err
 	}
 	if _, err = tx.Exec("DELETE FROM application_settings WHERE project_id = $1", id); err != nil {
 		return err
 	}
+	if _, err = tx.Exec("DELETE FROM slo_compliance WHERE project_id = $1", id); err != nil {
+		return err
+	}
 	if _, err = tx.Exec("DELETE FROM dashboards WHERE project_id = $1", id); err != nil {
 		return err
 	}
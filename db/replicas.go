@@ -0,0 +1,59 @@
+package db
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type Replica struct {
+	Id        string
+	Url       string
+	Heartbeat timeseries.Time
+}
+
+func (r *Replica) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS replica (
+		id TEXT NOT NULL PRIMARY KEY,
+		url TEXT NOT NULL,
+		heartbeat INTEGER NOT NULL
+	)`)
+}
+
+func (db *DB) PutReplica(r Replica) error {
+	res, err := db.db.Exec("UPDATE replica SET url = $1, heartbeat = $2 WHERE id = $3", r.Url, r.Heartbeat, r.Id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	_, err = db.db.Exec("INSERT INTO replica (id, url, heartbeat) VALUES ($1, $2, $3)", r.Id, r.Url, r.Heartbeat)
+	return err
+}
+
+// GetReplicas returns the replicas that have sent a heartbeat since the given time.
+func (db *DB) GetReplicas(since timeseries.Time) ([]Replica, error) {
+	rows, err := db.db.Query("SELECT id, url, heartbeat FROM replica WHERE heartbeat >= $1 ORDER BY id", since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var res []Replica
+	for rows.Next() {
+		var r Replica
+		if err = rows.Scan(&r.Id, &r.Url, &r.Heartbeat); err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, rows.Err()
+}
+
+func (db *DB) DeleteReplica(id string) error {
+	_, err := db.db.Exec("DELETE FROM replica WHERE id = $1", id)
+	return err
+}
@@ -0,0 +1,141 @@
+package db
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+type SLOComplianceDay model.SLOComplianceDay
+
+func (d *SLOComplianceDay) Migrate(m *Migrator) error {
+	return m.Exec(`
+	CREATE TABLE IF NOT EXISTS slo_compliance (
+		project_id TEXT NOT NULL REFERENCES project(id),
+		application_id TEXT NOT NULL,
+		slo TEXT NOT NULL,
+		date INT NOT NULL,
+		objective REAL NOT NULL,
+		total TEXT NOT NULL,
+		bad TEXT NOT NULL,
+		PRIMARY KEY (project_id, application_id, slo, date)
+	);
+`)
+}
+
+// SaveSLOComplianceDays replaces the SLI data of the days of the project.
+func (db *DB) SaveSLOComplianceDays(projectId ProjectId, days []*model.SLOComplianceDay) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	for _, d := range days {
+		total, err := json.Marshal(d.Total)
+		if err != nil {
+			return err
+		}
+		bad, err := json.Marshal(d.Bad)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			"DELETE FROM slo_compliance WHERE project_id = $1 AND application_id = $2 AND slo = $3 AND date = $4",
+			projectId, d.ApplicationId, d.SLO, d.Date)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			"INSERT INTO slo_compliance (project_id, application_id, slo, date, objective, total, bad) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			projectId, d.ApplicationId, d.SLO, d.Date, d.Objective, string(total), string(bad))
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetSLOComplianceDays returns the SLI data of the applications of the project for the days within the time range.
+// If appId is not zero, only the data of this application is returned.
+func (db *DB) GetSLOComplianceDays(projectId ProjectId, appId model.ApplicationId, from, to timeseries.Time) (map[model.ApplicationId][]*model.SLOComplianceDay, error) {
+	q := "SELECT application_id, slo, date, objective, total, bad FROM slo_compliance WHERE project_id = $1 AND date >= $2 AND date < $3"
+	args := []any{projectId, from, to}
+	if !appId.IsZero() {
+		q += " AND application_id = $4"
+		args = append(args, appId)
+	}
+	rows, err := db.db.Query(q+" ORDER BY application_id, slo, date", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+	res := map[model.ApplicationId][]*model.SLOComplianceDay{}
+	for rows.Next() {
+		var d model.SLOComplianceDay
+		var total, bad string
+		if err = rows.Scan(&d.ApplicationId, &d.SLO, &d.Date, &d.Objective, &total, &bad); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(total), &d.Total); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(bad), &d.Bad); err != nil {
+			return nil, err
+		}
+		res[d.ApplicationId] = append(res[d.ApplicationId], &d)
+	}
+	return res, rows.Err()
+}
+
+// GetSLOComplianceLastDate returns the last day the SLI data of the project has been saved for.
+func (db *DB) GetSLOComplianceLastDate(projectId ProjectId) (timeseries.Time, error) {
+	var last timeseries.Time
+	err := db.db.QueryRow("SELECT coalesce(max(date), 0) FROM slo_compliance WHERE project_id = $1", projectId).Scan(&last)
+	return last, err
+}
+
+// GetSLOCompliance calculates the SLO compliance of the applications of the project over the window ending at `to`.
+// The result can be narrowed down to a single application or to the applications of a category.
+func (db *DB) GetSLOCompliance(project *Project, appId model.ApplicationId, category model.ApplicationCategory, to timeseries.Time, window timeseries.Duration) ([]*model.SLOCompliance, error) {
+	from := to.Add(-window)
+	days, err := db.GetSLOComplianceDays(project.Id, appId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	incidents, err := db.GetApplicationIncidents(project.Id, from, to)
+	if err != nil {
+		return nil, err
+	}
+	deployments, err := db.GetApplicationDeployments(project.Id)
+	if err != nil {
+		return nil, err
+	}
+	var res []*model.SLOCompliance
+	for id, appDays := range days {
+		if category != "" && project.CalcApplicationCategory(id) != category {
+			continue
+		}
+		bySLO := map[model.SLOType][]*model.SLOComplianceDay{}
+		for _, d := range appDays {
+			bySLO[d.SLO] = append(bySLO[d.SLO], d)
+		}
+		for _, slo := range []model.SLOType{model.SLOTypeAvailability, model.SLOTypeLatency} {
+			if c := model.CalcSLOCompliance(bySLO[slo], incidents[id], deployments[id], to, window); c != nil {
+				res = append(res, c)
+			}
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].ApplicationId != res[j].ApplicationId {
+			return res[i].ApplicationId.String() < res[j].ApplicationId.String()
+		}
+		return res[i].SLO < res[j].SLO
+	})
+	return res, nil
+}
@@ -0,0 +1,83 @@
+package exporter
+
+import (
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+const month = float32(timeseries.Month)
+
+type applicationCosts struct {
+	usage          float32
+	crossAzTraffic float32
+	internetEgress float32
+}
+
+// estimateCosts estimates monthly costs the same way the Costs overview does:
+// the average resource usage of the application instances multiplied by the prices of their nodes.
+func estimateCosts(world *model.World) map[model.ApplicationId]*applicationCosts {
+	res := map[model.ApplicationId]*applicationCosts{}
+	var dataTransferPrice *model.DataTransferPrice
+	for _, n := range world.Nodes {
+		if n.Price == nil {
+			continue
+		}
+		if dataTransferPrice == nil && n.DataTransferPrice != nil {
+			dataTransferPrice = n.DataTransferPrice
+		}
+		for _, i := range n.Instances {
+			app := world.GetApplication(i.Owner.Id)
+			if app == nil {
+				continue
+			}
+			cs := res[app.Id]
+			if cs == nil {
+				cs = &applicationCosts{}
+				res[app.Id] = cs
+			}
+			cpuUsage := timeseries.NewAggregate(timeseries.NanSum)
+			memUsage := timeseries.NewAggregate(timeseries.NanSum)
+			for _, ct := range i.Containers {
+				cpuUsage.Add(ct.CpuUsage)
+				memUsage.Add(ct.MemoryRss)
+			}
+			if avg := average(cpuUsage.Get()); avg > 0 {
+				cs.usage += avg * n.Price.PerCPUCore * month
+			}
+			if avg := average(memUsage.Get()); avg > 0 {
+				cs.usage += avg * n.Price.PerMemoryByte * month
+			}
+			switch app.Id.Kind {
+			case model.ApplicationKindRds, model.ApplicationKindElasticacheCluster:
+				cs.usage += n.Price.Total * month
+			}
+		}
+	}
+	if dataTransferPrice != nil {
+		for appId, cs := range res {
+			app := world.GetApplication(appId)
+			cs.crossAzTraffic += monthlyTrafficCosts(app.TrafficStats.CrossAZEgress, dataTransferPrice.InterZoneEgressPerGB)
+			cs.crossAzTraffic += monthlyTrafficCosts(app.TrafficStats.CrossAZIngress, dataTransferPrice.InterZoneIngressPerGB)
+			cs.internetEgress += monthlyTrafficCosts(app.TrafficStats.InternetEgress, dataTransferPrice.GetInternetEgressPrice())
+		}
+	}
+	return res
+}
+
+func average(ts *timeseries.TimeSeries) float32 {
+	if ts.IsEmpty() {
+		return 0
+	}
+	avg := ts.Reduce(timeseries.NanSum) / ts.Map(timeseries.Defined).Reduce(timeseries.NanSum)
+	if timeseries.IsNaN(avg) {
+		return 0
+	}
+	return avg
+}
+
+func monthlyTrafficCosts(ts *timeseries.TimeSeries, perGBprice float32) float32 {
+	if perGBprice <= 0 {
+		return 0
+	}
+	return average(ts) * month / 1000 / 1000 / 1000 * perGBprice
+}
@@ -0,0 +1,73 @@
+package exporter
+
+import (
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Collector exposes the SLIs, check statuses, incidents, burn rates and cost estimates derived by Coroot as Prometheus metrics.
+// The incidents are read at scrape time, the other values come from the snapshot of the audited world.
+type Collector struct {
+	project   *db.Project
+	snapshot  *Snapshot
+	incidents map[model.ApplicationId]*model.ApplicationIncident // open incidents
+}
+
+func NewCollector(project *db.Project, snapshot *Snapshot, openIncidents map[model.ApplicationId]*model.ApplicationIncident) *Collector {
+	return &Collector{project: project, snapshot: snapshot, incidents: openIncidents}
+}
+
+var applicationLabels = []string{"project_id", "application_id", "namespace", "kind", "name"}
+
+func desc(name, help string, labels ...string) *prometheus.Desc {
+	return prometheus.NewDesc(name, help, append(applicationLabels, labels...), nil)
+}
+
+var (
+	dApplicationInfo       = desc("coroot_application_info", "Meta information about the application", "category")
+	dApplicationStatus     = desc("coroot_application_status", "The overall status of the application: 0=unknown, 1=ok, 2=info, 3=warning, 4=critical")
+	dCheckStatus           = desc("coroot_application_check_status", "The status of the audit check: 0=unknown, 1=ok, 2=info, 3=warning, 4=critical", "report", "check")
+	dAvailabilitySLI       = desc("coroot_application_availability_ratio", "The ratio of successful requests over the last hour")
+	dAvailabilityObjective = desc("coroot_application_availability_objective_ratio", "The availability objective")
+	dRequests              = desc("coroot_application_requests_per_second", "The number of requests per second")
+	dLatencySLI            = desc("coroot_application_latency_ratio", "The ratio of requests served faster than the objective threshold over the last hour")
+	dLatencyObjective      = desc("coroot_application_latency_objective_ratio", "The latency objective")
+	dLatencyThreshold      = desc("coroot_application_latency_objective_threshold_seconds", "The latency objective threshold")
+	dIncidentOpen          = desc("coroot_application_incident_open", "Whether the application has an open incident")
+	dIncidentSeverity      = desc("coroot_application_incident_severity", "The severity of the open incident: 3=warning, 4=critical")
+	dIncidentOpenedAt      = desc("coroot_application_incident_opened_at_timestamp_seconds", "The time the open incident was opened")
+	dBurnRate              = desc("coroot_application_slo_burn_rate", "The error budget burn rate of the SLO", "slo", "window", "long_window", "short_window")
+	dCost                  = desc("coroot_application_cost_monthly_usd", "The estimated monthly cost of the application", "type")
+)
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		dApplicationInfo, dApplicationStatus, dCheckStatus,
+		dAvailabilitySLI, dAvailabilityObjective, dRequests, dLatencySLI, dLatencyObjective, dLatencyThreshold,
+		dIncidentOpen, dIncidentSeverity, dIncidentOpenedAt, dBurnRate, dCost,
+	} {
+		ch <- d
+	}
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	labels := func(appId model.ApplicationId, extra ...string) []string {
+		return append([]string{string(c.project.Id), appId.String(), appId.Namespace, string(appId.Kind), appId.Name}, extra...)
+	}
+	for _, s := range c.snapshot.samples {
+		ch <- prometheus.MustNewConstMetric(s.desc, prometheus.GaugeValue, s.value, labels(s.appId, s.labels...)...)
+	}
+	for _, appId := range c.snapshot.applications {
+		gauge := func(d *prometheus.Desc, v float64) {
+			ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, labels(appId)...)
+		}
+		if incident := c.incidents[appId]; incident != nil {
+			gauge(dIncidentOpen, 1)
+			gauge(dIncidentSeverity, float64(incident.Severity))
+			gauge(dIncidentOpenedAt, float64(incident.OpenedAt))
+		} else {
+			gauge(dIncidentOpen, 0)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollector(t *testing.T) {
+	step := timeseries.Minute
+	w := model.NewWorld(0, timeseries.Time(0).Add(3*step), step, step)
+	app := w.GetOrCreateApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog"), false)
+	app.Status = model.WARNING
+	app.AvailabilitySLIs = append(app.AvailabilitySLIs, &model.AvailabilitySLI{
+		Config:         model.CheckConfigSLOAvailability{ObjectivePercentage: 99},
+		TotalRequests:  timeseries.NewWithData(0, step, []float32{100, 100, 100, 100}),
+		FailedRequests: timeseries.NewWithData(0, step, []float32{0, 10, 0, 10}),
+	})
+	incidents := map[model.ApplicationId]*model.ApplicationIncident{
+		app.Id: {Key: "abc", OpenedAt: 1700000000, Severity: model.CRITICAL},
+	}
+	c := NewCollector(&db.Project{Id: "p1"}, NewSnapshot(w), incidents)
+
+	expected := `
+# HELP coroot_application_availability_ratio The ratio of successful requests over the last hour
+# TYPE coroot_application_availability_ratio gauge
+coroot_application_availability_ratio{application_id="default:Deployment:catalog",kind="Deployment",name="catalog",namespace="default",project_id="p1"} 0.95
+# HELP coroot_application_incident_opened_at_timestamp_seconds The time the open incident was opened
+# TYPE coroot_application_incident_opened_at_timestamp_seconds gauge
+coroot_application_incident_opened_at_timestamp_seconds{application_id="default:Deployment:catalog",kind="Deployment",name="catalog",namespace="default",project_id="p1"} 1.7e+09
+# HELP coroot_application_incident_severity The severity of the open incident: 3=warning, 4=critical
+# TYPE coroot_application_incident_severity gauge
+coroot_application_incident_severity{application_id="default:Deployment:catalog",kind="Deployment",name="catalog",namespace="default",project_id="p1"} 4
+# HELP coroot_application_status The overall status of the application: 0=unknown, 1=ok, 2=info, 3=warning, 4=critical
+# TYPE coroot_application_status gauge
+coroot_application_status{application_id="default:Deployment:catalog",kind="Deployment",name="catalog",namespace="default",project_id="p1"} 3
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"coroot_application_availability_ratio",
+		"coroot_application_incident_opened_at_timestamp_seconds",
+		"coroot_application_incident_severity",
+		"coroot_application_status",
+	))
+}
+
+func TestSnapshots(t *testing.T) {
+	w := model.NewWorld(0, timeseries.Time(0).Add(3*timeseries.Minute), timeseries.Minute, timeseries.Minute)
+	app := w.GetOrCreateApplication(model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog"), false)
+	app.Status = model.WARNING
+
+	snapshots := NewSnapshots()
+	snapshots.Observe("p1", w)
+	snapshots.Observe("p2", w)
+	app.Status = model.CRITICAL // the world is shared with the other watchers, the snapshot mustn't follow its changes
+
+	c := NewCollector(&db.Project{Id: "p1"}, snapshots.Get("p1"), nil)
+	expected := `
+# HELP coroot_application_status The overall status of the application: 0=unknown, 1=ok, 2=info, 3=warning, 4=critical
+# TYPE coroot_application_status gauge
+coroot_application_status{application_id="default:Deployment:catalog",kind="Deployment",name="catalog",namespace="default",project_id="p1"} 3
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "coroot_application_status"))
+
+	snapshots.Retain(func(projectId db.ProjectId) bool { return projectId == "p2" })
+	assert.Nil(t, snapshots.Get("p1"))
+	assert.NotNil(t, snapshots.Get("p2"))
+	snapshots.Delete("p2")
+	assert.Nil(t, snapshots.Get("p2"))
+}
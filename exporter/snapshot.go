@@ -0,0 +1,131 @@
+package exporter
+
+import (
+	"math"
+	"sync"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/coroot/coroot/watchers"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type sample struct {
+	desc   *prometheus.Desc
+	appId  model.ApplicationId
+	value  float64
+	labels []string
+}
+
+// Snapshot contains the values exported for the applications of a project, extracted from an audited world.
+// It doesn't reference the world, so it can be kept after the world is discarded, and it's never modified once created.
+type Snapshot struct {
+	To timeseries.Time
+
+	applications []model.ApplicationId
+	samples      []sample
+}
+
+func NewSnapshot(world *model.World) *Snapshot {
+	s := &Snapshot{To: world.Ctx.To}
+	costs := estimateCosts(world)
+	for _, app := range world.Applications {
+		s.applications = append(s.applications, app.Id)
+		gauge := func(d *prometheus.Desc, v float64, extra ...string) {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return
+			}
+			s.samples = append(s.samples, sample{desc: d, appId: app.Id, value: v, labels: extra})
+		}
+
+		gauge(dApplicationInfo, 1, string(app.Category))
+		gauge(dApplicationStatus, float64(app.Status))
+		for _, r := range app.Reports {
+			for _, check := range r.Checks {
+				gauge(dCheckStatus, float64(check.Status), string(r.Name), string(check.Id))
+			}
+		}
+
+		if len(app.AvailabilitySLIs) > 0 {
+			sli := app.AvailabilitySLIs[0]
+			total := sli.TotalRequests.Reduce(timeseries.NanSum)
+			if total > 0 {
+				failed := sli.FailedRequests.Reduce(timeseries.NanSum)
+				if timeseries.IsNaN(failed) {
+					failed = 0
+				}
+				gauge(dAvailabilitySLI, float64(total-failed)/float64(total))
+			}
+			gauge(dRequests, float64(sli.TotalRequests.Last()))
+			gauge(dAvailabilityObjective, float64(sli.Config.ObjectivePercentage)/100)
+		}
+		if len(app.LatencySLIs) > 0 {
+			sli := app.LatencySLIs[0]
+			totalTs, fastTs := sli.GetTotalAndFast(false)
+			if total := totalTs.Reduce(timeseries.NanSum); total > 0 && !fastTs.IsEmpty() {
+				gauge(dLatencySLI, float64(fastTs.Reduce(timeseries.NanSum))/float64(total))
+			}
+			gauge(dLatencyObjective, float64(sli.Config.ObjectivePercentage)/100)
+			gauge(dLatencyThreshold, float64(sli.Config.ObjectiveBucket))
+		}
+
+		availabilityBurnRates, latencyBurnRates := watchers.BurnRates(world.Ctx, app)
+		for slo, brs := range map[string][]model.BurnRate{"availability": availabilityBurnRates, "latency": latencyBurnRates} {
+			for _, br := range brs {
+				lw, sw := utils.FormatDurationShort(br.LongWindow, 1), utils.FormatDurationShort(br.ShortWindow, 1)
+				gauge(dBurnRate, float64(br.LongWindowBurnRate), slo, "long", lw, sw)
+				gauge(dBurnRate, float64(br.ShortWindowBurnRate), slo, "short", lw, sw)
+			}
+		}
+
+		if cs, ok := costs[app.Id]; ok {
+			gauge(dCost, float64(cs.usage), "usage")
+			gauge(dCost, float64(cs.crossAzTraffic), "cross_az_traffic")
+			gauge(dCost, float64(cs.internetEgress), "internet_egress")
+		}
+	}
+	return s
+}
+
+// Snapshots keeps the latest snapshot of each project checked by this replica.
+// It's fed by the incident watcher (see watchers.WorldObserver).
+type Snapshots struct {
+	byProject map[db.ProjectId]*Snapshot
+	lock      sync.RWMutex
+}
+
+func NewSnapshots() *Snapshots {
+	return &Snapshots{byProject: map[db.ProjectId]*Snapshot{}}
+}
+
+func (s *Snapshots) Observe(projectId db.ProjectId, world *model.World) {
+	snapshot := NewSnapshot(world)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.byProject[projectId] = snapshot
+}
+
+func (s *Snapshots) Retain(keep func(projectId db.ProjectId) bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for id := range s.byProject {
+		if !keep(id) {
+			delete(s.byProject, id)
+		}
+	}
+}
+
+// Get returns the latest snapshot of the project, or nil if the project hasn't been checked by this replica.
+func (s *Snapshots) Get(projectId db.ProjectId) *Snapshot {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.byProject[projectId]
+}
+
+func (s *Snapshots) Delete(projectId db.ProjectId) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.byProject, projectId)
+}
@@ -18,6 +18,7 @@ export default class Api {
         incidents: {},
         license: {},
         fluxcd: true,
+        argocd: true,
     };
 
     constructor(router, vuetify, basePath) {
@@ -56,6 +57,7 @@ export default class Api {
                     this.context.incidents = response.data.context.incidents;
                     this.context.license = response.data.context.license;
                     this.context.fluxcd = response.data.context.fluxcd;
+                    this.context.argocd = response.data.context.argocd;
                 }
                 try {
                     const data = response.data.data !== undefined ? response.data.data : response.data;
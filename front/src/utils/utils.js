@@ -28,7 +28,12 @@ export default class Utils {
 
     appId(id) {
         const parts = id.split(':');
+        let cluster = '';
+        if (parts[0].includes('@')) {
+            [cluster, parts[0]] = parts[0].split('@');
+        }
         return {
+            cluster,
             ns: parts[0] !== '_' ? parts[0] : '',
             kind: parts[1],
             name: parts[3] ? parts[2] + ':' + parts[3] : parts[2],
@@ -12,14 +12,16 @@ import (
 
 	"github.com/coroot/coroot/api"
 	"github.com/coroot/coroot/cache"
-	"github.com/coroot/coroot/cloud"
+	"github.com/coroot/coroot/cache/storage"
 	cloud_pricing "github.com/coroot/coroot/cloud-pricing"
 	"github.com/coroot/coroot/collector"
 	"github.com/coroot/coroot/config"
 	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/exporter"
 	"github.com/coroot/coroot/grpc"
 	"github.com/coroot/coroot/keep"
 	"github.com/coroot/coroot/rbac"
+	"github.com/coroot/coroot/sharding"
 	"github.com/coroot/coroot/stats"
 	"github.com/coroot/coroot/utils"
 	"github.com/coroot/coroot/watchers"
@@ -100,6 +102,15 @@ func main() {
 			Interval: cfg.Cache.GCInterval,
 		},
 	}
+	var shards *sharding.Sharding
+	if cfg.Sharding.Enabled {
+		shards = sharding.New(database, cfg.Sharding.ReplicaId, cfg.Sharding.AdvertiseUrl)
+		shards.Start()
+		cacheConfig.Sharding = shards
+	}
+	if cfg.Cache.S3 != nil {
+		cacheConfig.Storage = storage.NewReadThrough(storage.NewFS(cacheConfig.Path), storage.NewS3(*cfg.Cache.S3))
+	}
 	promCache, err := cache.NewCache(cacheConfig, database, globalPrometheus)
 	if err != nil {
 		klog.Exitln(err)
@@ -121,6 +132,7 @@ func main() {
 		ch := make(chan os.Signal, 1)
 		signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
 		<-ch
+		shards.Stop()
 		grpcServer.Stop()
 		coll.Close()
 		os.Exit(0)
@@ -144,9 +156,12 @@ func main() {
 		klog.Exitln(err)
 	}
 
-	incidents := watchers.NewIncidents(database, a.IncidentRCA, keepClient)
+	incidents := watchers.NewIncidents(database, a.IncidentRCA, keepClient, cfg.Incidents)
+	metricsSnapshots := exporter.NewSnapshots()
+	incidents.SetWorldObserver(metricsSnapshots)
+	a.SetMetricsSnapshots(metricsSnapshots)
 
-	watchers.Start(database, promCache, pricing, incidents, !cfg.DoNotCheckForDeployments, globalClickhouse, cfg.ClickHouseSpaceManager)
+	watchers.Start(database, promCache, pricing, incidents, !cfg.DoNotCheckForDeployments, globalClickhouse, cfg.ClickHouseSpaceManager, shards, a.GetClickhouseClient)
 
 	statsCollector := stats.NewCollector(cfg.DisableUsageStatistics, instanceUuid, version, Edition, database, promCache, pricing, globalClickhouse)
 
@@ -206,6 +221,7 @@ func main() {
 		r.HandleFunc("/v1/config", coll.Config)
 	}
 	r.UseEncodedPath()
+	r.Use(shards.Middleware)
 	r.HandleFunc("/api/login", a.Login).Methods(http.MethodPost)
 	r.HandleFunc("/api/logout", a.Logout).Methods(http.MethodPost)
 
@@ -219,8 +235,17 @@ func main() {
 	r.HandleFunc("/api/project/{project}", a.Auth(a.Project)).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
 	r.HandleFunc("/api/project/{project}/status", a.Auth(a.Status)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/api_keys", a.Auth(a.ApiKeys)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/clusters", a.Auth(a.ProjectClusters)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/cache/backfill", a.Auth(a.CacheBackfill)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/cache/backfill/{job}", a.Auth(a.CacheBackfill)).Methods(http.MethodDelete)
+	r.HandleFunc("/api/project/{project}/snapshot", a.Auth(a.Snapshot)).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/snapshot/export", a.Auth(a.SnapshotExport)).Methods(http.MethodGet)
+	r.HandleFunc("/api/snapshots", a.Auth(a.SnapshotImport)).Methods(http.MethodPost)
+	r.HandleFunc("/api/scenarios", a.Auth(a.ScenarioImport)).Methods(http.MethodPost)
 	r.HandleFunc("/api/project/{project}/overview/{view}", a.Auth(a.Overview)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/incidents", a.Auth(a.Incidents)).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/anomalies", a.Auth(a.Anomalies)).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/slo_compliance", a.Auth(a.SLOCompliance)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/incident/{incident}", a.Auth(a.Incident)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/dashboards", a.Auth(a.Dashboards)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/dashboards/{dashboard}", a.Auth(a.Dashboards)).Methods(http.MethodGet, http.MethodPost)
@@ -230,9 +255,11 @@ func main() {
 	r.HandleFunc("/api/project/{project}/custom_applications", a.Auth(a.CustomApplications)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/custom_cloud_pricing", a.Auth(a.CustomCloudPricing)).Methods(http.MethodGet, http.MethodPost, http.MethodDelete)
 	r.HandleFunc("/api/project/{project}/integrations", a.Auth(a.Integrations)).Methods(http.MethodGet, http.MethodPut)
+	r.HandleFunc("/api/project/{project}/integrations/webhook/test", a.Auth(a.WebhookTestSend)).Methods(http.MethodPost)
 	r.HandleFunc("/api/project/{project}/integrations/{type}", a.Auth(a.Integration)).Methods(http.MethodGet, http.MethodPut, http.MethodDelete, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/app/{app}", a.Auth(a.Application)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/app/{app}/rca", a.Auth(a.RCA)).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/app/{app}/slo_compliance", a.Auth(a.SLOCompliance)).Methods(http.MethodGet)
 	r.HandleFunc("/api/project/{project}/app/{app}/inspection/{type}/config", a.Auth(a.Inspection)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/app/{app}/instrumentation/{type}", a.Auth(a.Instrumentation)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/app/{app}/profiling", a.Auth(a.Profiling)).Methods(http.MethodGet, http.MethodPost)
@@ -240,6 +267,9 @@ func main() {
 	r.HandleFunc("/api/project/{project}/app/{app}/logs", a.Auth(a.Logs)).Methods(http.MethodGet, http.MethodPost)
 	r.HandleFunc("/api/project/{project}/app/{app}/risks", a.Auth(a.Risks)).Methods(http.MethodPost)
 	r.HandleFunc("/api/project/{project}/node/{node}", a.Auth(a.Node)).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/metrics", a.ApiKeyAuth(a.Metrics)).Methods(http.MethodGet)
+	r.HandleFunc("/api/project/{project}/prometheus/api/v1/query_range", a.ApiKeyAuth(a.PromQueryRange)).Methods(http.MethodGet, http.MethodPost)
+	r.HandleFunc("/api/project/{project}/prometheus/api/v1/series", a.ApiKeyAuth(a.PromSeries)).Methods(http.MethodGet, http.MethodPost)
 	r.PathPrefix("/api/project/{project}/prom").HandlerFunc(a.Auth(a.Prom))
 
 	r.HandleFunc("/stats", func(w http.ResponseWriter, r *http.Request) {
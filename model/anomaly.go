@@ -0,0 +1,77 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type AnomalySignal string
+
+const (
+	AnomalySignalRequests AnomalySignal = "requests"
+	AnomalySignalErrors   AnomalySignal = "errors"
+	AnomalySignalLatency  AnomalySignal = "latency"
+	AnomalySignalCPU      AnomalySignal = "cpu"
+	AnomalySignalMemory   AnomalySignal = "memory"
+	AnomalySignalTraffic  AnomalySignal = "traffic"
+)
+
+type AnomalyBaseline string
+
+const (
+	AnomalyBaselineSeasonal AnomalyBaseline = "seasonal"
+	AnomalyBaselineEWMA     AnomalyBaseline = "ewma"
+)
+
+type Anomaly struct {
+	ApplicationId ApplicationId   `json:"application_id"`
+	Signal        AnomalySignal   `json:"signal"`
+	Severity      Status          `json:"severity"`
+	Score         float32         `json:"score"`
+	Value         float32         `json:"value"`
+	Expected      float32         `json:"expected"`
+	Since         timeseries.Time `json:"since"`
+	Baseline      AnomalyBaseline `json:"baseline"`
+}
+
+// AnomalySignals returns the series the anomaly detector watches for the application.
+// Errors and latency are fractions of the total requests, so they don't follow the traffic pattern.
+func (app *Application) AnomalySignals() map[AnomalySignal]*timeseries.TimeSeries {
+	res := map[AnomalySignal]*timeseries.TimeSeries{}
+	if len(app.AvailabilitySLIs) > 0 {
+		sli := app.AvailabilitySLIs[0]
+		if !sli.TotalRequests.IsEmpty() {
+			res[AnomalySignalRequests] = sli.TotalRequests
+			if !sli.FailedRequests.IsEmpty() {
+				res[AnomalySignalErrors] = timeseries.Div(sli.FailedRequests, sli.TotalRequests)
+			}
+		}
+	}
+	if len(app.LatencySLIs) > 0 {
+		total, fast := app.LatencySLIs[0].GetTotalAndFast(false)
+		if !total.IsEmpty() && !fast.IsEmpty() {
+			res[AnomalySignalLatency] = timeseries.Div(timeseries.Sub(total, fast), total)
+		}
+	}
+	cpu := timeseries.NewAggregate(timeseries.NanSum)
+	memory := timeseries.NewAggregate(timeseries.NanSum)
+	traffic := timeseries.NewAggregate(timeseries.NanSum)
+	for _, i := range app.Instances {
+		for _, c := range i.Containers {
+			cpu.Add(c.CpuUsage)
+			memory.Add(c.MemoryRss)
+		}
+		for _, u := range i.Upstreams {
+			traffic.Add(u.BytesSent, u.BytesReceived)
+		}
+	}
+	for s, ts := range map[AnomalySignal]*timeseries.TimeSeries{
+		AnomalySignalCPU:     cpu.Get(),
+		AnomalySignalMemory:  memory.Get(),
+		AnomalySignalTraffic: traffic.Get(),
+	} {
+		if !ts.IsEmpty() {
+			res[s] = ts
+		}
+	}
+	return res
+}
@@ -12,6 +12,8 @@ import (
 
 type Application struct {
 	Id ApplicationId
+	// ClusterName is the name of the cluster of a multi-cluster project the application belongs to.
+	ClusterName string
 
 	Custom   bool
 	Category ApplicationCategory
@@ -34,6 +36,7 @@ type Application struct {
 	Events      []*ApplicationEvent
 	Deployments []*ApplicationDeployment
 	Incidents   []*ApplicationIncident
+	Anomalies   []*Anomaly
 
 	LogMessages map[Severity]*LogMessages
 
@@ -46,6 +49,11 @@ type Application struct {
 
 	DNSRequests          map[DNSRequest]map[string]*timeseries.TimeSeries
 	DNSRequestsHistogram map[float32]*timeseries.TimeSeries
+
+	KafkaConsumerGroups []*KafkaConsumerGroup
+
+	CronJob *CronJob
+	Jobs    map[string]*Job
 }
 
 func NewApplication(id ApplicationId) *Application {
@@ -59,6 +67,8 @@ func NewApplication(id ApplicationId) *Application {
 
 		DNSRequests:          map[DNSRequest]map[string]*timeseries.TimeSeries{},
 		DNSRequestsHistogram: map[float32]*timeseries.TimeSeries{},
+
+		Jobs: map[string]*Job{},
 	}
 	return app
 }
@@ -111,6 +121,12 @@ func (app *Application) Labels() Labels {
 		res["db"] = fmt.Sprintf(`%s (RDS)`, app.Instances[0].Rds.Engine.Value())
 	case ApplicationKindElasticacheCluster:
 		res["db"] = fmt.Sprintf(`%s (EC)`, app.Instances[0].Elasticache.Engine.Value())
+	case ApplicationKindCloudSQL:
+		res["db"] = fmt.Sprintf(`%s (Cloud SQL)`, app.Instances[0].CloudSQL.Engine.Value())
+	case ApplicationKindMemorystore:
+		res["db"] = "redis (Memorystore)"
+	case ApplicationKindAzureDatabase:
+		res["db"] = fmt.Sprintf(`%s (Azure)`, app.Instances[0].AzureDatabase.Engine.Value())
 	case ApplicationKindUnknown, ApplicationKindDockerSwarmService, ApplicationKindNomadJobGroup:
 		if app.Id.Namespace != "_" {
 			res["ns"] = app.Id.Namespace
@@ -148,6 +164,9 @@ func (app *Application) Labels() Labels {
 			}
 		}
 	}
+	if app.ClusterName != "" {
+		res["cluster"] = app.ClusterName
+	}
 	return res
 }
 
@@ -187,6 +206,60 @@ func (app *Application) IsMemcached() bool {
 	return false
 }
 
+func (app *Application) IsKafka() bool {
+	for _, i := range app.Instances {
+		if i.Kafka != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsRabbitmq() bool {
+	for _, i := range app.Instances {
+		if i.Rabbitmq != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsNats() bool {
+	for _, i := range app.Instances {
+		if i.Nats != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsElasticsearch() bool {
+	for _, i := range app.Instances {
+		if i.Elasticsearch != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsCassandra() bool {
+	for _, i := range app.Instances {
+		if i.Cassandra != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsClickHouse() bool {
+	for _, i := range app.Instances {
+		if i.ClickHouse != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (app *Application) IsPostgres() bool {
 	for _, i := range app.Instances {
 		if i.Postgres != nil {
@@ -232,6 +305,24 @@ func (app *Application) IsNodejs() bool {
 	return false
 }
 
+func (app *Application) IsProxy() bool {
+	for _, i := range app.Instances {
+		if i.Proxy != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (app *Application) IsGo() bool {
+	for _, i := range app.Instances {
+		if i.GoRuntime != nil {
+			return true
+		}
+	}
+	return false
+}
+
 func (app *Application) IsStandalone() bool {
 	for _, d := range app.Downstreams {
 		if d.Application != d.RemoteApplication {
@@ -247,7 +338,8 @@ func (app *Application) IsStandalone() bool {
 }
 
 func (app *Application) IsDatabase() bool {
-	if app.Id.Kind == ApplicationKindRds || app.Id.Kind == ApplicationKindElasticacheCluster {
+	switch app.Id.Kind {
+	case ApplicationKindRds, ApplicationKindElasticacheCluster, ApplicationKindCloudSQL, ApplicationKindMemorystore, ApplicationKindAzureDatabase:
 		return true
 	}
 	for t := range app.ApplicationTypes() {
@@ -309,7 +401,10 @@ func (app *Application) ApplicationTypes() map[ApplicationType]bool {
 		}
 	}
 
-	if app.Id.Kind == ApplicationKindExternalService {
+	switch app.Id.Kind {
+	case ApplicationKindKubernetesControlPlane:
+		res[ApplicationTypeKubernetes] = true
+	case ApplicationKindExternalService:
 		for _, d := range app.Downstreams {
 			for p := range d.RequestsCount {
 				t := p.ToApplicationType()
@@ -33,6 +33,7 @@ var BuiltinCategoryPatterns = map[ApplicationCategory][]string{
 	ApplicationCategoryApplication: {},
 	ApplicationCategoryControlPlane: {
 		"kube-system/*",
+		"_/kubernetes",
 		"*/kubelet",
 		"*/kube-apiserver",
 		"*/k3s",
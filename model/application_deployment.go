@@ -99,6 +99,18 @@ type ApplicationDeploymentNotifications struct {
 	Webhook struct {
 		State ApplicationDeploymentState `json:"state"`
 	} `json:"webhook"`
+	Email struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"email"`
+	Mattermost struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"mattermost"`
+	GoogleChat struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"googlechat"`
+	Discord struct {
+		State ApplicationDeploymentState `json:"state"`
+	} `json:"discord"`
 }
 
 type ApplicationDeploymentSummary struct {
@@ -121,6 +133,7 @@ type ApplicationDeploymentStatus struct {
 	Message    string
 	Lifetime   timeseries.Duration
 	Summary    []ApplicationDeploymentSummary
+	Previous   *MetricsSnapshot // the snapshot of the previous deployment the summary was calculated against
 	Deployment *ApplicationDeployment
 	Last       bool
 }
@@ -146,6 +159,7 @@ func CalcApplicationDeploymentStatuses(app *Application, checkConfigs CheckConfi
 					break
 				}
 			}
+			s.Previous = prev
 			s.Summary, s.Status = CalcApplicationDeploymentSummary(app, checkConfigs, d.StartedAt, d.MetricsSnapshot, prev)
 		case !d.FinishedAt.IsZero():
 			s.Status = OK
@@ -13,6 +13,7 @@ const (
 	ApplicationEventTypeRollout
 	ApplicationEventTypeInstanceDown
 	ApplicationEventTypeInstanceUp
+	ApplicationEventTypeArgoCDSync
 )
 
 type ApplicationEvent struct {
@@ -14,6 +14,9 @@ var (
 )
 
 type ApplicationId struct {
+	// ClusterId is set only in the worlds of multi-cluster projects. It's the id of the member project (cluster) the application belongs to.
+	// Unlike the project name, it never changes, so the check configs, incidents, and settings keyed by the application id survive renames.
+	ClusterId string
 	Namespace string
 	Kind      ApplicationKind
 	Name      string
@@ -47,7 +50,17 @@ func NewApplicationIdFromString(src string) (ApplicationId, error) {
 	if len(parts) < 3 {
 		return ApplicationId{}, fmt.Errorf("invalid application id: %s", src)
 	}
-	return ApplicationId{Namespace: parts[0], Kind: ApplicationKind(parts[1]), Name: parts[2]}, nil
+	id := ApplicationId{Namespace: parts[0], Kind: ApplicationKind(parts[1]), Name: parts[2]}
+	if cluster, ns, ok := strings.Cut(id.Namespace, "@"); ok {
+		id.ClusterId, id.Namespace = cluster, ns
+	}
+	return id, nil
+}
+
+// WithCluster returns the id qualified with the cluster name.
+func (a ApplicationId) WithCluster(cluster string) ApplicationId {
+	a.ClusterId = cluster
+	return a
 }
 
 func (a ApplicationId) IsZero() bool {
@@ -55,6 +68,9 @@ func (a ApplicationId) IsZero() bool {
 }
 
 func (a ApplicationId) String() string {
+	if a.ClusterId != "" {
+		return fmt.Sprintf("%s@%s:%s:%s", a.ClusterId, a.Namespace, a.Kind, a.Name)
+	}
 	return fmt.Sprintf("%s:%s:%s", a.Namespace, a.Kind, a.Name)
 }
 
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplicationIdString(t *testing.T) {
+	id := NewApplicationId("shop", ApplicationKindDeployment, "catalog")
+	assert.Equal(t, "shop:Deployment:catalog", id.String())
+	assert.Equal(t, "eu-1@shop:Deployment:catalog", id.WithCluster("eu-1").String())
+
+	for _, s := range []string{"shop:Deployment:catalog", "eu-1@shop:Deployment:catalog", "eu-1@external:ExternalService:db.example.com:5432"} {
+		id, err := NewApplicationIdFromString(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, id.String())
+	}
+
+	id, err := NewApplicationIdFromString("eu-1@_:Unknown:redis")
+	require.NoError(t, err)
+	assert.Equal(t, ApplicationId{ClusterId: "eu-1", Namespace: "_", Kind: ApplicationKindUnknown, Name: "redis"}, id)
+}
@@ -16,6 +16,7 @@ type IncidentDetails struct {
 	LatencyBurnRates      []BurnRate `json:"latency_burn_rates"`
 	AvailabilityImpact    Impact     `json:"availability_impact"`
 	LatencyImpact         Impact     `json:"latency_impact"`
+	ReopenCount           int        `json:"reopen_count,omitempty"`
 }
 
 type RCA struct {
@@ -27,7 +28,7 @@ type RCA struct {
 	DetailedRootCause string          `json:"detailed_root_cause_analysis"`
 	PropagationMap    *PropagationMap `json:"propagation_map"`
 	Widgets           []*Widget       `json:"widgets"`
-    Insights          []string        `json:"insights,omitempty"`
+	Insights          []string        `json:"insights,omitempty"`
 }
 
 type PropagationMap struct {
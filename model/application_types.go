@@ -33,6 +33,9 @@ const (
 	ApplicationTypePython          ApplicationType = "python"
 	ApplicationTypeRuby            ApplicationType = "ruby"
 	ApplicationTypeEnvoy           ApplicationType = "envoy"
+	ApplicationTypeNginx           ApplicationType = "nginx"
+	ApplicationTypeHaproxy         ApplicationType = "haproxy"
+	ApplicationTypeKubernetes      ApplicationType = "kubernetes"
 	ApplicationTypePrometheus      ApplicationType = "prometheus"
 	ApplicationTypeVictoriaMetrics ApplicationType = "victoria-metrics"
 	ApplicationTypeVictoriaLogs    ApplicationType = "victoria-logs"
@@ -112,6 +115,22 @@ func (at ApplicationType) AuditReport() AuditReportName {
 		return AuditReportMongodb
 	case ApplicationTypeMemcached:
 		return AuditReportMemcached
+	case ApplicationTypeKafka:
+		return AuditReportKafka
+	case ApplicationTypeRabbitmq:
+		return AuditReportRabbitmq
+	case ApplicationTypeNats:
+		return AuditReportNats
+	case ApplicationTypeElasticsearch, ApplicationTypeOpensearch:
+		return AuditReportElastic
+	case ApplicationTypeCassandra:
+		return AuditReportCassandra
+	case ApplicationTypeClickHouse:
+		return AuditReportClickHouse
+	case ApplicationTypeEnvoy, ApplicationTypeNginx, ApplicationTypeHaproxy:
+		return AuditReportProxy
+	case ApplicationTypeKubernetes:
+		return AuditReportKubernetes
 	case ApplicationTypeJava:
 		return AuditReportJvm
 	case ApplicationTypeDotNet:
@@ -120,6 +139,8 @@ func (at ApplicationType) AuditReport() AuditReportName {
 		return AuditReportPython
 	case ApplicationTypeNodeJS:
 		return AuditReportNodejs
+	case ApplicationTypeGolang:
+		return AuditReportGo
 	}
 	return ""
 }
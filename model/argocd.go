@@ -0,0 +1,105 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+const (
+	ApplicationKindArgoCDApplication    ApplicationKind = "Application"
+	ApplicationKindArgoCDApplicationSet ApplicationKind = "ApplicationSet"
+)
+
+type ArgoCD struct {
+	Applications    map[ApplicationId]*ArgoCDApplication
+	ApplicationSets map[ApplicationId]*ArgoCDApplicationSet
+}
+
+func NewArgoCD() *ArgoCD {
+	return &ArgoCD{
+		Applications:    make(map[ApplicationId]*ArgoCDApplication),
+		ApplicationSets: make(map[ApplicationId]*ArgoCDApplicationSet),
+	}
+}
+
+type ArgoCDApplication struct {
+	Project         LabelLastValue
+	Repo            LabelLastValue
+	DestServer      LabelLastValue
+	DestNamespace   LabelLastValue
+	SyncStatus      LabelLastValue // Synced, OutOfSync or Unknown
+	HealthStatus    LabelLastValue // Healthy, Progressing, Degraded, Suspended, Missing or Unknown
+	Revision        LabelLastValue
+	revisionHistory map[string]*timeseries.TimeSeries
+
+	Syncs []*ArgoCDSync
+
+	// the applications deployed by this Argo CD Application
+	Workloads map[ApplicationId]bool
+}
+
+func NewArgoCDApplication() *ArgoCDApplication {
+	return &ArgoCDApplication{
+		revisionHistory: map[string]*timeseries.TimeSeries{},
+		Workloads:       map[ApplicationId]bool{},
+	}
+}
+
+// UpdateRevision records the revision reported at the points where the series has values.
+func (a *ArgoCDApplication) UpdateRevision(values *timeseries.TimeSeries, revision string) {
+	if revision == "" {
+		return
+	}
+	a.Revision.Update(values, revision)
+	a.revisionHistory[revision] = values
+}
+
+// RevisionAt returns the revision the application was synced to at the given time, if known.
+func (a *ArgoCDApplication) RevisionAt(t timeseries.Time) string {
+	res := ""
+	for revision, ts := range a.revisionHistory {
+		iter := ts.Iter()
+		for iter.Next() {
+			pt, v := iter.Value()
+			if pt > t {
+				break
+			}
+			if pt == t && !timeseries.IsNaN(v) && revision > res {
+				res = revision
+			}
+		}
+	}
+	return res
+}
+
+func (a *ArgoCDApplication) LastSync() *ArgoCDSync {
+	if len(a.Syncs) == 0 {
+		return nil
+	}
+	return a.Syncs[len(a.Syncs)-1]
+}
+
+func (a *ArgoCDApplication) SortSyncs() {
+	sort.Slice(a.Syncs, func(i, j int) bool {
+		if a.Syncs[i].Time == a.Syncs[j].Time {
+			return a.Syncs[i].Phase < a.Syncs[j].Phase
+		}
+		return a.Syncs[i].Time < a.Syncs[j].Time
+	})
+}
+
+type ArgoCDSync struct {
+	Time     timeseries.Time
+	Phase    string // Succeeded, Failed, Error, ...
+	Revision string
+}
+
+func (s *ArgoCDSync) Succeeded() bool {
+	return s.Phase == "Succeeded"
+}
+
+type ArgoCDApplicationSet struct {
+	Status            LabelLastValue
+	OwnedApplications *timeseries.TimeSeries
+}
@@ -25,14 +25,25 @@ const (
 	AuditReportMongodb     AuditReportName = "Mongodb"
 	AuditReportMemcached   AuditReportName = "Memcached"
 	AuditReportMysql       AuditReportName = "Mysql"
+	AuditReportKafka       AuditReportName = "Kafka"
+	AuditReportRabbitmq    AuditReportName = "RabbitMQ"
+	AuditReportNats        AuditReportName = "NATS"
+	AuditReportElastic     AuditReportName = "Elasticsearch"
+	AuditReportCassandra   AuditReportName = "Cassandra"
+	AuditReportClickHouse  AuditReportName = "ClickHouse"
+	AuditReportProxy       AuditReportName = "Proxy"
+	AuditReportKubernetes  AuditReportName = "Kubernetes"
 	AuditReportJvm         AuditReportName = "JVM"
 	AuditReportDotNet      AuditReportName = ".NET"
 	AuditReportPython      AuditReportName = "Python"
 	AuditReportNodejs      AuditReportName = "Node.js"
+	AuditReportGo          AuditReportName = "Go"
 	AuditReportNode        AuditReportName = "Node"
 	AuditReportDeployments AuditReportName = "Deployments"
+	AuditReportJobs        AuditReportName = "Jobs"
 	AuditReportProfiling   AuditReportName = "Profiling"
 	AuditReportTracing     AuditReportName = "Tracing"
+	AuditReportAnomalies   AuditReportName = "Anomalies"
 )
 
 type ConfigurationHint struct {
@@ -155,6 +166,7 @@ func (r *AuditReport) CreateCheck(cfg CheckConfig) *Check {
 		ConditionFormatTemplate: cfg.ConditionFormatTemplate,
 
 		typ:             cfg.Type,
+		severity:        cfg.Severity,
 		messageTemplate: cfg.MessageTemplate,
 		items:           utils.NewStringSet(),
 	}
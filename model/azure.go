@@ -0,0 +1,22 @@
+package model
+
+import "github.com/coroot/coroot/timeseries"
+
+type AzureDatabase struct {
+	Engine LabelLastValue
+
+	LifeSpan *timeseries.TimeSeries
+}
+
+func (d *AzureDatabase) ApplicationType() ApplicationType {
+	if d == nil {
+		return ApplicationTypeUnknown
+	}
+	switch d.Engine.Value() {
+	case "postgres":
+		return ApplicationTypePostgres
+	case "mysql":
+		return ApplicationTypeMysql
+	}
+	return ApplicationTypeUnknown
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type Cassandra struct {
+	Up *timeseries.TimeSeries
+
+	ReadLatency  map[string]*timeseries.TimeSeries // the 99th percentile, by keyspace
+	WriteLatency map[string]*timeseries.TimeSeries // the 99th percentile, by keyspace
+
+	PendingCompactions *timeseries.TimeSeries
+	DroppedMessages    map[string]*timeseries.TimeSeries // per second, by message type
+	Hints              *timeseries.TimeSeries            // per second
+
+	// the state of the peers as seen by the node through gossip: 1 is up, 0 is down
+	Endpoints map[string]*timeseries.TimeSeries
+}
+
+func NewCassandra() *Cassandra {
+	return &Cassandra{
+		ReadLatency:     map[string]*timeseries.TimeSeries{},
+		WriteLatency:    map[string]*timeseries.TimeSeries{},
+		DroppedMessages: map[string]*timeseries.TimeSeries{},
+		Endpoints:       map[string]*timeseries.TimeSeries{},
+	}
+}
+
+func (c *Cassandra) IsUp() bool {
+	return c.Up.Last() > 0
+}
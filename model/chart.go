@@ -424,6 +424,9 @@ func EventsToAnnotations(events []*ApplicationEvent, ctx timeseries.Context) []A
 			case ApplicationEventTypeInstanceDown:
 				msgs = append(msgs, e.Details+" is down")
 				i = "mdi-alert-octagon-outline"
+			case ApplicationEventTypeArgoCDSync:
+				msgs = append(msgs, e.Details)
+				i = "mdi-sync"
 			}
 			if icon == "" {
 				icon = i
@@ -57,6 +57,7 @@ type CheckConfig struct {
 	Unit                    CheckUnit
 	MessageTemplate         string
 	ConditionFormatTemplate string
+	Severity                Status // the status of the failed check, WARNING if not set
 }
 
 var Checks = struct {
@@ -76,6 +77,7 @@ var Checks = struct {
 	NetworkTCPConnections      CheckConfig
 	InstanceAvailability       CheckConfig
 	DeploymentStatus           CheckConfig
+	Anomalies                  CheckConfig
 	InstanceRestarts           CheckConfig
 	RedisAvailability          CheckConfig
 	RedisLatency               CheckConfig
@@ -92,6 +94,10 @@ var Checks = struct {
 	DotNetAvailability         CheckConfig
 	PythonGILWaitingTime       CheckConfig
 	NodejsEventLoopBlockedTime CheckConfig
+	GoGoroutineLeak            CheckConfig
+	GoGcCpu                    CheckConfig
+	GoMemoryLimit              CheckConfig
+	GoSchedulerLatency         CheckConfig
 	DnsLatency                 CheckConfig
 	DnsServerErrors            CheckConfig
 	DnsNxdomainErrors          CheckConfig
@@ -99,6 +105,60 @@ var Checks = struct {
 	MysqlReplicationStatus     CheckConfig
 	MysqlReplicationLag        CheckConfig
 	MysqlConnections           CheckConfig
+	KafkaAvailability          CheckConfig
+	KafkaUnderReplicated       CheckConfig
+	KafkaOfflinePartitions     CheckConfig
+	KafkaIsrShrinks            CheckConfig
+	KafkaConsumerLag           CheckConfig
+	RabbitmqAvailability       CheckConfig
+	RabbitmqQueueGrowth        CheckConfig
+	RabbitmqUnackedMessages    CheckConfig
+	RabbitmqNoConsumers        CheckConfig
+	RabbitmqAlarms             CheckConfig
+	NatsAvailability           CheckConfig
+	NatsSlowConsumers          CheckConfig
+	NatsPendingGrowth          CheckConfig
+	NatsAckPending             CheckConfig
+	NatsNoConsumers            CheckConfig
+	ElasticsearchClusterHealth CheckConfig
+	ElasticsearchUnassigned    CheckConfig
+	ElasticsearchHeapUsage     CheckConfig
+	ElasticsearchSearchLatency CheckConfig
+	ElasticsearchIndexLatency  CheckConfig
+	ElasticsearchRejections    CheckConfig
+	CassandraAvailability      CheckConfig
+	CassandraReadLatency       CheckConfig
+	CassandraWriteLatency      CheckConfig
+	CassandraCompactions       CheckConfig
+	CassandraDroppedMessages   CheckConfig
+	CassandraHints             CheckConfig
+	ClickHouseAvailability     CheckConfig
+	ClickHouseParts            CheckConfig
+	ClickHouseMerges           CheckConfig
+	ClickHouseReplicationQueue CheckConfig
+	ClickHouseReplicationDelay CheckConfig
+	ClickHouseKeeperErrors     CheckConfig
+	ClickHouseFailedQueries    CheckConfig
+	ClickHouseMemoryLimit      CheckConfig
+	ClickHouseDiskUsage        CheckConfig
+
+	ProxyUpstreamErrors          CheckConfig
+	ProxyUpstreamConnectFailures CheckConfig
+	ProxyCircuitBreakers         CheckConfig
+
+	KubernetesAPIServerLatency     CheckConfig
+	KubernetesAPIServerErrors      CheckConfig
+	KubernetesEtcdFsyncLatency     CheckConfig
+	KubernetesEtcdCommitLatency    CheckConfig
+	KubernetesEtcdLeaderChanges    CheckConfig
+	KubernetesSchedulerPendingPods CheckConfig
+	KubernetesKubeletPLEG          CheckConfig
+	KubernetesCoreDNSErrors        CheckConfig
+	KubernetesCoreDNSLatency       CheckConfig
+
+	JobFailedRuns          CheckConfig
+	JobDurationRegression  CheckConfig
+	CronJobMissedSchedules CheckConfig
 }{
 	index: map[CheckId]*CheckConfig{},
 
@@ -217,6 +277,13 @@ var Checks = struct {
 		MessageTemplate:         `the rollout has already been in progress for {{.Value}}`,
 		ConditionFormatTemplate: "a rollout is in progress > <threshold>",
 	},
+	Anomalies: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Anomalies",
+		DefaultThreshold:        5,
+		MessageTemplate:         `anomalous behavior of {{.Items "signal"}}`,
+		ConditionFormatTemplate: "the deviation of a signal from its baseline > <threshold> standard deviations",
+	},
 	RedisAvailability: CheckConfig{
 		Type:                    CheckTypeItemBased,
 		Title:                   "Redis availability",
@@ -330,6 +397,38 @@ var Checks = struct {
 		ConditionFormatTemplate: "the time Node.js event loop executes blocking code > <threshold>",
 		Unit:                    CheckUnitSecond,
 	},
+	GoGoroutineLeak: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Go goroutine leak",
+		DefaultThreshold:        20,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `the number of goroutines is growing steadily on {{.Items "Go instance"}}`,
+		ConditionFormatTemplate: "the number of goroutines is growing by > <threshold> per hour",
+	},
+	GoGcCpu: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Go GC CPU usage",
+		DefaultThreshold:        10,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `high garbage collection overhead on {{.Items "Go instance"}}`,
+		ConditionFormatTemplate: "the share of CPU time spent on garbage collection > <threshold>",
+	},
+	GoMemoryLimit: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Go memory limit",
+		DefaultThreshold:        90,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `the runtime memory is approaching the memory limit on {{.Items "Go instance"}}`,
+		ConditionFormatTemplate: "the memory used by the Go runtime > <threshold> of the soft memory limit (GOMEMLIMIT)",
+	},
+	GoSchedulerLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Go scheduler latency",
+		DefaultThreshold:        0.01,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `high goroutine scheduling latency on {{.Items "Go instance"}}`,
+		ConditionFormatTemplate: "the 99th percentile of the time goroutines wait to be scheduled > <threshold>",
+	},
 	DnsLatency: CheckConfig{
 		Type:                    CheckTypeValueBased,
 		Title:                   "DNS latency",
@@ -383,6 +482,387 @@ var Checks = struct {
 		ConditionFormatTemplate: "the number of connections > <threshold> of `max_connections`",
 		Unit:                    CheckUnitPercent,
 	},
+	KafkaAvailability: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Kafka availability",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "kafka broker"}} unavailable`,
+		ConditionFormatTemplate: "the number of unavailable kafka brokers > <threshold>",
+	},
+	KafkaUnderReplicated: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "Kafka under-replicated partitions",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "partition"}} under-replicated`,
+		ConditionFormatTemplate: "the number of under-replicated partitions > <threshold>",
+	},
+	KafkaOfflinePartitions: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "Kafka offline partitions",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "partition"}} without a leader`,
+		ConditionFormatTemplate: "the number of partitions without an active leader > <threshold>",
+	},
+	KafkaIsrShrinks: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "Kafka ISR shrinks",
+		DefaultThreshold:        0,
+		MessageTemplate:         `in-sync replica sets shrank {{.Count "time"}}`,
+		ConditionFormatTemplate: "the number of ISR (in-sync replica set) shrinks > <threshold>",
+	},
+	KafkaConsumerLag: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Kafka consumer lag",
+		DefaultThreshold:        300,
+		MessageTemplate:         `{{.ItemsWithToBe "consumer group"}} falling behind`,
+		ConditionFormatTemplate: "the consumer group lag has been growing for > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	RabbitmqAvailability: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ availability",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "rabbitmq node"}} unavailable`,
+		ConditionFormatTemplate: "the number of unavailable rabbitmq nodes > <threshold>",
+	},
+	RabbitmqQueueGrowth: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ queue depth",
+		DefaultThreshold:        300,
+		MessageTemplate:         `{{.ItemsWithToBe "queue"}} growing`,
+		ConditionFormatTemplate: "the number of messages ready for delivery has been growing for > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	RabbitmqUnackedMessages: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ unacknowledged messages",
+		DefaultThreshold:        1000,
+		MessageTemplate:         `{{.ItemsWithHave "queue"}} too many unacknowledged messages`,
+		ConditionFormatTemplate: "the number of messages delivered but not yet acknowledged > <threshold>",
+	},
+	RabbitmqNoConsumers: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ consumers",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithHave "queue"}} lost all consumers`,
+		ConditionFormatTemplate: "the number of consumers of a queue has dropped to zero",
+	},
+	RabbitmqAlarms: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "RabbitMQ resource alarms",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "rabbitmq node"}} blocking publishers due to a memory or disk alarm`,
+		ConditionFormatTemplate: "a memory or free disk space alarm is in effect",
+	},
+	NatsAvailability: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "NATS availability",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "nats server"}} unavailable`,
+		ConditionFormatTemplate: "the number of unavailable nats servers > <threshold>",
+	},
+	NatsSlowConsumers: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "NATS slow consumers",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "slow consumer"}} detected`,
+		ConditionFormatTemplate: "the number of clients disconnected for not keeping up with the message flow > <threshold>",
+	},
+	NatsPendingGrowth: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "NATS pending messages",
+		DefaultThreshold:        300,
+		MessageTemplate:         `{{.ItemsWithToBe "JetStream consumer"}} falling behind`,
+		ConditionFormatTemplate: "the number of messages pending for a JetStream consumer has been growing for > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	NatsAckPending: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "NATS unacknowledged messages",
+		DefaultThreshold:        1000,
+		MessageTemplate:         `{{.ItemsWithHave "JetStream consumer"}} too many unacknowledged messages`,
+		ConditionFormatTemplate: "the number of messages delivered to a JetStream consumer but not yet acknowledged > <threshold>",
+	},
+	NatsNoConsumers: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "NATS consumers",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithHave "JetStream stream"}} lost all consumers`,
+		ConditionFormatTemplate: "the number of consumers of a JetStream stream has dropped to zero",
+	},
+	ElasticsearchClusterHealth: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Elasticsearch cluster health",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "cluster"}} not healthy`,
+		ConditionFormatTemplate: "the cluster health status is yellow or red",
+	},
+	ElasticsearchUnassigned: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "Elasticsearch unassigned shards",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "shard"}} unassigned`,
+		ConditionFormatTemplate: "the number of unassigned shards > <threshold>",
+	},
+	ElasticsearchHeapUsage: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Elasticsearch heap usage",
+		DefaultThreshold:        85,
+		MessageTemplate:         `{{.ItemsWithToBe "node"}} running out of JVM heap`,
+		ConditionFormatTemplate: "the JVM heap usage of a node > <threshold>",
+		Unit:                    CheckUnitPercent,
+	},
+	ElasticsearchSearchLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Elasticsearch search latency",
+		DefaultThreshold:        0.1,
+		MessageTemplate:         `{{.ItemsWithToBe "node"}} serving search queries slowly`,
+		ConditionFormatTemplate: "the average search query time of a node > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	ElasticsearchIndexLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Elasticsearch indexing latency",
+		DefaultThreshold:        0.05,
+		MessageTemplate:         `{{.ItemsWithToBe "node"}} indexing documents slowly`,
+		ConditionFormatTemplate: "the average time to index a document on a node > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	ElasticsearchRejections: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "Elasticsearch thread pool rejections",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "task"}} rejected by thread pools`,
+		ConditionFormatTemplate: "the number of tasks rejected by the thread pools > <threshold>",
+	},
+	CassandraAvailability: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cassandra availability",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "cassandra node"}} down`,
+		ConditionFormatTemplate: "the number of unavailable cassandra nodes > <threshold>",
+	},
+	CassandraReadLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cassandra read latency",
+		DefaultThreshold:        0.1,
+		MessageTemplate:         `{{.ItemsWithToBe "keyspace"}} serving reads slowly`,
+		ConditionFormatTemplate: "the 99th percentile of read latency of a keyspace > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	CassandraWriteLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cassandra write latency",
+		DefaultThreshold:        0.05,
+		MessageTemplate:         `{{.ItemsWithToBe "keyspace"}} serving writes slowly`,
+		ConditionFormatTemplate: "the 99th percentile of write latency of a keyspace > <threshold>",
+		Unit:                    CheckUnitSecond,
+	},
+	CassandraCompactions: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Cassandra pending compactions",
+		DefaultThreshold:        100,
+		MessageTemplate:         `{{.ItemsWithHave "cassandra node"}} too many pending compactions`,
+		ConditionFormatTemplate: "the number of pending compaction tasks of a node > <threshold>",
+	},
+	CassandraDroppedMessages: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "Cassandra dropped messages",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "message"}} dropped`,
+		ConditionFormatTemplate: "the number of messages dropped for not being processed within the timeout > <threshold>",
+	},
+	CassandraHints: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "Cassandra hints",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "hint"}} written for unavailable replicas`,
+		ConditionFormatTemplate: "the number of hints written for unavailable replicas > <threshold>",
+	},
+	ClickHouseAvailability: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse availability",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.ItemsWithToBe "clickhouse server"}} unavailable`,
+		ConditionFormatTemplate: "the number of unavailable clickhouse servers > <threshold>",
+	},
+	ClickHouseParts: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse parts",
+		DefaultThreshold:        300,
+		MessageTemplate:         `{{.ItemsWithHave "clickhouse server"}} too many parts in a partition`,
+		ConditionFormatTemplate: "the maximum number of active parts in a partition > <threshold>",
+	},
+	ClickHouseMerges: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse merges",
+		DefaultThreshold:        90,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `{{.ItemsWithHave "clickhouse server"}} a merge backlog`,
+		ConditionFormatTemplate: "the usage of the background merge and mutation pool > <threshold>",
+	},
+	ClickHouseReplicationQueue: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse replication queue",
+		DefaultThreshold:        100,
+		MessageTemplate:         `{{.ItemsWithHave "clickhouse server"}} a large replication queue`,
+		ConditionFormatTemplate: "the number of replication tasks in the queue of a replicated table > <threshold>",
+	},
+	ClickHouseReplicationDelay: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse replication delay",
+		DefaultThreshold:        300,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `{{.ItemsWithToBe "clickhouse server"}} lagging behind`,
+		ConditionFormatTemplate: "the maximum replication delay of a replicated table > <threshold>",
+	},
+	ClickHouseKeeperErrors: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "ClickHouse Keeper sessions",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "ZooKeeper/Keeper session error"}} occurred`,
+		ConditionFormatTemplate: "the number of ZooKeeper/Keeper connection and session errors > <threshold>",
+	},
+	ClickHouseFailedQueries: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse failed queries",
+		DefaultThreshold:        5,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `{{.ItemsWithToBe "clickhouse server"}} failing queries`,
+		ConditionFormatTemplate: "the percentage of failed queries > <threshold>",
+	},
+	ClickHouseMemoryLimit: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "ClickHouse memory limit",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "query"}} exceeded the memory limit`,
+		ConditionFormatTemplate: "the number of queries failed due to exceeding the memory limit > <threshold>",
+	},
+	ClickHouseDiskUsage: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "ClickHouse disk usage",
+		DefaultThreshold:        85,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `{{.ItemsWithToBe "disk"}} running out of space`,
+		ConditionFormatTemplate: "the disk space usage of a clickhouse disk > <threshold>",
+	},
+	ProxyUpstreamErrors: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Upstream errors",
+		DefaultThreshold:        5,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `{{.ItemsWithToBe "upstream"}} responding with 5xx errors`,
+		ConditionFormatTemplate: "the percentage of 5xx responses of an upstream > <threshold>",
+	},
+	ProxyUpstreamConnectFailures: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Upstream connections",
+		DefaultThreshold:        0,
+		MessageTemplate:         `failed to connect to {{.Items "upstream"}}`,
+		ConditionFormatTemplate: "the number of failed connection attempts to an upstream > <threshold>",
+	},
+	ProxyCircuitBreakers: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Circuit breakers",
+		DefaultThreshold:        0,
+		MessageTemplate:         `circuit breakers tripped for {{.Items "upstream"}}`,
+		ConditionFormatTemplate: "the number of requests rejected by the circuit breakers of an upstream > <threshold>",
+	},
+	KubernetesAPIServerLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "API server latency",
+		DefaultThreshold:        1,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `{{.ItemsWithToBe "API server"}} serving requests slowly`,
+		ConditionFormatTemplate: "the 99th percentile of non-streaming request latency of an API server > <threshold>",
+	},
+	KubernetesAPIServerErrors: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "API server errors",
+		DefaultThreshold:        1,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `{{.ItemsWithToBe "API server"}} responding with 5xx errors`,
+		ConditionFormatTemplate: "the percentage of 5xx responses of an API server > <threshold>",
+	},
+	KubernetesEtcdFsyncLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "etcd WAL fsync latency",
+		DefaultThreshold:        0.01,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `{{.ItemsWithToBe "etcd member"}} experiencing slow disk writes`,
+		ConditionFormatTemplate: "the 99th percentile of WAL fsync latency of an etcd member > <threshold>",
+	},
+	KubernetesEtcdCommitLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "etcd commit latency",
+		DefaultThreshold:        0.025,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `{{.ItemsWithToBe "etcd member"}} committing to the backend slowly`,
+		ConditionFormatTemplate: "the 99th percentile of backend commit latency of an etcd member > <threshold>",
+	},
+	KubernetesEtcdLeaderChanges: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "etcd leader changes",
+		DefaultThreshold:        0,
+		MessageTemplate:         `the etcd cluster has changed its leader {{.Count "time"}}`,
+		ConditionFormatTemplate: "the number of etcd leader changes > <threshold>",
+	},
+	KubernetesSchedulerPendingPods: CheckConfig{
+		Type:                    CheckTypeValueBased,
+		Title:                   "Unschedulable pods",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Value}} pods cannot be scheduled`,
+		ConditionFormatTemplate: "the number of pods in the unschedulable queue of the scheduler > <threshold>",
+	},
+	KubernetesKubeletPLEG: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "Kubelet PLEG",
+		DefaultThreshold:        1,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `the kubelet is slow to relist pods on {{.Items "node"}}`,
+		ConditionFormatTemplate: "the 99th percentile of the kubelet's PLEG relist duration on a node > <threshold>",
+	},
+	KubernetesCoreDNSErrors: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "CoreDNS errors",
+		DefaultThreshold:        1,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `{{.ItemsWithToBe "CoreDNS instance"}} responding with SERVFAIL`,
+		ConditionFormatTemplate: "the percentage of SERVFAIL responses of a CoreDNS instance > <threshold>",
+	},
+	KubernetesCoreDNSLatency: CheckConfig{
+		Type:                    CheckTypeItemBased,
+		Title:                   "CoreDNS latency",
+		DefaultThreshold:        0.1,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `{{.ItemsWithToBe "CoreDNS instance"}} serving queries slowly`,
+		ConditionFormatTemplate: "the 99th percentile of query latency of a CoreDNS instance > <threshold>",
+	},
+	JobFailedRuns: CheckConfig{
+		Type:                    CheckTypeEventBased,
+		Title:                   "Failed runs",
+		DefaultThreshold:        0,
+		MessageTemplate:         `{{.Count "run"}} failed since the last successful one`,
+		ConditionFormatTemplate: "the number of failed runs since the last successful one > <threshold>",
+		Severity:                CRITICAL,
+	},
+	JobDurationRegression: CheckConfig{
+		Type:                    CheckTypeValueBased,
+		Title:                   "Run duration",
+		DefaultThreshold:        100,
+		Unit:                    CheckUnitPercent,
+		MessageTemplate:         `the last run took {{.Value}} longer than the previous ones on average`,
+		ConditionFormatTemplate: "the duration of the last run exceeds the average duration of the previous runs by > <threshold>",
+	},
+	CronJobMissedSchedules: CheckConfig{
+		Type:                    CheckTypeValueBased,
+		Title:                   "Missed schedules",
+		DefaultThreshold:        300,
+		Unit:                    CheckUnitSecond,
+		MessageTemplate:         `the CronJob should have been scheduled {{.Value}} ago`,
+		ConditionFormatTemplate: "a scheduled run hasn't been started for > <threshold>",
+		Severity:                CRITICAL,
+	},
 }
 
 func init() {
@@ -442,6 +922,7 @@ type Check struct {
 	ConditionFormatTemplate string    `json:"condition_format_template"`
 
 	typ             CheckType
+	severity        Status
 	messageTemplate string
 	items           *utils.StringSet
 	count           int64
@@ -538,7 +1019,11 @@ func (ch *Check) Calc() {
 		ch.SetStatus(UNKNOWN, "failed to render message: %s", err)
 		return
 	}
-	ch.SetStatus(WARNING, buf.String())
+	status := WARNING
+	if ch.severity != UNKNOWN {
+		status = ch.severity
+	}
+	ch.SetStatus(status, buf.String())
 }
 
 type CheckConfigSource string
@@ -551,16 +1036,31 @@ type CheckConfigSimple struct {
 	Threshold float32 `json:"threshold"`
 }
 
+// CheckConfigSLOSpans defines an SLI calculated from the server spans of an OpenTelemetry service stored in ClickHouse.
+type CheckConfigSLOSpans struct {
+	// ServiceName defaults to the tracing service of the application.
+	ServiceName string `json:"service_name,omitempty"`
+	// Route is a regular expression the http.route attribute of the spans must match.
+	Route string `json:"route,omitempty"`
+}
+
 type CheckConfigSLOAvailability struct {
-	Custom              bool    `json:"custom"`
-	TotalRequestsQuery  string  `json:"total_requests_query"`
-	FailedRequestsQuery string  `json:"failed_requests_query"`
-	ObjectivePercentage float32 `json:"objective_percentage"`
+	Custom              bool                 `json:"custom"`
+	TotalRequestsQuery  string               `json:"total_requests_query"`
+	FailedRequestsQuery string               `json:"failed_requests_query"`
+	Spans               *CheckConfigSLOSpans `json:"spans,omitempty"`
+	ObjectivePercentage float32              `json:"objective_percentage"`
 
 	Source CheckConfigSource `json:"source,omitempty"`
 	Error  string            `json:"error,omitempty"`
 }
 
+// FromSpans reports whether the failed and total requests are counted from the server spans
+// (the spans with the error status are failed) instead of the PromQL queries.
+func (cfg *CheckConfigSLOAvailability) FromSpans() bool {
+	return cfg.Custom && cfg.Spans != nil
+}
+
 func (cfg *CheckConfigSLOAvailability) Total() string {
 	return fmt.Sprintf(`sum(rate(%s[$RANGE]))`, cfg.TotalRequestsQuery)
 }
@@ -570,15 +1070,22 @@ func (cfg *CheckConfigSLOAvailability) Failed() string {
 }
 
 type CheckConfigSLOLatency struct {
-	Custom              bool    `json:"custom"`
-	HistogramQuery      string  `json:"histogram_query"`
-	ObjectiveBucket     float32 `json:"objective_bucket"`
-	ObjectivePercentage float32 `json:"objective_percentage"`
+	Custom              bool                 `json:"custom"`
+	HistogramQuery      string               `json:"histogram_query"`
+	Spans               *CheckConfigSLOSpans `json:"spans,omitempty"`
+	ObjectiveBucket     float32              `json:"objective_bucket"`
+	ObjectivePercentage float32              `json:"objective_percentage"`
 
 	Source CheckConfigSource `json:"source,omitempty"`
 	Error  string            `json:"error,omitempty"`
 }
 
+// FromSpans reports whether the latency histogram is built from the durations of the server spans
+// instead of the PromQL histogram query.
+func (cfg *CheckConfigSLOLatency) FromSpans() bool {
+	return cfg.Custom && cfg.Spans != nil
+}
+
 func (cfg *CheckConfigSLOLatency) Histogram() string {
 	return fmt.Sprintf("sum by(le)(rate(%s[$RANGE]))", cfg.HistogramQuery)
 }
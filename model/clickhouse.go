@@ -0,0 +1,61 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+// ClickHouse holds the metrics of a ClickHouse server exposed by its built-in Prometheus endpoint.
+type ClickHouse struct {
+	Up *timeseries.TimeSeries
+
+	MaxPartsPerPartition *timeseries.TimeSeries
+	MergesRunning        *timeseries.TimeSeries
+	MergesPoolSize       *timeseries.TimeSeries
+
+	ReplicationQueueSize *timeseries.TimeSeries
+	ReplicationDelay     *timeseries.TimeSeries // seconds
+	ReadonlyReplicas     *timeseries.TimeSeries
+	KeeperErrors         *timeseries.TimeSeries // per second
+
+	Queries             *timeseries.TimeSeries // per second
+	FailedQueries       *timeseries.TimeSeries // per second
+	MemoryLimitExceeded *timeseries.TimeSeries // per second
+
+	Disks map[string]*ClickHouseDisk
+}
+
+func NewClickHouse() *ClickHouse {
+	return &ClickHouse{Disks: map[string]*ClickHouseDisk{}}
+}
+
+func (ch *ClickHouse) IsUp() bool {
+	return ch.Up.Last() > 0
+}
+
+// MergesPoolUsage returns the percentage of the background merge and mutation pool in use.
+func (ch *ClickHouse) MergesPoolUsage() *timeseries.TimeSeries {
+	return timeseries.Aggregate2(ch.MergesRunning, ch.MergesPoolSize, func(running, size float32) float32 { return running / size * 100 })
+}
+
+func (ch *ClickHouse) FailedQueriesPercent() *timeseries.TimeSeries {
+	return timeseries.Aggregate2(ch.FailedQueries, ch.Queries, func(failed, total float32) float32 { return failed / total * 100 })
+}
+
+func (ch *ClickHouse) GetOrCreateDisk(name string) *ClickHouseDisk {
+	d := ch.Disks[name]
+	if d == nil {
+		d = &ClickHouseDisk{}
+		ch.Disks[name] = d
+	}
+	return d
+}
+
+// ClickHouseDisk reflects the free_space and total_space columns of system.disks.
+type ClickHouseDisk struct {
+	Available *timeseries.TimeSeries
+	Total     *timeseries.TimeSeries
+}
+
+func (d *ClickHouseDisk) Usage() *timeseries.TimeSeries {
+	return timeseries.Aggregate2(d.Available, d.Total, func(available, total float32) float32 { return (total - available) / total * 100 })
+}
@@ -119,3 +119,24 @@ func GuessService(services []string, w *World, app *Application) string {
 	}
 	return service
 }
+
+// UnclaimedServices returns the services that can't be attributed to any of the applications of the world.
+func UnclaimedServices(services []string, w *World) []string {
+	claimed := map[string]bool{}
+	for _, app := range w.Applications {
+		if app.Settings != nil && app.Settings.Tracing != nil {
+			claimed[app.Settings.Tracing.Service] = true
+			continue
+		}
+		if s := guessService(services, app.Id); s != "" {
+			claimed[s] = true
+		}
+	}
+	var res []string
+	for _, s := range services {
+		if !claimed[s] {
+			res = append(res, s)
+		}
+	}
+	return res
+}
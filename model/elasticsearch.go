@@ -0,0 +1,51 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+// Elasticsearch holds the metrics of an Elasticsearch or OpenSearch node.
+type Elasticsearch struct {
+	Up   *timeseries.TimeSeries
+	Node LabelLastValue
+
+	HeapUsed *timeseries.TimeSeries
+	HeapMax  *timeseries.TimeSeries
+
+	SearchTime    *timeseries.TimeSeries            // seconds/second
+	Searches      *timeseries.TimeSeries            // per second
+	IndexingTime  *timeseries.TimeSeries            // seconds/second
+	IndexedDocs   *timeseries.TimeSeries            // per second
+	RejectedTasks map[string]*timeseries.TimeSeries // per second, by thread pool
+
+	// the cluster-level metrics, shared by all the nodes of the cluster
+	Cluster *ElasticsearchCluster
+}
+
+func NewElasticsearch() *Elasticsearch {
+	return &Elasticsearch{RejectedTasks: map[string]*timeseries.TimeSeries{}}
+}
+
+func (es *Elasticsearch) IsUp() bool {
+	return es.Up.Last() > 0
+}
+
+func (es *Elasticsearch) HeapUsage() *timeseries.TimeSeries {
+	return timeseries.Aggregate2(es.HeapUsed, es.HeapMax, func(used, max float32) float32 { return used / max * 100 })
+}
+
+func (es *Elasticsearch) SearchLatency() *timeseries.TimeSeries {
+	return timeseries.Div(es.SearchTime, es.Searches)
+}
+
+func (es *Elasticsearch) IndexingLatency() *timeseries.TimeSeries {
+	return timeseries.Div(es.IndexingTime, es.IndexedDocs)
+}
+
+type ElasticsearchCluster struct {
+	Name             string
+	Status           LabelLastValue
+	Version          LabelLastValue
+	Nodes            *timeseries.TimeSeries
+	UnassignedShards *timeseries.TimeSeries
+}
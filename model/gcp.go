@@ -0,0 +1,35 @@
+package model
+
+import "github.com/coroot/coroot/timeseries"
+
+type CloudSQL struct {
+	State LabelLastValue
+
+	Engine LabelLastValue
+
+	LifeSpan *timeseries.TimeSeries
+}
+
+func (c *CloudSQL) ApplicationType() ApplicationType {
+	if c == nil {
+		return ApplicationTypeUnknown
+	}
+	switch c.Engine.Value() {
+	case "postgres":
+		return ApplicationTypePostgres
+	case "mysql":
+		return ApplicationTypeMysql
+	}
+	return ApplicationTypeUnknown
+}
+
+type Memorystore struct {
+	LifeSpan *timeseries.TimeSeries
+}
+
+func (m *Memorystore) ApplicationType() ApplicationType {
+	if m == nil {
+		return ApplicationTypeUnknown
+	}
+	return ApplicationTypeRedis
+}
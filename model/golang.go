@@ -0,0 +1,39 @@
+package model
+
+import "github.com/coroot/coroot/timeseries"
+
+// GOMEMLIMIT defaults to math.MaxInt64, which means there is no limit
+const goNoMemoryLimit = 1 << 62
+
+type GoRuntime struct {
+	Version LabelLastValue
+	Up      *timeseries.TimeSeries
+
+	Goroutines    *timeseries.TimeSeries
+	GcPauseTime   *timeseries.TimeSeries // seconds/second
+	GcCpuFraction *timeseries.TimeSeries // 0..1
+	HeapInuse     *timeseries.TimeSeries
+	MemoryUsed    *timeseries.TimeSeries // obtained from the OS and not released back, what GOMEMLIMIT bounds
+	MemoryLimit   *timeseries.TimeSeries // GOMEMLIMIT
+	SchedLatency  *timeseries.TimeSeries // the 99th percentile, seconds
+}
+
+func (g *GoRuntime) IsUp() bool {
+	return g.Up.Last() > 0
+}
+
+// SoftMemoryLimit returns GOMEMLIMIT with the unset values replaced by NaN.
+func (g *GoRuntime) SoftMemoryLimit() *timeseries.TimeSeries {
+	return g.MemoryLimit.Map(func(t timeseries.Time, v float32) float32 {
+		if v >= goNoMemoryLimit {
+			return timeseries.NaN
+		}
+		return v
+	})
+}
+
+// MemoryLimitUsage returns the memory used by the runtime in percent of GOMEMLIMIT.
+// The limit bounds all the memory mapped by the runtime (the heap, stacks, GC metadata, etc.), not only the heap in use.
+func (g *GoRuntime) MemoryLimitUsage() *timeseries.TimeSeries {
+	return timeseries.Aggregate2(g.MemoryUsed, g.SoftMemoryLimit(), func(used, limit float32) float32 { return used / limit * 100 })
+}
@@ -33,13 +33,17 @@ type Instance struct {
 
 	Pod *Pod
 
-	Rds         *Rds
-	Elasticache *Elasticache
-
-	Jvms   map[string]*Jvm
-	DotNet map[string]*DotNet
-	Python *Python
-	Nodejs *Nodejs
+	Rds           *Rds
+	Elasticache   *Elasticache
+	CloudSQL      *CloudSQL
+	Memorystore   *Memorystore
+	AzureDatabase *AzureDatabase
+
+	Jvms      map[string]*Jvm
+	DotNet    map[string]*DotNet
+	Python    *Python
+	Nodejs    *Nodejs
+	GoRuntime *GoRuntime
 
 	Volumes []*Volume
 
@@ -59,11 +63,18 @@ type Instance struct {
 	clusterRole      *timeseries.TimeSeries
 	ClusterComponent *Application
 
-	Postgres  *Postgres
-	Redis     *Redis
-	Mongodb   *Mongodb
-	Memcached *Memcached
-	Mysql     *Mysql
+	Postgres      *Postgres
+	Redis         *Redis
+	Mongodb       *Mongodb
+	Memcached     *Memcached
+	Mysql         *Mysql
+	Kafka         *Kafka
+	Rabbitmq      *Rabbitmq
+	Nats          *Nats
+	Elasticsearch *Elasticsearch
+	Cassandra     *Cassandra
+	ClickHouse    *ClickHouse
+	Proxy         *Proxy
 }
 
 func NewInstance(name string, owner *Application) *Instance {
@@ -91,6 +102,15 @@ func (instance *Instance) ApplicationTypes() map[ApplicationType]bool {
 	if t := instance.Elasticache.ApplicationType(); t != ApplicationTypeUnknown {
 		res[t] = true
 	}
+	if t := instance.CloudSQL.ApplicationType(); t != ApplicationTypeUnknown {
+		res[t] = true
+	}
+	if t := instance.Memorystore.ApplicationType(); t != ApplicationTypeUnknown {
+		res[t] = true
+	}
+	if t := instance.AzureDatabase.ApplicationType(); t != ApplicationTypeUnknown {
+		res[t] = true
+	}
 	return res
 }
 
@@ -106,6 +126,18 @@ func (instance *Instance) InstrumentedType() ApplicationType {
 		return ApplicationTypeMongodb
 	case instance.Memcached != nil:
 		return ApplicationTypeMemcached
+	case instance.Kafka != nil:
+		return ApplicationTypeKafka
+	case instance.Rabbitmq != nil:
+		return ApplicationTypeRabbitmq
+	case instance.Nats != nil:
+		return ApplicationTypeNats
+	case instance.Elasticsearch != nil:
+		return ApplicationTypeElasticsearch
+	case instance.Cassandra != nil:
+		return ApplicationTypeCassandra
+	case instance.ClickHouse != nil:
+		return ApplicationTypeClickHouse
 	}
 	return ApplicationTypeUnknown
 }
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type Kafka struct {
+	Up       *timeseries.TimeSeries
+	BrokerId LabelLastValue
+
+	// the broker-level metrics exposed by the JMX exporter
+	UnderReplicatedPartitions *timeseries.TimeSeries
+	OfflinePartitions         *timeseries.TimeSeries
+	IsrShrinks                *timeseries.TimeSeries
+	ActiveController          *timeseries.TimeSeries
+
+	// the cluster-level metrics exposed by kafka-exporter, shared by all the brokers of the cluster
+	Cluster *KafkaCluster
+}
+
+func NewKafka() *Kafka {
+	return &Kafka{}
+}
+
+func (k *Kafka) IsUp() bool {
+	return k.Up.Last() > 0
+}
+
+type KafkaCluster struct {
+	Brokers                   *timeseries.TimeSeries
+	UnderReplicatedPartitions *timeseries.TimeSeries
+	OfflinePartitions         *timeseries.TimeSeries
+	ConsumerGroups            map[string]*KafkaConsumerGroup
+}
+
+func NewKafkaCluster() *KafkaCluster {
+	return &KafkaCluster{ConsumerGroups: map[string]*KafkaConsumerGroup{}}
+}
+
+func (c *KafkaCluster) GetOrCreateConsumerGroup(name string) *KafkaConsumerGroup {
+	g := c.ConsumerGroups[name]
+	if g == nil {
+		g = &KafkaConsumerGroup{Name: name, Lag: map[string]*timeseries.TimeSeries{}}
+		c.ConsumerGroups[name] = g
+	}
+	return g
+}
+
+type KafkaConsumerGroup struct {
+	Name    string
+	Lag     map[string]*timeseries.TimeSeries // by topic
+	Members *timeseries.TimeSeries
+
+	// the applications consuming messages as members of the group
+	Consumers []*Application
+}
+
+func (g *KafkaConsumerGroup) TotalLag() *timeseries.TimeSeries {
+	total := timeseries.NewAggregate(timeseries.NanSum)
+	for _, lag := range g.Lag {
+		total.Add(lag)
+	}
+	return total.Get()
+}
+
+// KafkaLagGrowthDuration returns for how long the consumer lag has been growing: the time passed since the lag
+// was at a level it has never dropped back to and which is at most half of the current lag.
+// The lag that is being drained (is below half of its peak since then) isn't considered growing.
+func KafkaLagGrowthDuration(lag *timeseries.TimeSeries) timeseries.Duration {
+	if lag.IsEmpty() {
+		return 0
+	}
+	var times []timeseries.Time
+	var values []float32
+	iter := lag.Iter()
+	for iter.Next() {
+		t, v := iter.Value()
+		if timeseries.IsNaN(v) {
+			continue
+		}
+		times = append(times, t)
+		values = append(values, v)
+	}
+	if len(values) < 2 {
+		return 0
+	}
+	last := values[len(values)-1]
+	if last <= 0 {
+		return 0
+	}
+	since := -1
+	lowest, peak := last, last
+	for i := len(values) - 2; i >= 0; i-- {
+		v := values[i]
+		if v < lowest {
+			lowest = v
+			if last >= 2*v && last >= peak/2 {
+				since = i
+			}
+		}
+		if v > peak {
+			peak = v
+		}
+	}
+	if since < 0 {
+		return 0
+	}
+	return times[len(times)-1].Sub(times[since])
+}
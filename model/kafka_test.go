@@ -0,0 +1,23 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafkaLagGrowthDuration(t *testing.T) {
+	lag := func(vs ...float32) *timeseries.TimeSeries {
+		return timeseries.NewWithData(0, timeseries.Minute, vs)
+	}
+	assert.Equal(t, timeseries.Duration(0), KafkaLagGrowthDuration(nil))
+	assert.Equal(t, timeseries.Duration(0), KafkaLagGrowthDuration(lag(0, 0, 0)))
+	assert.Equal(t, timeseries.Duration(0), KafkaLagGrowthDuration(lag(5, 4, 6, 5, 6))) // a steady lag
+
+	assert.Equal(t, 4*timeseries.Minute, KafkaLagGrowthDuration(lag(0, 10, 20, 15, 30)))
+	assert.Equal(t, 2*timeseries.Minute, KafkaLagGrowthDuration(lag(50, 0, 0, 10, 20)))
+	assert.Equal(t, 3*timeseries.Minute, KafkaLagGrowthDuration(lag(100, 10, timeseries.NaN, 30, 40)))
+	assert.Equal(t, timeseries.Duration(0), KafkaLagGrowthDuration(lag(0, 100, 50, 10))) // the lag is being drained
+	assert.Equal(t, 2*timeseries.Minute, KafkaLagGrowthDuration(lag(0, 100, 5, 10, 20)))
+}
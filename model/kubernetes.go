@@ -22,20 +22,89 @@ const (
 	ApplicationKindDatabaseCluster    ApplicationKind = "DatabaseCluster"
 	ApplicationKindRds                ApplicationKind = "RDS"
 	ApplicationKindElasticacheCluster ApplicationKind = "ElasticacheCluster"
+	ApplicationKindCloudSQL           ApplicationKind = "CloudSQL"
+	ApplicationKindMemorystore        ApplicationKind = "Memorystore"
+	ApplicationKindAzureDatabase      ApplicationKind = "AzureDatabase"
 	ApplicationKindNomadJobGroup      ApplicationKind = "NomadJobGroup"
 	ApplicationKindArgoWorkflow       ApplicationKind = "Workflow"
 	ApplicationKindSparkApplication   ApplicationKind = "SparkApplication"
 	ApplicationKindCustomApplication  ApplicationKind = "CustomApplication"
+	ApplicationKindOTelService        ApplicationKind = "OTelService" // a service known only from its OpenTelemetry spans
 )
 
-type Job struct{}
+type JobStatus string
+
+const (
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusUnknown   JobStatus = "unknown"
+)
+
+// Job is a single run of a Kubernetes Job. A CronJob application has a Job per run.
+type Job struct {
+	Name           string
+	StartTime      timeseries.Time
+	CompletionTime timeseries.Time
+	Active         *timeseries.TimeSeries
+	Failed         *timeseries.TimeSeries // the number of failed pods, i.e. the number of retries
+	Completed      *timeseries.TimeSeries // the Complete condition
+	Stopped        *timeseries.TimeSeries // the Failed condition, the run won't be retried anymore
+	FailureReason  LabelLastValue
+}
+
+func (j *Job) Status() JobStatus {
+	switch {
+	case j.Stopped.Last() > 0:
+		return JobStatusFailed
+	case j.Completed.Last() > 0 || !j.CompletionTime.IsZero():
+		return JobStatusSucceeded
+	case j.Active.Last() > 0:
+		return JobStatusRunning
+	}
+	return JobStatusUnknown
+}
+
+// Duration returns the duration of a completed run or the time elapsed since the start of a running one.
+func (j *Job) Duration(now timeseries.Time) timeseries.Duration {
+	switch {
+	case j.StartTime.IsZero():
+		return 0
+	case !j.CompletionTime.IsZero():
+		return j.CompletionTime.Sub(j.StartTime)
+	case j.Status() == JobStatusRunning:
+		return now.Sub(j.StartTime)
+	}
+	return 0
+}
+
+func (j *Job) Retries() int {
+	// kube-state-metrics stops reporting the number of failed pods once the run has failed for a known reason
+	v := j.Failed.Reduce(timeseries.Max)
+	if timeseries.IsNaN(v) {
+		return 0
+	}
+	return int(v)
+}
 
 type CronJob struct {
-	Schedule          LabelLastValue
-	ConcurrencyPolicy LabelLastValue
-	StatusActive      *timeseries.TimeSeries
-	LastScheduleTime  *timeseries.TimeSeries
-	NextScheduleTime  *timeseries.TimeSeries
+	Schedule           LabelLastValue
+	ConcurrencyPolicy  LabelLastValue
+	Suspended          bool
+	StatusActive       *timeseries.TimeSeries
+	LastScheduleTime   timeseries.Time
+	LastSuccessfulTime timeseries.Time
+	NextScheduleTime   timeseries.Time
+}
+
+// MissedScheduleBy returns how long ago the CronJob should have been scheduled but wasn't.
+// The controller updates the last schedule time once it creates a Job,
+// so the next schedule time remains in the past until the missed run happens.
+func (cj *CronJob) MissedScheduleBy(now timeseries.Time) timeseries.Duration {
+	if cj.Suspended || cj.NextScheduleTime.IsZero() || !cj.NextScheduleTime.Before(now) {
+		return 0
+	}
+	return now.Sub(cj.NextScheduleTime)
 }
 
 type DaemonSet struct {
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+const (
+	ApplicationKindKubernetesControlPlane ApplicationKind = "KubernetesControlPlane"
+
+	SchedulerQueueUnschedulable = "unschedulable"
+)
+
+// KubernetesControlPlaneApplicationId is the id of the pseudo-application representing the cluster itself.
+var KubernetesControlPlaneApplicationId = NewApplicationId("_", ApplicationKindKubernetesControlPlane, "kubernetes")
+
+// KubernetesControlPlane holds the health metrics of the cluster components, keyed by the instance (or node) name.
+type KubernetesControlPlane struct {
+	APIServers map[string]*KubernetesAPIServer
+	Etcd       map[string]*KubernetesEtcd
+	Schedulers map[string]*KubernetesScheduler
+	Kubelets   map[string]*KubernetesKubelet
+	CoreDNS    map[string]*KubernetesCoreDNS
+}
+
+func NewKubernetesControlPlane() *KubernetesControlPlane {
+	return &KubernetesControlPlane{
+		APIServers: map[string]*KubernetesAPIServer{},
+		Etcd:       map[string]*KubernetesEtcd{},
+		Schedulers: map[string]*KubernetesScheduler{},
+		Kubelets:   map[string]*KubernetesKubelet{},
+		CoreDNS:    map[string]*KubernetesCoreDNS{},
+	}
+}
+
+func (cp *KubernetesControlPlane) IsEmpty() bool {
+	return len(cp.APIServers) == 0 && len(cp.Etcd) == 0 && len(cp.Schedulers) == 0 && len(cp.Kubelets) == 0 && len(cp.CoreDNS) == 0
+}
+
+func (cp *KubernetesControlPlane) GetOrCreateAPIServer(instance string) *KubernetesAPIServer {
+	s := cp.APIServers[instance]
+	if s == nil {
+		s = &KubernetesAPIServer{}
+		cp.APIServers[instance] = s
+	}
+	return s
+}
+
+func (cp *KubernetesControlPlane) GetOrCreateEtcd(instance string) *KubernetesEtcd {
+	e := cp.Etcd[instance]
+	if e == nil {
+		e = &KubernetesEtcd{}
+		cp.Etcd[instance] = e
+	}
+	return e
+}
+
+func (cp *KubernetesControlPlane) GetOrCreateScheduler(instance string) *KubernetesScheduler {
+	s := cp.Schedulers[instance]
+	if s == nil {
+		s = &KubernetesScheduler{PendingPods: map[string]*timeseries.TimeSeries{}}
+		cp.Schedulers[instance] = s
+	}
+	return s
+}
+
+func (cp *KubernetesControlPlane) GetOrCreateKubelet(node string) *KubernetesKubelet {
+	k := cp.Kubelets[node]
+	if k == nil {
+		k = &KubernetesKubelet{}
+		cp.Kubelets[node] = k
+	}
+	return k
+}
+
+func (cp *KubernetesControlPlane) GetOrCreateCoreDNS(instance string) *KubernetesCoreDNS {
+	d := cp.CoreDNS[instance]
+	if d == nil {
+		d = &KubernetesCoreDNS{}
+		cp.CoreDNS[instance] = d
+	}
+	return d
+}
+
+type KubernetesAPIServer struct {
+	Requests *timeseries.TimeSeries // per second
+	Errors   *timeseries.TimeSeries // 5xx responses per second
+	Latency  *timeseries.TimeSeries // p99, seconds (excluding WATCH and CONNECT requests)
+}
+
+func (s *KubernetesAPIServer) ErrorsPercent() *timeseries.TimeSeries {
+	return timeseries.Aggregate2(s.Errors, s.Requests, func(errors, total float32) float32 { return errors / total * 100 })
+}
+
+type KubernetesEtcd struct {
+	HasLeader       *timeseries.TimeSeries
+	LeaderChanges   *timeseries.TimeSeries // per second
+	WalFsyncLatency *timeseries.TimeSeries // p99, seconds
+	CommitLatency   *timeseries.TimeSeries // p99, seconds
+}
+
+type KubernetesScheduler struct {
+	PendingPods map[string]*timeseries.TimeSeries // by queue: active, backoff, gated, unschedulable
+}
+
+type KubernetesKubelet struct {
+	PlegRelistLatency *timeseries.TimeSeries // p99, seconds
+}
+
+type KubernetesCoreDNS struct {
+	Requests *timeseries.TimeSeries // per second
+	ServFail *timeseries.TimeSeries // SERVFAIL responses per second
+	Latency  *timeseries.TimeSeries // p99, seconds
+}
+
+func (d *KubernetesCoreDNS) ErrorsPercent() *timeseries.TimeSeries {
+	return timeseries.Aggregate2(d.ServFail, d.Requests, func(errors, total float32) float32 { return errors / total * 100 })
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type Nats struct {
+	Up            *timeseries.TimeSeries
+	Connections   *timeseries.TimeSeries
+	SlowConsumers *timeseries.TimeSeries // per second
+	InMsgs        *timeseries.TimeSeries // per second
+	OutMsgs       *timeseries.TimeSeries // per second
+
+	// JetStream
+	StreamConsumers map[string]*timeseries.TimeSeries // by stream
+	Consumers       map[NatsConsumerKey]*NatsConsumer
+}
+
+func NewNats() *Nats {
+	return &Nats{
+		StreamConsumers: map[string]*timeseries.TimeSeries{},
+		Consumers:       map[NatsConsumerKey]*NatsConsumer{},
+	}
+}
+
+func (n *Nats) IsUp() bool {
+	return n.Up.Last() > 0
+}
+
+func (n *Nats) GetOrCreateConsumer(stream, name string) *NatsConsumer {
+	k := NatsConsumerKey{Stream: stream, Name: name}
+	c := n.Consumers[k]
+	if c == nil {
+		c = &NatsConsumer{}
+		n.Consumers[k] = c
+	}
+	return c
+}
+
+type NatsConsumerKey struct {
+	Stream string
+	Name   string
+}
+
+func (k NatsConsumerKey) String() string {
+	return k.Stream + "/" + k.Name
+}
+
+type NatsConsumer struct {
+	Pending    *timeseries.TimeSeries
+	AckPending *timeseries.TimeSeries
+}
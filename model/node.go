@@ -8,6 +8,7 @@ import (
 
 const (
 	CloudProviderAWS   = "aws"
+	CloudProviderGCP   = "gcp"
 	CloudProviderAzure = "azure"
 )
 
@@ -142,9 +143,16 @@ func (n *Node) IsUp() bool {
 	if n == nil {
 		return false
 	}
-	// currently, we don't collect OS metrics for Elasticache nodes
-	if len(n.Instances) == 1 && n.Instances[0].Owner.Id.Kind == ApplicationKindElasticacheCluster {
-		return n.Instances[0].Elasticache.Status.Value() == "available"
+	// currently, we don't collect OS metrics for Elasticache, Memorystore and Azure Database nodes
+	if len(n.Instances) == 1 {
+		switch i := n.Instances[0]; i.Owner.Id.Kind {
+		case ApplicationKindElasticacheCluster:
+			return i.Elasticache.Status.Value() == "available"
+		case ApplicationKindMemorystore:
+			return !i.Memorystore.LifeSpan.TailIsEmpty()
+		case ApplicationKindAzureDatabase:
+			return !i.AzureDatabase.LifeSpan.TailIsEmpty()
+		}
 	}
 
 	return !n.MemoryTotalBytes.TailIsEmpty()
@@ -14,6 +14,9 @@ type Pod struct {
 
 	ReplicaSet string
 
+	// the value of the app.kubernetes.io/instance label, used by Helm and Argo CD to track the resources of a release
+	AppInstance string
+
 	InitContainers map[string]*Container
 }
 
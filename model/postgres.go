@@ -65,6 +65,9 @@ type Postgres struct {
 	WalCurrentLsn *timeseries.TimeSeries
 	WalReceiveLsn *timeseries.TimeSeries
 	WalReplayLsn  *timeseries.TimeSeries
+
+	// reported by managed services (e.g., Cloud SQL, Azure Database) that don't expose the WAL positions
+	ReplicationLagSeconds *timeseries.TimeSeries
 }
 
 func NewPostgres(internalExporter bool) *Postgres {
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+// Proxy holds the per-route and per-upstream metrics of a reverse proxy or an ingress controller
+// exposed by Envoy, ingress-nginx or haproxy-exporter.
+type Proxy struct {
+	Routes    map[string]*ProxyStats
+	Upstreams map[string]*ProxyUpstream
+}
+
+func NewProxy() *Proxy {
+	return &Proxy{
+		Routes:    map[string]*ProxyStats{},
+		Upstreams: map[string]*ProxyUpstream{},
+	}
+}
+
+func (p *Proxy) GetOrCreateRoute(name string) *ProxyStats {
+	r := p.Routes[name]
+	if r == nil {
+		r = NewProxyStats()
+		p.Routes[name] = r
+	}
+	return r
+}
+
+func (p *Proxy) GetOrCreateUpstream(name string) *ProxyUpstream {
+	u := p.Upstreams[name]
+	if u == nil {
+		u = &ProxyUpstream{ProxyStats: NewProxyStats()}
+		p.Upstreams[name] = u
+	}
+	return u
+}
+
+// ProxyStats describes the requests served through a route (a virtual host or a listener) or sent to an upstream.
+type ProxyStats struct {
+	Requests         *timeseries.TimeSeries // per second
+	Errors           *timeseries.TimeSeries // 5xx responses per second
+	LatencyHistogram map[float32]*timeseries.TimeSeries
+	AvgLatency       *timeseries.TimeSeries // seconds, used if the proxy doesn't expose a histogram
+}
+
+func NewProxyStats() *ProxyStats {
+	return &ProxyStats{LatencyHistogram: map[float32]*timeseries.TimeSeries{}}
+}
+
+func (s *ProxyStats) ErrorsPercent() *timeseries.TimeSeries {
+	return timeseries.Aggregate2(s.Errors, s.Requests, func(errors, total float32) float32 { return errors / total * 100 })
+}
+
+func (s *ProxyStats) Histogram() []HistogramBucket {
+	buckets := make([]HistogramBucket, 0, len(s.LatencyHistogram))
+	for le, ts := range s.LatencyHistogram {
+		buckets = append(buckets, HistogramBucket{Le: le, TimeSeries: ts})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Le < buckets[j].Le
+	})
+	return buckets
+}
+
+// Latency returns the given quantile of the response time if the histogram is available, otherwise the average.
+func (s *ProxyStats) Latency(q float32) *timeseries.TimeSeries {
+	if len(s.LatencyHistogram) > 0 {
+		return Quantile(s.Histogram(), q)
+	}
+	return s.AvgLatency
+}
+
+type ProxyUpstream struct {
+	*ProxyStats
+
+	ConnectFailures     *timeseries.TimeSeries // per second
+	CircuitBreakerTrips *timeseries.TimeSeries // requests and connections rejected by circuit breakers per second
+
+	// the Kubernetes Service behind the upstream, if known
+	ServiceName      string
+	ServiceNamespace string
+}
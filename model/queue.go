@@ -0,0 +1,53 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+// the methods of the messages counted by node-agent for the messaging protocols
+const (
+	MessagingMethodProduce = "produce"
+	MessagingMethodConsume = "consume"
+)
+
+type MessagingClient struct {
+	Application *Application
+	Messages    *timeseries.TimeSeries // per second
+}
+
+// MessagingClients returns the applications producing and consuming messages through the message broker
+// according to the protocol-level data of their connections.
+func (app *Application) MessagingClients(protocol Protocol) ([]*MessagingClient, []*MessagingClient) {
+	var producers, consumers []*MessagingClient
+	for _, d := range app.Downstreams {
+		if d.Application == nil {
+			continue
+		}
+		if ts := d.GetConnectionsRequestsSum(protocolFilter(protocol, MessagingMethodProduce)); !ts.IsEmpty() {
+			producers = append(producers, &MessagingClient{Application: d.Application, Messages: ts})
+		}
+		if ts := d.GetConnectionsRequestsSum(protocolFilter(protocol, MessagingMethodConsume)); !ts.IsEmpty() {
+			consumers = append(consumers, &MessagingClient{Application: d.Application, Messages: ts})
+		}
+	}
+	for _, clients := range [][]*MessagingClient{producers, consumers} {
+		sort.Slice(clients, func(i, j int) bool {
+			return clients[i].Application.Id.String() < clients[j].Application.Id.String()
+		})
+	}
+	return producers, consumers
+}
+
+func protocolFilter(protocol Protocol, method string) func(p Protocol) bool {
+	return func(p Protocol) bool {
+		return p == protocol+Protocol("-"+method)
+	}
+}
+
+// BacklogGrowthDuration returns for how long a backlog (a queue depth, pending messages) has been growing.
+// It follows the same rules as the Kafka consumer lag (see KafkaLagGrowthDuration).
+func BacklogGrowthDuration(backlog *timeseries.TimeSeries) timeseries.Duration {
+	return KafkaLagGrowthDuration(backlog)
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBacklogGrowthDuration(t *testing.T) {
+	backlog := func(vs ...float32) *timeseries.TimeSeries {
+		return timeseries.NewWithData(0, timeseries.Minute, vs)
+	}
+	assert.Equal(t, timeseries.Duration(0), BacklogGrowthDuration(backlog(20, 25, 20, 25))) // a steady queue
+	assert.Equal(t, 3*timeseries.Minute, BacklogGrowthDuration(backlog(0, 100, 200, 400)))
+	assert.Equal(t, timeseries.Duration(0), BacklogGrowthDuration(backlog(400, 200, 100, 50))) // the queue is being drained
+}
+
+func TestMessagingClients(t *testing.T) {
+	rabbitmq := NewApplication(NewApplicationId("mq", ApplicationKindStatefulSet, "rabbitmq"))
+	connect := func(name string, requests map[Protocol]map[string]*timeseries.TimeSeries) *Application {
+		app := NewApplication(NewApplicationId("shop", ApplicationKindDeployment, name))
+		rabbitmq.Downstreams[app.Id] = &AppToAppConnection{Application: app, RemoteApplication: rabbitmq, RequestsCount: requests}
+		return app
+	}
+	rate := func(v float32) *timeseries.TimeSeries {
+		return timeseries.NewWithData(0, timeseries.Minute, []float32{v})
+	}
+	orders := connect("orders", map[Protocol]map[string]*timeseries.TimeSeries{
+		"rabbitmq-produce": {"ok": rate(5)},
+	})
+	billing := connect("billing", map[Protocol]map[string]*timeseries.TimeSeries{
+		"rabbitmq-consume": {"ok": rate(3), "failed": rate(1)},
+	})
+	connect("catalog", map[Protocol]map[string]*timeseries.TimeSeries{
+		ProtocolHttp: {"200": rate(10)},
+	})
+
+	producers, consumers := rabbitmq.MessagingClients(ProtocolRabbitmq)
+	assert.Len(t, producers, 1)
+	assert.Same(t, orders, producers[0].Application)
+	assert.Equal(t, float32(5), producers[0].Messages.Last())
+	assert.Len(t, consumers, 1)
+	assert.Same(t, billing, consumers[0].Application)
+	assert.Equal(t, float32(4), consumers[0].Messages.Last())
+
+	producers, consumers = rabbitmq.MessagingClients(ProtocolNats)
+	assert.Empty(t, producers)
+	assert.Empty(t, consumers)
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"github.com/coroot/coroot/timeseries"
+)
+
+type Rabbitmq struct {
+	Up      *timeseries.TimeSeries
+	Node    LabelLastValue
+	Version LabelLastValue
+
+	MemoryAlarm *timeseries.TimeSeries
+	DiskAlarm   *timeseries.TimeSeries
+
+	MessagesPublished *timeseries.TimeSeries
+	MessagesDelivered *timeseries.TimeSeries
+
+	Queues map[RabbitmqQueueKey]*RabbitmqQueue
+}
+
+func NewRabbitmq() *Rabbitmq {
+	return &Rabbitmq{Queues: map[RabbitmqQueueKey]*RabbitmqQueue{}}
+}
+
+func (r *Rabbitmq) IsUp() bool {
+	return r.Up.Last() > 0
+}
+
+func (r *Rabbitmq) GetOrCreateQueue(vhost, name string) *RabbitmqQueue {
+	k := RabbitmqQueueKey{Vhost: vhost, Name: name}
+	q := r.Queues[k]
+	if q == nil {
+		q = &RabbitmqQueue{}
+		r.Queues[k] = q
+	}
+	return q
+}
+
+type RabbitmqQueueKey struct {
+	Vhost string
+	Name  string
+}
+
+func (k RabbitmqQueueKey) String() string {
+	if k.Vhost == "" || k.Vhost == "/" {
+		return k.Name
+	}
+	return k.Vhost + "/" + k.Name
+}
+
+type RabbitmqQueue struct {
+	Ready     *timeseries.TimeSeries
+	Unacked   *timeseries.TimeSeries
+	Consumers *timeseries.TimeSeries
+}
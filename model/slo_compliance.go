@@ -0,0 +1,172 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/coroot/coroot/timeseries"
+)
+
+type SLOType string
+
+const (
+	SLOTypeAvailability SLOType = "availability"
+	SLOTypeLatency      SLOType = "latency"
+
+	SLOComplianceDefaultWindow = 28 * timeseries.Day
+	SLOComplianceMaxWindow     = 30 * timeseries.Day
+)
+
+// SLOComplianceDay holds the number of the total and bad events of an application's SLI
+// for each hour of a day (UTC), and the objective that was in effect that day.
+type SLOComplianceDay struct {
+	ApplicationId ApplicationId
+	SLO           SLOType
+	Date          timeseries.Time
+	Objective     float32
+	Total         []float32
+	Bad           []float32
+}
+
+func (d *SLOComplianceDay) sum() (total, bad float32) {
+	for i := range d.Total {
+		total += d.Total[i]
+		bad += d.Bad[i]
+	}
+	return total, bad
+}
+
+// sumRange returns the number of the events within the time range with the hourly precision.
+func (d *SLOComplianceDay) sumRange(from, to timeseries.Time) (total, bad float32) {
+	for i := range d.Total {
+		t := d.Date.Add(timeseries.Duration(i) * timeseries.Hour)
+		if t.Add(timeseries.Hour) <= from || t >= to {
+			continue
+		}
+		total += d.Total[i]
+		bad += d.Bad[i]
+	}
+	return total, bad
+}
+
+type SLOCompliance struct {
+	ApplicationId ApplicationId   `json:"application_id"`
+	SLO           SLOType         `json:"slo"`
+	From          timeseries.Time `json:"from"`
+	To            timeseries.Time `json:"to"`
+	Objective     float32         `json:"objective"`
+
+	TotalEvents float32 `json:"total_events"`
+	BadEvents   float32 `json:"bad_events"`
+	// Compliance is the percentage of good events over the window.
+	Compliance float32 `json:"compliance"`
+	Met        bool    `json:"met"`
+	// ErrorBudgetRemaining is the percentage of the error budget left at the end of the window, negative if overspent.
+	ErrorBudgetRemaining float32 `json:"error_budget_remaining"`
+
+	BurnDown    []SLOBudgetPoint          `json:"burn_down"`
+	Incidents   []SLOComplianceIncident   `json:"incidents"`
+	Deployments []SLOComplianceDeployment `json:"deployments"`
+}
+
+// SLOBudgetPoint is the percentage of the error budget remaining at the end of the day.
+type SLOBudgetPoint struct {
+	Date      timeseries.Time `json:"date"`
+	Remaining float32         `json:"remaining"`
+}
+
+type SLOComplianceIncident struct {
+	Key        string          `json:"key"`
+	OpenedAt   timeseries.Time `json:"opened_at"`
+	ResolvedAt timeseries.Time `json:"resolved_at"`
+	Severity   Status          `json:"severity"`
+	// BudgetConsumed is the percentage of the error budget consumed while the incident was open.
+	BudgetConsumed float32 `json:"budget_consumed"`
+}
+
+type SLOComplianceDeployment struct {
+	StartedAt timeseries.Time `json:"started_at"`
+	Version   string          `json:"version"`
+}
+
+// CalcSLOCompliance calculates the compliance of an application's SLO over the window ending at `to`
+// from the daily SLI data. The error budget is the share of bad events allowed by the objective
+// in effect at the end of the window. It returns nil if there are no events within the window.
+func CalcSLOCompliance(days []*SLOComplianceDay, incidents []*ApplicationIncident, deployments []*ApplicationDeployment, to timeseries.Time, window timeseries.Duration) *SLOCompliance {
+	from := to.Add(-window)
+	var inWindow []*SLOComplianceDay
+	for _, d := range days {
+		if d.Date >= from && d.Date < to {
+			inWindow = append(inWindow, d)
+		}
+	}
+	if len(inWindow) == 0 {
+		return nil
+	}
+	sort.Slice(inWindow, func(i, j int) bool { return inWindow[i].Date < inWindow[j].Date })
+	last := inWindow[len(inWindow)-1]
+	res := &SLOCompliance{
+		ApplicationId: last.ApplicationId,
+		SLO:           last.SLO,
+		From:          from,
+		To:            to,
+		Objective:     last.Objective,
+	}
+	for _, d := range inWindow {
+		total, bad := d.sum()
+		res.TotalEvents += total
+		res.BadEvents += bad
+	}
+	if res.TotalEvents == 0 {
+		return nil
+	}
+	budget := res.TotalEvents * (1 - res.Objective/100)
+	remaining := func(consumed float32) float32 {
+		if budget == 0 {
+			if consumed > 0 {
+				return -100
+			}
+			return 100
+		}
+		return (1 - consumed/budget) * 100
+	}
+	res.Compliance = (1 - res.BadEvents/res.TotalEvents) * 100
+	res.Met = res.Compliance >= res.Objective
+	res.ErrorBudgetRemaining = remaining(res.BadEvents)
+
+	var consumed float32
+	for _, d := range inWindow {
+		_, bad := d.sum()
+		consumed += bad
+		res.BurnDown = append(res.BurnDown, SLOBudgetPoint{Date: d.Date, Remaining: remaining(consumed)})
+	}
+
+	for _, i := range incidents {
+		resolvedAt := i.ResolvedAt
+		if resolvedAt.IsZero() || resolvedAt > to {
+			resolvedAt = to
+		}
+		if resolvedAt < from || i.OpenedAt >= to {
+			continue
+		}
+		var bad float32
+		for _, d := range inWindow {
+			_, b := d.sumRange(i.OpenedAt, resolvedAt)
+			bad += b
+		}
+		res.Incidents = append(res.Incidents, SLOComplianceIncident{
+			Key:            i.Key,
+			OpenedAt:       i.OpenedAt,
+			ResolvedAt:     i.ResolvedAt,
+			Severity:       i.Severity,
+			BudgetConsumed: 100 - remaining(bad),
+		})
+	}
+
+	for _, d := range deployments {
+		if d.StartedAt < from || d.StartedAt >= to {
+			continue
+		}
+		res.Deployments = append(res.Deployments, SLOComplianceDeployment{StartedAt: d.StartedAt, Version: d.Version()})
+	}
+	return res
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalcSLOCompliance(t *testing.T) {
+	appId := NewApplicationId("shop", ApplicationKindDeployment, "catalog")
+	start := timeseries.Time(0).Add(100 * timeseries.Day)
+	var days []*SLOComplianceDay
+	for i := 0; i < 4; i++ {
+		d := &SLOComplianceDay{
+			ApplicationId: appId,
+			SLO:           SLOTypeAvailability,
+			Date:          start.Add(timeseries.Duration(i) * timeseries.Day),
+			Objective:     99,
+			Total:         make([]float32, 24),
+			Bad:           make([]float32, 24),
+		}
+		for h := range d.Total {
+			d.Total[h] = 1000
+		}
+		days = append(days, d)
+	}
+	days[2].Bad[10] = 480 // a half of the error budget of the 4 days
+
+	incidentStart := days[2].Date.Add(10 * timeseries.Hour)
+	incidents := []*ApplicationIncident{
+		{Key: "i1", OpenedAt: incidentStart, ResolvedAt: incidentStart.Add(timeseries.Hour), Severity: CRITICAL},
+		{Key: "i0", OpenedAt: start.Add(-2 * timeseries.Day), ResolvedAt: start.Add(-timeseries.Day), Severity: WARNING},
+	}
+	deployments := []*ApplicationDeployment{
+		{ApplicationId: appId, Name: "catalog-1", StartedAt: start.Add(-timeseries.Day)},
+		{ApplicationId: appId, Name: "catalog-2", StartedAt: days[1].Date.Add(timeseries.Hour)},
+	}
+
+	to := start.Add(4 * timeseries.Day)
+	c := CalcSLOCompliance(days, incidents, deployments, to, 4*timeseries.Day)
+	require.NotNil(t, c)
+	assert.Equal(t, float32(96000), c.TotalEvents)
+	assert.Equal(t, float32(480), c.BadEvents)
+	assert.InDelta(t, 99.5, c.Compliance, 0.001)
+	assert.True(t, c.Met)
+	assert.InDelta(t, 50, c.ErrorBudgetRemaining, 0.001)
+
+	require.Len(t, c.BurnDown, 4)
+	assert.InDelta(t, 100, c.BurnDown[1].Remaining, 0.001)
+	assert.InDelta(t, 50, c.BurnDown[2].Remaining, 0.001)
+	assert.InDelta(t, 50, c.BurnDown[3].Remaining, 0.001)
+
+	require.Len(t, c.Incidents, 1)
+	assert.Equal(t, "i1", c.Incidents[0].Key)
+	assert.InDelta(t, 50, c.Incidents[0].BudgetConsumed, 0.001)
+
+	require.Len(t, c.Deployments, 1)
+	assert.Equal(t, days[1].Date.Add(timeseries.Hour), c.Deployments[0].StartedAt)
+
+	c = CalcSLOCompliance(days, nil, nil, to, timeseries.Day)
+	require.NotNil(t, c)
+	assert.Equal(t, float32(100), c.Compliance)
+	assert.Equal(t, float32(100), c.ErrorBudgetRemaining)
+
+	assert.Nil(t, CalcSLOCompliance(days, nil, nil, start, 4*timeseries.Day))
+}
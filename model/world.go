@@ -23,11 +23,18 @@ type World struct {
 	Nodes        []*Node
 	Applications map[ApplicationId]*Application
 
-	Flux *Flux
+	Flux   *Flux
+	ArgoCD *ArgoCD
+
+	KubernetesControlPlane *KubernetesControlPlane
 
 	AWS AWS
 
 	IntegrationStatus IntegrationStatus
+
+	// AnomalyHistory holds the anomaly signals of the applications in the same time window of previous seasons
+	// (e.g., a day and a week ago) shifted to the current time window. It's used to learn the anomaly baselines.
+	AnomalyHistory map[ApplicationId]map[AnomalySignal][]*timeseries.TimeSeries
 }
 
 func NewWorld(from, to timeseries.Time, step, rawStep timeseries.Duration) *World {
@@ -0,0 +1,89 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+)
+
+type Discord struct {
+	webhookUrl string
+}
+
+func NewDiscord(webhookUrl string) *Discord {
+	return &Discord{webhookUrl: strings.TrimRight(webhookUrl, "/")}
+}
+
+type discordMessage struct {
+	Id     string         `json:"id,omitempty"`
+	Embeds []discordEmbed `json:"embeds"`
+}
+
+type discordEmbed struct {
+	Title       string `json:"title"`
+	Url         string `json:"url,omitempty"`
+	Description string `json:"description,omitempty"`
+	Color       int    `json:"color"`
+}
+
+func (d *Discord) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	embed := discordEmbed{
+		Url:   incidentUrl(baseUrl, n),
+		Color: discordColor(n.Status),
+	}
+	if n.Status == model.OK {
+		embed.Title = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
+	} else {
+		embed.Title = fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name)
+	}
+	if n.Details != nil {
+		var lines []string
+		for _, r := range n.Details.Reports {
+			lines = append(lines, fmt.Sprintf("• **%s** / %s: %s", r.Name, r.Check, r.Message))
+		}
+		embed.Description = strings.Join(lines, "\n")
+	}
+	msg := discordMessage{Embeds: []discordEmbed{embed}}
+
+	// the original message of the incident is updated in place rather than posting a new one
+	if n.ExternalKey != "" {
+		if err := sendJsonWithMethod(ctx, http.MethodPatch, d.webhookUrl+"/messages/"+n.ExternalKey, nil, msg, nil); err != nil {
+			return fmt.Errorf("discord error: %w", err)
+		}
+		return nil
+	}
+	var created discordMessage
+	if err := sendJson(ctx, d.webhookUrl+"?wait=true", nil, msg, &created); err != nil {
+		return fmt.Errorf("discord error: %w", err)
+	}
+	n.ExternalKey = created.Id
+	return nil
+}
+
+func (d *Discord) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	text := deploymentMarkdown(project, ds)
+	if text == "" {
+		return nil
+	}
+	title, description, _ := strings.Cut(text, "\n")
+	msg := discordMessage{Embeds: []discordEmbed{{
+		Title:       strings.ReplaceAll(title, "**", ""),
+		Url:         deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, ds.Deployment),
+		Description: strings.TrimSpace(description),
+		Color:       discordColor(ds.Status),
+	}}}
+	if err := sendJson(ctx, d.webhookUrl, nil, msg, nil); err != nil {
+		return fmt.Errorf("discord error: %w", err)
+	}
+	return nil
+}
+
+func discordColor(s model.Status) int {
+	c, _ := strconv.ParseInt(strings.TrimPrefix(s.Color(), "#"), 16, 32)
+	return int(c)
+}
@@ -0,0 +1,326 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+)
+
+type Email struct {
+	cfg *db.IntegrationEmail
+	to  []string
+}
+
+func NewEmail(cfg *db.IntegrationEmail, to []string) *Email {
+	if len(to) == 0 {
+		to = cfg.DefaultTo
+	}
+	return &Email{cfg: cfg, to: to}
+}
+
+type emailIncidentValues struct {
+	Title       string
+	Status      string
+	Color       string
+	Resolved    bool
+	Application model.ApplicationId
+	Reports     []db.IncidentNotificationDetailsReport
+	URL         string
+}
+
+type emailDeploymentValues struct {
+	Title   string
+	Project string
+	Status  string
+	Color   string
+	Version string
+	Summary []string
+	URL     string
+}
+
+func (e *Email) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	values := emailIncidentValues{
+		Status:      strings.ToUpper(n.Status.String()),
+		Color:       n.Status.Color(),
+		Resolved:    n.Status == model.OK,
+		Application: n.ApplicationId,
+		URL:         incidentUrl(baseUrl, n),
+	}
+	if values.Resolved {
+		values.Title = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
+	} else {
+		values.Title = fmt.Sprintf("[%s] %s is not meeting its SLOs", values.Status, n.ApplicationId.Name)
+	}
+	if n.Details != nil {
+		values.Reports = n.Details.Reports
+	}
+	text, html, err := renderEmail(emailIncidentTextTemplate, emailIncidentHtmlTemplate, values)
+	if err != nil {
+		return err
+	}
+	thread := fmt.Sprintf("<incident-%s-%s@coroot>", n.ProjectId, n.IncidentKey)
+	// the external key is set once the first message of the incident has been sent
+	headers := threadHeaders(thread, n.ExternalKey == "")
+	subject := fmt.Sprintf("[Coroot] %s incident %s", n.ApplicationId.Name, n.IncidentKey)
+	if err = e.send(ctx, subject, text, html, headers); err != nil {
+		return err
+	}
+	n.ExternalKey = thread
+	return nil
+}
+
+func (e *Email) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	d := ds.Deployment
+	status := "Deployed"
+	var summary []string
+	switch ds.State {
+	case model.ApplicationDeploymentStateInProgress:
+		return nil
+	case model.ApplicationDeploymentStateStuck:
+		status = "Stuck"
+	case model.ApplicationDeploymentStateCancelled:
+		status = "Cancelled"
+	case model.ApplicationDeploymentStateSummary:
+		for _, s := range ds.Summary {
+			summary = append(summary, fmt.Sprintf("%s %s", s.Emoji(), s.Message))
+		}
+		if len(summary) == 0 {
+			summary = append(summary, "No notable changes")
+		}
+	}
+	values := emailDeploymentValues{
+		Title:   fmt.Sprintf("Deployment of %s to %s", d.ApplicationId.Name, project.Name),
+		Project: project.Name,
+		Status:  status,
+		Color:   ds.Status.Color(),
+		Version: d.Version(),
+		Summary: summary,
+		URL:     deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, d),
+	}
+	text, html, err := renderEmail(emailDeploymentTextTemplate, emailDeploymentHtmlTemplate, values)
+	if err != nil {
+		return err
+	}
+	thread := fmt.Sprintf("<deployment-%s-%s@coroot>", project.Id, d.Id())
+	// no email is sent for a deployment in progress
+	first := d.Notifications == nil || d.Notifications.Email.State <= model.ApplicationDeploymentStateInProgress
+	return e.send(ctx, values.Title+": "+status, text, html, threadHeaders(thread, first))
+}
+
+// threadHeaders returns the headers grouping the messages into one thread: the first message gets the thread id
+// as its Message-ID, and the following ones reference it.
+func threadHeaders(thread string, first bool) map[string]string {
+	if first {
+		return map[string]string{"Message-ID": thread}
+	}
+	return map[string]string{
+		"Message-ID":  fmt.Sprintf("<%s@coroot>", utils.NanoId(16)),
+		"References":  thread,
+		"In-Reply-To": thread,
+	}
+}
+
+func (e *Email) send(ctx context.Context, subject, text, html string, headers map[string]string) error {
+	from, err := mail.ParseAddress(e.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	var to []*mail.Address
+	for _, addr := range e.to {
+		a, err := mail.ParseAddress(addr)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %s: %w", addr, err)
+		}
+		to = append(to, a)
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	msg, err := buildEmail(from, to, subject, text, html, headers)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(e.cfg.SmtpHost, strconv.Itoa(e.cfg.SmtpPort))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: e.cfg.SmtpHost}
+	if e.cfg.SmtpTls == db.SmtpTlsTls {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	c, err := smtp.NewClient(conn, e.cfg.SmtpHost)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer c.Close()
+	if e.cfg.SmtpTls == db.SmtpTlsStartTls {
+		if err = c.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if a := e.cfg.Auth; a != nil && a.User != "" {
+		if ok, _ := c.Extension("AUTH"); ok {
+			if err = c.Auth(smtp.PlainAuth("", a.User, a.Password, e.cfg.SmtpHost)); err != nil {
+				return err
+			}
+		}
+	}
+	if err = c.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err = c.Rcpt(rcpt.Address); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+func buildEmail(from *mail.Address, to []*mail.Address, subject, text, html string, headers map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	recipients := make([]string, 0, len(to))
+	for _, a := range to {
+		recipients = append(recipients, a.String())
+	}
+	h := textproto.MIMEHeader{}
+	h.Set("From", from.String())
+	h.Set("To", strings.Join(recipients, ", "))
+	h.Set("Subject", mime.QEncoding.Encode("utf-8", subject))
+	h.Set("Date", time.Now().Format(time.RFC1123Z))
+	h.Set("MIME-Version", "1.0")
+	h.Set("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%s", mw.Boundary()))
+	for k, v := range headers {
+		h.Set(k, v)
+	}
+	var header bytes.Buffer
+	for k, vs := range h {
+		for _, v := range vs {
+			fmt.Fprintf(&header, "%s: %s\r\n", k, v)
+		}
+	}
+	header.WriteString("\r\n")
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{contentType: "text/plain; charset=utf-8", body: text},
+		{contentType: "text/html; charset=utf-8", body: html},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qw := quotedprintable.NewWriter(pw)
+		if _, err = qw.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err = qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return append(header.Bytes(), buf.Bytes()...), nil
+}
+
+func renderEmail(textTmpl *template.Template, htmlTmpl *htmltemplate.Template, values any) (string, string, error) {
+	var text, html bytes.Buffer
+	if err := textTmpl.Execute(&text, values); err != nil {
+		return "", "", err
+	}
+	if err := htmlTmpl.Execute(&html, values); err != nil {
+		return "", "", err
+	}
+	return text.String(), html.String(), nil
+}
+
+var (
+	emailIncidentTextTemplate = template.Must(template.New("incident").Parse(`{{ .Title }}
+{{ range .Reports }}
+* {{ .Name }} / {{ .Check }}: {{ .Message }}{{ end }}
+
+View incident: {{ .URL }}
+`))
+
+	emailIncidentHtmlTemplate = htmltemplate.Must(htmltemplate.New("incident").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; font-size: 14px;">
+<div style="border-left: 4px solid {{ .Color }}; padding-left: 12px;">
+<h3 style="margin: 0 0 8px 0;"><a href="{{ .URL }}">{{ .Title }}</a></h3>
+{{ if .Reports }}<ul>
+{{ range .Reports }}<li><b>{{ .Name }}</b> / {{ .Check }}: {{ .Message }}</li>
+{{ end }}</ul>{{ end }}
+<p><a href="{{ .URL }}">View incident</a></p>
+</div>
+</body>
+</html>
+`))
+
+	emailDeploymentTextTemplate = template.Must(template.New("deployment").Parse(`{{ .Title }}
+
+Status: {{ .Status }}
+Version: {{ .Version }}
+{{ if .Summary }}
+Summary:{{ range .Summary }}
+* {{ . }}{{ end }}
+{{ end }}
+View deployment: {{ .URL }}
+`))
+
+	emailDeploymentHtmlTemplate = htmltemplate.Must(htmltemplate.New("deployment").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; font-size: 14px;">
+<div style="border-left: 4px solid {{ .Color }}; padding-left: 12px;">
+<h3 style="margin: 0 0 8px 0;"><a href="{{ .URL }}">{{ .Title }}</a></h3>
+<p><b>Status:</b> {{ .Status }}<br><b>Version:</b> {{ .Version }}</p>
+{{ if .Summary }}<p><b>Summary</b></p>
+<ul>
+{{ range .Summary }}<li>{{ . }}</li>
+{{ end }}</ul>{{ end }}
+<p><a href="{{ .URL }}">View deployment</a></p>
+</div>
+</body>
+</html>
+`))
+)
@@ -0,0 +1,76 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+)
+
+type GoogleChat struct {
+	webhookUrl string
+}
+
+func NewGoogleChat(webhookUrl string) *GoogleChat {
+	return &GoogleChat{webhookUrl: webhookUrl}
+}
+
+type googleChatMessage struct {
+	Text   string            `json:"text"`
+	Thread *googleChatThread `json:"thread,omitempty"`
+}
+
+type googleChatThread struct {
+	ThreadKey string `json:"threadKey"`
+}
+
+func (g *GoogleChat) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	var text string
+	if n.Status == model.OK {
+		text = fmt.Sprintf("<%s|*%s* incident resolved>", incidentUrl(baseUrl, n), n.ApplicationId.Name)
+	} else {
+		text = fmt.Sprintf("[%s] <%s|*%s* is not meeting its SLOs>", strings.ToUpper(n.Status.String()), incidentUrl(baseUrl, n), n.ApplicationId.Name)
+	}
+	if n.Details != nil {
+		for _, r := range n.Details.Reports {
+			text += fmt.Sprintf("\n• *%s* / %s: %s", r.Name, r.Check, r.Message)
+		}
+	}
+	// Google Chat groups messages with the same thread key into a single thread
+	msg := googleChatMessage{
+		Text:   text,
+		Thread: &googleChatThread{ThreadKey: fmt.Sprintf("%s-%s", n.ProjectId, n.IncidentKey)},
+	}
+	return g.send(ctx, msg)
+}
+
+func (g *GoogleChat) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	d := ds.Deployment
+	text := deploymentMarkdown(project, ds)
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "**", "*")
+	msg := googleChatMessage{
+		Text:   text,
+		Thread: &googleChatThread{ThreadKey: fmt.Sprintf("%s-%s", project.Id, d.Id())},
+	}
+	return g.send(ctx, msg)
+}
+
+func (g *GoogleChat) send(ctx context.Context, msg googleChatMessage) error {
+	u, err := url.Parse(g.webhookUrl)
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %w", err)
+	}
+	q := u.Query()
+	q.Set("messageReplyOption", "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD")
+	u.RawQuery = q.Encode()
+	if err = sendJson(ctx, u.String(), nil, msg, nil); err != nil {
+		return fmt.Errorf("google chat error: %w", err)
+	}
+	return nil
+}
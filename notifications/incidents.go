@@ -35,10 +35,10 @@ func (n *IncidentNotifier) Enqueue(project *db.Project, app *model.Application,
 		return
 	}
 	if slack := notificationSettings.Slack; slack != nil && slack.Enabled {
-		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeSlack, SlackChannel: slack.Channel})
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeSlack, SlackChannel: slack.Channel, DigestInterval: slack.DigestInterval})
 	}
 	if teams := notificationSettings.Teams; teams != nil && teams.Enabled {
-		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeTeams})
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeTeams, DigestInterval: teams.DigestInterval})
 	}
 	if pagerduty := notificationSettings.Pagerduty; pagerduty != nil && pagerduty.Enabled {
 		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypePagerduty})
@@ -47,7 +47,19 @@ func (n *IncidentNotifier) Enqueue(project *db.Project, app *model.Application,
 		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeOpsgenie})
 	}
 	if webhook := notificationSettings.Webhook; webhook != nil && webhook.Enabled {
-		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeWebhook})
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeWebhook, DigestInterval: webhook.DigestInterval})
+	}
+	if email := notificationSettings.Email; email != nil && email.Enabled {
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeEmail, EmailTo: db.NewEmailRecipients(email.To)})
+	}
+	if mattermost := notificationSettings.Mattermost; mattermost != nil && mattermost.Enabled {
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeMattermost, MattermostChannel: mattermost.ChannelId})
+	}
+	if googleChat := notificationSettings.GoogleChat; googleChat != nil && googleChat.Enabled {
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeGoogleChat})
+	}
+	if discord := notificationSettings.Discord; discord != nil && discord.Enabled {
+		n.enqueue(now, project, app, incident, db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeDiscord})
 	}
 	n.sendIncidents()
 }
@@ -71,13 +83,26 @@ func (n *IncidentNotifier) sendIncidents() {
 		destination db.IncidentNotificationDestination
 	}
 	failedDestinations := map[destinationKey]bool{}
-	notifications, err := n.db.GetNotSentIncidentNotifications(timeseries.Now().Add(-retryWindow))
+	digests := map[destinationKey][]db.IncidentNotification{}
+	now := timeseries.Now()
+	notifications, err := n.db.GetNotSentIncidentNotifications(now.Add(-retryWindow - db.MaxNotificationDigestInterval))
 	if err != nil {
 		klog.Errorln(err)
 		return
 	}
 	for _, notification := range notifications {
+		// the resolve notifications are held back until the end of the reopen cooldown
+		if notification.Timestamp.After(now) {
+			continue
+		}
 		dKey := destinationKey{projectId: notification.ProjectId, destination: notification.Destination}
+		if notification.Destination.Digest() {
+			digests[dKey] = append(digests[dKey], notification)
+			continue
+		}
+		if notification.Timestamp.Before(now.Add(-retryWindow)) {
+			continue
+		}
 		if failedDestinations[dKey] {
 			continue
 		}
@@ -89,17 +114,7 @@ func (n *IncidentNotifier) sendIncidents() {
 		var sendErr error
 		client := getClient(notification.Destination, integrations)
 		if client != nil {
-			if notification.Destination.IntegrationType == db.IntegrationTypeSlack {
-				if prevNotifications, err := n.db.GetPreviousIncidentNotifications(notification); err != nil {
-					klog.Errorln(err)
-				} else {
-					for _, pn := range prevNotifications {
-						if pn.ExternalKey != "" {
-							notification.ExternalKey = pn.ExternalKey
-						}
-					}
-				}
-			}
+			n.setThreadKey(&notification)
 			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
 			sendErr = client.SendIncident(ctx, integrations.BaseUrl, &notification)
 			cancel()
@@ -114,6 +129,80 @@ func (n *IncidentNotifier) sendIncidents() {
 			}
 		}
 	}
+
+	for dKey, ns := range digests {
+		project := projects[dKey.projectId]
+		if project == nil {
+			continue
+		}
+		// notifications are ordered by timestamp within an incident, so the digest is due
+		// once the earliest pending notification has waited for the whole interval
+		first := ns[0].Timestamp
+		for _, notification := range ns {
+			if notification.Timestamp.Before(first) {
+				first = notification.Timestamp
+			}
+		}
+		if now.Sub(first) < dKey.destination.DigestInterval {
+			continue
+		}
+		n.sendDigest(project, dKey.destination, ns)
+	}
+}
+
+func (n *IncidentNotifier) sendDigest(project *db.Project, destination db.IncidentNotificationDestination, ns []db.IncidentNotification) {
+	integrations := project.Settings.Integrations
+	client := getClient(destination, integrations)
+	if client == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	dc, ok := client.(DigestClient)
+	if !ok {
+		// the items are sent one by one and marked as sent as they go out, so a retry doesn't repeat the delivered ones
+		for _, notification := range ns {
+			n.setThreadKey(&notification)
+			if err := client.SendIncident(ctx, integrations.BaseUrl, &notification); err != nil {
+				klog.Errorf("failed to send digest to %s: %s", destination.IntegrationType, err)
+				return
+			}
+			notification.SentAt = timeseries.Now()
+			if err := n.db.UpdateIncidentNotification(notification); err != nil {
+				klog.Errorln(err)
+			}
+		}
+		return
+	}
+	if err := dc.SendIncidentDigest(ctx, integrations.BaseUrl, NewIncidentDigest(ns)); err != nil {
+		klog.Errorf("failed to send digest to %s: %s", destination.IntegrationType, err)
+		return
+	}
+	sentAt := timeseries.Now()
+	for _, notification := range ns {
+		notification.SentAt = sentAt
+		if err := n.db.UpdateIncidentNotification(notification); err != nil {
+			klog.Errorln(err)
+		}
+	}
+}
+
+// setThreadKey sets the external key of the previous notifications of the incident, so that the integrations
+// supporting threads post the notification as a reply.
+func (n *IncidentNotifier) setThreadKey(notification *db.IncidentNotification) {
+	if !threaded(notification.Destination.IntegrationType) {
+		return
+	}
+	prevNotifications, err := n.db.GetPreviousIncidentNotifications(*notification)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	for _, pn := range prevNotifications {
+		if pn.ExternalKey != "" {
+			notification.ExternalKey = pn.ExternalKey
+		}
+	}
 }
 
 func (n *IncidentNotifier) enqueue(now timeseries.Time, project *db.Project, app *model.Application, incident *model.ApplicationIncident, destination db.IncidentNotificationDestination) {
@@ -126,7 +215,8 @@ func (n *IncidentNotifier) enqueue(now timeseries.Time, project *db.Project, app
 		Status:        incident.Severity,
 	}
 	switch destination.IntegrationType {
-	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeWebhook:
+	case db.IntegrationTypeSlack, db.IntegrationTypeTeams, db.IntegrationTypeWebhook,
+		db.IntegrationTypeEmail, db.IntegrationTypeMattermost, db.IntegrationTypeGoogleChat, db.IntegrationTypeDiscord:
 		if incident.Resolved() {
 			n.onResolve("", notification, incidentDetails(app, incident))
 		} else {
@@ -0,0 +1,106 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+)
+
+type Mattermost struct {
+	url     string
+	token   string
+	channel string
+}
+
+func NewMattermost(url, token, channel string) *Mattermost {
+	return &Mattermost{
+		url:     strings.TrimRight(url, "/"),
+		token:   token,
+		channel: channel,
+	}
+}
+
+type mattermostPost struct {
+	Id        string         `json:"id,omitempty"`
+	ChannelId string         `json:"channel_id"`
+	RootId    string         `json:"root_id,omitempty"`
+	Message   string         `json:"message"`
+	Props     map[string]any `json:"props,omitempty"`
+}
+
+type mattermostAttachment struct {
+	Fallback string `json:"fallback"`
+	Color    string `json:"color"`
+	Text     string `json:"text"`
+}
+
+func (m *Mattermost) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
+	var ch, rootId string
+	parts := strings.Split(n.ExternalKey, ":")
+	if len(parts) == 2 {
+		ch, rootId = parts[0], parts[1]
+	}
+	if ch == "" {
+		ch = m.channel
+	}
+	var text, fallback string
+	if n.Status == model.OK {
+		text = fmt.Sprintf("[**%s** incident resolved](%s)", n.ApplicationId.Name, incidentUrl(baseUrl, n))
+		fallback = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
+	} else {
+		text = fmt.Sprintf("[%s] [**%s** is not meeting its SLOs](%s)", strings.ToUpper(n.Status.String()), n.ApplicationId.Name, incidentUrl(baseUrl, n))
+		fallback = fmt.Sprintf("%s is not meeting its SLOs", n.ApplicationId.Name)
+	}
+	if n.Details != nil {
+		for _, r := range n.Details.Reports {
+			text += fmt.Sprintf("\n* **%s** / %s: %s", r.Name, r.Check, r.Message)
+		}
+	}
+	post := mattermostPost{
+		ChannelId: ch,
+		RootId:    rootId,
+		Props: map[string]any{
+			"attachments": []mattermostAttachment{{Fallback: fallback, Color: n.Status.Color(), Text: text}},
+		},
+	}
+	var created mattermostPost
+	if err := m.post(ctx, post, &created); err != nil {
+		return err
+	}
+	// replies are attached to the root post of the incident
+	if rootId == "" {
+		rootId = created.Id
+	}
+	n.ExternalKey = fmt.Sprintf("%s:%s", ch, rootId)
+	return nil
+}
+
+func (m *Mattermost) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	d := ds.Deployment
+	text := deploymentMarkdown(project, ds)
+	if text == "" {
+		return nil
+	}
+	post := mattermostPost{
+		ChannelId: m.channel,
+		Props: map[string]any{
+			"attachments": []mattermostAttachment{{
+				Fallback: fmt.Sprintf("Deployment of %s to %s", d.ApplicationId.Name, project.Name),
+				Color:    ds.Status.Color(),
+				Text:     text,
+			}},
+		},
+	}
+	return m.post(ctx, post, nil)
+}
+
+func (m *Mattermost) post(ctx context.Context, post mattermostPost, res *mattermostPost) error {
+	headers := map[string]string{"Authorization": "Bearer " + m.token}
+	if err := sendJson(ctx, m.url+"/api/v4/posts", headers, post, res); err != nil {
+		return fmt.Errorf("mattermost error: %w", err)
+	}
+	return nil
+}
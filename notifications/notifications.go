@@ -1,9 +1,15 @@
 package notifications
 
 import (
+	"bytes"
 	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/coroot/coroot/db"
@@ -22,6 +28,83 @@ type NotificationClient interface {
 	SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error
 }
 
+// DigestClient is implemented by clients that can deliver several incident notifications as a single summary message.
+type DigestClient interface {
+	SendIncidentDigest(ctx context.Context, baseUrl string, digest *IncidentDigest) error
+}
+
+type IncidentDigest struct {
+	Items []*IncidentDigestItem
+}
+
+type IncidentDigestItem struct {
+	Notification db.IncidentNotification
+	Updates      int
+}
+
+// NewIncidentDigest collapses notifications into one item per incident, keeping the latest status
+// and the most recent non-empty details.
+func NewIncidentDigest(ns []db.IncidentNotification) *IncidentDigest {
+	d := &IncidentDigest{}
+	byKey := map[string]*IncidentDigestItem{}
+	for _, n := range ns {
+		key := string(n.ProjectId) + "/" + n.IncidentKey
+		item := byKey[key]
+		if item == nil {
+			item = &IncidentDigestItem{}
+			byKey[key] = item
+			d.Items = append(d.Items, item)
+		}
+		item.Updates++
+		if n.Timestamp.Before(item.Notification.Timestamp) {
+			continue
+		}
+		details := item.Notification.Details
+		item.Notification = n
+		if n.Details == nil {
+			item.Notification.Details = details
+		}
+	}
+	sort.SliceStable(d.Items, func(i, j int) bool {
+		ni, nj := d.Items[i].Notification, d.Items[j].Notification
+		if ni.Status != nj.Status {
+			return ni.Status > nj.Status
+		}
+		return ni.Timestamp.Before(nj.Timestamp)
+	})
+	return d
+}
+
+func (d *IncidentDigest) Counts() (open int, resolved int) {
+	for _, i := range d.Items {
+		if i.Notification.Status == model.OK {
+			resolved++
+		} else {
+			open++
+		}
+	}
+	return
+}
+
+func (d *IncidentDigest) Title() string {
+	open, resolved := d.Counts()
+	return fmt.Sprintf("Incident digest: %d open, %d resolved", open, resolved)
+}
+
+func (i *IncidentDigestItem) Summary() string {
+	n := i.Notification
+	var s string
+	if n.Status == model.OK {
+		s = fmt.Sprintf("%s incident resolved", n.ApplicationId.Name)
+	} else {
+		s = fmt.Sprintf("[%s] %s is not meeting its SLOs", strings.ToUpper(n.Status.String()), n.ApplicationId.Name)
+	}
+	if i.Updates > 1 {
+		s += fmt.Sprintf(" (%d updates)", i.Updates)
+	}
+	return s
+}
+
 func getClient(destination db.IncidentNotificationDestination, integrations db.Integrations) NotificationClient {
 	switch destination.IntegrationType {
 	case db.IntegrationTypeSlack:
@@ -44,10 +127,36 @@ func getClient(destination db.IncidentNotificationDestination, integrations db.I
 		if cfg := integrations.Webhook; cfg != nil && cfg.Incidents {
 			return NewWebhook(cfg)
 		}
+	case db.IntegrationTypeEmail:
+		if cfg := integrations.Email; cfg != nil && cfg.Incidents {
+			return NewEmail(cfg, destination.EmailTo.List())
+		}
+	case db.IntegrationTypeMattermost:
+		if cfg := integrations.Mattermost; cfg != nil && cfg.Incidents {
+			return NewMattermost(cfg.Url, cfg.Token, cmp.Or(destination.MattermostChannel, cfg.DefaultChannelId))
+		}
+	case db.IntegrationTypeGoogleChat:
+		if cfg := integrations.GoogleChat; cfg != nil && cfg.Incidents {
+			return NewGoogleChat(cfg.WebhookUrl)
+		}
+	case db.IntegrationTypeDiscord:
+		if cfg := integrations.Discord; cfg != nil && cfg.Incidents {
+			return NewDiscord(cfg.WebhookUrl)
+		}
 	}
 	return nil
 }
 
+// threaded reports whether the client relies on the external key of previous notifications
+// to reply in a thread or to update the original message.
+func threaded(t db.IntegrationType) bool {
+	switch t {
+	case db.IntegrationTypeSlack, db.IntegrationTypeMattermost, db.IntegrationTypeDiscord, db.IntegrationTypeEmail:
+		return true
+	}
+	return false
+}
+
 func incidentDetails(app *model.Application, incident *model.ApplicationIncident) *db.IncidentNotificationDetails {
 	var reports []db.IncidentNotificationDetailsReport
 	if !incident.Resolved() {
@@ -69,10 +178,84 @@ func incidentDetails(app *model.Application, incident *model.ApplicationIncident
 		//}
 		//}
 	}
-	if len(reports) == 0 {
+	details := &db.IncidentNotificationDetails{
+		Reports:               reports,
+		AvailabilityBurnRates: incident.Details.AvailabilityBurnRates,
+		LatencyBurnRates:      incident.Details.LatencyBurnRates,
+		AvailabilityImpact:    incident.Details.AvailabilityImpact.AffectedRequestPercentage,
+		LatencyImpact:         incident.Details.LatencyImpact.AffectedRequestPercentage,
+	}
+	if incident.RCA != nil {
+		details.Summary = incident.RCA.ShortSummary
+		details.RootCause = incident.RCA.RootCause
+	}
+	if len(details.Reports) == 0 && len(details.AvailabilityBurnRates) == 0 && len(details.LatencyBurnRates) == 0 && details.Summary == "" {
 		return nil
 	}
-	return &db.IncidentNotificationDetails{Reports: reports}
+	return details
+}
+
+func deploymentMarkdown(project *db.Project, ds model.ApplicationDeploymentStatus) string {
+	d := ds.Deployment
+	status := "Deployed"
+	switch ds.State {
+	case model.ApplicationDeploymentStateInProgress:
+		return ""
+	case model.ApplicationDeploymentStateStuck:
+		status = "Stuck"
+	case model.ApplicationDeploymentStateCancelled:
+		status = "Cancelled"
+	}
+	text := fmt.Sprintf("Deployment of [**%s**](%s) to **%s**\n", d.ApplicationId.Name, deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, d), project.Name)
+	text += fmt.Sprintf("**Status**: %s\n", status)
+	text += fmt.Sprintf("**Version**: %s\n", d.Version())
+	if ds.State == model.ApplicationDeploymentStateSummary {
+		text += "**Summary**:\n"
+		if len(ds.Summary) == 0 {
+			text += "No notable changes\n"
+		}
+		for _, s := range ds.Summary {
+			text += fmt.Sprintf("* %s %s\n", s.Emoji(), s.Message)
+		}
+	}
+	return text
+}
+
+func sendJson(ctx context.Context, url string, headers map[string]string, req any, res any) error {
+	return sendJsonWithMethod(ctx, http.MethodPost, url, headers, req, res)
+}
+
+func sendJsonWithMethod(ctx context.Context, method, url string, headers map[string]string, req any, res any) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s: %s", resp.Status, string(body))
+	}
+	if res != nil && len(body) > 0 {
+		if err = json.Unmarshal(body, res); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+	}
+	return nil
 }
 
 func incidentUrl(baseUrl string, n *db.IncidentNotification) string {
@@ -0,0 +1,296 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNotification(status model.Status) *db.IncidentNotification {
+	return &db.IncidentNotification{
+		ProjectId:     "p1",
+		ApplicationId: model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog"),
+		IncidentKey:   "abc123",
+		Status:        status,
+		Details: &db.IncidentNotificationDetails{
+			Reports: []db.IncidentNotificationDetailsReport{{Name: model.AuditReportLogs, Check: "Errors", Message: "12 errors occurred"}},
+		},
+	}
+}
+
+func TestMattermostThreading(t *testing.T) {
+	var posts []mattermostPost
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/posts", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var p mattermostPost
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&p))
+		posts = append(posts, p)
+		p.Id = "post" + strconv.Itoa(len(posts))
+		_ = json.NewEncoder(w).Encode(p)
+	}))
+	defer srv.Close()
+
+	m := NewMattermost(srv.URL, "token", "ch1")
+	n := testNotification(model.CRITICAL)
+	require.NoError(t, m.SendIncident(context.Background(), "http://coroot", n))
+	assert.Equal(t, "ch1:post1", n.ExternalKey)
+
+	resolved := testNotification(model.OK)
+	resolved.ExternalKey = n.ExternalKey
+	require.NoError(t, m.SendIncident(context.Background(), "http://coroot", resolved))
+	assert.Equal(t, "ch1:post1", resolved.ExternalKey)
+
+	require.Len(t, posts, 2)
+	assert.Equal(t, "", posts[0].RootId)
+	assert.Equal(t, "post1", posts[1].RootId)
+}
+
+func TestDiscordUpdateOnResolve(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		_ = json.NewEncoder(w).Encode(discordMessage{Id: "m1"})
+	}))
+	defer srv.Close()
+
+	d := NewDiscord(srv.URL + "/api/webhooks/1/token")
+	n := testNotification(model.WARNING)
+	require.NoError(t, d.SendIncident(context.Background(), "http://coroot", n))
+	assert.Equal(t, "m1", n.ExternalKey)
+
+	resolved := testNotification(model.OK)
+	resolved.ExternalKey = n.ExternalKey
+	require.NoError(t, d.SendIncident(context.Background(), "http://coroot", resolved))
+	assert.Equal(t, []string{
+		"POST /api/webhooks/1/token?wait=true",
+		"PATCH /api/webhooks/1/token/messages/m1",
+	}, requests)
+}
+
+func TestGoogleChatThreadKey(t *testing.T) {
+	var msg googleChatMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "REPLY_MESSAGE_FALLBACK_TO_NEW_THREAD", r.URL.Query().Get("messageReplyOption"))
+		assert.Equal(t, "k", r.URL.Query().Get("key"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&msg))
+	}))
+	defer srv.Close()
+
+	require.NoError(t, NewGoogleChat(srv.URL+"/v1/spaces/s/messages?key=k").SendIncident(context.Background(), "http://coroot", testNotification(model.CRITICAL)))
+	require.NotNil(t, msg.Thread)
+	assert.Equal(t, "p1-abc123", msg.Thread.ThreadKey)
+	assert.Contains(t, msg.Text, "12 errors occurred")
+}
+
+func TestEmail(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	type received struct {
+		from string
+		rcpt []string
+		data string
+	}
+	ch := make(chan received, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+		var res received
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch {
+			case cmd == "EHLO" || cmd == "HELO":
+				reply("250 localhost")
+			case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+				res.from = line[len("MAIL FROM:"):]
+				reply("250 OK")
+			case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+				res.rcpt = append(res.rcpt, line[len("RCPT TO:"):])
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				res.data = data.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 bye")
+				ch <- res
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	p, _ := strconv.Atoi(port)
+	cfg := &db.IntegrationEmail{SmtpHost: host, SmtpPort: p, SmtpTls: db.SmtpTlsNone, From: "Coroot <coroot@example.com>", DefaultTo: []string{"oncall@example.com"}}
+	require.NoError(t, cfg.Validate())
+
+	// the recipients with display names containing commas and colons survive the round trip through the database
+	destination := db.IncidentNotificationDestination{
+		IntegrationType: db.IntegrationTypeEmail,
+		EmailTo:         db.NewEmailRecipients([]string{`"Doe, John" <team@example.com>`, `"Lead: on-call" <lead@example.com>`}),
+	}
+	v, err := destination.Value()
+	require.NoError(t, err)
+	var scanned db.IncidentNotificationDestination
+	require.NoError(t, scanned.Scan(v))
+	assert.Equal(t, destination, scanned)
+
+	n := testNotification(model.CRITICAL)
+	require.NoError(t, NewEmail(cfg, scanned.EmailTo.List()).SendIncident(context.Background(), "http://coroot", n))
+	res := <-ch
+	assert.Equal(t, "<coroot@example.com>", res.from)
+	assert.Equal(t, []string{"<team@example.com>", "<lead@example.com>"}, res.rcpt)
+	assert.Contains(t, res.data, `To: "Doe, John" <team@example.com>, "Lead: on-call" <lead@example.com>`)
+	// the first message of the incident starts the thread the following ones reply to
+	assert.Contains(t, res.data, "Message-Id: <incident-p1-abc123@coroot>")
+	assert.NotContains(t, res.data, "References:")
+	assert.Equal(t, "<incident-p1-abc123@coroot>", n.ExternalKey)
+	assert.Equal(t, "<incident-p1-abc123@coroot>", threadHeaders(n.ExternalKey, false)["In-Reply-To"])
+	assert.Contains(t, res.data, "Content-Type: text/plain; charset=utf-8")
+	assert.Contains(t, res.data, "Content-Type: text/html; charset=utf-8")
+	assert.Contains(t, res.data, "12 errors occurred")
+}
+
+func TestEmailDestinationWithoutRecipients(t *testing.T) {
+	for _, destination := range []db.IncidentNotificationDestination{
+		{IntegrationType: db.IntegrationTypeEmail, EmailTo: db.NewEmailRecipients(nil)},
+		{IntegrationType: db.IntegrationTypeEmail, DigestInterval: 5 * timeseries.Minute},
+	} {
+		v, err := destination.Value()
+		require.NoError(t, err)
+		var scanned db.IncidentNotificationDestination
+		require.NoError(t, scanned.Scan(v))
+		assert.Equal(t, destination, scanned)
+		assert.Empty(t, scanned.EmailTo.List())
+	}
+}
+
+func TestWebhookSignatureAndDestinations(t *testing.T) {
+	type request struct {
+		body      string
+		timestamp string
+		signature string
+		header    string
+	}
+	var primary, secondary []request
+	handler := func(reqs *[]request) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			*reqs = append(*reqs, request{
+				body:      string(body),
+				timestamp: r.Header.Get(WebhookTimestampHeader),
+				signature: r.Header.Get(WebhookSignatureHeader),
+				header:    r.Header.Get("X-Team"),
+			})
+		}
+	}
+	srv1 := httptest.NewServer(handler(&primary))
+	defer srv1.Close()
+	srv2 := httptest.NewServer(handler(&secondary))
+	defer srv2.Close()
+
+	cfg := &db.IntegrationWebhook{
+		IntegrationWebhookDestination: db.IntegrationWebhookDestination{Url: srv1.URL, Secret: "s3cr3t"},
+		Destinations: []db.IntegrationWebhookDestination{
+			{Url: srv2.URL, CustomHeaders: []utils.Header{{Key: "X-Team", Value: "sre"}}},
+		},
+		Incidents:        true,
+		IncidentTemplate: `{"severity": "{{ .Severity }}", "key": "{{ .IncidentKey }}", "root_cause": "{{ .RootCause }}"}`,
+	}
+	require.NoError(t, cfg.Validate())
+
+	n := testNotification(model.CRITICAL)
+	n.Details.RootCause = "disk is full"
+	require.NoError(t, NewWebhook(cfg).SendIncident(context.Background(), "http://coroot", n))
+
+	require.Len(t, primary, 1)
+	require.Len(t, secondary, 1)
+	expectedBody := `{"severity": "critical", "key": "abc123", "root_cause": "disk is full"}`
+	assert.Equal(t, expectedBody, primary[0].body)
+	assert.Equal(t, expectedBody, secondary[0].body)
+
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte(primary[0].timestamp + "." + expectedBody))
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), primary[0].signature)
+
+	assert.Empty(t, secondary[0].signature)
+	assert.Equal(t, "sre", secondary[0].header)
+
+	// a failing destination doesn't fail the notification, otherwise its retry would be re-posted to the others
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	cfg.Destinations = append(cfg.Destinations, db.IntegrationWebhookDestination{Url: failing.URL})
+	require.NoError(t, NewWebhook(cfg).SendIncident(context.Background(), "http://coroot", n))
+	assert.Len(t, primary, 2)
+	assert.Len(t, secondary, 2)
+
+	cfg.IntegrationWebhookDestination = db.IntegrationWebhookDestination{}
+	cfg.Destinations = []db.IntegrationWebhookDestination{{Url: failing.URL}}
+	assert.Error(t, NewWebhook(cfg).SendIncident(context.Background(), "http://coroot", n))
+	assert.Len(t, cfg.AllDestinations(), 1)
+}
+
+func TestDeploymentMetricDeltas(t *testing.T) {
+	curr := &model.MetricsSnapshot{Duration: timeseries.Minute, Requests: 600, Errors: 60, CPUUsage: 30}
+	prev := &model.MetricsSnapshot{Duration: timeseries.Minute, Requests: 600, Errors: 6, CPUUsage: 60}
+	deltas := map[string]DeploymentMetricDelta{}
+	for _, d := range deploymentMetricDeltas(curr, prev) {
+		deltas[d.Name] = d
+	}
+	assert.Equal(t, float32(10), deltas["requests"].Current)
+	assert.Equal(t, float32(0), *deltas["requests"].ChangePercent)
+	assert.Equal(t, float32(10), deltas["errors"].Current)
+	assert.Equal(t, float32(900), *deltas["errors"].ChangePercent)
+	assert.Equal(t, float32(0.5), deltas["cpu_usage"].Current)
+	assert.Equal(t, float32(-50), *deltas["cpu_usage"].ChangePercent)
+
+	for _, d := range deploymentMetricDeltas(curr, nil) {
+		assert.Nil(t, d.Previous)
+		assert.Nil(t, d.ChangePercent)
+	}
+}
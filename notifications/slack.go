@@ -66,6 +66,38 @@ func (s *Slack) SendIncident(ctx context.Context, baseUrl string, n *db.Incident
 	return nil
 }
 
+const slackDigestMaxItems = 20
+
+func (s *Slack) SendIncidentDigest(ctx context.Context, baseUrl string, digest *IncidentDigest) error {
+	blocks := []slack.Block{
+		s.section(s.text("*%s*", digest.Title())),
+	}
+	color := model.OK
+	for i, item := range digest.Items {
+		if i == slackDigestMaxItems {
+			blocks = append(blocks, s.section(s.text("_and %d more_", len(digest.Items)-slackDigestMaxItems)))
+			break
+		}
+		n := item.Notification
+		if n.Status > color {
+			color = n.Status
+		}
+		text := fmt.Sprintf("<%s|*%s*>", incidentUrl(baseUrl, &n), item.Summary())
+		if n.Status != model.OK && n.Details != nil {
+			for _, r := range n.Details.Reports {
+				text += fmt.Sprintf("\n• *%s* / %s: %s", r.Name, r.Check, r.Message)
+			}
+		}
+		blocks = append(blocks, s.section(s.text("%s", text)))
+	}
+	body := s.body(color.Color(), digest.Title(), blocks...)
+	_, _, err := s.client.PostMessageContext(ctx, s.channel, body, slack.MsgOptionDisableLinkUnfurl())
+	if err != nil {
+		return fmt.Errorf("slack error: %w", err)
+	}
+	return nil
+}
+
 func (s *Slack) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
 	d := ds.Deployment
 
@@ -0,0 +1,229 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+
+	"github.com/atc0005/go-teams-notify/v2/adaptivecard"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/slack-go/slack"
+)
+
+// SLOReportClient is implemented by clients that can deliver periodic SLO compliance reports.
+type SLOReportClient interface {
+	SendSLOReport(ctx context.Context, report *SLOReport) error
+}
+
+// SLOReport summarizes the SLO compliance of the applications of a category over a window.
+type SLOReport struct {
+	ProjectId   db.ProjectId              `json:"project_id"`
+	ProjectName string                    `json:"project_name"`
+	Category    model.ApplicationCategory `json:"category,omitempty"`
+	BaseUrl     string                    `json:"-"`
+	From        timeseries.Time           `json:"from"`
+	To          timeseries.Time           `json:"to"`
+	Items       []*model.SLOCompliance    `json:"items"`
+}
+
+func (r *SLOReport) Title() string {
+	if r.Category != "" {
+		return fmt.Sprintf("SLO report of the %s applications of %s", r.Category, r.ProjectName)
+	}
+	return fmt.Sprintf("SLO report of %s", r.ProjectName)
+}
+
+func (r *SLOReport) Period() string {
+	const layout = "Jan 2, 2006"
+	return fmt.Sprintf("%s – %s", r.From.ToStandard().UTC().Format(layout), r.To.Add(-timeseries.Second).ToStandard().UTC().Format(layout))
+}
+
+func (r *SLOReport) Violations() int {
+	var n int
+	for _, i := range r.Items {
+		if !i.Met {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *SLOReport) Status() model.Status {
+	if r.Violations() > 0 {
+		return model.WARNING
+	}
+	return model.OK
+}
+
+func (r *SLOReport) appUrl(id model.ApplicationId) string {
+	return fmt.Sprintf("%s/p/%s/app/%s", r.BaseUrl, r.ProjectId, id.String())
+}
+
+type sloReportLine struct {
+	Emoji       string
+	Application string
+	URL         string
+	SLO         model.SLOType
+	Compliance  string
+	Objective   string
+	Budget      string
+	Incidents   int
+	Deployments int
+}
+
+func (r *SLOReport) lines() []sloReportLine {
+	var res []sloReportLine
+	for _, i := range r.Items {
+		l := sloReportLine{
+			Emoji:       "✅",
+			Application: i.ApplicationId.Name,
+			URL:         r.appUrl(i.ApplicationId),
+			SLO:         i.SLO,
+			Compliance:  formatPercentage(i.Compliance),
+			Objective:   formatPercentage(i.Objective),
+			Budget:      formatPercentage(i.ErrorBudgetRemaining),
+			Incidents:   len(i.Incidents),
+			Deployments: len(i.Deployments),
+		}
+		if !i.Met {
+			l.Emoji = "❌"
+		}
+		res = append(res, l)
+	}
+	return res
+}
+
+func formatPercentage(v float32) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", v), "0"), ".") + "%"
+}
+
+// Markdown renders the report in the Markdown dialect understood by most chat integrations.
+func (r *SLOReport) Markdown() string {
+	var buf bytes.Buffer
+	_ = sloReportMarkdownTemplate.Execute(&buf, struct {
+		*SLOReport
+		Lines []sloReportLine
+	}{r, r.lines()})
+	return buf.String()
+}
+
+// HTML renders the report as a standalone page suitable for emails and printing.
+func (r *SLOReport) HTML() (string, error) {
+	var buf bytes.Buffer
+	err := sloReportHtmlTemplate.Execute(&buf, struct {
+		*SLOReport
+		Lines []sloReportLine
+		Color string
+	}{r, r.lines(), r.Status().Color()})
+	return buf.String(), err
+}
+
+func (s *Slack) SendSLOReport(ctx context.Context, report *SLOReport) error {
+	text := strings.ReplaceAll(report.Markdown(), "**", "*")
+	for _, l := range report.lines() {
+		text = strings.ReplaceAll(text, fmt.Sprintf("[*%s*](%s)", l.Application, l.URL), fmt.Sprintf("<%s|*%s*>", l.URL, l.Application))
+	}
+	body := s.body(report.Status().Color(), report.Title(), s.section(s.text("%s", text)))
+	if _, _, err := s.client.PostMessageContext(ctx, s.channel, body, slack.MsgOptionDisableLinkUnfurl()); err != nil {
+		return fmt.Errorf("slack error: %w", err)
+	}
+	return nil
+}
+
+func (t *Teams) SendSLOReport(ctx context.Context, report *SLOReport) error {
+	_, text, _ := strings.Cut(report.Markdown(), "\n")
+	card, err := adaptivecard.NewTextBlockCard(strings.ReplaceAll(text, "\n", "\n\n"), report.Title(), true)
+	if err != nil {
+		return err
+	}
+	msg, err := adaptivecard.NewMessageFromCard(card)
+	if err != nil {
+		return err
+	}
+	return t.client.SendWithContext(ctx, t.webhookUrl, msg)
+}
+
+func (m *Mattermost) SendSLOReport(ctx context.Context, report *SLOReport) error {
+	post := mattermostPost{
+		ChannelId: m.channel,
+		Props: map[string]any{
+			"attachments": []mattermostAttachment{{
+				Fallback: report.Title(),
+				Color:    report.Status().Color(),
+				Text:     report.Markdown(),
+			}},
+		},
+	}
+	return m.post(ctx, post, nil)
+}
+
+func (g *GoogleChat) SendSLOReport(ctx context.Context, report *SLOReport) error {
+	text := strings.ReplaceAll(report.Markdown(), "**", "*")
+	for _, l := range report.lines() {
+		text = strings.ReplaceAll(text, fmt.Sprintf("[*%s*](%s)", l.Application, l.URL), fmt.Sprintf("<%s|%s>", l.URL, l.Application))
+	}
+	return g.send(ctx, googleChatMessage{Text: text})
+}
+
+func (d *Discord) SendSLOReport(ctx context.Context, report *SLOReport) error {
+	_, description, _ := strings.Cut(report.Markdown(), "\n")
+	msg := discordMessage{Embeds: []discordEmbed{{
+		Title:       report.Title(),
+		Description: strings.TrimSpace(description),
+		Color:       discordColor(report.Status()),
+	}}}
+	if err := sendJson(ctx, d.webhookUrl, nil, msg, nil); err != nil {
+		return fmt.Errorf("discord error: %w", err)
+	}
+	return nil
+}
+
+func (e *Email) SendSLOReport(ctx context.Context, report *SLOReport) error {
+	html, err := report.HTML()
+	if err != nil {
+		return err
+	}
+	return e.send(ctx, "[Coroot] "+report.Title(), report.Markdown(), html, nil)
+}
+
+func (wh *Webhook) SendSLOReport(ctx context.Context, report *SLOReport) error {
+	data, err := json.Marshal(struct {
+		*SLOReport
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	}{report, report.Title(), report.Markdown()})
+	if err != nil {
+		return err
+	}
+	return wh.send(ctx, data)
+}
+
+var (
+	sloReportMarkdownTemplate = template.Must(template.New("slo_report").Parse(`**{{ .Title }}**
+{{ .Period }}: {{ len .Items }} SLOs{{ if .Violations }}, {{ .Violations }} not met{{ end }}
+{{ range .Lines }}
+{{ .Emoji }} [**{{ .Application }}**]({{ .URL }}) {{ .SLO }}: {{ .Compliance }} (objective {{ .Objective }}), error budget left: {{ .Budget }}{{ if .Incidents }}, incidents: {{ .Incidents }}{{ end }}{{ if .Deployments }}, deployments: {{ .Deployments }}{{ end }}{{ end }}
+`))
+
+	sloReportHtmlTemplate = htmltemplate.Must(htmltemplate.New("slo_report").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{ .Title }}</title></head>
+<body style="font-family: sans-serif; font-size: 14px;">
+<div style="border-left: 4px solid {{ .Color }}; padding-left: 12px;">
+<h3 style="margin: 0 0 8px 0;">{{ .Title }}</h3>
+<p>{{ .Period }}: {{ len .Items }} SLOs{{ if .Violations }}, {{ .Violations }} not met{{ end }}</p>
+<table style="border-collapse: collapse;" cellpadding="4">
+<tr style="text-align: left;"><th></th><th>Application</th><th>SLO</th><th>Compliance</th><th>Objective</th><th>Error budget left</th><th>Incidents</th><th>Deployments</th></tr>
+{{ range .Lines }}<tr><td>{{ .Emoji }}</td><td><a href="{{ .URL }}">{{ .Application }}</a></td><td>{{ .SLO }}</td><td>{{ .Compliance }}</td><td>{{ .Objective }}</td><td>{{ .Budget }}</td><td>{{ .Incidents }}</td><td>{{ .Deployments }}</td></tr>
+{{ end }}</table>
+</div>
+</body>
+</html>
+`))
+)
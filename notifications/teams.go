@@ -63,6 +63,31 @@ func (t *Teams) SendIncident(ctx context.Context, baseUrl string, n *db.Incident
 	return nil
 }
 
+func (t *Teams) SendIncidentDigest(ctx context.Context, baseUrl string, digest *IncidentDigest) error {
+	text := ""
+	for _, item := range digest.Items {
+		n := item.Notification
+		text += fmt.Sprintf("* [%s](%s)\n", item.Summary(), incidentUrl(baseUrl, &n))
+		if n.Status != model.OK && n.Details != nil {
+			for _, r := range n.Details.Reports {
+				text += fmt.Sprintf("    * **%s** / %s: %s\n", r.Name, r.Check, r.Message)
+			}
+		}
+	}
+	card, err := adaptivecard.NewTextBlockCard(text, digest.Title(), true)
+	if err != nil {
+		return err
+	}
+	msg, err := adaptivecard.NewMessageFromCard(card)
+	if err != nil {
+		return err
+	}
+	if err = t.client.SendWithContext(ctx, t.webhookUrl, msg); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (t *Teams) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
 	d := ds.Deployment
 
@@ -2,19 +2,31 @@ package notifications
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"text/template"
-
-	"github.com/coroot/coroot/utils"
+	"time"
 
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/utils"
+	"k8s.io/klog"
+)
+
+const (
+	WebhookTimestampHeader = "X-Coroot-Timestamp"
+	WebhookSignatureHeader = "X-Coroot-Signature"
 )
 
 type Webhook struct {
@@ -23,17 +35,52 @@ type Webhook struct {
 
 type IncidentTemplateValues struct {
 	Status      string                                 `json:"status"`
+	Severity    string                                 `json:"severity"`
+	IncidentKey string                                 `json:"incident_key"`
 	Application model.ApplicationId                    `json:"application"`
 	Reports     []db.IncidentNotificationDetailsReport `json:"reports"`
 	URL         string                                 `json:"url"`
+
+	AvailabilityBurnRates []model.BurnRate `json:"availability_burn_rates"`
+	LatencyBurnRates      []model.BurnRate `json:"latency_burn_rates"`
+	AvailabilityImpact    float32          `json:"availability_impact"`
+	LatencyImpact         float32          `json:"latency_impact"`
+	Summary               string           `json:"summary"`
+	RootCause             string           `json:"root_cause"`
+}
+
+type DigestTemplateValues struct {
+	Title     string                   `json:"title"`
+	Open      int                      `json:"open"`
+	Resolved  int                      `json:"resolved"`
+	Incidents []IncidentTemplateValues `json:"incidents"`
 }
 
+const defaultDigestTemplate = `{{ json . }}`
+
 type DeploymentTemplateValues struct {
-	Status      string              `json:"status"`
-	Application model.ApplicationId `json:"application"`
-	Version     string              `json:"version"`
-	Summary     []string            `json:"summary"`
-	URL         string              `json:"url"`
+	Status      string                  `json:"status"`
+	Application model.ApplicationId     `json:"application"`
+	Version     string                  `json:"version"`
+	Summary     []string                `json:"summary"`
+	Metrics     []DeploymentMetricDelta `json:"metrics"`
+	URL         string                  `json:"url"`
+}
+
+// DeploymentMetricDelta compares a metric of the deployment with the previous one.
+// Previous and ChangePercent are omitted if there is no previous deployment to compare with.
+type DeploymentMetricDelta struct {
+	Name          string   `json:"name"`
+	Unit          string   `json:"unit"`
+	Current       float32  `json:"current"`
+	Previous      *float32 `json:"previous,omitempty"`
+	ChangePercent *float32 `json:"change_percent,omitempty"`
+}
+
+type WebhookDelivery struct {
+	Url    string `json:"url"`
+	Status string `json:"status,omitempty"`
+	Error  string `json:"error,omitempty"`
 }
 
 func NewWebhook(cfg *db.IntegrationWebhook) *Webhook {
@@ -41,39 +88,71 @@ func NewWebhook(cfg *db.IntegrationWebhook) *Webhook {
 }
 
 func (wh *Webhook) SendIncident(ctx context.Context, baseUrl string, n *db.IncidentNotification) error {
-	tmpl, err := template.New("incidentTemplate").Funcs(templateFunctions).Parse(wh.cfg.IncidentTemplate)
+	data, err := wh.RenderIncident(baseUrl, n)
 	if err != nil {
-		return fmt.Errorf("invalid incident template: %s", err)
+		return err
 	}
+	return wh.send(ctx, data)
+}
 
+func (wh *Webhook) RenderIncident(baseUrl string, n *db.IncidentNotification) ([]byte, error) {
+	tmpl, err := template.New("incidentTemplate").Funcs(templateFunctions).Parse(wh.cfg.IncidentTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid incident template: %s", err)
+	}
 	var data bytes.Buffer
-	values := IncidentTemplateValues{
-		Status:      strings.ToUpper(n.Status.String()),
-		Application: n.ApplicationId,
-		URL:         incidentUrl(baseUrl, n),
+	if err = tmpl.Execute(&data, incidentTemplateValues(baseUrl, n)); err != nil {
+		return nil, fmt.Errorf("invalid incident template: %s", err)
 	}
-	if n.Details != nil {
-		values.Reports = n.Details.Reports
+	return data.Bytes(), nil
+}
+
+func (wh *Webhook) SendIncidentDigest(ctx context.Context, baseUrl string, digest *IncidentDigest) error {
+	data, err := wh.RenderIncidentDigest(baseUrl, digest)
+	if err != nil {
+		return err
 	}
-	err = tmpl.Execute(&data, values)
+	return wh.send(ctx, data)
+}
+
+func (wh *Webhook) RenderIncidentDigest(baseUrl string, digest *IncidentDigest) ([]byte, error) {
+	tmpl, err := template.New("digestTemplate").Funcs(templateFunctions).Parse(cmp.Or(wh.cfg.DigestTemplate, defaultDigestTemplate))
 	if err != nil {
-		return fmt.Errorf("invalid incident template: %s", err)
+		return nil, fmt.Errorf("invalid digest template: %s", err)
 	}
 
-	return wh.send(ctx, data.Bytes())
+	values := DigestTemplateValues{Title: digest.Title()}
+	values.Open, values.Resolved = digest.Counts()
+	for _, item := range digest.Items {
+		values.Incidents = append(values.Incidents, incidentTemplateValues(baseUrl, &item.Notification))
+	}
+	var data bytes.Buffer
+	if err = tmpl.Execute(&data, values); err != nil {
+		return nil, fmt.Errorf("invalid digest template: %s", err)
+	}
+	return data.Bytes(), nil
 }
 
 func (wh *Webhook) SendDeployment(ctx context.Context, project *db.Project, ds model.ApplicationDeploymentStatus) error {
+	data, err := wh.RenderDeployment(project, ds)
+	if err != nil || data == nil {
+		return err
+	}
+	return wh.send(ctx, data)
+}
+
+// RenderDeployment returns nil if no notification should be sent for the deployment state.
+func (wh *Webhook) RenderDeployment(project *db.Project, ds model.ApplicationDeploymentStatus) ([]byte, error) {
 	tmpl, err := template.New("deploymentTemplate").Funcs(templateFunctions).Parse(wh.cfg.DeploymentTemplate)
 	if err != nil {
-		return fmt.Errorf("invalid deployment template: %s", err)
+		return nil, fmt.Errorf("invalid deployment template: %s", err)
 	}
 
 	status := "Deployed"
 	var summary []string
 	switch ds.State {
 	case model.ApplicationDeploymentStateInProgress:
-		return nil
+		return nil, nil
 	case model.ApplicationDeploymentStateStuck:
 		status = "Stuck"
 	case model.ApplicationDeploymentStateCancelled:
@@ -93,47 +172,154 @@ func (wh *Webhook) SendDeployment(ctx context.Context, project *db.Project, ds m
 		Status:      status,
 		Version:     ds.Deployment.Version(),
 		Summary:     summary,
+		Metrics:     deploymentMetricDeltas(ds.Deployment.MetricsSnapshot, ds.Previous),
 		URL:         deploymentUrl(project.Settings.Integrations.BaseUrl, project.Id, ds.Deployment),
 	})
 	if err != nil {
-		return fmt.Errorf("invalid deployment template: %s", err)
+		return nil, fmt.Errorf("invalid deployment template: %s", err)
 	}
-
-	return wh.send(ctx, data.Bytes())
+	return data.Bytes(), nil
 }
 
+// send fails only if none of the destinations accepted the payload:
+// a retry would post it again to the destinations that already received it.
 func (wh *Webhook) send(ctx context.Context, data []byte) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, wh.cfg.Url, bytes.NewReader(utils.EscapeJsonMultilineStrings(data)))
+	deliveries := wh.Deliver(ctx, data)
+	var errs []error
+	for _, d := range deliveries {
+		if d.Error != "" {
+			errs = append(errs, fmt.Errorf("%s: %s", d.Url, d.Error))
+		}
+	}
+	if len(errs) < len(deliveries) {
+		for _, err := range errs {
+			klog.Errorln("failed to deliver webhook to", err)
+		}
+		return nil
+	}
+	return errors.Join(errs...)
+}
+
+// Deliver posts the payload to every configured destination and reports the result of each attempt.
+func (wh *Webhook) Deliver(ctx context.Context, data []byte) []WebhookDelivery {
+	body := utils.EscapeJsonMultilineStrings(data)
+	var res []WebhookDelivery
+	for _, d := range wh.cfg.AllDestinations() {
+		status, err := deliver(ctx, d, body)
+		delivery := WebhookDelivery{Url: d.Url, Status: status}
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		res = append(res, delivery)
+	}
+	return res
+}
+
+func deliver(ctx context.Context, d db.IntegrationWebhookDestination, body []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Url, bytes.NewReader(body))
 	if err != nil {
-		return err
+		return "", err
 	}
-	if wh.cfg.BasicAuth != nil && wh.cfg.BasicAuth.User != "" && wh.cfg.BasicAuth.Password != "" {
-		req.SetBasicAuth(wh.cfg.BasicAuth.User, wh.cfg.BasicAuth.Password)
+	if d.BasicAuth != nil && d.BasicAuth.User != "" && d.BasicAuth.Password != "" {
+		req.SetBasicAuth(d.BasicAuth.User, d.BasicAuth.Password)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	for _, h := range wh.cfg.CustomHeaders {
+	for _, h := range d.CustomHeaders {
 		req.Header.Add(h.Key, h.Value)
 	}
+	if d.Secret != "" {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, ts)
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(d.Secret, ts, body))
+	}
 	httpClient := &http.Client{}
-	if wh.cfg.TlsSkipVerify {
+	if d.TlsSkipVerify {
 		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
 	}
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return err
+		return "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("response status: %s", resp.Status)
+			return resp.Status, fmt.Errorf("response status: %s", resp.Status)
 		}
-		return fmt.Errorf("%s: %s", resp.Status, string(body))
+		return resp.Status, fmt.Errorf("%s: %s", resp.Status, string(body))
 	}
+	return resp.Status, nil
+}
 
-	return nil
+// WebhookSignature returns the value of the signature header: a hex-encoded HMAC-SHA256
+// of "<timestamp>.<body>". Receivers should also reject requests with stale timestamps to prevent replays.
+func WebhookSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func incidentTemplateValues(baseUrl string, n *db.IncidentNotification) IncidentTemplateValues {
+	v := IncidentTemplateValues{
+		Status:      strings.ToUpper(n.Status.String()),
+		Severity:    n.Status.String(),
+		IncidentKey: n.IncidentKey,
+		Application: n.ApplicationId,
+		URL:         incidentUrl(baseUrl, n),
+	}
+	if d := n.Details; d != nil {
+		v.Reports = d.Reports
+		v.AvailabilityBurnRates = d.AvailabilityBurnRates
+		v.LatencyBurnRates = d.LatencyBurnRates
+		v.AvailabilityImpact = d.AvailabilityImpact
+		v.LatencyImpact = d.LatencyImpact
+		v.Summary = d.Summary
+		v.RootCause = d.RootCause
+	}
+	return v
+}
+
+func deploymentMetricDeltas(curr, prev *model.MetricsSnapshot) []DeploymentMetricDelta {
+	if curr == nil || curr.Duration <= 0 {
+		return nil
+	}
+	var res []DeploymentMetricDelta
+	add := func(name, unit string, f func(ms *model.MetricsSnapshot) float32) {
+		c := f(curr)
+		if c <= 0 && prev == nil {
+			return
+		}
+		d := DeploymentMetricDelta{Name: name, Unit: unit, Current: c}
+		if prev != nil && prev.Duration > 0 {
+			p := f(prev)
+			d.Previous = &p
+			if p > 0 {
+				change := (c - p) * 100 / p
+				d.ChangePercent = &change
+			}
+		}
+		res = append(res, d)
+	}
+	perSecond := func(v float32, ms *model.MetricsSnapshot) float32 {
+		return v / float32(ms.Duration.ToStandard().Seconds())
+	}
+	add("requests", "rps", func(ms *model.MetricsSnapshot) float32 { return perSecond(float32(ms.Requests), ms) })
+	add("errors", "%", func(ms *model.MetricsSnapshot) float32 {
+		if ms.Requests == 0 {
+			return 0
+		}
+		return float32(ms.Errors) * 100 / float32(ms.Requests)
+	})
+	add("cpu_usage", "cores", func(ms *model.MetricsSnapshot) float32 { return perSecond(ms.CPUUsage, ms) })
+	add("memory_usage", "bytes", func(ms *model.MetricsSnapshot) float32 { return float32(ms.MemoryUsage) })
+	add("restarts", "", func(ms *model.MetricsSnapshot) float32 { return float32(ms.Restarts) })
+	add("oom_kills", "", func(ms *model.MetricsSnapshot) float32 { return float32(ms.OOMKills) })
+	add("log_errors", "", func(ms *model.MetricsSnapshot) float32 { return float32(ms.LogErrors) })
+	return res
 }
 
 var (
@@ -235,6 +235,86 @@ func (c *Client) QueryRange(ctx context.Context, query string, filterLabels Filt
 	return maps.Values(res), nil
 }
 
+func (c *Client) Series(ctx context.Context, selectors []string, from, to timeseries.Time) ([]model.Labels, error) {
+	u := c.url
+	u.Path = path.Join(u.Path, "/api/v1/series")
+	q := u.Query()
+	for _, s := range selectors {
+		s, err := addExtraSelector(s, c.config.ExtraSelector)
+		if err != nil {
+			return nil, err
+		}
+		q.Add("match[]", s)
+	}
+	q.Set("start", from.String())
+	q.Set("end", to.String())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), strings.NewReader(q.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, h := range c.config.CustomHeaders {
+		req.Header.Add(h.Key, h.Value)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var res struct {
+		Error string         `json:"error"`
+		Data  []model.Labels `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		if res.Error != "" {
+			return nil, errors.New(res.Error)
+		}
+		return nil, errors.New(resp.Status)
+	}
+	return res.Data, nil
+}
+
+// Retention returns the storage retention reported by the Prometheus runtime info endpoint.
+// It returns zero if the retention is unknown, e.g., the server doesn't implement the endpoint or the retention is size-based.
+func (c *Client) Retention(ctx context.Context) (timeseries.Duration, error) {
+	u := c.url
+	u.Path = path.Join(u.Path, "/api/v1/status/runtimeinfo")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	for _, h := range c.config.CustomHeaders {
+		req.Header.Add(h.Key, h.Value)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, nil
+	}
+	var res struct {
+		Data struct {
+			StorageRetention string `json:"storageRetention"`
+		} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return 0, nil
+	}
+	// e.g., "15d" or "15d or 10GiB"
+	for _, part := range strings.Split(res.Data.StorageRetention, " or ") {
+		if d, err := promModel.ParseDuration(strings.TrimSpace(part)); err == nil {
+			return timeseries.Duration(time.Duration(d).Seconds()), nil
+		}
+	}
+	return 0, nil
+}
+
 func (c *Client) Proxy(r *http.Request, w http.ResponseWriter) {
 	reStr, err := mux.CurrentRoute(r).GetPathRegexp()
 	if err != nil {
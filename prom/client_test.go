@@ -79,3 +79,29 @@ func Test_addExtraSelector(t *testing.T) {
 		`{cluster="cluster1"}`,
 		`rate(node_resources_cpu_usage_seconds_total{cluster="cluster1",mode!="idle"}[30s]) / ignoring (mode) group_left () sum without (mode) (rate(node_resources_cpu_usage_seconds_total{cluster="cluster1"}[30s])) * 100`)
 }
+
+func TestRetention(t *testing.T) {
+	retention := "15d or 10GiB"
+	h := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/status/runtimeinfo" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"status":"success","data":{"storageRetention":"` + retention + `"}}`))
+	}
+	ts := httptest.NewServer(http.HandlerFunc(h))
+	defer ts.Close()
+
+	client, err := NewClient(NewClientConfig(ts.URL, timeseries.Minute))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	r, err := client.Retention(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 15*timeseries.Day, r)
+
+	retention = "10GiB"
+	r, err = client.Retention(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, timeseries.Duration(0), r)
+}
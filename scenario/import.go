@@ -0,0 +1,71 @@
+package scenario
+
+import (
+	"context"
+
+	"github.com/coroot/coroot/cache"
+	"github.com/coroot/coroot/constructor"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// Import generates the metrics of the scenario ending now and mounts them as a read-only snapshot project,
+// which makes scenarios usable as demo data.
+func Import(ctx context.Context, database *db.DB, promCache *cache.Cache, s *Scenario, name string) (*db.Project, error) {
+	if name == "" {
+		name = s.Name
+	}
+	to := timeseries.Now().Truncate(s.Step)
+	from := to.Add(-s.dataDuration())
+
+	project := &db.Project{Name: name}
+	if err := database.SaveProject(project); err != nil {
+		return nil, err
+	}
+	if err := importScenario(ctx, database, promCache, s, project, from, to); err != nil {
+		if dErr := database.DeleteProject(project.Id); dErr != nil {
+			klog.Errorln("failed to delete the partially imported project:", dErr)
+		}
+		if dErr := promCache.DeleteProject(project.Id); dErr != nil {
+			klog.Errorln("failed to delete the cached data of the partially imported project:", dErr)
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+func importScenario(ctx context.Context, database *db.DB, promCache *cache.Cache, s *Scenario, project *db.Project, from, to timeseries.Time) error {
+	project.Settings.Readonly = true
+	project.Settings.ApiKeys = nil
+	project.Settings.Snapshot = &db.ProjectSnapshot{SourceProjectName: s.Name, From: from, To: to, Created: to}
+	if err := database.SaveProjectSettings(project); err != nil {
+		return err
+	}
+
+	c := newSyntheticCache(newGenerator(s, to))
+	if err := c.record(ctx, database, project, from, to); err != nil {
+		return err
+	}
+	queries := make([]string, 0, len(constructor.QUERIES)+len(c.records))
+	for _, q := range constructor.QUERIES {
+		queries = append(queries, q.Query)
+	}
+	for rr := range c.records {
+		queries = append(queries, rr)
+	}
+	pointsCount := int(to.Sub(from)/s.Step) + 1
+	for _, q := range queries {
+		mvs, err := c.QueryRange(ctx, q, from, to, s.Step, timeseries.FillAny)
+		if err != nil {
+			return err
+		}
+		if len(mvs) == 0 {
+			continue
+		}
+		if err = promCache.Import(project.Id, q, from, pointsCount, s.Step, mvs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,404 @@
+package scenario
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+)
+
+// protocolQueries maps the supported L7 protocols to the prefixes of their *_count, *_latency_total and *_histogram queries.
+var protocolQueries = map[model.Protocol]string{
+	model.ProtocolHttp:       "container_http_requests",
+	model.ProtocolPostgres:   "container_postgres_queries",
+	model.ProtocolMysql:      "container_mysql_queries",
+	model.ProtocolMongodb:    "container_mongo_queries",
+	model.ProtocolRedis:      "container_redis_queries",
+	model.ProtocolMemcached:  "container_memcached_queries",
+	model.ProtocolKafka:      "container_kafka_requests",
+	model.ProtocolCassandra:  "container_cassandra_queries",
+	model.ProtocolClickhouse: "container_clickhouse_queries",
+}
+
+const (
+	nodeAgentJob = "coroot-node-agent"
+	mib          = 1 << 20
+	gib          = 1 << 30
+
+	// rolloutDuration is how long the instances of the previous ReplicaSet overlap with the new ones.
+	rolloutDuration = timeseries.Minute
+)
+
+type series struct {
+	labels model.Labels
+	value  func(t timeseries.Time) float32
+}
+
+type node struct {
+	*Node
+	machineId string
+	ip        string
+}
+
+type instance struct {
+	app       *Application
+	ownerKind model.ApplicationKind
+	ownerName string
+	node      *node
+	pod       string
+	uid       string
+	ip        string
+	from, to  timeseries.Time // the lifetime of the pod, zero means unbounded
+}
+
+func (i *instance) containerId() string {
+	return fmt.Sprintf("/k8s/%s/%s/%s", i.app.Namespace, i.pod, i.app.Name)
+}
+
+// generator produces the raw series of the scenario as if they were collected by node-agent and kube-state-metrics.
+type generator struct {
+	s   *Scenario
+	end timeseries.Time
+
+	nodes     map[string]*node
+	instances map[string][]*instance
+	series    map[string][]*series
+}
+
+func newGenerator(s *Scenario, end timeseries.Time) *generator {
+	g := &generator{
+		s:         s,
+		end:       end,
+		nodes:     map[string]*node{},
+		instances: map[string][]*instance{},
+		series:    map[string][]*series{},
+	}
+	for i, n := range s.Nodes {
+		g.nodes[n.Name] = &node{Node: n, machineId: hash(n.Name, 32), ip: fmt.Sprintf("192.168.0.%d", i+1)}
+	}
+	for i, a := range s.Applications {
+		g.addInstances(i, a)
+	}
+	for _, n := range s.Nodes {
+		g.addNode(g.nodes[n.Name])
+	}
+	for _, a := range s.Applications {
+		for _, i := range g.instances[a.Name] {
+			g.addPod(i)
+			g.addContainer(i)
+		}
+		g.addWorkload(a)
+	}
+	for _, c := range s.Connections {
+		g.addConnection(c)
+	}
+	return g
+}
+
+func (g *generator) add(query string, ls model.Labels, value func(t timeseries.Time) float32) {
+	g.series[query] = append(g.series[query], &series{labels: ls, value: value})
+}
+
+func (g *generator) nodeUp(n *node, t timeseries.Time) bool {
+	return n.DownSince == 0 || t < g.end.Add(-n.DownSince)
+}
+
+func (g *generator) alive(i *instance, t timeseries.Time) bool {
+	return (i.from == 0 || t >= i.from) && (i.to == 0 || t < i.to)
+}
+
+// running reports whether the container of the instance is running and being monitored at t.
+func (g *generator) running(i *instance, t timeseries.Time) bool {
+	return g.alive(i, t) && g.nodeUp(i.node, t)
+}
+
+func (g *generator) addInstances(idx int, a *Application) {
+	nodes := a.Nodes
+	if len(nodes) == 0 {
+		for _, n := range g.s.Nodes {
+			nodes = append(nodes, n.Name)
+		}
+	}
+	generations := 1
+	if a.Rollout > 0 {
+		generations = 2
+	}
+	for gen := 0; gen < generations; gen++ {
+		for n := 0; n < a.Instances; n++ {
+			i := &instance{
+				app:       a,
+				ownerKind: a.Kind,
+				ownerName: a.Name,
+				node:      g.nodes[nodes[n%len(nodes)]],
+				ip:        fmt.Sprintf("10.%d.%d.%d", gen+1, idx+1, n+1),
+			}
+			switch a.Kind {
+			case model.ApplicationKindDeployment:
+				// the hex suffix of the ReplicaSet name makes the constructor attribute the pods to the Deployment
+				i.ownerKind = model.ApplicationKindReplicaSet
+				i.ownerName = a.Name + "-" + hash(fmt.Sprintf("%s/%d", a.Name, gen), 10)
+				i.pod = i.ownerName + "-" + hash(fmt.Sprintf("%s/%d", i.ownerName, n), 5)
+			case model.ApplicationKindStatefulSet:
+				i.pod = fmt.Sprintf("%s-%d", a.Name, n)
+			case model.ApplicationKindDaemonSet:
+				i.node = g.nodes[g.s.Nodes[n].Name]
+				i.pod = a.Name + "-" + hash(i.node.Name, 5)
+			}
+			i.uid = hash(i.pod, 32)
+			if a.Rollout > 0 {
+				rollout := g.end.Add(-a.Rollout)
+				if gen == 0 {
+					i.to = rollout.Add(rolloutDuration)
+				} else {
+					i.from = rollout
+				}
+			}
+			g.instances[a.Name] = append(g.instances[a.Name], i)
+		}
+	}
+}
+
+func (g *generator) addNode(n *node) {
+	up := func(v func(t timeseries.Time) float32) func(t timeseries.Time) float32 {
+		return func(t timeseries.Time) float32 {
+			if !g.nodeUp(n, t) {
+				return timeseries.NaN
+			}
+			return v(t)
+		}
+	}
+	constant := func(v float32) func(t timeseries.Time) float32 {
+		return func(t timeseries.Time) float32 { return v }
+	}
+	ls := func(kv ...string) model.Labels {
+		res := model.Labels{model.LabelMachineId: n.machineId}
+		for i := 0; i+1 < len(kv); i += 2 {
+			res[kv[i]] = kv[i+1]
+		}
+		return res
+	}
+	g.add("node_info", ls("hostname", n.Name, "kernel_version", "6.1.0"), up(constant(1)))
+	g.add("node_agent_info", ls("version", "1.0.0"), up(constant(1)))
+	g.add("node_cpu_cores", ls(), up(constant(n.CpuCores)))
+	g.add("node_cpu_usage_percent", ls(), up(func(t timeseries.Time) float32 { return n.CpuUsage.At(t, g.end) }))
+	g.add("node_memory_total_bytes", ls(), up(constant(n.MemoryGiB*gib)))
+	g.add("node_memory_available_bytes", ls(), up(func(t timeseries.Time) float32 {
+		used := float32(0)
+		for _, a := range g.s.Applications {
+			for _, i := range g.instances[a.Name] {
+				if i.node == n && g.alive(i, t) {
+					used += a.MemoryMiB.At(t, g.end) * mib
+				}
+			}
+		}
+		return n.MemoryGiB*gib*0.9 - used
+	}))
+	// kube-state-metrics keeps reporting the node when the node itself is down
+	g.add("kube_node_info", model.Labels{"node": n.Name, model.LabelSystemUuid: n.machineId}, constant(1))
+	g.add("up", model.Labels{"job": nodeAgentJob, "instance": n.ip}, func(t timeseries.Time) float32 {
+		if g.nodeUp(n, t) {
+			return 1
+		}
+		return 0
+	})
+}
+
+func (g *generator) addPod(i *instance) {
+	alive := func(t timeseries.Time) float32 {
+		if g.alive(i, t) {
+			return 1
+		}
+		return timeseries.NaN
+	}
+	g.add("kube_pod_info", model.Labels{
+		"uid": i.uid, "namespace": i.app.Namespace, "pod": i.pod,
+		"created_by_kind": string(i.ownerKind), "created_by_name": i.ownerName,
+		"node": i.node.Name, "pod_ip": i.ip, "host_ip": i.node.ip,
+	}, alive)
+	g.add("kube_pod_status_phase", model.Labels{"uid": i.uid, "phase": "Running"}, alive)
+	g.add("kube_pod_status_scheduled", model.Labels{"uid": i.uid}, alive)
+	g.add("kube_pod_status_ready", model.Labels{"uid": i.uid}, func(t timeseries.Time) float32 {
+		if !g.alive(i, t) {
+			return timeseries.NaN
+		}
+		if !g.nodeUp(i.node, t) || g.crashed(i, t) {
+			return 0
+		}
+		return 1
+	})
+	g.add("kube_pod_container_status_running", model.Labels{"uid": i.uid, "namespace": i.app.Namespace, "pod": i.pod, "container": i.app.Name}, alive)
+}
+
+func (g *generator) addWorkload(a *Application) {
+	replicas := func(t timeseries.Time) float32 { return float32(a.Instances) }
+	ls := model.Labels{"namespace": a.Namespace}
+	switch a.Kind {
+	case model.ApplicationKindDeployment:
+		ls["deployment"] = a.Name
+		g.add("kube_deployment_spec_replicas", ls, replicas)
+	case model.ApplicationKindStatefulSet:
+		ls["statefulset"] = a.Name
+		g.add("kube_statefulset_replicas", ls, replicas)
+	case model.ApplicationKindDaemonSet:
+		ls["daemonset"] = a.Name
+		g.add("kube_daemonset_status_desired_number_scheduled", ls, replicas)
+	}
+}
+
+// crashes returns the number of container restarts caused by the crash loop by t.
+func (g *generator) crashes(i *instance, t timeseries.Time) float32 {
+	cl := i.app.CrashLoop
+	if cl == nil {
+		return 0
+	}
+	from, to := g.end.Add(-cl.Since), g.end.Add(-cl.Until)
+	if cl.Since == 0 {
+		from = i.from
+	}
+	if t < from {
+		return 0
+	}
+	if t > to {
+		t = to
+	}
+	return float32(t.Sub(from) / cl.Period)
+}
+
+// crashed reports whether the container has just been restarted and is not ready yet.
+func (g *generator) crashed(i *instance, t timeseries.Time) bool {
+	return g.crashes(i, t) > g.crashes(i, t.Add(-g.s.Step))
+}
+
+func (g *generator) addContainer(i *instance) {
+	a := i.app
+	ls := func(kv ...string) model.Labels {
+		res := model.Labels{model.LabelMachineId: i.node.machineId, model.LabelContainerId: i.containerId()}
+		for j := 0; j+1 < len(kv); j += 2 {
+			res[kv[j]] = kv[j+1]
+		}
+		return res
+	}
+	running := func(v func(t timeseries.Time) float32) func(t timeseries.Time) float32 {
+		return func(t timeseries.Time) float32 {
+			if !g.running(i, t) {
+				return timeseries.NaN
+			}
+			return v(t)
+		}
+	}
+	g.add("container_info", ls("image", fmt.Sprintf("registry.local/%s:1.0", a.Name)), running(func(t timeseries.Time) float32 { return 1 }))
+	g.add("container_cpu_usage", ls(), running(func(t timeseries.Time) float32 { return a.Cpu.At(t, g.end) }))
+	g.add("container_memory_rss", ls(), running(func(t timeseries.Time) float32 { return a.MemoryMiB.At(t, g.end) * mib }))
+	if a.CpuLimit > 0 {
+		g.add("container_cpu_limit", ls(), running(func(t timeseries.Time) float32 { return a.CpuLimit }))
+	}
+	if a.MemoryLimitMiB > 0 {
+		g.add("container_memory_limit", ls(), running(func(t timeseries.Time) float32 { return a.MemoryLimitMiB * mib }))
+	}
+	g.add("container_net_tcp_listen_info", ls("listen_addr", fmt.Sprintf("%s:%d", i.ip, a.Port)), running(func(t timeseries.Time) float32 { return 1 }))
+
+	// the counters are reported by the agent, so their statuses are the ones of the agent's scrape job
+	counter := ls("job", nodeAgentJob, "instance", i.node.ip)
+	g.add("container_restarts", counter, running(func(t timeseries.Time) float32 { return g.crashes(i, t) }))
+	if a.CrashLoop != nil && a.CrashLoop.OOM {
+		g.add("container_oom_kills_total", counter, running(func(t timeseries.Time) float32 { return g.crashes(i, t) }))
+	}
+}
+
+func (g *generator) addConnection(c *Connection) {
+	servers := g.instances[c.To]
+	prefix := protocolQueries[c.Protocol]
+	okStatus, failedStatus := "ok", "failed"
+	if c.Protocol == model.ProtocolHttp {
+		okStatus, failedStatus = "200", "500"
+	}
+	port := strconv.Itoa(g.s.getApplication(c.To).Port)
+
+	for _, client := range g.instances[c.From] {
+		// available returns the number of server instances accepting connections at t
+		available := func(t timeseries.Time) float32 {
+			var n float32
+			for _, s := range servers {
+				if g.running(s, t) {
+					n++
+				}
+			}
+			return n
+		}
+		for _, server := range servers {
+			client, server := client, server
+			addr := server.ip + ":" + port
+			ls := func(kv ...string) model.Labels {
+				res := model.Labels{
+					model.LabelMachineId:         client.node.machineId,
+					model.LabelContainerId:       client.containerId(),
+					model.LabelDestination:       addr,
+					model.LabelActualDestination: addr,
+				}
+				for j := 0; j+1 < len(kv); j += 2 {
+					res[kv[j]] = kv[j+1]
+				}
+				return res
+			}
+			// rps returns the request rate from the client to the server, NaN if there is no traffic between them
+			rps := func(t timeseries.Time) float32 {
+				if !g.running(client, t) || !g.running(server, t) {
+					return timeseries.NaN
+				}
+				return c.Rps.At(t, g.end) / available(t)
+			}
+			g.add("container_net_tcp_successful_connects", ls(), rps)
+			g.add("container_net_tcp_active_connections", ls(), func(t timeseries.Time) float32 {
+				if timeseries.IsNaN(rps(t)) {
+					return timeseries.NaN
+				}
+				return 1
+			})
+			g.add("container_net_tcp_failed_connects", ls(), func(t timeseries.Time) float32 {
+				if !g.running(client, t) || !g.alive(server, t) || g.running(server, t) {
+					return timeseries.NaN
+				}
+				return c.Rps.At(t, g.end) / float32(len(servers))
+			})
+			g.add("container_net_latency", model.Labels{
+				model.LabelMachineId:     client.node.machineId,
+				model.LabelContainerId:   client.containerId(),
+				model.LabelDestinationIP: server.ip,
+			}, func(t timeseries.Time) float32 {
+				if !g.running(client, t) || !g.running(server, t) {
+					return timeseries.NaN
+				}
+				return 0.0005
+			})
+
+			errors := func(t timeseries.Time) float32 {
+				return c.Errors.At(t, g.end)
+			}
+			g.add(prefix+"_count", ls("status", okStatus), func(t timeseries.Time) float32 {
+				return rps(t) * (1 - errors(t))
+			})
+			g.add(prefix+"_count", ls("status", failedStatus), func(t timeseries.Time) float32 {
+				return rps(t) * errors(t)
+			})
+			g.add(prefix+"_latency_total", ls(), func(t timeseries.Time) float32 {
+				return rps(t) * c.Latency.At(t, g.end)
+			})
+			for _, le := range model.DefaultHistogramBuckets {
+				le := le
+				g.add(prefix+"_histogram", ls("le", strconv.FormatFloat(float64(le), 'f', -1, 32)), func(t timeseries.Time) float32 {
+					if c.Latency.At(t, g.end) > le {
+						return 0 * rps(t)
+					}
+					return rps(t)
+				})
+			}
+			g.add(prefix+"_histogram", ls("le", "+Inf"), rps)
+		}
+	}
+}
+
+func hash(s string, length int) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))[:length]
+}
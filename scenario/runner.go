@@ -0,0 +1,307 @@
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/config"
+	"github.com/coroot/coroot/constructor"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/coroot/coroot/utils"
+	"github.com/coroot/coroot/watchers"
+	promModel "github.com/prometheus/common/model"
+)
+
+// syntheticCache serves the generated series to the constructor in place of the Prometheus cache.
+type syntheticCache struct {
+	g       *generator
+	step    timeseries.Duration
+	names   map[string]string // query -> query name
+	records map[string][]*model.MetricValues
+}
+
+func newSyntheticCache(g *generator) *syntheticCache {
+	c := &syntheticCache{g: g, step: g.s.Step, names: map[string]string{}, records: map[string][]*model.MetricValues{}}
+	for _, q := range constructor.QUERIES {
+		c.names[q.Query] = q.Name
+	}
+	return c
+}
+
+func (c *syntheticCache) GetStep(from, to timeseries.Time) (timeseries.Duration, error) {
+	return c.step, nil
+}
+
+func (c *syntheticCache) QueryRange(ctx context.Context, query string, from, to timeseries.Time, step timeseries.Duration, fillFunc timeseries.FillFunc) ([]*model.MetricValues, error) {
+	from = from.Truncate(step)
+	to = to.Truncate(step)
+	pointsCount := int(to.Sub(from)/step + 1)
+	var res []*model.MetricValues
+	if records, ok := c.records[query]; ok {
+		for _, r := range records {
+			var rFrom timeseries.Time
+			var data []float32
+			iter := r.Values.Iter()
+			for iter.Next() {
+				t, v := iter.Value()
+				if data == nil {
+					rFrom = t
+				}
+				data = append(data, v)
+			}
+			// the records cover only the scenario, e.g., the anomaly history is requested for the previous days
+			if data == nil || rFrom.After(to) || rFrom.Add(timeseries.Duration(len(data)-1)*c.step).Before(from) {
+				continue
+			}
+			ts := timeseries.New(from, pointsCount, step)
+			if fillFunc(ts, rFrom, c.step, data) {
+				res = append(res, metricValues(r.Labels, ts))
+			}
+		}
+		return res, nil
+	}
+	for _, s := range c.g.series[c.names[query]] {
+		data := make([]float32, pointsCount)
+		defined := false
+		for i := range data {
+			data[i] = s.value(from.Add(timeseries.Duration(i) * step))
+			defined = defined || !timeseries.IsNaN(data[i])
+		}
+		if defined {
+			res = append(res, metricValues(s.labels, timeseries.NewWithData(from, step, data)))
+		}
+	}
+	return res, nil
+}
+
+// metricValues moves the special labels to the dedicated fields as the cache does while reading chunks.
+func metricValues(labels model.Labels, values *timeseries.TimeSeries) *model.MetricValues {
+	mv := &model.MetricValues{Labels: model.Labels{}, LabelsHash: promModel.LabelsToSignature(labels), Values: values}
+	for k, v := range labels {
+		switch k {
+		case model.LabelMachineId:
+			mv.MachineID = v
+		case model.LabelSystemUuid:
+			mv.SystemUUID = v
+		case model.LabelContainerId:
+			mv.ContainerId = v
+		case model.LabelDestination:
+			mv.Destination = v
+		case model.LabelActualDestination:
+			mv.ActualDestination = v
+		case model.LabelDestinationIP:
+			mv.Destination = v
+			mv.DestIp = true
+		default:
+			mv.Labels[k] = v
+		}
+	}
+	return mv
+}
+
+// record calculates the recording rules over the generated data as the cache updater does.
+func (c *syntheticCache) record(ctx context.Context, database *db.DB, project *db.Project, from, to timeseries.Time) error {
+	world, err := constructor.New(database, project, c, nil,
+		constructor.OptionLoadInstanceToInstanceConnections,
+		constructor.OptionDoNotLoadRawSLIs,
+		constructor.OptionLoadContainerLogs,
+	).LoadWorld(ctx, from, to, c.step, nil)
+	if err != nil {
+		return err
+	}
+	for name, rule := range constructor.RecordingRules {
+		c.records[name] = rule(database, project, world)
+	}
+	return nil
+}
+
+type Result struct {
+	Project *db.Project
+	World   *model.World
+	// Incidents contains the open incidents by application.
+	Incidents map[model.ApplicationId]*model.ApplicationIncident
+}
+
+// Run creates a project for the scenario and passes the generated metrics through the constructor, the deployment
+// and incident watchers. The incident watcher evaluates the SLOs at the current time, so the scenario ends now.
+func Run(ctx context.Context, database *db.DB, s *Scenario) (*Result, error) {
+	end := timeseries.Now().Truncate(s.Step)
+	project := &db.Project{Name: s.Name}
+	if err := database.SaveProject(project); err != nil {
+		return nil, err
+	}
+	c := newSyntheticCache(newGenerator(s, end))
+	if err := c.record(ctx, database, project, end.Add(-s.dataDuration()), end); err != nil {
+		return nil, err
+	}
+
+	from := end.Add(-s.Duration)
+	world, err := constructor.New(database, project, c, nil).LoadWorld(ctx, from, end, s.Step, nil)
+	if err != nil {
+		return nil, err
+	}
+	watchers.NewDeployments(database, nil).Check(project, world)
+
+	// the world is reloaded to pick up the deployments detected by the watcher
+	if world, err = constructor.New(database, project, c, nil).LoadWorld(ctx, from, end, s.Step, nil); err != nil {
+		return nil, err
+	}
+	incidents := map[model.ApplicationId]*model.ApplicationIncident{}
+	rca := func(ctx context.Context, project *db.Project, world *model.World, incident *model.ApplicationIncident) {
+		if !incident.Resolved() {
+			incidents[incident.ApplicationId] = incident
+		}
+	}
+	watchers.NewIncidents(database, rca, nil, config.Incidents{}).Check(project, world)
+
+	// the statuses of the SLO checks are derived from the incidents, so the world is audited once again
+	// as it would be shown in the UI right after the incidents were opened
+	if world, err = constructor.New(database, project, c, nil).LoadWorld(ctx, from, timeseries.Now(), s.Step, nil); err != nil {
+		return nil, err
+	}
+	auditor.Audit(world, project, nil, false, nil)
+	for _, incident := range incidents {
+		rca := &model.RCA{Status: "OK", PropagationMap: PropagationMap(world, incident.ApplicationId)}
+		if err = database.UpdateIncidentRCA(project.Id, incident, rca); err != nil {
+			return nil, err
+		}
+	}
+	return &Result{Project: project, World: world, Incidents: incidents}, nil
+}
+
+// PropagationMap builds the map of the applications the incident may have propagated from: it starts with the
+// affected application and follows the upstreams that are either unhealthy themselves or reached through a failing connection.
+func PropagationMap(w *model.World, appId model.ApplicationId) *model.PropagationMap {
+	app := w.GetApplication(appId)
+	if app == nil {
+		return nil
+	}
+	res := &model.PropagationMap{}
+	visited := map[model.ApplicationId]*model.PropagationMapApplication{}
+	var visit func(app *model.Application) *model.PropagationMapApplication
+	visit = func(app *model.Application) *model.PropagationMapApplication {
+		if a := visited[app.Id]; a != nil {
+			return a
+		}
+		a := &model.PropagationMapApplication{
+			Id:     app.Id,
+			Icon:   app.ApplicationType().Icon(),
+			Labels: app.Labels(),
+			Status: app.Status,
+		}
+		visited[app.Id] = a
+		res.Applications = append(res.Applications, a)
+		for _, r := range app.Reports {
+			for _, ch := range r.Checks {
+				if ch.Status >= model.WARNING {
+					a.Issue("%s", ch.Title)
+				}
+			}
+		}
+		ids := make([]model.ApplicationId, 0, len(app.Upstreams))
+		for id := range app.Upstreams {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+		for _, id := range ids {
+			conn := app.Upstreams[id]
+			if conn.RemoteApplication == nil || conn.RemoteApplication.Id == app.Id {
+				continue
+			}
+			link := &model.PropagationMapApplicationLink{Id: id, Stats: utils.NewStringSet()}
+			if status, msg := conn.Status(); status >= model.WARNING {
+				link.AddIssues(msg)
+			} else {
+				link.Status = status
+			}
+			if errors := conn.GetConnectionsErrorsSum(nil); errors.Last() > 0 {
+				link.AddIssues("errors")
+			}
+			if link.Status < model.WARNING && conn.RemoteApplication.Status < model.WARNING {
+				continue
+			}
+			upstream := visit(conn.RemoteApplication)
+			a.Upstreams = append(a.Upstreams, link)
+			upstream.Downstreams = append(upstream.Downstreams, &model.PropagationMapApplicationLink{Id: app.Id, Status: link.Status, Stats: link.Stats})
+		}
+		return a
+	}
+	visit(app)
+	return res
+}
+
+// Verify returns the mismatches between the result and the expectations of the scenario.
+func (r *Result) Verify(s *Scenario) []error {
+	var errs []error
+
+	expected := map[model.ApplicationId]string{}
+	for _, i := range s.Expect.Incidents {
+		expected[s.getApplication(i.Application).Id()] = i.Severity
+	}
+	for id, severity := range expected {
+		incident := r.Incidents[id]
+		switch {
+		case incident == nil:
+			errs = append(errs, fmt.Errorf("%s: no incident", id.Name))
+		case severity != "" && incident.Severity.String() != severity:
+			errs = append(errs, fmt.Errorf("%s: incident severity is %s, expected %s", id.Name, incident.Severity, severity))
+		}
+	}
+	for id := range r.Incidents {
+		if _, ok := expected[id]; !ok {
+			errs = append(errs, fmt.Errorf("%s: unexpected incident", id.Name))
+		}
+	}
+
+	for name, checks := range s.Expect.Checks {
+		app := r.World.GetApplication(s.getApplication(name).Id())
+		if app == nil {
+			errs = append(errs, fmt.Errorf("%s: no such application in the world", name))
+			continue
+		}
+		for id, status := range checks {
+			var check *model.Check
+			for _, report := range app.Reports {
+				for _, ch := range report.Checks {
+					if ch.Id == id {
+						check = ch
+					}
+				}
+			}
+			switch {
+			case check == nil:
+				errs = append(errs, fmt.Errorf("%s: no %s check", name, id))
+			case check.Status.String() != status:
+				errs = append(errs, fmt.Errorf("%s: %s is %s (%s), expected %s", name, id, check.Status, check.Message, status))
+			}
+		}
+	}
+
+	for name, statuses := range s.Expect.Propagation {
+		incident := r.Incidents[s.getApplication(name).Id()]
+		if incident == nil || incident.RCA == nil || incident.RCA.PropagationMap == nil {
+			errs = append(errs, fmt.Errorf("%s: no propagation map", name))
+			continue
+		}
+		byId := map[model.ApplicationId]*model.PropagationMapApplication{}
+		for _, a := range incident.RCA.PropagationMap.Applications {
+			byId[a.Id] = a
+		}
+		for appName, status := range statuses {
+			a := byId[s.getApplication(appName).Id()]
+			switch {
+			case a == nil && status != "":
+				errs = append(errs, fmt.Errorf("%s: %s is not on the propagation map", name, appName))
+			case a != nil && status == "":
+				errs = append(errs, fmt.Errorf("%s: %s is not expected on the propagation map", name, appName))
+			case a != nil && a.Status.String() != status:
+				errs = append(errs, fmt.Errorf("%s: %s is %s on the propagation map, expected %s", name, appName, a.Status, status))
+			}
+		}
+	}
+	return errs
+}
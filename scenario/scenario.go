@@ -0,0 +1,374 @@
+// Package scenario builds synthetic worlds from YAML descriptions of applications, instances, connections
+// and the shapes of their metrics. The generated metrics go through the regular constructor, auditor and
+// incident watcher, so scenarios serve both as a regression suite for the detection logic and as demo data.
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	defaultDuration  = timeseries.Hour
+	defaultStep      = 15 * timeseries.Second
+	defaultNamespace = "default"
+	defaultPort      = 8080
+)
+
+type Scenario struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+
+	// Duration is the time range of the world checked against the expectations, it ends at the end of the scenario.
+	Duration timeseries.Duration `yaml:"duration"`
+	// Step is the scrape interval of the generated metrics.
+	Step timeseries.Duration `yaml:"step"`
+
+	Nodes        []*Node        `yaml:"nodes"`
+	Applications []*Application `yaml:"applications"`
+	Connections  []*Connection  `yaml:"connections"`
+
+	Expect Expectations `yaml:"expect"`
+}
+
+type Node struct {
+	Name      string  `yaml:"name"`
+	CpuCores  float32 `yaml:"cpu_cores"`
+	MemoryGiB float32 `yaml:"memory_gib"`
+	// CpuUsage is the node CPU utilization, in percent.
+	CpuUsage *Shape `yaml:"cpu_usage"`
+	// DownSince makes the node stop reporting metrics the given time before the end of the scenario.
+	DownSince timeseries.Duration `yaml:"down_since"`
+}
+
+type Application struct {
+	Name      string                `yaml:"name"`
+	Namespace string                `yaml:"namespace"`
+	Kind      model.ApplicationKind `yaml:"kind"`
+	Instances int                   `yaml:"instances"`
+	// Nodes the instances are scheduled on in a round-robin manner, all the nodes if empty.
+	Nodes []string `yaml:"nodes"`
+	Port  int      `yaml:"port"`
+
+	// Cpu is the CPU usage of every instance, in cores.
+	Cpu      *Shape  `yaml:"cpu"`
+	CpuLimit float32 `yaml:"cpu_limit"`
+	// MemoryMiB is the RSS of every instance.
+	MemoryMiB      *Shape  `yaml:"memory_mib"`
+	MemoryLimitMiB float32 `yaml:"memory_limit_mib"`
+
+	CrashLoop *CrashLoop `yaml:"crash_loop"`
+	// Rollout replaces the instances of a Deployment with the ones of a new ReplicaSet the given time before the end of the scenario.
+	Rollout timeseries.Duration `yaml:"rollout"`
+}
+
+// CrashLoop restarts the containers of the application once per period within the window.
+type CrashLoop struct {
+	Since  timeseries.Duration `yaml:"since"`
+	Until  timeseries.Duration `yaml:"until"`
+	Period timeseries.Duration `yaml:"period"`
+	OOM    bool                `yaml:"oom"`
+}
+
+type Connection struct {
+	From     string         `yaml:"from"`
+	To       string         `yaml:"to"`
+	Protocol model.Protocol `yaml:"protocol"`
+
+	// Rps is the request rate of every client instance, spread evenly across the available server instances.
+	Rps *Shape `yaml:"rps"`
+	// Errors is the fraction of failed requests.
+	Errors *Shape `yaml:"errors"`
+	// Latency is the response time, in seconds.
+	Latency *Shape `yaml:"latency"`
+}
+
+type Expectations struct {
+	// Incidents lists all the applications expected to have an open incident.
+	Incidents []ExpectedIncident `yaml:"incidents"`
+	// Checks contains the expected check statuses by application name and check id (e.g., MemoryOOM).
+	Checks map[string]map[model.CheckId]string `yaml:"checks"`
+	// Propagation contains the expected statuses of the applications on the propagation map of an incident,
+	// an empty status means the application must not be on the map.
+	Propagation map[string]map[string]string `yaml:"propagation"`
+}
+
+type ExpectedIncident struct {
+	Application string `yaml:"application"`
+	Severity    string `yaml:"severity"`
+}
+
+type ShapeKind string
+
+const (
+	// ShapeConstant is the value all the time.
+	ShapeConstant ShapeKind = "constant"
+	// ShapeStep is the peak within the window and the value outside it.
+	ShapeStep ShapeKind = "step"
+	// ShapeRamp grows linearly from the value to the peak within the window and stays at the peak after it.
+	ShapeRamp ShapeKind = "ramp"
+	// ShapeSawtooth grows from the value to the peak once per period within the window.
+	ShapeSawtooth ShapeKind = "sawtooth"
+	// ShapeAbsent has no data within the window.
+	ShapeAbsent ShapeKind = "absent"
+)
+
+// Shape describes a time series relative to the end of the scenario. The window starts Since before the end
+// and ends Until before the end. A bare number in YAML is a constant.
+type Shape struct {
+	Kind   ShapeKind           `yaml:"shape"`
+	Value  float32             `yaml:"value"`
+	Peak   float32             `yaml:"peak"`
+	Since  timeseries.Duration `yaml:"since"`
+	Until  timeseries.Duration `yaml:"until"`
+	Period timeseries.Duration `yaml:"period"`
+}
+
+func (s *Shape) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		v, err := strconv.ParseFloat(value.Value, 32)
+		if err != nil {
+			return fmt.Errorf("invalid shape %q: %w", value.Value, err)
+		}
+		*s = Shape{Kind: ShapeConstant, Value: float32(v)}
+		return nil
+	}
+	type plain Shape
+	if err := value.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	if s.Kind == "" {
+		s.Kind = ShapeConstant
+	}
+	return nil
+}
+
+func (s *Shape) validate() error {
+	switch s.Kind {
+	case ShapeConstant, ShapeAbsent:
+	case ShapeStep, ShapeRamp:
+		if s.Since <= s.Until {
+			return fmt.Errorf("%s: 'since' must be greater than 'until'", s.Kind)
+		}
+	case ShapeSawtooth:
+		if s.Period <= 0 {
+			return fmt.Errorf("%s: 'period' must be positive", s.Kind)
+		}
+	default:
+		return fmt.Errorf("unknown shape: %s", s.Kind)
+	}
+	return nil
+}
+
+// At returns the value of the shape at t, the window is relative to the end of the scenario.
+// A nil shape has no data.
+func (s *Shape) At(t, end timeseries.Time) float32 {
+	if s == nil {
+		return timeseries.NaN
+	}
+	from, to := end.Add(-s.Since), end.Add(-s.Until)
+	inWindow := s.Since == 0 || (t >= from && t < to)
+	switch s.Kind {
+	case ShapeStep:
+		if inWindow {
+			return s.Peak
+		}
+	case ShapeRamp:
+		switch {
+		case t < from:
+		case t >= to:
+			return s.Peak
+		default:
+			return s.Value + (s.Peak-s.Value)*float32(t.Sub(from))/float32(to.Sub(from))
+		}
+	case ShapeSawtooth:
+		if inWindow {
+			phase := t.Sub(from) % s.Period
+			if s.Since == 0 {
+				phase = timeseries.Duration(t) % s.Period
+			}
+			return s.Value + (s.Peak-s.Value)*float32(phase)/float32(s.Period)
+		}
+	case ShapeAbsent:
+		if inWindow {
+			return timeseries.NaN
+		}
+	}
+	return s.Value
+}
+
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func Parse(data []byte) (*Scenario, error) {
+	s := &Scenario{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Scenario) validate() error {
+	if s.Duration == 0 {
+		s.Duration = defaultDuration
+	}
+	if s.Step == 0 {
+		s.Step = defaultStep
+	}
+	if len(s.Nodes) == 0 {
+		return fmt.Errorf("no nodes")
+	}
+	nodes := map[string]bool{}
+	for _, n := range s.Nodes {
+		if n.Name == "" || nodes[n.Name] {
+			return fmt.Errorf("invalid or duplicate node name: %q", n.Name)
+		}
+		nodes[n.Name] = true
+		if n.CpuCores == 0 {
+			n.CpuCores = 4
+		}
+		if n.MemoryGiB == 0 {
+			n.MemoryGiB = 16
+		}
+		if n.CpuUsage == nil {
+			n.CpuUsage = &Shape{Kind: ShapeConstant, Value: 20}
+		}
+		if err := n.CpuUsage.validate(); err != nil {
+			return fmt.Errorf("node %s: %w", n.Name, err)
+		}
+	}
+	apps := map[string]bool{}
+	for _, a := range s.Applications {
+		if a.Name == "" || apps[a.Name] {
+			return fmt.Errorf("invalid or duplicate application name: %q", a.Name)
+		}
+		apps[a.Name] = true
+		if a.Namespace == "" {
+			a.Namespace = defaultNamespace
+		}
+		switch a.Kind {
+		case "":
+			a.Kind = model.ApplicationKindDeployment
+		case model.ApplicationKindDeployment, model.ApplicationKindStatefulSet, model.ApplicationKindDaemonSet:
+		default:
+			return fmt.Errorf("application %s: unsupported kind %s", a.Name, a.Kind)
+		}
+		if a.Kind == model.ApplicationKindDaemonSet {
+			a.Instances = len(s.Nodes)
+			a.Nodes = nil
+		}
+		if a.Instances == 0 {
+			a.Instances = 1
+		}
+		if a.Rollout > 0 && a.Kind != model.ApplicationKindDeployment {
+			return fmt.Errorf("application %s: only Deployments can be rolled out", a.Name)
+		}
+		for _, n := range a.Nodes {
+			if !nodes[n] {
+				return fmt.Errorf("application %s: unknown node %s", a.Name, n)
+			}
+		}
+		if a.Port == 0 {
+			a.Port = defaultPort
+		}
+		if a.Cpu == nil {
+			a.Cpu = &Shape{Kind: ShapeConstant, Value: 0.1}
+		}
+		if a.MemoryMiB == nil {
+			a.MemoryMiB = &Shape{Kind: ShapeConstant, Value: 100}
+		}
+		for _, sh := range []*Shape{a.Cpu, a.MemoryMiB} {
+			if err := sh.validate(); err != nil {
+				return fmt.Errorf("application %s: %w", a.Name, err)
+			}
+		}
+		if a.CrashLoop != nil && a.CrashLoop.Period <= 0 {
+			return fmt.Errorf("application %s: crash loop period must be positive", a.Name)
+		}
+	}
+	for _, c := range s.Connections {
+		if !apps[c.From] || !apps[c.To] {
+			return fmt.Errorf("connection %s -> %s: unknown application", c.From, c.To)
+		}
+		if c.Protocol == "" {
+			c.Protocol = model.ProtocolHttp
+		}
+		if _, ok := protocolQueries[c.Protocol]; !ok {
+			return fmt.Errorf("connection %s -> %s: unsupported protocol %s", c.From, c.To, c.Protocol)
+		}
+		if c.Rps == nil {
+			c.Rps = &Shape{Kind: ShapeConstant, Value: 10}
+		}
+		if c.Errors == nil {
+			c.Errors = &Shape{Kind: ShapeConstant}
+		}
+		if c.Latency == nil {
+			c.Latency = &Shape{Kind: ShapeConstant, Value: 0.01}
+		}
+		for _, sh := range []*Shape{c.Rps, c.Errors, c.Latency} {
+			if err := sh.validate(); err != nil {
+				return fmt.Errorf("connection %s -> %s: %w", c.From, c.To, err)
+			}
+		}
+	}
+	for name := range s.Expect.Checks {
+		if !apps[name] {
+			return fmt.Errorf("expect: unknown application %s", name)
+		}
+	}
+	for _, i := range s.Expect.Incidents {
+		if !apps[i.Application] {
+			return fmt.Errorf("expect: unknown application %s", i.Application)
+		}
+	}
+	for name, statuses := range s.Expect.Propagation {
+		if !apps[name] {
+			return fmt.Errorf("expect: unknown application %s", name)
+		}
+		for n := range statuses {
+			if !apps[n] {
+				return fmt.Errorf("expect: unknown application %s", n)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Scenario) getApplication(name string) *Application {
+	for _, a := range s.Applications {
+		if a.Name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func (a *Application) Id() model.ApplicationId {
+	return model.NewApplicationId(a.Namespace, a.Kind, a.Name)
+}
+
+// dataDuration is the time range of the generated metrics: the SLO burn rates are calculated over the longest alerting window.
+func (s *Scenario) dataDuration() timeseries.Duration {
+	d := s.Duration
+	if model.MaxAlertRuleWindow > d {
+		d = model.MaxAlertRuleWindow
+	}
+	return d + timeseries.Hour
+}
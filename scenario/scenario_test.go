@@ -0,0 +1,62 @@
+package scenario
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShape(t *testing.T) {
+	end := timeseries.Time(10000)
+	s, err := Parse([]byte(`
+name: test
+nodes: [{name: n}]
+applications:
+  - name: app
+    cpu: 0.5
+    memory_mib: {shape: ramp, value: 100, peak: 200, since: 1000s, until: 500s}
+connections:
+  - from: app
+    to: app
+    errors: {shape: step, peak: 0.5, since: 100s}
+`))
+	require.NoError(t, err)
+	a := s.getApplication("app")
+	assert.Equal(t, float32(0.5), a.Cpu.At(end, end))
+	assert.Equal(t, float32(100), a.MemoryMiB.At(end.Add(-2000), end))
+	assert.Equal(t, float32(150), a.MemoryMiB.At(end.Add(-750), end))
+	assert.Equal(t, float32(200), a.MemoryMiB.At(end.Add(-100), end))
+	c := s.Connections[0]
+	assert.Equal(t, float32(0), c.Errors.At(end.Add(-200), end))
+	assert.Equal(t, float32(0.5), c.Errors.At(end.Add(-50), end))
+	assert.Equal(t, model.ProtocolHttp, c.Protocol)
+
+	_, err = Parse([]byte(`{name: test, nodes: [{name: n}], connections: [{from: a, to: b}]}`))
+	assert.Error(t, err)
+}
+
+func TestScenarios(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, f := range files {
+		s, err := Load(f)
+		require.NoError(t, err)
+		t.Run(s.Name, func(t *testing.T) {
+			database, err := db.NewSqlite(t.TempDir())
+			require.NoError(t, err)
+			require.NoError(t, database.Migrate())
+			res, err := Run(context.Background(), database, s)
+			require.NoError(t, err)
+			for _, err := range res.Verify(s) {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+const virtualNodesPerReplica = 128
+
+// Ring assigns keys to replicas using consistent hashing.
+// Every replica is represented by many virtual nodes, so when a replica joins or leaves
+// only the keys of its virtual nodes move, and the load stays evenly distributed.
+type Ring struct {
+	hashes []uint64
+	owners map[uint64]string
+}
+
+func NewRing(replicaIds ...string) *Ring {
+	r := &Ring{owners: map[uint64]string{}}
+	for _, id := range replicaIds {
+		for i := 0; i < virtualNodesPerReplica; i++ {
+			h := hash(id + "#" + strconv.Itoa(i))
+			// on a collision, the smallest id wins regardless of the order of replicas
+			if owner, ok := r.owners[h]; ok && owner < id {
+				continue
+			}
+			if _, ok := r.owners[h]; !ok {
+				r.hashes = append(r.hashes, h)
+			}
+			r.owners[h] = id
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// Owner returns the id of the replica responsible for the key, or an empty string if the ring is empty.
+func (r *Ring) Owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := hash(key)
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.owners[r.hashes[i]]
+}
+
+func hash(s string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(s))
+	// fnv has poor avalanche on similar short strings, mix the bits to spread virtual nodes evenly
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
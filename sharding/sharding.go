@@ -0,0 +1,173 @@
+package sharding
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/gorilla/mux"
+	"k8s.io/klog"
+)
+
+const (
+	HeartbeatInterval = 10 * time.Second
+	ReplicaTimeout    = 30 * timeseries.Second
+
+	proxiedByHeader = "X-Coroot-Proxied-By"
+)
+
+// Sharding distributes projects across the replicas sharing the same database.
+// A nil *Sharding means that sharding is disabled and the replica owns every project.
+type Sharding struct {
+	db   *db.DB
+	self db.Replica
+
+	lock     sync.RWMutex
+	ring     *Ring
+	replicas map[string]db.Replica
+
+	heartbeatLock sync.Mutex
+	stopped       bool
+}
+
+func New(database *db.DB, replicaId, url string) *Sharding {
+	return &Sharding{
+		db:       database,
+		self:     db.Replica{Id: replicaId, Url: url},
+		ring:     NewRing(replicaId),
+		replicas: map[string]db.Replica{replicaId: {Id: replicaId, Url: url}},
+	}
+}
+
+func (s *Sharding) Start() {
+	s.heartbeat()
+	go func() {
+		for range time.Tick(HeartbeatInterval) {
+			s.heartbeat()
+		}
+	}()
+}
+
+// Stop deregisters the replica, so the other replicas take over its projects without waiting for the heartbeat timeout.
+func (s *Sharding) Stop() {
+	if s == nil {
+		return
+	}
+	s.heartbeatLock.Lock()
+	defer s.heartbeatLock.Unlock()
+	s.stopped = true
+	if err := s.db.DeleteReplica(s.self.Id); err != nil {
+		klog.Errorln("failed to deregister the replica:", err)
+	}
+}
+
+func (s *Sharding) heartbeat() {
+	s.heartbeatLock.Lock()
+	defer s.heartbeatLock.Unlock()
+	if s.stopped {
+		return
+	}
+	now := timeseries.Now()
+	s.self.Heartbeat = now
+	if err := s.db.PutReplica(s.self); err != nil {
+		klog.Errorln("failed to send heartbeat:", err)
+		return
+	}
+	replicas, err := s.db.GetReplicas(now.Add(-ReplicaTimeout))
+	if err != nil {
+		klog.Errorln("failed to get replicas:", err)
+		return
+	}
+	s.update(replicas)
+}
+
+func (s *Sharding) update(replicas []db.Replica) {
+	byId := map[string]db.Replica{s.self.Id: s.self}
+	for _, r := range replicas {
+		byId[r.Id] = r
+	}
+	ids := make([]string, 0, len(byId))
+	for id := range byId {
+		ids = append(ids, id)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for id := range byId {
+		if _, ok := s.replicas[id]; !ok {
+			klog.Infoln("replica joined:", id)
+		}
+	}
+	for id := range s.replicas {
+		if _, ok := byId[id]; !ok {
+			klog.Infoln("replica left:", id)
+		}
+	}
+	if len(byId) != len(s.replicas) {
+		klog.Infof("rebalancing projects across %d replicas", len(byId))
+	}
+	s.replicas = byId
+	s.ring = NewRing(ids...)
+}
+
+// Owner returns the replica responsible for the project.
+func (s *Sharding) Owner(projectId db.ProjectId) db.Replica {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.replicas[s.ring.Owner(string(projectId))]
+}
+
+func (s *Sharding) IsOwner(projectId db.ProjectId) bool {
+	if s == nil {
+		return true
+	}
+	return s.Owner(projectId).Id == s.self.Id
+}
+
+// Middleware proxies the requests for projects owned by other replicas to their owners.
+// Requests already proxied by another replica are served locally to avoid loops while replicas disagree on the ring.
+func (s *Sharding) Middleware(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		projectId := mux.Vars(r)["project"]
+		if projectId == "" || r.Header.Get(proxiedByHeader) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		owner := s.Owner(db.ProjectId(projectId))
+		if owner.Id == "" || owner.Id == s.self.Id {
+			next.ServeHTTP(w, r)
+			return
+		}
+		target, err := url.Parse(owner.Url)
+		if err != nil || target.Host == "" {
+			klog.Errorf("invalid url of replica %s: %s", owner.Id, owner.Url)
+			next.ServeHTTP(w, r)
+			return
+		}
+		proxy := &httputil.ReverseProxy{
+			Rewrite: func(pr *httputil.ProxyRequest) {
+				pr.Out.URL.Scheme = target.Scheme
+				pr.Out.URL.Host = target.Host
+				pr.Out.Host = target.Host
+				pr.SetXForwarded()
+				pr.Out.Header.Set(proxiedByHeader, s.self.Id)
+			},
+			ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				klog.Warningf("failed to proxy the request to replica %s: %s", owner.Id, err)
+				if r.Method == http.MethodGet {
+					next.ServeHTTP(w, r)
+					return
+				}
+				http.Error(w, "", http.StatusBadGateway)
+			},
+		}
+		proxy.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,132 @@
+package sharding
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	assert.Equal(t, "", NewRing().Owner("p1"))
+
+	var projects []string
+	for i := 0; i < 1000; i++ {
+		projects = append(projects, fmt.Sprintf("project-%d", i))
+	}
+	owners := func(r *Ring) map[string]string {
+		res := map[string]string{}
+		for _, p := range projects {
+			res[p] = r.Owner(p)
+		}
+		return res
+	}
+
+	three := owners(NewRing("a", "b", "c"))
+	assert.Equal(t, three, owners(NewRing("c", "a", "b")), "the assignment must not depend on the order of replicas")
+
+	counts := map[string]int{}
+	for _, o := range three {
+		counts[o]++
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		assert.InDelta(t, len(projects)/3, counts[id], float64(len(projects))/10, id)
+	}
+
+	// a replica joins: only the projects moving to it change their owner
+	four := owners(NewRing("a", "b", "c", "d"))
+	moved := 0
+	for p, o := range four {
+		if o != three[p] {
+			assert.Equal(t, "d", o)
+			moved++
+		}
+	}
+	assert.InDelta(t, len(projects)/4, moved, float64(len(projects))/10)
+
+	// a replica leaves: only its projects are reassigned
+	two := owners(NewRing("a", "c"))
+	for p, o := range two {
+		if three[p] != "b" {
+			assert.Equal(t, three[p], o)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	handler := func(name string) http.Handler {
+		r := mux.NewRouter()
+		r.HandleFunc("/api/project/{project}/overview", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, "%s:%s", name, r.Header.Get(proxiedByHeader))
+		})
+		return r
+	}
+
+	remoteRouter := mux.NewRouter()
+	remote := httptest.NewServer(remoteRouter)
+	defer remote.Close()
+
+	s := New(nil, "local", "http://local")
+	s.update([]db.Replica{{Id: "remote", Url: remote.URL}})
+	remoteShards := New(nil, "remote", remote.URL)
+	remoteShards.update([]db.Replica{{Id: "local", Url: "http://local"}})
+
+	remoteRouter.Use(remoteShards.Middleware)
+	remoteRouter.Handle("/api/project/{project}/overview", handler("remote"))
+
+	localRouter := mux.NewRouter()
+	localRouter.Use(s.Middleware)
+	localRouter.Handle("/api/project/{project}/overview", handler("local"))
+	local := httptest.NewServer(localRouter)
+	defer local.Close()
+
+	get := func(projectId string) string {
+		resp, err := http.Get(local.URL + "/api/project/" + projectId + "/overview")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+
+	var localProject, remoteProject string
+	for i := 0; localProject == "" || remoteProject == ""; i++ {
+		p := fmt.Sprintf("p%d", i)
+		if s.IsOwner(db.ProjectId(p)) {
+			localProject = p
+		} else {
+			remoteProject = p
+		}
+	}
+	assert.Equal(t, "local:", get(localProject))
+	assert.Equal(t, "remote:local", get(remoteProject))
+
+	var disabled *Sharding
+	assert.True(t, disabled.IsOwner("p1"))
+}
+
+func TestStop(t *testing.T) {
+	database, err := db.NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate())
+
+	a, b := New(database, "a", "http://a"), New(database, "b", "http://b")
+	a.heartbeat()
+	b.heartbeat()
+	a.heartbeat()
+	assert.Len(t, a.replicas, 2)
+
+	b.Stop()
+	b.heartbeat()
+	a.heartbeat()
+	assert.Len(t, a.replicas, 1, "the stopped replica must leave the ring immediately")
+
+	var disabled *Sharding
+	disabled.Stop()
+}
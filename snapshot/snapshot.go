@@ -0,0 +1,456 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coroot/coroot/cache"
+	"github.com/coroot/coroot/cache/chunk"
+	"github.com/coroot/coroot/cache/storage"
+	"github.com/coroot/coroot/clickhouse"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+// A snapshot is a gzipped tarball bundling everything needed to rebuild the world of a project for a time range.
+// The entries are written in the following order, so that the snapshot can be imported in a single pass:
+//
+//	manifest.json      the format version, the source project, the time range and the list of queries
+//	project.json       the project settings, check configs, deployments, incidents and application settings
+//	metrics/<n>.db     the cached series of the n-th query in the chunk format (absent if the query has no data)
+//	logs.json          an optional excerpt of the ClickHouse logs
+//	traces.json        an optional excerpt of the ClickHouse traces
+const (
+	Version = 2
+
+	manifestFile = "manifest.json"
+	projectFile  = "project.json"
+	metricsDir   = "metrics"
+	logsFile     = "logs.json"
+	tracesFile   = "traces.json"
+
+	DefaultExcerptLimit = 1000
+
+	maxEntrySize   = 512 << 20
+	maxPointsCount = 1 << 20
+)
+
+var ErrTooLarge = errors.New("the snapshot is too large")
+
+// MaxSize limits the decompressed size of an imported snapshot.
+var MaxSize int64 = 8 << 30
+
+type Manifest struct {
+	Version     int                 `json:"version"`
+	ProjectId   db.ProjectId        `json:"project_id"`
+	ProjectName string              `json:"project_name"`
+	From        timeseries.Time     `json:"from"`
+	To          timeseries.Time     `json:"to"`
+	Step        timeseries.Duration `json:"step"`
+	Created     timeseries.Time     `json:"created"`
+	Queries     []string            `json:"queries"`
+}
+
+type Project struct {
+	Settings            db.ProjectSettings                                     `json:"settings"`
+	CheckConfigs        model.CheckConfigs                                     `json:"check_configs"`
+	Deployments         map[model.ApplicationId][]*model.ApplicationDeployment `json:"deployments"`
+	Incidents           map[model.ApplicationId][]*model.ApplicationIncident   `json:"incidents"`
+	ApplicationSettings map[model.ApplicationId]*model.ApplicationSettings     `json:"application_settings"`
+}
+
+type Excerpts struct {
+	Logs   []*model.LogEntry  `json:"logs"`
+	Traces []*model.TraceSpan `json:"traces"`
+}
+
+type ExportOptions struct {
+	From timeseries.Time
+	To   timeseries.Time
+	// ClickHouse is used to include the logs and traces excerpts, they are skipped if it's nil.
+	ClickHouse   *clickhouse.Client
+	ExcerptLimit int
+}
+
+// Export is a snapshot ready to be written. Everything that can fail before the archive is started
+// (the time range, the project data, the list of queries) is checked by NewExport,
+// so that the errors can be reported before the first byte is sent.
+type Export struct {
+	cacheClient *cache.Client
+	opts        ExportOptions
+	manifest    Manifest
+	project     Project
+}
+
+func NewExport(database *db.DB, promCache *cache.Cache, project *db.Project, opts ExportOptions) (*Export, error) {
+	if project.Settings.Snapshot != nil {
+		return nil, fmt.Errorf("the project is a snapshot itself")
+	}
+	cacheClient := promCache.GetCacheClient(project.Id)
+	step, err := cacheClient.GetStep(opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	if step <= 0 {
+		return nil, fmt.Errorf("no cached data for the time range")
+	}
+	from, to := opts.From.Truncate(step), opts.To.Truncate(step)
+	if cacheTo, err := cacheClient.GetTo(); err != nil {
+		return nil, err
+	} else if cacheTo < to {
+		to = cacheTo.Truncate(step)
+	}
+	if to <= from {
+		return nil, fmt.Errorf("no cached data for the time range")
+	}
+
+	p := Project{Settings: project.Settings}
+	// credentials must not leave the installation
+	p.Settings.ApiKeys = nil
+	p.Settings.Integrations = db.Integrations{}
+	if p.CheckConfigs, err = database.GetCheckConfigs(project.Id); err != nil {
+		return nil, err
+	}
+	if p.Deployments, err = database.GetApplicationDeployments(project.Id); err != nil {
+		return nil, err
+	}
+	if p.Incidents, err = database.GetApplicationIncidents(project.Id, from, to); err != nil {
+		return nil, err
+	}
+	if p.ApplicationSettings, err = database.GetApplicationSettingsByProject(project.Id); err != nil {
+		return nil, err
+	}
+
+	queries, err := cacheClient.GetQueries()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(queries)
+	manifest := Manifest{
+		Version:     Version,
+		ProjectId:   project.Id,
+		ProjectName: project.Name,
+		From:        from,
+		To:          to,
+		Step:        step,
+		Created:     timeseries.Now(),
+		Queries:     queries,
+	}
+	return &Export{cacheClient: cacheClient, opts: opts, manifest: manifest, project: p}, nil
+}
+
+// Write streams the snapshot as a gzipped tar archive.
+func (e *Export) Write(ctx context.Context, w io.Writer) error {
+	from, to, step, queries := e.manifest.From, e.manifest.To, e.manifest.Step, e.manifest.Queries
+	var err error
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err = writeJson(tw, manifestFile, e.manifest); err != nil {
+		return err
+	}
+	if err = writeJson(tw, projectFile, e.project); err != nil {
+		return err
+	}
+	pointsCount := int(to.Sub(from)/step) + 1
+	buf := &bytes.Buffer{}
+	for i, q := range queries {
+		mvs, err := e.cacheClient.QueryRange(ctx, q, from, to, step, timeseries.FillAny)
+		if err != nil {
+			return err
+		}
+		if len(mvs) == 0 {
+			continue
+		}
+		buf.Reset()
+		if err = chunk.Write(buf, from, pointsCount, step, true, mvs); err != nil {
+			return err
+		}
+		if err = writeFile(tw, metricsFile(i), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if e.opts.ClickHouse != nil {
+		limit := e.opts.ExcerptLimit
+		if limit <= 0 {
+			limit = DefaultExcerptLimit
+		}
+		tsCtx := timeseries.Context{From: from, To: to, Step: step, RawStep: step}
+		logs, err := e.opts.ClickHouse.GetLogs(ctx, clickhouse.LogQuery{Ctx: tsCtx, Source: model.LogSourceOtel, Limit: limit})
+		if err != nil {
+			klog.Warningln("failed to get logs:", err)
+		} else if err = writeJson(tw, logsFile, logs); err != nil {
+			return err
+		}
+		traces, err := e.opts.ClickHouse.GetRootSpans(ctx, clickhouse.SpanQuery{Ctx: tsCtx, Limit: limit})
+		if err != nil {
+			klog.Warningln("failed to get traces:", err)
+		} else if err = writeJson(tw, tracesFile, traces); err != nil {
+			return err
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Import mounts the snapshot as a new read-only project. The metrics are written to the cache
+// and never updated or garbage-collected; the rest of the data is stored in the database as for a regular project.
+// The entries are streamed, and the project is deleted if the import fails halfway.
+func Import(ctx context.Context, r io.Reader, database *db.DB, promCache *cache.Cache, name string) (*db.Project, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(&limitedReader{r: gz, n: MaxSize + 1})
+
+	manifest := &Manifest{}
+	if err = readJson(tr, manifestFile, manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version != Version {
+		return nil, fmt.Errorf("unsupported snapshot version: %d", manifest.Version)
+	}
+	if manifest.Step <= 0 || manifest.To < manifest.From || manifest.To.Sub(manifest.From)/manifest.Step >= maxPointsCount {
+		return nil, fmt.Errorf("invalid snapshot time range")
+	}
+	p := &Project{}
+	if err = readJson(tr, projectFile, p); err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		name = fmt.Sprintf("%s-%s", manifest.ProjectName, manifest.To.ToStandard().Format("20060102-1504"))
+	}
+	project := &db.Project{Name: name}
+	if err = database.SaveProject(project); err != nil {
+		return nil, err
+	}
+	if err = importProject(ctx, tr, database, promCache, project, manifest, p); err != nil {
+		if dErr := database.DeleteProject(project.Id); dErr != nil {
+			klog.Errorln("failed to delete the partially imported project:", dErr)
+		}
+		if dErr := promCache.DeleteProject(project.Id); dErr != nil {
+			klog.Errorln("failed to delete the cached data of the partially imported project:", dErr)
+		}
+		return nil, err
+	}
+	return project, nil
+}
+
+func importProject(ctx context.Context, tr *tar.Reader, database *db.DB, promCache *cache.Cache, project *db.Project, manifest *Manifest, p *Project) error {
+	project.Settings = p.Settings
+	project.Settings.Readonly = true
+	project.Settings.ApiKeys = nil
+	project.Settings.Integrations = db.Integrations{}
+	project.Settings.Snapshot = &db.ProjectSnapshot{
+		SourceProjectId:   manifest.ProjectId,
+		SourceProjectName: manifest.ProjectName,
+		From:              manifest.From,
+		To:                manifest.To,
+		Created:           manifest.Created,
+	}
+	if err := database.SaveProjectSettings(project); err != nil {
+		return err
+	}
+	if err := importProjectData(database, project.Id, p); err != nil {
+		return err
+	}
+
+	pointsCount := int(manifest.To.Sub(manifest.From)/manifest.Step) + 1
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid snapshot: %w", err)
+		}
+		if h.Size > maxEntrySize {
+			return fmt.Errorf("%w: %s", ErrTooLarge, h.Name)
+		}
+		switch {
+		case h.Name == logsFile || h.Name == tracesFile:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err = promCache.Storage().Put(ctx, storage.Key(string(project.Id), h.Name), data); err != nil {
+				return err
+			}
+		case strings.HasPrefix(h.Name, metricsDir+"/"):
+			var i int
+			if _, err = fmt.Sscanf(h.Name, metricsDir+"/%d.db", &i); err != nil || i < 0 || i >= len(manifest.Queries) {
+				return fmt.Errorf("invalid snapshot: unexpected entry %s", h.Name)
+			}
+			dest := map[uint64]*model.MetricValues{}
+			if err = chunk.ReadFrom(tr, manifest.From, pointsCount, manifest.Step, dest, timeseries.FillAny); err != nil {
+				return fmt.Errorf("invalid snapshot data for query #%d: %w", i, err)
+			}
+			mvs := make([]*model.MetricValues, 0, len(dest))
+			for _, mv := range dest {
+				mvs = append(mvs, mv)
+			}
+			if err = promCache.Import(project.Id, manifest.Queries[i], manifest.From, pointsCount, manifest.Step, mvs); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// GetExcerpts returns the logs and traces bundled with the snapshot.
+func GetExcerpts(ctx context.Context, promCache *cache.Cache, projectId db.ProjectId) (*Excerpts, error) {
+	res := &Excerpts{}
+	for name, dest := range map[string]any{logsFile: &res.Logs, tracesFile: &res.Traces} {
+		r, err := promCache.Storage().Get(ctx, storage.Key(string(projectId), name))
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = json.NewDecoder(r).Decode(dest)
+		_ = r.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func importProjectData(database *db.DB, projectId db.ProjectId, p *Project) error {
+	for appId, configs := range p.CheckConfigs {
+		for checkId, cfg := range configs {
+			if err := database.SaveCheckConfig(projectId, appId, checkId, cfg); err != nil {
+				return err
+			}
+		}
+	}
+	for _, deployments := range p.Deployments {
+		for _, d := range deployments {
+			if err := database.SaveApplicationDeployment(projectId, d); err != nil {
+				return err
+			}
+			if d.MetricsSnapshot != nil {
+				if err := database.SaveApplicationDeploymentMetricsSnapshot(projectId, d); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for appId, incidents := range p.Incidents {
+		for _, i := range incidents {
+			if err := database.CreateIncident(projectId, appId, i); err != nil {
+				return err
+			}
+			if i.Resolved() {
+				if err := database.ResolveIncident(projectId, appId, i); err != nil {
+					return err
+				}
+			}
+			if i.RCA != nil {
+				if err := database.UpdateIncidentRCA(projectId, i, i.RCA); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for appId, s := range p.ApplicationSettings {
+		if s == nil {
+			continue
+		}
+		var settings []any
+		if s.Profiling != nil {
+			settings = append(settings, s.Profiling)
+		}
+		if s.Tracing != nil {
+			settings = append(settings, s.Tracing)
+		}
+		if s.Logs != nil {
+			settings = append(settings, s.Logs)
+		}
+		for _, i := range s.Instrumentation {
+			settings = append(settings, i)
+		}
+		if s.RiskOverrides != nil {
+			settings = append(settings, s.RiskOverrides)
+		}
+		for _, v := range settings {
+			if err := database.SaveApplicationSetting(projectId, appId, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func readJson(tr *tar.Reader, name string, v any) error {
+	h, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("invalid snapshot: %w", err)
+	}
+	if h.Name != name {
+		return fmt.Errorf("invalid snapshot: %s is expected, got %s", name, h.Name)
+	}
+	if h.Size > maxEntrySize {
+		return fmt.Errorf("%w: %s", ErrTooLarge, h.Name)
+	}
+	if err = json.NewDecoder(tr).Decode(v); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+func metricsFile(i int) string {
+	return path.Join(metricsDir, fmt.Sprintf("%d.db", i))
+}
+
+// limitedReader fails with ErrTooLarge rather than truncating the data as io.LimitReader does.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n <= 0 {
+		return n, ErrTooLarge
+	}
+	return n, err
+}
+
+func writeJson(tw *tar.Writer, name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeFile(tw, name, data)
+}
+
+func writeFile(tw *tar.Writer, name string, data []byte) error {
+	h := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+	if err := tw.WriteHeader(h); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
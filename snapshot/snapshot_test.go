@@ -0,0 +1,59 @@
+package snapshot
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportInvalid(t *testing.T) {
+	build := func(entries ...func(tw *tar.Writer)) io.Reader {
+		buf := &bytes.Buffer{}
+		gz := gzip.NewWriter(buf)
+		tw := tar.NewWriter(gz)
+		for _, e := range entries {
+			e(tw)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, gz.Close())
+		return buf
+	}
+	entry := func(name string, v any) func(tw *tar.Writer) {
+		return func(tw *tar.Writer) {
+			require.NoError(t, writeJson(tw, name, v))
+		}
+	}
+	manifest := Manifest{Version: Version, ProjectName: "p", From: 0, To: 3600, Step: 30}
+
+	_, err := Import(context.Background(), build(entry(projectFile, Project{}), entry(manifestFile, manifest)), nil, nil, "")
+	assert.ErrorContains(t, err, "manifest.json is expected, got project.json")
+
+	old := manifest
+	old.Version = 1
+	_, err = Import(context.Background(), build(entry(manifestFile, old)), nil, nil, "")
+	assert.ErrorContains(t, err, "unsupported snapshot version: 1")
+
+	huge := manifest
+	huge.To = 1 << 40
+	_, err = Import(context.Background(), build(entry(manifestFile, huge)), nil, nil, "")
+	assert.ErrorContains(t, err, "invalid snapshot time range")
+
+	maxSize := MaxSize
+	MaxSize = 1 << 20
+	defer func() {
+		MaxSize = maxSize
+	}()
+	bomb := build(entry(manifestFile, manifest), func(tw *tar.Writer) {
+		require.NoError(t, writeFile(tw, projectFile, []byte(strings.Repeat(" ", 2<<20)+"{}")))
+	})
+	_, err = Import(context.Background(), bomb, nil, nil, "")
+	assert.True(t, errors.Is(err, ErrTooLarge), err)
+}
@@ -0,0 +1,197 @@
+package timeseries
+
+import (
+	"math"
+	"sort"
+)
+
+// madScale makes the median absolute deviation a consistent estimator of the standard deviation for normally distributed data.
+const madScale = 1.4826
+
+// Median returns the median of the defined values or NaN if there are none.
+func Median(values []float32) float32 {
+	vs := make([]float32, 0, len(values))
+	for _, v := range values {
+		if !IsNaN(v) {
+			vs = append(vs, v)
+		}
+	}
+	if len(vs) == 0 {
+		return NaN
+	}
+	sort.Slice(vs, func(i, j int) bool { return vs[i] < vs[j] })
+	m := len(vs) / 2
+	if len(vs)%2 == 0 {
+		return (vs[m-1] + vs[m]) / 2
+	}
+	return vs[m]
+}
+
+// MAD returns the median absolute deviation of the defined values from their median.
+func MAD(values []float32) float32 {
+	m := Median(values)
+	if IsNaN(m) {
+		return NaN
+	}
+	deviations := make([]float32, 0, len(values))
+	for _, v := range values {
+		if !IsNaN(v) {
+			deviations = append(deviations, float32(math.Abs(float64(v-m))))
+		}
+	}
+	return Median(deviations)
+}
+
+// RobustZScore returns how many (robustly estimated) standard deviations v is away from the median of the sample.
+// Unlike the classic z-score, it's not affected by the outliers present in the sample.
+// minDeviation is used instead of the sample deviation if the latter is smaller, which prevents flat samples from producing huge scores.
+func RobustZScore(sample []float32, v float32, minDeviation float32) float32 {
+	m := Median(sample)
+	if IsNaN(m) || IsNaN(v) {
+		return NaN
+	}
+	return zScore(v, m, MAD(sample)*madScale, minDeviation)
+}
+
+func zScore(v, expected, deviation, minDeviation float32) float32 {
+	if IsNaN(deviation) || deviation < minDeviation {
+		deviation = minDeviation
+	}
+	if deviation <= 0 {
+		switch {
+		case v > expected:
+			return float32(math.Inf(1))
+		case v < expected:
+			return float32(math.Inf(-1))
+		}
+		return 0
+	}
+	return (v - expected) / deviation
+}
+
+// EWMA returns the exponentially weighted moving average of the series: each point is the average of the preceding points,
+// so the result can be used as a one-step-ahead forecast. NaNs are skipped.
+func EWMA(ts *TimeSeries, alpha float32) *TimeSeries {
+	if ts.IsEmpty() {
+		return ts
+	}
+	res := New(ts.from, len(ts.data), ts.step)
+	s := NaN
+	for i, v := range ts.data {
+		res.data[i] = s
+		if IsNaN(v) {
+			continue
+		}
+		if IsNaN(s) {
+			s = v
+		} else {
+			s = alpha*v + (1-alpha)*s
+		}
+	}
+	res.last = res.data[len(res.data)-1]
+	return res
+}
+
+// Baseline describes the expected behavior of a series: the expected value and the typical deviation from it for every point.
+type Baseline struct {
+	Expected  *TimeSeries
+	Deviation *TimeSeries
+}
+
+// NewSeasonalBaseline learns the baseline from the same time window in previous seasons (e.g., the same hour yesterday
+// and a week ago) shifted to the current time window. The seasons must be aligned with each other point by point:
+// the expected value is the median of the seasons, and the deviation is their scaled MAD. Misaligned seasons are ignored.
+func NewSeasonalBaseline(seasons ...*TimeSeries) *Baseline {
+	var defined []*TimeSeries
+	pointsCount := 0
+	for _, s := range seasons {
+		if s.IsEmpty() {
+			continue
+		}
+		if len(defined) > 0 && (s.from != defined[0].from || s.step != defined[0].step) {
+			continue
+		}
+		defined = append(defined, s)
+		if len(s.data) > pointsCount {
+			pointsCount = len(s.data)
+		}
+	}
+	if len(defined) == 0 {
+		return nil
+	}
+	from, step := defined[0].from, defined[0].step
+	b := &Baseline{Expected: New(from, pointsCount, step), Deviation: New(from, pointsCount, step)}
+	sample := make([]float32, len(defined))
+	for i := 0; i < pointsCount; i++ {
+		for j, s := range defined {
+			sample[j] = NaN
+			if i < len(s.data) {
+				sample[j] = s.data[i]
+			}
+		}
+		b.Expected.data[i] = Median(sample)
+		b.Deviation.data[i] = MAD(sample) * madScale
+	}
+	b.Expected.last = b.Expected.data[pointsCount-1]
+	b.Deviation.last = b.Deviation.data[pointsCount-1]
+	return b
+}
+
+// NewEWMABaseline learns the baseline from the series itself: the expected value is the EWMA forecast, and the deviation is
+// the scaled MAD of the forecast errors observed before the given time. It's used when no history is available.
+func NewEWMABaseline(ts *TimeSeries, alpha float32, trainUntil Time) *Baseline {
+	if ts.IsEmpty() {
+		return nil
+	}
+	expected := EWMA(ts, alpha)
+	var residuals []float32
+	iter, fIter := ts.Iter(), expected.Iter()
+	for iter.Next() && fIter.Next() {
+		t, v := iter.Value()
+		if t >= trainUntil {
+			break
+		}
+		_, f := fIter.Value()
+		if !IsNaN(v) && !IsNaN(f) {
+			residuals = append(residuals, v-f)
+		}
+	}
+	if len(residuals) == 0 {
+		return nil
+	}
+	deviation := float32(math.Abs(float64(Median(residuals)))) + MAD(residuals)*madScale
+	return &Baseline{Expected: expected, Deviation: expected.WithNewValue(deviation)}
+}
+
+// Score returns the z-scores of the series relative to the baseline.
+// minDeviation protects against false positives on series with very stable baselines, see RobustZScore.
+func (b *Baseline) Score(ts *TimeSeries, minDeviation float32) *TimeSeries {
+	if b == nil || ts.IsEmpty() {
+		return nil
+	}
+	res := New(ts.from, len(ts.data), ts.step)
+	for i, v := range ts.data {
+		t := ts.from.Add(Duration(i) * ts.step)
+		expected, deviation := b.at(t)
+		if IsNaN(v) || IsNaN(expected) {
+			continue
+		}
+		res.data[i] = zScore(v, expected, deviation, minDeviation)
+	}
+	res.last = res.data[len(res.data)-1]
+	return res
+}
+
+// ExpectedAt returns the expected value at the given time.
+func (b *Baseline) ExpectedAt(t Time) float32 {
+	expected, _ := b.at(t)
+	return expected
+}
+
+func (b *Baseline) at(t Time) (float32, float32) {
+	idx := int(t.Sub(b.Expected.from) / b.Expected.step)
+	if idx < 0 || idx >= len(b.Expected.data) {
+		return NaN, NaN
+	}
+	return b.Expected.data[idx], b.Deviation.data[idx]
+}
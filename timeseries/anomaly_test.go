@@ -0,0 +1,54 @@
+package timeseries
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRobustZScore(t *testing.T) {
+	sample := []float32{10, 11, 9, 10, 12, 10, 1000, NaN}
+	assert.Equal(t, float32(10), Median(sample))
+	assert.Equal(t, float32(1), MAD(sample))
+
+	assert.InDelta(t, 0, RobustZScore(sample, 10, 0), 0.001)
+	assert.Greater(t, RobustZScore(sample, 20, 0), float32(5), "the outlier must not inflate the deviation")
+	assert.InDelta(t, 1, RobustZScore(sample, 20, 10), 0.001)
+	assert.True(t, IsNaN(RobustZScore([]float32{NaN}, 1, 0)))
+	assert.True(t, IsInf(RobustZScore([]float32{5, 5, 5}, 6, 0), 1))
+}
+
+func TestEWMA(t *testing.T) {
+	ts := NewWithData(0, 1, []float32{10, NaN, 20, 20})
+	assert.Equal(t, "TimeSeries(0, 4, 1, [. 10 10 15])", EWMA(ts, 0.5).String())
+}
+
+func TestSeasonalBaseline(t *testing.T) {
+	yesterday := NewWithData(0, 1, []float32{10, 100, 10}).Shift(100)
+	twoDaysAgo := NewWithData(100, 1, []float32{12, 110, 11})
+	weekAgo := NewWithData(100, 1, []float32{11, 90, NaN})
+	misaligned := NewWithData(101, 1, []float32{1000, 1000})
+	b := NewSeasonalBaseline(yesterday, twoDaysAgo, weekAgo, misaligned, nil)
+	assert.Equal(t, float32(100), b.ExpectedAt(101))
+	assert.True(t, IsNaN(b.ExpectedAt(103)))
+
+	score := b.Score(NewWithData(100, 1, []float32{11, 100, 40}), 1)
+	assert.InDelta(t, 0, score.data[0], 0.001)
+	assert.InDelta(t, 0, score.data[1], 0.001)
+	assert.Greater(t, score.data[2], float32(20))
+}
+
+func TestEWMABaseline(t *testing.T) {
+	data := make([]float32, 60)
+	for i := range data {
+		data[i] = 100 + float32(math.Sin(float64(i)))*5
+	}
+	data[59] = 300
+	ts := NewWithData(0, 1, data)
+	score := NewEWMABaseline(ts, 0.3, 50).Score(ts, 0)
+	for i := 1; i < 50; i++ {
+		assert.Less(t, float32(math.Abs(float64(score.data[i]))), float32(4), "point %d", i)
+	}
+	assert.Greater(t, score.Last(), float32(10))
+}
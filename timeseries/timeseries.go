@@ -253,6 +253,63 @@ func FillSum(ts *TimeSeries, from Time, step Duration, data []float32) bool {
 	return changed
 }
 
+// FillMax keeps the maximum of the source points falling into every destination bucket.
+// Unlike FillAny, it doesn't hide short spikes when the destination step is larger than the source step.
+func FillMax(ts *TimeSeries, from Time, step Duration, data []float32) bool {
+	return fillExtremum(ts, from, step, data, func(v, vv float32) bool { return v > vv })
+}
+
+// FillMin keeps the minimum of the source points falling into every destination bucket.
+// It's used for the series where a short drop matters, e.g., available memory.
+func FillMin(ts *TimeSeries, from Time, step Duration, data []float32) bool {
+	return fillExtremum(ts, from, step, data, func(v, vv float32) bool { return v < vv })
+}
+
+func fillExtremum(ts *TimeSeries, from Time, step Duration, data []float32, better func(v, vv float32) bool) bool {
+	changed := false
+	maxIndex := len(ts.data) - 1
+	tSrc, iSrc := from, 0
+	if ts.from.Sub(tSrc) >= ts.step {
+		tSrc = tSrc.Add(ts.from.Sub(tSrc.Truncate(ts.step)).Truncate(ts.step))
+		if tSrc > ts.from {
+			tSrc = tSrc.Add(-ts.step)
+		}
+		iSrc = int((tSrc - from) / Time(step))
+	}
+	tDst, iDst := ts.from, 0
+	if tSrc > tDst {
+		tDst = tSrc.Truncate(ts.step)
+		if tDst < tSrc {
+			tDst = tDst.Add(ts.step)
+		}
+		iDst = int((tDst - ts.from) / Time(ts.step))
+	}
+	vv := ts.data[iDst]
+	for _, v := range data[iSrc:] {
+		if tSrc > tDst {
+			ts.data[iDst] = vv
+			iDst++
+			if iDst > maxIndex {
+				break
+			}
+			vv = ts.data[iDst]
+			tDst += Time(ts.step)
+		}
+		if !IsNaN(v) {
+			if IsNaN(vv) || better(v, vv) {
+				vv = v
+			}
+			changed = true
+		}
+		tSrc += Time(step)
+	}
+	if iDst <= maxIndex {
+		ts.data[iDst] = vv
+	}
+	ts.last = ts.data[maxIndex]
+	return changed
+}
+
 func (ts *TimeSeries) Iter() *Iterator {
 	if ts.IsEmpty() {
 		return &Iterator{data: nil}
@@ -375,6 +432,16 @@ func (ts *TimeSeries) NewWithData(data []float32) *TimeSeries {
 	return NewWithData(ts.from, ts.step, data)
 }
 
+// Shift returns a copy of the series moved forward in time by d.
+func (ts *TimeSeries) Shift(d Duration) *TimeSeries {
+	if ts.IsEmpty() {
+		return nil
+	}
+	data := make([]float32, ts.Len())
+	copy(data, ts.data)
+	return NewWithData(ts.from.Add(d), ts.step, data)
+}
+
 func (ts *TimeSeries) LastNotNull() (Time, float32) {
 	if ts.IsEmpty() {
 		return 0, NaN
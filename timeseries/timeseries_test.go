@@ -204,3 +204,20 @@ func TestIterFrom(t *testing.T) {
 	iter = ts.IterFrom(100)
 	assert.False(t, iter.Next())
 }
+
+func TestFillMaxMin(t *testing.T) {
+	data := []float32{1, 5, 3, NaN, 2, 8, 7, 6}
+	var ts *TimeSeries
+
+	ts = New(30, 4, 30*Second)
+	FillMax(ts, 15, 15*Second, data)
+	assert.Equal(t, "TimeSeries(30, 4, 30, [5 3 8 7])", ts.String())
+	FillMax(ts, 30, 30*Second, []float32{9, 1, 1, 1})
+	assert.Equal(t, "TimeSeries(30, 4, 30, [9 3 8 7])", ts.String())
+
+	ts = New(30, 4, 30*Second)
+	FillMin(ts, 15, 15*Second, data)
+	assert.Equal(t, "TimeSeries(30, 4, 30, [1 3 2 6])", ts.String())
+	FillMin(ts, 30, 30*Second, []float32{9, 1, 1, 1})
+	assert.Equal(t, "TimeSeries(30, 4, 30, [1 1 1 1])", ts.String())
+}
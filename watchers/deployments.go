@@ -156,6 +156,54 @@ func (w *Deployments) sendNotifications(project *db.Project, world *model.World)
 					needSave = true
 				}
 			}
+			if email := integrations.Email; email != nil && email.Deployments && notificationSettings.Email != nil && notificationSettings.Email.Enabled && d.Notifications.Email.State < ds.State {
+				client := notifications.NewEmail(email, notificationSettings.Email.To)
+				ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+				err := client.SendDeployment(ctx, project, ds)
+				cancel()
+				if err != nil {
+					klog.Errorln(err)
+				} else {
+					d.Notifications.Email.State = ds.State
+					needSave = true
+				}
+			}
+			if mattermost := integrations.Mattermost; mattermost != nil && mattermost.Deployments && notificationSettings.Mattermost != nil && notificationSettings.Mattermost.Enabled && d.Notifications.Mattermost.State < ds.State {
+				client := notifications.NewMattermost(mattermost.Url, mattermost.Token, cmp.Or(notificationSettings.Mattermost.ChannelId, mattermost.DefaultChannelId))
+				ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+				err := client.SendDeployment(ctx, project, ds)
+				cancel()
+				if err != nil {
+					klog.Errorln(err)
+				} else {
+					d.Notifications.Mattermost.State = ds.State
+					needSave = true
+				}
+			}
+			if googleChat := integrations.GoogleChat; googleChat != nil && googleChat.Deployments && notificationSettings.GoogleChat != nil && notificationSettings.GoogleChat.Enabled && d.Notifications.GoogleChat.State < ds.State {
+				client := notifications.NewGoogleChat(googleChat.WebhookUrl)
+				ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+				err := client.SendDeployment(ctx, project, ds)
+				cancel()
+				if err != nil {
+					klog.Errorln(err)
+				} else {
+					d.Notifications.GoogleChat.State = ds.State
+					needSave = true
+				}
+			}
+			if discord := integrations.Discord; discord != nil && discord.Deployments && notificationSettings.Discord != nil && notificationSettings.Discord.Enabled && d.Notifications.Discord.State < ds.State {
+				client := notifications.NewDiscord(discord.WebhookUrl)
+				ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+				err := client.SendDeployment(ctx, project, ds)
+				cancel()
+				if err != nil {
+					klog.Errorln(err)
+				} else {
+					d.Notifications.Discord.State = ds.State
+					needSave = true
+				}
+			}
 			if !needSave {
 				continue
 			}
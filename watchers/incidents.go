@@ -3,9 +3,11 @@ package watchers
 import (
 	"context"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/coroot/coroot/auditor"
+	"github.com/coroot/coroot/config"
 	"github.com/coroot/coroot/db"
 	"github.com/coroot/coroot/keep"
 	"github.com/coroot/coroot/model"
@@ -20,23 +22,67 @@ type Incidents struct {
 	rca        IncidentRCA
 	notifier   *notifications.IncidentNotifier
 	keepClient *keep.Client
+	cfg        config.Incidents
+	observer   WorldObserver
+}
+
+// WorldObserver receives the audited world of each project checked by this replica.
+// The world is shared with the other watchers, so the observer must copy what it needs instead of retaining the world.
+type WorldObserver interface {
+	Observe(projectId db.ProjectId, world *model.World)
+	// Retain drops the state of the projects that aren't checked by this replica anymore.
+	Retain(keep func(projectId db.ProjectId) bool)
 }
 
 type IncidentRCA func(ctx context.Context, project *db.Project, world *model.World, incident *model.ApplicationIncident)
 
-func NewIncidents(db *db.DB, rca IncidentRCA, keepClient *keep.Client) *Incidents {
-	return &Incidents{db: db, notifier: notifications.NewIncidentNotifier(db), rca: rca, keepClient: keepClient}
+func NewIncidents(db *db.DB, rca IncidentRCA, keepClient *keep.Client, cfg config.Incidents) *Incidents {
+	return &Incidents{
+		db:         db,
+		notifier:   notifications.NewIncidentNotifier(db),
+		rca:        rca,
+		keepClient: keepClient,
+		cfg:        cfg,
+	}
+}
+
+func (w *Incidents) SetWorldObserver(observer WorldObserver) {
+	w.observer = observer
+}
+
+func (w *Incidents) retain(keep func(projectId db.ProjectId) bool) {
+	if w != nil && w.observer != nil {
+		w.observer.Retain(keep)
+	}
 }
 
 func (w *Incidents) Check(project *db.Project, world *model.World) {
 	start := time.Now()
 
 	auditor.Audit(world, project, nil, false, nil)
+	if w.observer != nil {
+		w.observer.Observe(project.Id, world)
+	}
 
 	var apps int
 
 	now := timeseries.Now()
 
+	violations, err := w.db.GetIncidentViolations(project.Id)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	currentViolations := map[model.ApplicationId]timeseries.Time{}
+	defer func() {
+		if maps.Equal(violations, currentViolations) {
+			return
+		}
+		if err := w.db.SaveIncidentViolations(project.Id, currentViolations); err != nil {
+			klog.Errorln(err)
+		}
+	}()
+
 	for _, app := range world.Applications {
 		var (
 			aBadF, aTotalF sumFromFunc
@@ -74,25 +120,52 @@ func (w *Incidents) Check(project *db.Project, world *model.World) {
 				status = br.Severity
 			}
 		}
+		if s := jobsStatus(app); s > status {
+			status = s
+		}
 		if status == model.UNKNOWN {
 			continue
 		}
 		apps++
 
+		since := trackViolation(violations, currentViolations, app.Id, status, now)
 		incident, err := w.db.GetLastOpenIncident(project.Id, app.Id)
 		if err != nil {
 			klog.Errorln(err)
 			continue
 		}
-		needNotify := false
+		needNotify, silent := false, false
 		switch {
 		case incident == nil && status <= model.OK:
 			continue
+		case incident == nil && now.Sub(since) < w.cfg.MinDuration:
+			klog.Infof("%s: %s has been violating its SLOs for %s, waiting for %s before opening an incident", project.Id, app.Id, now.Sub(since), w.cfg.MinDuration)
+			continue
 		case incident == nil:
+			incident, err = w.reopenRecentlyResolved(project.Id, app.Id, now)
+			if err != nil {
+				klog.Errorln(err)
+				continue
+			}
+			if incident != nil {
+				incident.Severity = status
+				incident.ResolvedAt = 0
+				incident.Details.AvailabilityBurnRates = details.AvailabilityBurnRates
+				incident.Details.LatencyBurnRates = details.LatencyBurnRates
+				incident.Details.AvailabilityImpact.AffectedRequestPercentage = calcImpact(incident.OpenedAt, aBadF, aTotalF)
+				incident.Details.LatencyImpact.AffectedRequestPercentage = calcImpact(incident.OpenedAt, lBadF, lTotalF)
+				incident.Details.ReopenCount++
+				if silent, err = w.reopen(project.Id, incident); err != nil {
+					klog.Errorln(err)
+					continue
+				}
+				needNotify = true
+				break
+			}
 			incident = &model.ApplicationIncident{
 				ApplicationId: app.Id,
 				Key:           utils.NanoId(8),
-				OpenedAt:      now,
+				OpenedAt:      since,
 				Severity:      status,
 				Details:       details,
 			}
@@ -124,17 +197,25 @@ func (w *Incidents) Check(project *db.Project, world *model.World) {
 				}
 			}
 		}
-		if w.rca != nil {
+		// the RCA explains SLO violations, so it's skipped for the incidents of the jobs having no SLIs
+		if w.rca != nil && (len(details.AvailabilityBurnRates) > 0 || len(details.LatencyBurnRates) > 0) {
 			w.rca(context.TODO(), project, world, incident)
 		}
 		if needNotify {
-			w.notifier.Enqueue(project, app, incident, now)
+			if !silent {
+				notifyAt := now
+				// the resolve is held back for the cooldown, so that the receivers aren't notified of a flapping violation
+				if incident.Resolved() {
+					notifyAt = now.Add(w.cfg.ReopenCooldown)
+				}
+				w.notifier.Enqueue(project, app, incident, notifyAt)
+			}
 			if w.keepClient != nil {
                 alert := keep.Alert{
 					ID:          incident.Key,
 					Name:        fmt.Sprintf("Incident for %s", app.Id.Name),
 					Description: fmt.Sprintf("Incident detected for application %s", app.Id.Name),
-					Severity:    string(incident.Severity),
+					Severity:    incident.Severity.String(),
 					Status:      "firing",
 					Labels: map[string]string{
 						"project":     string(project.Id),
@@ -159,8 +240,83 @@ func (w *Incidents) Check(project *db.Project, world *model.World) {
 	klog.Infof("%s: checked %d apps in %s", project.Id, apps, time.Since(start).Truncate(time.Millisecond))
 }
 
+// jobsStatus returns the incident status of a periodic job, which usually has no SLOs:
+// only the CRITICAL failed runs and missed schedules open an incident, while a slower run doesn't.
+func jobsStatus(app *model.Application) model.Status {
+	status := model.UNKNOWN
+	for _, r := range app.Reports {
+		if r.Name != model.AuditReportJobs {
+			continue
+		}
+		for _, ch := range r.Checks {
+			switch ch.Id {
+			case model.Checks.JobFailedRuns.Id, model.Checks.CronJobMissedSchedules.Id:
+				s := model.OK
+				if ch.Status == model.CRITICAL {
+					s = model.CRITICAL
+				}
+				if s > status {
+					status = s
+				}
+			}
+		}
+	}
+	return status
+}
+
+// trackViolation returns the time the application started violating its SLOs according to the previous violations
+// and records it in the current ones. A non-violating status resets the tracking.
+// The applications missing from the current violations (resolved or removed) are pruned when they are saved.
+func trackViolation(previous, current map[model.ApplicationId]timeseries.Time, appId model.ApplicationId, status model.Status, now timeseries.Time) timeseries.Time {
+	if status <= model.OK {
+		return now
+	}
+	since, ok := previous[appId]
+	if !ok {
+		since = now
+	}
+	current[appId] = since
+	return since
+}
+
+// reopenRecentlyResolved returns the last incident of the application if it was resolved within the cooldown period.
+func (w *Incidents) reopenRecentlyResolved(projectId db.ProjectId, appId model.ApplicationId, now timeseries.Time) (*model.ApplicationIncident, error) {
+	if w.cfg.ReopenCooldown <= 0 {
+		return nil, nil
+	}
+	last, err := w.db.GetLastResolvedIncident(projectId, appId)
+	if err != nil || last == nil {
+		return nil, err
+	}
+	if now.Sub(last.ResolvedAt) > w.cfg.ReopenCooldown {
+		return nil, nil
+	}
+	return last, nil
+}
+
+// reopen reopens the incident resolved within the cooldown. It withdraws the resolve notifications held back for
+// the cooldown and returns true if there were any, as the receivers don't need to be notified of the reopening then.
+func (w *Incidents) reopen(projectId db.ProjectId, incident *model.ApplicationIncident) (bool, error) {
+	if err := w.db.ReopenIncident(projectId, incident.ApplicationId, incident); err != nil {
+		return false, err
+	}
+	withdrawn, err := w.db.DeleteNotSentIncidentNotifications(projectId, incident.ApplicationId, incident.Key, model.OK)
+	if err != nil {
+		return false, err
+	}
+	return withdrawn > 0, nil
+}
+
 type sumFromFunc func(from timeseries.Time) float32
 
+// BurnRates calculates the error budget burn rates of the application's availability and latency SLOs
+// over the alerting windows, as the incident watcher does.
+func BurnRates(ctx timeseries.Context, app *model.Application) (availabilityBurnRates, latencyBurnRates []model.BurnRate) {
+	availabilityBurnRates, _, _ = availability(ctx, app)
+	latencyBurnRates, _, _ = latency(ctx, app)
+	return availabilityBurnRates, latencyBurnRates
+}
+
 func availability(ctx timeseries.Context, app *model.Application) ([]model.BurnRate, sumFromFunc, sumFromFunc) {
 	if len(app.AvailabilitySLIs) == 0 {
 		return nil, nil, nil
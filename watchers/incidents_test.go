@@ -0,0 +1,135 @@
+package watchers
+
+import (
+	"testing"
+
+	"github.com/coroot/coroot/config"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackViolation(t *testing.T) {
+	catalog := model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog")
+	front := model.NewApplicationId("default", model.ApplicationKindDeployment, "front")
+	removed := model.NewApplicationId("default", model.ApplicationKindDeployment, "removed")
+
+	// each check starts from the violations saved by the previous one
+	saved := map[model.ApplicationId]timeseries.Time{removed: 50}
+	current := map[model.ApplicationId]timeseries.Time{}
+	assert.Equal(t, timeseries.Time(100), trackViolation(saved, current, catalog, model.WARNING, 100))
+	assert.Equal(t, map[model.ApplicationId]timeseries.Time{catalog: 100}, current)
+
+	saved, current = current, map[model.ApplicationId]timeseries.Time{}
+	assert.Equal(t, timeseries.Time(100), trackViolation(saved, current, catalog, model.CRITICAL, 130))
+
+	saved, current = current, map[model.ApplicationId]timeseries.Time{}
+	assert.Equal(t, timeseries.Time(160), trackViolation(saved, current, catalog, model.OK, 160))
+	assert.Empty(t, current)
+
+	saved, current = current, map[model.ApplicationId]timeseries.Time{}
+	assert.Equal(t, timeseries.Time(190), trackViolation(saved, current, catalog, model.CRITICAL, 190))
+
+	saved, current = current, map[model.ApplicationId]timeseries.Time{}
+	assert.Equal(t, timeseries.Time(200), trackViolation(saved, current, front, model.CRITICAL, 200))
+	assert.Equal(t, timeseries.Time(190), trackViolation(saved, current, catalog, model.CRITICAL, 220))
+	assert.Equal(t, map[model.ApplicationId]timeseries.Time{catalog: 190, front: 200}, current)
+}
+
+func TestJobsStatus(t *testing.T) {
+	app := model.NewApplication(model.NewApplicationId("default", model.ApplicationKindCronJob, "backup"))
+	assert.Equal(t, model.UNKNOWN, jobsStatus(app))
+
+	r := model.NewAuditReport(app, timeseries.Context{}, nil, model.AuditReportJobs, false)
+	failedRuns := r.CreateCheck(model.Checks.JobFailedRuns)
+	duration := r.CreateCheck(model.Checks.JobDurationRegression)
+	missedSchedules := r.CreateCheck(model.Checks.CronJobMissedSchedules)
+	app.Reports = append(app.Reports, r)
+	calc := func() {
+		for _, ch := range r.Checks {
+			ch.Calc()
+		}
+	}
+	calc()
+	assert.Equal(t, model.OK, jobsStatus(app))
+
+	// a slower run doesn't open an incident
+	duration.SetValue(300)
+	calc()
+	assert.Equal(t, model.WARNING, duration.Status)
+	assert.Equal(t, model.OK, jobsStatus(app))
+
+	failedRuns.Inc(1)
+	calc()
+	assert.Equal(t, model.CRITICAL, jobsStatus(app))
+
+	failedRuns.ResetCounter()
+	failedRuns.SetStatus(model.OK, "")
+	missedSchedules.SetValue(600)
+	calc()
+	assert.Equal(t, model.CRITICAL, jobsStatus(app))
+}
+
+func TestReopenWithinCooldown(t *testing.T) {
+	database, err := db.NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate())
+	project := &db.Project{Name: "test"}
+	require.NoError(t, database.SaveProject(project))
+	w := &Incidents{db: database, cfg: config.Incidents{ReopenCooldown: 10 * timeseries.Minute}}
+
+	appId := model.NewApplicationId("default", model.ApplicationKindDeployment, "catalog")
+	destination := db.IncidentNotificationDestination{IntegrationType: db.IntegrationTypeSlack, SlackChannel: "ops"}
+	resolve := func(resolvedAt timeseries.Time) db.IncidentNotification {
+		incident, err := database.GetLastOpenIncident(project.Id, appId)
+		require.NoError(t, err)
+		require.NotNil(t, incident)
+		incident.ResolvedAt = resolvedAt
+		incident.Severity = model.OK
+		require.NoError(t, database.ResolveIncident(project.Id, appId, incident))
+		n := db.IncidentNotification{
+			ProjectId:     project.Id,
+			ApplicationId: appId,
+			IncidentKey:   incident.Key,
+			Status:        model.OK,
+			Destination:   destination,
+			Timestamp:     resolvedAt.Add(w.cfg.ReopenCooldown),
+		}
+		database.PutIncidentNotification(n)
+		return n
+	}
+	require.NoError(t, database.CreateIncident(project.Id, appId, &model.ApplicationIncident{ApplicationId: appId, Key: "i1", OpenedAt: 100, Severity: model.CRITICAL}))
+
+	resolve(400)
+	last, err := w.reopenRecentlyResolved(project.Id, appId, 1100)
+	require.NoError(t, err)
+	assert.Nil(t, last, "the cooldown has passed")
+
+	// the violation is back within the cooldown: the held back resolve is withdrawn and the reopening isn't notified
+	last, err = w.reopenRecentlyResolved(project.Id, appId, 700)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	silent, err := w.reopen(project.Id, last)
+	require.NoError(t, err)
+	assert.True(t, silent)
+	pending, err := database.GetNotSentIncidentNotifications(0)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	open, err := database.GetLastOpenIncident(project.Id, appId)
+	require.NoError(t, err)
+	require.NotNil(t, open)
+	assert.Equal(t, "i1", open.Key)
+
+	// the resolve has already been sent: the receivers are notified of the reopening
+	n := resolve(800)
+	n.SentAt = n.Timestamp
+	require.NoError(t, database.UpdateIncidentNotification(n))
+	last, err = w.reopenRecentlyResolved(project.Id, appId, 900)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+	silent, err = w.reopen(project.Id, last)
+	require.NoError(t, err)
+	assert.False(t, silent)
+}
@@ -0,0 +1,310 @@
+package watchers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/coroot/coroot/cache"
+	cloud_pricing "github.com/coroot/coroot/cloud-pricing"
+	"github.com/coroot/coroot/constructor"
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/notifications"
+	"github.com/coroot/coroot/timeseries"
+	"k8s.io/klog"
+)
+
+const (
+	// sloComplianceDaysPerCheck limits the number of the days computed during an iteration
+	// to catch up gradually after a downtime or when the history is computed for the first time.
+	sloComplianceDaysPerCheck = 3
+)
+
+// SLOCompliance computes and persists the daily SLI data of the applications
+// and sends the periodic SLO reports once the reporting period is over.
+type SLOCompliance struct {
+	db      *db.DB
+	cache   *cache.Cache
+	pricing *cloud_pricing.Manager
+	ch      ClickhouseClientFactory
+
+	checked     map[db.ProjectId]timeseries.Time
+	checkedLock sync.Mutex
+}
+
+func NewSLOCompliance(database *db.DB, cache *cache.Cache, pricing *cloud_pricing.Manager, ch ClickhouseClientFactory) *SLOCompliance {
+	return &SLOCompliance{db: database, cache: cache, pricing: pricing, ch: ch, checked: map[db.ProjectId]timeseries.Time{}}
+}
+
+func (w *SLOCompliance) Check(project *db.Project) {
+	start := time.Now()
+	to, err := w.cacheTo(project)
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	if to.IsZero() {
+		return
+	}
+	// the beginning of the current day, which is the end of the last complete one
+	to = to.Add(timeseries.Second).Truncate(timeseries.Day)
+
+	w.checkedLock.Lock()
+	last, ok := w.checked[project.Id]
+	w.checkedLock.Unlock()
+	if !ok {
+		if last, err = w.db.GetSLOComplianceLastDate(project.Id); err != nil {
+			klog.Errorln(err)
+			return
+		}
+	}
+
+	date := to.Add(-model.SLOComplianceMaxWindow)
+	if !last.IsZero() && last.Add(timeseries.Day).After(date) {
+		date = last.Add(timeseries.Day)
+	}
+	var computed int
+	for ; date.Before(to) && computed < sloComplianceDaysPerCheck; date = date.Add(timeseries.Day) {
+		days, err := w.calcDay(project, date)
+		if err != nil {
+			klog.Errorln("failed to calculate SLO compliance:", err)
+			return
+		}
+		if err = w.db.SaveSLOComplianceDays(project.Id, days); err != nil {
+			klog.Errorln("failed to save SLO compliance:", err)
+			return
+		}
+		w.checkedLock.Lock()
+		w.checked[project.Id] = date
+		w.checkedLock.Unlock()
+		last = date
+		computed++
+	}
+	if computed > 0 {
+		klog.Infof("%s: computed SLO compliance for %d days in %s", project.Id, computed, time.Since(start).Truncate(time.Millisecond))
+	}
+	if last.Add(timeseries.Day).Before(to) {
+		// the reports are sent only when the history is complete
+		return
+	}
+	w.sendReports(project, to)
+}
+
+func (w *SLOCompliance) calcDay(project *db.Project, date timeseries.Time) ([]*model.SLOComplianceDay, error) {
+	world, err := w.loadWorld(project, date, date.Add(timeseries.Day-timeseries.Hour), timeseries.Hour)
+	if err != nil || world == nil {
+		return nil, err
+	}
+	var res []*model.SLOComplianceDay
+	for _, app := range world.Applications {
+		for _, sli := range app.AvailabilitySLIs {
+			d := newSLOComplianceDay(app.Id, model.SLOTypeAvailability, date, sli.Config.ObjectivePercentage)
+			d.fill(sli.TotalRequests, d.Total)
+			d.fill(sli.FailedRequests, d.Bad)
+			if d.hasEvents() {
+				res = append(res, (*model.SLOComplianceDay)(d))
+			}
+			break
+		}
+		for _, sli := range app.LatencySLIs {
+			d := newSLOComplianceDay(app.Id, model.SLOTypeLatency, date, sli.Config.ObjectivePercentage)
+			total, fast := sli.GetTotalAndFast(false)
+			d.fill(total, d.Total)
+			d.fill(timeseries.Sub(total, fast), d.Bad)
+			if d.hasEvents() {
+				res = append(res, (*model.SLOComplianceDay)(d))
+			}
+			break
+		}
+	}
+	return res, nil
+}
+
+type sloComplianceDay model.SLOComplianceDay
+
+func newSLOComplianceDay(appId model.ApplicationId, slo model.SLOType, date timeseries.Time, objective float32) *sloComplianceDay {
+	return &sloComplianceDay{
+		ApplicationId: appId,
+		SLO:           slo,
+		Date:          date,
+		Objective:     objective,
+		Total:         make([]float32, 24),
+		Bad:           make([]float32, 24),
+	}
+}
+
+// fill converts the hourly averages of the per-second rate into the numbers of events per hour.
+func (d *sloComplianceDay) fill(rate *timeseries.TimeSeries, dst []float32) {
+	if rate.IsEmpty() {
+		return
+	}
+	iter := rate.Iter()
+	for iter.Next() {
+		t, v := iter.Value()
+		i := int(t.Sub(d.Date) / timeseries.Hour)
+		if i < 0 || i >= len(dst) || timeseries.IsNaN(v) || v < 0 {
+			continue
+		}
+		dst[i] = v * float32(timeseries.Hour)
+	}
+}
+
+func (d *sloComplianceDay) hasEvents() bool {
+	for _, v := range d.Total {
+		if v > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *SLOCompliance) cacheTo(project *db.Project) (timeseries.Time, error) {
+	if !project.Multicluster() {
+		return w.cache.GetCacheClient(project.Id).GetTo()
+	}
+	members, err := w.db.GetClusters(project)
+	if err != nil {
+		return 0, err
+	}
+	var to timeseries.Time
+	for _, member := range members {
+		cacheTo, err := w.cache.GetCacheClient(member.Id).GetTo()
+		if err != nil {
+			return 0, err
+		}
+		if !cacheTo.IsZero() && (to.IsZero() || cacheTo.Before(to)) {
+			to = cacheTo
+		}
+	}
+	return to, nil
+}
+
+func (w *SLOCompliance) loadWorld(project *db.Project, from, to timeseries.Time, step timeseries.Duration) (*model.World, error) {
+	if !project.Multicluster() {
+		ctr := constructor.New(w.db, project, w.cache.GetCacheClient(project.Id), w.pricing)
+		if ch := newClickhouseClient(w.ch, project); ch != nil {
+			defer ch.Close()
+			ctr.WithTraces(ch)
+		}
+		return ctr.LoadWorld(context.TODO(), from, to, step, nil)
+	}
+	members, err := w.db.GetClusters(project)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []constructor.Cluster
+	for _, member := range members {
+		cl := constructor.Cluster{Project: member, Cache: w.cache.GetCacheClient(member.Id)}
+		if ch := newClickhouseClient(w.ch, member); ch != nil {
+			defer ch.Close()
+			cl.Traces = ch
+		}
+		clusters = append(clusters, cl)
+	}
+	if len(clusters) == 0 {
+		return nil, nil
+	}
+	ctr := constructor.New(w.db, project, nil, w.pricing)
+	return ctr.LoadMulticlusterWorld(context.TODO(), clusters, from, to, step, nil)
+}
+
+// sloReportSettingName is the name of the setting holding the end of the last period whose report has been delivered to the destination.
+func sloReportSettingName(projectId db.ProjectId, category model.ApplicationCategory, destination db.IntegrationType) string {
+	return fmt.Sprintf("slo_report:%s:%s:%s", projectId, category, destination)
+}
+
+func (w *SLOCompliance) sendReports(project *db.Project, now timeseries.Time) {
+	integrations := project.Settings.Integrations
+	for name, category := range project.GetApplicationCategories() {
+		settings := category.NotificationSettings.SLOReports
+		if !settings.Enabled {
+			continue
+		}
+		to := settings.Period.Start(now)
+		clients := w.pendingSLOReportClients(project.Id, name, to, sloReportClients(integrations, settings))
+		if len(clients) == 0 {
+			continue
+		}
+		window := settings.Period.Window()
+		items, err := w.db.GetSLOCompliance(project, model.ApplicationId{}, name, to, window)
+		if err != nil {
+			klog.Errorln(err)
+			continue
+		}
+		w.deliverSLOReport(&notifications.SLOReport{
+			ProjectId:   project.Id,
+			ProjectName: project.Name,
+			Category:    name,
+			BaseUrl:     integrations.BaseUrl,
+			From:        to.Add(-window),
+			To:          to,
+			Items:       items,
+		}, clients)
+	}
+}
+
+// pendingSLOReportClients returns the clients of the destinations that haven't received the report of the period ending at the given time.
+func (w *SLOCompliance) pendingSLOReportClients(projectId db.ProjectId, category model.ApplicationCategory, to timeseries.Time, clients map[db.IntegrationType]notifications.SLOReportClient) map[db.IntegrationType]notifications.SLOReportClient {
+	res := map[db.IntegrationType]notifications.SLOReportClient{}
+	for destination, client := range clients {
+		var lastSent timeseries.Time
+		if err := w.db.GetSetting(sloReportSettingName(projectId, category, destination), &lastSent); err != nil && !errors.Is(err, db.ErrNotFound) {
+			klog.Errorln(err)
+			continue
+		}
+		if lastSent.Before(to) {
+			res[destination] = client
+		}
+	}
+	return res
+}
+
+// deliverSLOReport sends the report and marks its period as sent for each destination that has accepted it,
+// so that the failed deliveries are retried on the next iteration. Empty reports are not sent.
+func (w *SLOCompliance) deliverSLOReport(report *notifications.SLOReport, clients map[db.IntegrationType]notifications.SLOReportClient) {
+	for destination, client := range clients {
+		if len(report.Items) > 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			err := client.SendSLOReport(ctx, report)
+			cancel()
+			if err != nil {
+				klog.Errorf("failed to send SLO report to %s: %s", destination, err)
+				continue
+			}
+			klog.Infof("%s: sent SLO report of %s (%d SLOs) to %s", report.ProjectId, report.Category, len(report.Items), destination)
+		}
+		if err := w.db.SetSetting(sloReportSettingName(report.ProjectId, report.Category, destination), report.To); err != nil {
+			klog.Errorln(err)
+		}
+	}
+}
+
+func sloReportClients(integrations db.Integrations, settings db.ApplicationCategorySLOReportNotificationSettings) map[db.IntegrationType]notifications.SLOReportClient {
+	res := map[db.IntegrationType]notifications.SLOReportClient{}
+	if slack := integrations.Slack; slack != nil && settings.Slack != nil && settings.Slack.Enabled {
+		res[db.IntegrationTypeSlack] = notifications.NewSlack(slack.Token, cmp.Or(settings.Slack.Channel, slack.DefaultChannel))
+	}
+	if teams := integrations.Teams; teams != nil && settings.Teams != nil && settings.Teams.Enabled {
+		res[db.IntegrationTypeTeams] = notifications.NewTeams(teams.WebhookUrl)
+	}
+	if webhook := integrations.Webhook; webhook != nil && settings.Webhook != nil && settings.Webhook.Enabled {
+		res[db.IntegrationTypeWebhook] = notifications.NewWebhook(webhook)
+	}
+	if email := integrations.Email; email != nil && settings.Email != nil && settings.Email.Enabled {
+		res[db.IntegrationTypeEmail] = notifications.NewEmail(email, settings.Email.To)
+	}
+	if mattermost := integrations.Mattermost; mattermost != nil && settings.Mattermost != nil && settings.Mattermost.Enabled {
+		res[db.IntegrationTypeMattermost] = notifications.NewMattermost(mattermost.Url, mattermost.Token, cmp.Or(settings.Mattermost.ChannelId, mattermost.DefaultChannelId))
+	}
+	if googleChat := integrations.GoogleChat; googleChat != nil && settings.GoogleChat != nil && settings.GoogleChat.Enabled {
+		res[db.IntegrationTypeGoogleChat] = notifications.NewGoogleChat(googleChat.WebhookUrl)
+	}
+	if discord := integrations.Discord; discord != nil && settings.Discord != nil && settings.Discord.Enabled {
+		res[db.IntegrationTypeDiscord] = notifications.NewDiscord(discord.WebhookUrl)
+	}
+	return res
+}
@@ -0,0 +1,54 @@
+package watchers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/notifications"
+	"github.com/coroot/coroot/timeseries"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testSLOReportClient struct {
+	err  error
+	sent int
+}
+
+func (c *testSLOReportClient) SendSLOReport(ctx context.Context, report *notifications.SLOReport) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.sent++
+	return nil
+}
+
+func TestDeliverSLOReport(t *testing.T) {
+	database, err := db.NewSqlite(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, database.Migrate())
+	w := &SLOCompliance{db: database}
+
+	slack := &testSLOReportClient{}
+	email := &testSLOReportClient{err: errors.New("smtp: connection refused")}
+	clients := map[db.IntegrationType]notifications.SLOReportClient{db.IntegrationTypeSlack: slack, db.IntegrationTypeEmail: email}
+	to := timeseries.Time(0).Add(7 * timeseries.Day)
+	report := &notifications.SLOReport{ProjectId: "p1", Category: model.ApplicationCategoryApplication, To: to, Items: []*model.SLOCompliance{{}}}
+
+	pending := w.pendingSLOReportClients(report.ProjectId, report.Category, to, clients)
+	require.Len(t, pending, 2)
+	w.deliverSLOReport(report, pending)
+	assert.Equal(t, 1, slack.sent)
+
+	// the report of the period is retried for the failed destination only
+	email.err = nil
+	pending = w.pendingSLOReportClients(report.ProjectId, report.Category, to, clients)
+	assert.Equal(t, map[db.IntegrationType]notifications.SLOReportClient{db.IntegrationTypeEmail: email}, pending)
+	w.deliverSLOReport(report, pending)
+	assert.Equal(t, 1, slack.sent)
+	assert.Equal(t, 1, email.sent)
+	assert.Empty(t, w.pendingSLOReportClients(report.ProjectId, report.Category, to, clients))
+}
@@ -2,6 +2,7 @@ package watchers
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
@@ -11,19 +12,21 @@ import (
 	"github.com/coroot/coroot/config"
 	"github.com/coroot/coroot/constructor"
 	"github.com/coroot/coroot/db"
+	"github.com/coroot/coroot/model"
+	"github.com/coroot/coroot/sharding"
 	"github.com/coroot/coroot/timeseries"
 	"k8s.io/klog"
 )
 
-func Start(database *db.DB, cache *cache.Cache, pricing *pricing.Manager, incidents *Incidents, checkDeployments bool, globalClickHouse *db.IntegrationClickhouse, spaceManagerCfg config.ClickHouseSpaceManager) {
+// ClickhouseClientFactory returns the ClickHouse client of the project, or nil if the project has no ClickHouse.
+type ClickhouseClientFactory func(project *db.Project) (*clickhouse.Client, error)
+
+func Start(database *db.DB, cache *cache.Cache, pricing *pricing.Manager, incidents *Incidents, checkDeployments bool, globalClickHouse *db.IntegrationClickhouse, spaceManagerCfg config.ClickHouseSpaceManager, shards *sharding.Sharding, clickhouseClient ClickhouseClientFactory) {
 	var deployments *Deployments
 	if checkDeployments {
 		deployments = NewDeployments(database, pricing)
 	}
-
-	if incidents == nil && deployments == nil {
-		return
-	}
+	sloCompliance := NewSLOCompliance(database, cache, pricing, clickhouseClient)
 
 	projectChan := make(chan db.ProjectId, 1000)
 
@@ -31,20 +34,24 @@ func Start(database *db.DB, cache *cache.Cache, pricing *pricing.Manager, incide
 	pendingLock := sync.Mutex{}
 	lastSpaceManagerRun := time.Time{}
 
+	enqueue := func(projectId db.ProjectId) {
+		pendingLock.Lock()
+		defer pendingLock.Unlock()
+		if !pending[projectId] {
+			pending[projectId] = true
+			select {
+			case projectChan <- projectId:
+			default:
+				// Channel full, skip this update
+				pending[projectId] = false
+			}
+		}
+	}
+
 	// Fast consumer goroutine - just receives and deduplicates
 	go func() {
 		for projectId := range cache.Updates() {
-			pendingLock.Lock()
-			if !pending[projectId] {
-				pending[projectId] = true
-				select {
-				case projectChan <- projectId:
-				default:
-					// Channel full, skip this update
-					pending[projectId] = false
-				}
-			}
-			pendingLock.Unlock()
+			enqueue(projectId)
 		}
 		close(projectChan)
 	}()
@@ -56,50 +63,60 @@ func Start(database *db.DB, cache *cache.Cache, pricing *pricing.Manager, incide
 			delete(pending, projectId)
 			pendingLock.Unlock()
 
-			if !database.GetPrimaryLock(context.TODO()) {
+			if shards != nil {
+				if !shards.IsOwner(projectId) {
+					klog.Infoln("the project is owned by another replica: skipping", projectId)
+					incidents.retain(shards.IsOwner)
+					continue
+				}
+			} else if !database.GetPrimaryLock(context.TODO()) {
 				klog.Infoln("not the primary replica: skipping")
+				incidents.retain(func(db.ProjectId) bool { return false })
 				continue
 			}
 
-			handleProjectUpdate(database, cache, pricing, incidents, deployments, projectId)
+			handleProjectUpdate(database, cache, pricing, clickhouseClient, incidents, deployments, sloCompliance, projectId)
+
+			// multi-cluster projects have no cache of their own, so they are checked once any of their clusters is updated
+			if ids, err := database.GetMulticlusterProjects(projectId); err != nil {
+				klog.Errorln(err)
+			} else {
+				for _, id := range ids {
+					enqueue(id)
+				}
+			}
 
 			if time.Since(lastSpaceManagerRun) >= time.Hour {
 				lastSpaceManagerRun = time.Now()
-				runSpaceManagerOnce(spaceManagerCfg, database, globalClickHouse)
+				runSpaceManagerOnce(spaceManagerCfg, database, globalClickHouse, shards)
+				forgetRemovedProjects(database, incidents, shards)
 			}
 		}
 	}()
 }
 
-func handleProjectUpdate(database *db.DB, cache *cache.Cache, pricing *pricing.Manager, incidents *Incidents, deployments *Deployments, projectId db.ProjectId) {
+func handleProjectUpdate(database *db.DB, cache *cache.Cache, pricing *pricing.Manager, clickhouseClient ClickhouseClientFactory, incidents *Incidents, deployments *Deployments, sloCompliance *SLOCompliance, projectId db.ProjectId) {
 	start := time.Now()
 	project, err := database.GetProject(projectId)
 	if err != nil {
 		klog.Errorln(err)
+		if errors.Is(err, db.ErrNotFound) {
+			incidents.retain(func(id db.ProjectId) bool { return id != projectId })
+		}
 		return
 	}
 
-	cacheClient := cache.GetCacheClient(project.Id)
-	cacheTo, err := cacheClient.GetTo()
-	if err != nil {
-		klog.Errorln(err)
-		return
-	}
-	if cacheTo.IsZero() {
-		return
+	var world *model.World
+	if project.Multicluster() {
+		world, err = loadMulticlusterWorld(database, cache, pricing, clickhouseClient, project)
+	} else {
+		world, err = loadWorld(database, cache, pricing, clickhouseClient, project)
 	}
-	to := cacheTo
-	from := to.Add(-timeseries.Hour)
-	step, err := cacheClient.GetStep(from, to)
 	if err != nil {
-		klog.Errorln(err)
+		klog.Errorln("failed to load world:", err)
 		return
 	}
-	cacheClient.GetStatus()
-	ctr := constructor.New(database, project, cacheClient, pricing)
-	world, err := ctr.LoadWorld(context.TODO(), from, to, step, nil)
-	if err != nil {
-		klog.Errorln("failed to load world:", err)
+	if world == nil {
 		return
 	}
 
@@ -118,11 +135,111 @@ func handleProjectUpdate(database *db.DB, cache *cache.Cache, pricing *pricing.M
 			deployments.Check(project, world)
 		}()
 	}
+	if sloCompliance != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sloCompliance.Check(project)
+		}()
+	}
 	wg.Wait()
 	klog.Infof("%s: iteration done in %s", project.Id, time.Since(start).Truncate(time.Millisecond))
 }
 
-func runSpaceManagerOnce(cfg config.ClickHouseSpaceManager, database *db.DB, globalClickHouse *db.IntegrationClickhouse) {
+func loadWorld(database *db.DB, cache *cache.Cache, pricing *pricing.Manager, clickhouseClient ClickhouseClientFactory, project *db.Project) (*model.World, error) {
+	cacheClient := cache.GetCacheClient(project.Id)
+	cacheTo, err := cacheClient.GetTo()
+	if err != nil {
+		return nil, err
+	}
+	if cacheTo.IsZero() {
+		return nil, nil
+	}
+	to := cacheTo
+	from := to.Add(-timeseries.Hour)
+	step, err := cacheClient.GetStep(from, to)
+	if err != nil {
+		return nil, err
+	}
+	cacheClient.GetStatus()
+	// the world is audited by the incident watcher, so the anomaly check needs the history
+	ctr := constructor.New(database, project, cacheClient, pricing, constructor.OptionLoadAnomalyHistory)
+	if ch := newClickhouseClient(clickhouseClient, project); ch != nil {
+		defer ch.Close()
+		ctr.WithTraces(ch)
+	}
+	return ctr.LoadWorld(context.TODO(), from, to, step, nil)
+}
+
+func newClickhouseClient(factory ClickhouseClientFactory, project *db.Project) *clickhouse.Client {
+	if factory == nil {
+		return nil
+	}
+	ch, err := factory(project)
+	if err != nil {
+		klog.Warningln("failed to get clickhouse client:", err)
+		return nil
+	}
+	return ch
+}
+
+// loadMulticlusterWorld loads the last hour of the clusters up to the cluster whose cache lags behind the most.
+func loadMulticlusterWorld(database *db.DB, cache *cache.Cache, pricing *pricing.Manager, clickhouseClient ClickhouseClientFactory, project *db.Project) (*model.World, error) {
+	members, err := database.GetClusters(project)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []constructor.Cluster
+	var to timeseries.Time
+	for _, member := range members {
+		cacheClient := cache.GetCacheClient(member.Id)
+		cacheTo, err := cacheClient.GetTo()
+		if err != nil {
+			return nil, err
+		}
+		if cacheTo.IsZero() {
+			continue
+		}
+		if to.IsZero() || cacheTo.Before(to) {
+			to = cacheTo
+		}
+		cl := constructor.Cluster{Project: member, Cache: cacheClient}
+		if ch := newClickhouseClient(clickhouseClient, member); ch != nil {
+			defer ch.Close()
+			cl.Traces = ch
+		}
+		clusters = append(clusters, cl)
+	}
+	if len(clusters) == 0 {
+		return nil, nil
+	}
+	from := to.Add(-timeseries.Hour)
+	var step timeseries.Duration
+	for _, cl := range clusters {
+		s, err := cl.Cache.GetStep(from, to)
+		if err != nil {
+			return nil, err
+		}
+		step = max(step, s)
+	}
+	ctr := constructor.New(database, project, nil, pricing, constructor.OptionLoadAnomalyHistory)
+	return ctr.LoadMulticlusterWorld(context.TODO(), clusters, from, to, step, nil)
+}
+
+// forgetRemovedProjects drops the state kept for the projects that have been deleted or moved to another replica.
+func forgetRemovedProjects(database *db.DB, incidents *Incidents, shards *sharding.Sharding) {
+	names, err := database.GetProjectNames()
+	if err != nil {
+		klog.Errorln(err)
+		return
+	}
+	incidents.retain(func(projectId db.ProjectId) bool {
+		_, ok := names[projectId]
+		return ok && shards.IsOwner(projectId)
+	})
+}
+
+func runSpaceManagerOnce(cfg config.ClickHouseSpaceManager, database *db.DB, globalClickHouse *db.IntegrationClickhouse, shards *sharding.Sharding) {
 	if !cfg.Enabled {
 		klog.Infof("clickhouse space manager disabled")
 		return
@@ -132,6 +249,13 @@ func runSpaceManagerOnce(cfg config.ClickHouseSpaceManager, database *db.DB, glo
 		klog.Errorf("clickhouse space manager: failed to get projects: %v", err)
 		return
 	}
+	owned := projects[:0]
+	for _, p := range projects {
+		if shards.IsOwner(p.Id) {
+			owned = append(owned, p)
+		}
+	}
+	projects = owned
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()